@@ -0,0 +1,99 @@
+package graphml
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// AnonymizeOptions is a policy for Anonymize.
+type AnonymizeOptions struct {
+	// DropKeys removes keys with given attribute names or IDs, together with their data.
+	DropKeys []string
+	// HashIDs replaces IDs of graphs, nodes and edges with pseudonyms derived from hashes of the original IDs.
+	// The same ID always gets the same pseudonym, thus edges still reference the right nodes.
+	HashIDs bool
+	// Salt is mixed into hashes of IDs. Without a salt, short IDs can be recovered by brute force.
+	Salt string
+	// StripLabels removes data of keys with string values (including keys without a type) and yFiles data,
+	// which contains labels of nodes and edges. Unrecognized "label" attributes of elements and top-level
+	// comments are removed as well.
+	StripLabels bool
+}
+
+// Anonymize removes or pseudonymizes identifying information in the document according to the policy.
+func (doc *Document) Anonymize(opt AnonymizeOptions) {
+	drop := make(map[string]bool)
+	for _, name := range opt.DropKeys {
+		drop[name] = true
+	}
+	removed := make(map[string]bool)
+	keys := doc.Keys[:0]
+	for _, k := range doc.Keys {
+		if drop[k.Name] || drop[k.ID] ||
+			(opt.StripLabels && (k.YFilesType != "" || k.Type == "" || k.Type == "string")) {
+			removed[k.ID] = true
+			continue
+		}
+		keys = append(keys, k)
+	}
+	doc.Keys = keys
+	if opt.StripLabels {
+		doc.Comments = nil
+	}
+	hash := func(prefix, id string) string {
+		if !opt.HashIDs || id == "" {
+			return id
+		}
+		h := sha256.Sum256([]byte(opt.Salt + id))
+		return prefix + hex.EncodeToString(h[:8])
+	}
+	scrub := func(o *ExtObject, prefix string) {
+		o.ID = hash(prefix, o.ID)
+		if len(removed) != 0 {
+			data := o.Data[:0]
+			for _, d := range o.Data {
+				if !removed[d.Key] {
+					data = append(data, d)
+				}
+			}
+			o.Data = data
+		}
+		if opt.StripLabels {
+			attrs := o.Unrecognized[:0]
+			for _, a := range o.Unrecognized {
+				if a.Name.Local != "label" {
+					attrs = append(attrs, a)
+				}
+			}
+			o.Unrecognized = attrs
+		}
+	}
+	if len(removed) != 0 {
+		data := doc.Data[:0]
+		for _, d := range doc.Data {
+			if !removed[d.Key] {
+				data = append(data, d)
+			}
+		}
+		doc.Data = data
+	}
+	var visit func(graphs []Graph)
+	visit = func(graphs []Graph) {
+		for i := range graphs {
+			g := &graphs[i]
+			scrub(&g.ExtObject, "g")
+			for j := range g.Nodes {
+				n := &g.Nodes[j]
+				scrub(&n.ExtObject, "n")
+				visit(n.Graphs)
+			}
+			for j := range g.Edges {
+				e := &g.Edges[j]
+				scrub(&e.ExtObject, "e")
+				e.Source = hash("n", e.Source)
+				e.Target = hash("n", e.Target)
+			}
+		}
+	}
+	visit(doc.Graphs)
+}
@@ -0,0 +1,74 @@
+package graphml
+
+const (
+	// SchemaLocation is a location of the official GraphML schema.
+	SchemaLocation = "http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd"
+	// XSINamespace is a namespace of XML Schema instance attributes, such as xsi:schemaLocation.
+	XSINamespace = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// Attr returns a value of an attribute of the root element. Attributes without a prefix have an empty namespace;
+// namespace declarations have "xmlns" namespace (or an empty namespace and "xmlns" name for the default namespace).
+func (doc *Document) Attr(ns, name string) (string, bool) {
+	return findAttr(doc.Attrs, ns, name)
+}
+
+// SetAttr sets a value of an attribute of the root element, replacing an existing value. See Attr for details.
+func (doc *Document) SetAttr(ns, name, value string) {
+	for i, a := range doc.Attrs {
+		if a.Name.Space == ns && a.Name.Local == name {
+			doc.Attrs[i].Value = value
+			return
+		}
+	}
+	doc.Attrs = append(doc.Attrs, newAttr(ns, name, value))
+}
+
+// RemoveAttr removes an attribute of the root element. It reports if the attribute was set.
+func (doc *Document) RemoveAttr(ns, name string) bool {
+	for i, a := range doc.Attrs {
+		if a.Name.Space == ns && a.Name.Local == name {
+			doc.Attrs = append(doc.Attrs[:i], doc.Attrs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// EnsureNamespace declares the GraphML namespace as the default namespace of the document, replacing any other
+// default namespace. Documents without it can only be read by tools that tolerate missing namespaces.
+func (doc *Document) EnsureNamespace() {
+	doc.SetAttr("", "xmlns", Namespace)
+}
+
+// NamespacePrefix returns a prefix declared for the namespace on the root element, if any.
+func (doc *Document) NamespacePrefix(ns string) (string, bool) {
+	for _, a := range doc.Attrs {
+		if a.Name.Space == "xmlns" && a.Value == ns {
+			return a.Name.Local, true
+		}
+	}
+	return "", false
+}
+
+// DeclareNamespace declares a namespace prefix on the root element, replacing an existing declaration
+// of the same prefix.
+func (doc *Document) DeclareNamespace(prefix, ns string) {
+	doc.SetAttr("xmlns", prefix, ns)
+}
+
+// SetSchemaLocation sets the xsi:schemaLocation attribute of the document to point to a given GraphML schema,
+// or to the official schema (see SchemaLocation) if loc is empty. It also declares the GraphML namespace
+// and the "xsi" prefix, if they are not declared yet.
+func (doc *Document) SetSchemaLocation(loc string) {
+	if loc == "" {
+		loc = SchemaLocation
+	}
+	if ns, ok := doc.Attr("", "xmlns"); !ok || ns != Namespace {
+		doc.EnsureNamespace()
+	}
+	if _, ok := doc.NamespacePrefix(XSINamespace); !ok {
+		doc.DeclareNamespace("xsi", XSINamespace)
+	}
+	doc.SetAttr(XSINamespace, "schemaLocation", Namespace+" "+loc)
+}
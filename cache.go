@@ -0,0 +1,113 @@
+//go:build !tinygo && !graphml_noreflect
+
+package graphml
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// cacheMagic is written at the beginning of every cache file.
+// The last byte is a version of the format and should be bumped each time the Document structure changes.
+const cacheMagic = "GMLC\x02"
+
+// ErrCacheVersion is returned by LoadCache when the stream was not written by SaveCache or was written by an incompatible version.
+var ErrCacheVersion = errors.New("graphml: unsupported cache format")
+
+func init() {
+	// register all token types that may appear in Data
+	gob.Register(xml.StartElement{})
+	gob.Register(xml.EndElement{})
+	gob.Register(xml.CharData{})
+	gob.Register(xml.Comment{})
+	gob.Register(xml.ProcInst{})
+	gob.Register(xml.Directive{})
+}
+
+// cacheDoc is a form of the document stored in the cache.
+type cacheDoc struct {
+	Doc *Document
+	// EmptyDefaults are indexes of keys with an empty default value. Gob doesn't distinguish empty slices
+	// from nil ones, thus such keys would otherwise lose their default.
+	EmptyDefaults []int
+}
+
+// SaveCache writes a document to the stream in a compact binary format.
+//
+// The cache is intended only for fast reloading of documents by the same application (see LoadCache).
+// Use Encode to write a GraphML document that can be consumed by other tools.
+func SaveCache(w io.Writer, doc *Document) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(cacheMagic); err != nil {
+		return err
+	}
+	c := cacheDoc{Doc: doc}
+	for i := range doc.Keys {
+		if k := &doc.Keys[i]; k.Default != nil && len(k.Default) == 0 {
+			c.EmptyDefaults = append(c.EmptyDefaults, i)
+		}
+	}
+	if err := gob.NewEncoder(bw).Encode(&c); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// LoadCache reads a document previously written by SaveCache.
+// Typed values of data elements are decoded with codecs registered at the time of the call (see RegisterCodec).
+func LoadCache(r io.Reader) (*Document, error) {
+	br := bufio.NewReader(r)
+	var magic [len(cacheMagic)]byte
+	if _, err := io.ReadFull(br, magic[:]); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrCacheVersion
+	} else if err != nil {
+		return nil, err
+	}
+	if string(magic[:]) != cacheMagic {
+		return nil, ErrCacheVersion
+	}
+	var c cacheDoc
+	if err := gob.NewDecoder(br).Decode(&c); err != nil {
+		return nil, fmt.Errorf("graphml: cannot decode cache: %w", err)
+	}
+	doc := c.Doc
+	if doc == nil {
+		doc = new(Document)
+	}
+	for _, i := range c.EmptyDefaults {
+		if i < 0 || i >= len(doc.Keys) {
+			return nil, fmt.Errorf("graphml: cannot decode cache: invalid key index %d", i)
+		}
+		doc.Keys[i].Default = []xml.Token{}
+	}
+	// typed values are not stored, decode them again the same way as the decoder does
+	decodeValues(doc.Data)
+	decodeGraphValues(doc.Graphs)
+	return doc, nil
+}
+
+// decodeValues decodes typed values of data elements with registered codecs. As in the decoder,
+// the content of elements that fail to decode is kept as raw tokens.
+func decodeValues(data []Data) {
+	for i := range data {
+		_ = data[i].DecodeValue()
+	}
+}
+
+func decodeGraphValues(graphs []Graph) {
+	for i := range graphs {
+		g := &graphs[i]
+		decodeValues(g.Data)
+		for j := range g.Nodes {
+			decodeValues(g.Nodes[j].Data)
+			decodeGraphValues(g.Nodes[j].Graphs)
+		}
+		for j := range g.Edges {
+			decodeValues(g.Edges[j].Data)
+		}
+	}
+}
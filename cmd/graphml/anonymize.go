@@ -0,0 +1,54 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+
+	"github.com/dennwc/graphml"
+)
+
+var cmdAnonymize = &command{
+	name:  "anonymize",
+	usage: "[file]",
+	short: "remove identifying information from a GraphML file",
+	run:   runAnonymize,
+}
+
+func runAnonymize(fs *flag.FlagSet, args []string) error {
+	var (
+		out      = fs.String("o", "-", "output file")
+		compress = fs.Bool("z", false, "compress the output with gzip (implied by .gz extension)")
+		hashIDs  = fs.Bool("hash-ids", true, "replace element IDs with hashes")
+		salt     = fs.String("salt", "", "salt for hashed IDs (random by default, set it to get the same IDs on each run)")
+		strip    = fs.Bool("strip-labels", true, "remove string attributes, labels and comments")
+		drop     listFlag
+	)
+	fs.Var(&drop, "drop-keys", "comma-separated list of attributes to remove")
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		return errUsage
+	}
+	in := "-"
+	if fs.NArg() == 1 {
+		in = fs.Arg(0)
+	}
+	if *hashIDs && *salt == "" {
+		var b [16]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return err
+		}
+		*salt = hex.EncodeToString(b[:])
+	}
+	doc, err := readDocument(in, nil)
+	if err != nil {
+		return err
+	}
+	doc.Anonymize(graphml.AnonymizeOptions{
+		DropKeys:    drop,
+		HashIDs:     *hashIDs,
+		Salt:        *salt,
+		StripLabels: *strip,
+	})
+	return writeDocument(*out, doc, *compress, nil)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/dennwc/graphml"
+)
+
+var cmdCat = &command{
+	name:  "cat",
+	usage: "file [file ...]",
+	short: "concatenate GraphML files into one document with separate graphs",
+	run:   runCat,
+}
+
+func runCat(fs *flag.FlagSet, args []string) error {
+	var (
+		out      = fs.String("o", "-", "output file")
+		compress = fs.Bool("z", false, "compress the output with gzip (implied by .gz extension)")
+	)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	docs := make([]*graphml.Document, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		doc, err := readDocument(path, nil)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+	doc, err := graphml.Merge(docs, &graphml.MergeOptions{OnConflict: graphml.MergeRename, SeparateGraphs: true})
+	if err != nil {
+		return err
+	}
+	return writeDocument(*out, doc, *compress, nil)
+}
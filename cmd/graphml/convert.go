@@ -0,0 +1,266 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dennwc/graphml"
+	"github.com/dennwc/graphml/convert"
+)
+
+var cmdConvert = &command{
+	name:  "convert",
+	usage: "input output",
+	short: "convert graphs between GraphML and other formats",
+	run:   runConvert,
+}
+
+// format is a graph file format supported by the convert command.
+type format struct {
+	read  func(r io.Reader) (*graphml.Document, error)
+	write func(w io.Writer, doc *graphml.Document) error
+	// writeTo is used instead of write by formats that write multiple files into a directory or a file path.
+	writeTo func(path string, doc *graphml.Document) error
+}
+
+var formats = map[string]format{
+	"graphml": {
+		read:  graphml.Decode,
+		write: graphml.Encode,
+	},
+	"dot": {
+		read:  convert.FromDOT,
+		write: func(w io.Writer, doc *graphml.Document) error { return convert.ToDOT(w, doc, nil) },
+	},
+	"gexf":      {read: convert.FromGEXF, write: convert.ToGEXF},
+	"gml":       {read: convert.FromGML, write: convert.ToGML},
+	"pajek":     {read: convert.FromPajek, write: convert.ToPajek},
+	"tgf":       {read: convert.FromTGF, write: convert.ToTGF},
+	"jgf":       {read: convert.FromJGF, write: convert.ToJGF},
+	"yaml":      {read: convert.FromYAML, write: convert.ToYAML},
+	"gdf":       {read: convert.FromGDF, write: convert.ToGDF},
+	"graph6":    {read: convert.FromGraph6, write: convert.ToGraph6},
+	"sparse6":   {read: convert.FromGraph6, write: convert.ToSparse6},
+	"mtx":       {read: convert.FromMatrixMarket, write: convert.ToMatrixMarket},
+	"dimacs":    {read: convert.FromDIMACS, write: func(w io.Writer, doc *graphml.Document) error { return convert.ToDIMACS(w, doc, convert.DIMACSEdge) }},
+	"leda":      {read: func(r io.Reader) (*graphml.Document, error) { return convert.FromLEDA(r, nil) }, write: func(w io.Writer, doc *graphml.Document) error { return convert.ToLEDA(w, doc, nil) }},
+	"dl":        {read: convert.FromDL, write: func(w io.Writer, doc *graphml.Document) error { return convert.ToDL(w, doc, convert.DLEdgeList) }},
+	"cytoscape": {write: convert.ToCytoscapeJSON},
+	"d3":        {write: func(w io.Writer, doc *graphml.Document) error { return convert.ToD3(w, doc, nil) }},
+	"graphson":  {write: func(w io.Writer, doc *graphml.Document) error { return convert.ToGraphSON(w, doc, convert.GraphSONv3) }},
+	"d2":        {write: convert.ToD2},
+	"plantuml":  {write: func(w io.Writer, doc *graphml.Document) error { return convert.ToPlantUML(w, doc, nil) }},
+	"sql":       {write: func(w io.Writer, doc *graphml.Document) error { return convert.ToSQL(w, doc, convert.SQLPostgres) }},
+	"svg":       {write: func(w io.Writer, doc *graphml.Document) error { return convert.RenderSVG(w, doc, nil) }},
+	"xlsx":      {writeTo: convert.ToXLSX},
+	"neo4j":     {writeTo: convert.ToNeo4jCSV},
+	"parquet":   {writeTo: convert.ToParquet},
+	// csv is handled separately, since it uses two files
+	"csv": {},
+}
+
+// formatExts maps file extensions to format names.
+var formatExts = map[string]string{
+	".graphml": "graphml",
+	".xml":     "graphml",
+	".dot":     "dot",
+	".gv":      "dot",
+	".gexf":    "gexf",
+	".gml":     "gml",
+	".net":     "pajek",
+	".tgf":     "tgf",
+	".json":    "jgf",
+	".yaml":    "yaml",
+	".yml":     "yaml",
+	".gdf":     "gdf",
+	".g6":      "graph6",
+	".s6":      "sparse6",
+	".mtx":     "mtx",
+	".col":     "dimacs",
+	".gw":      "leda",
+	".dl":      "dl",
+	".d2":      "d2",
+	".puml":    "plantuml",
+	".sql":     "sql",
+	".svg":     "svg",
+	".xlsx":    "xlsx",
+	".csv":     "csv",
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// detectFormat returns a format set by a flag, or a format derived from the file extension.
+func detectFormat(name, path string) (string, error) {
+	if name == "" {
+		name = formatExts[baseExt(path)]
+		if name == "" {
+			return "", fmt.Errorf("cannot detect format of %q, set it explicitly", path)
+		}
+	}
+	if _, ok := formats[name]; !ok {
+		return "", fmt.Errorf("unknown format %q, supported formats: %s", name, formatNames())
+	}
+	return name, nil
+}
+
+func runConvert(fs *flag.FlagSet, args []string) error {
+	var (
+		from     = fs.String("from", "", "input format (detected from the file extension by default)")
+		to       = fs.String("to", "", "output format (detected from the file extension by default)")
+		compress = fs.Bool("z", false, "compress the output with gzip (implied by .gz extension)")
+		edges    = fs.String("edges", "", "edge table for CSV input or output (the main file is the node table, edges are in name_edges.csv by default)")
+		attrs    = mapFlag{}
+	)
+	fs.Var(attrs, "attr", "rename attributes, as old=new pairs (can be repeated)")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return errUsage
+	}
+	in, out := fs.Arg(0), fs.Arg(1)
+	inFmt, err := detectFormat(*from, in)
+	if err != nil {
+		return err
+	}
+	outFmt, err := detectFormat(*to, out)
+	if err != nil {
+		return err
+	}
+
+	var doc *graphml.Document
+	switch inFmt {
+	case "graphml":
+		doc, err = readDocument(in, nil)
+	case "csv":
+		doc, err = readCSV(in, *edges, attrs)
+	default:
+		f := formats[inFmt]
+		if f.read == nil {
+			return fmt.Errorf("format %q cannot be used as input", inFmt)
+		}
+		var r io.ReadCloser
+		r, err = openInput(in)
+		if err != nil {
+			return err
+		}
+		doc, err = f.read(r)
+		r.Close()
+	}
+	if err != nil {
+		return err
+	}
+	if inFmt != "csv" {
+		renameAttrs(doc, attrs)
+	}
+
+	switch outFmt {
+	case "graphml":
+		return writeDocument(out, doc, *compress, nil)
+	case "csv":
+		return writeCSV(out, *edges, doc, *compress)
+	}
+	f := formats[outFmt]
+	if f.writeTo != nil {
+		return f.writeTo(out, doc)
+	}
+	w, err := createOutput(out, *compress)
+	if err != nil {
+		return err
+	}
+	if err = f.write(w, doc); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// renameAttrs renames attributes of the document according to the mapping of old names to new ones.
+func renameAttrs(doc *graphml.Document, names map[string]string) {
+	if len(names) == 0 {
+		return
+	}
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if name, ok := names[k.Name]; ok {
+			k.Name = name
+		}
+	}
+}
+
+// edgeTable returns a default path of the edge table for a given node table: "name_edges.csv" for "name.csv".
+// It returns an empty string for stdin and stdout.
+func edgeTable(nodes string) string {
+	if nodes == "-" {
+		return ""
+	}
+	gz := ""
+	if isGzip(nodes) {
+		gz = nodes[len(nodes)-len(extGz):]
+		nodes = nodes[:len(nodes)-len(extGz)]
+	}
+	ext := filepath.Ext(nodes)
+	return strings.TrimSuffix(nodes, ext) + "_edges" + ext + gz
+}
+
+func readCSV(nodes, edges string, names map[string]string) (*graphml.Document, error) {
+	if edges == "" {
+		// the edge table is optional for input
+		if path := edgeTable(nodes); path != "" {
+			if _, err := os.Stat(path); err == nil {
+				edges = path
+			}
+		}
+	}
+	nr, err := openInput(nodes)
+	if err != nil {
+		return nil, err
+	}
+	defer nr.Close()
+	var er io.Reader
+	if edges != "" {
+		f, err := openInput(edges)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		er = f
+	}
+	return convert.FromCSV(nr, er, &convert.CSVMapping{Names: names})
+}
+
+func writeCSV(nodes, edges string, doc *graphml.Document, compress bool) error {
+	if edges == "" {
+		edges = edgeTable(nodes)
+	}
+	if edges == "" {
+		return fmt.Errorf("edge table must be set for CSV output to stdout")
+	}
+	nw, err := createOutput(nodes, compress)
+	if err != nil {
+		return err
+	}
+	defer nw.Close()
+	ew, err := createOutput(edges, compress)
+	if err != nil {
+		return err
+	}
+	defer ew.Close()
+	if err = convert.ToCSV(nw, ew, doc, nil); err != nil {
+		return err
+	}
+	if err = nw.Close(); err != nil {
+		return err
+	}
+	return ew.Close()
+}
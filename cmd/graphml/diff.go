@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+var cmdDiff = &command{
+	name:  "diff",
+	usage: "old new",
+	short: "show differences between two GraphML files",
+	run:   runDiff,
+}
+
+// patchOp is a JSON Patch (RFC 6902) operation.
+type patchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonPatch converts changes to JSON Patch operations. Elements are addressed as /<element>s/<id>,
+// and their attributes as /<element>s/<id>/<attr>.
+func jsonPatch(changes []graphml.Change) []patchOp {
+	out := make([]patchOp, 0, len(changes))
+	for _, c := range changes {
+		p := patchOp{Path: "/" + c.Element + "s/" + pointerEscaper.Replace(c.ID)}
+		if c.Attr != "" {
+			p.Path += "/" + pointerEscaper.Replace(c.Attr)
+		}
+		switch c.Op {
+		case graphml.ChangeAdd:
+			p.Op = "add"
+			if c.Attr != "" {
+				p.Value = c.New
+			} else if c.Attrs != nil {
+				p.Value = c.Attrs
+			} else {
+				p.Value = map[string]string{}
+			}
+		case graphml.ChangeRemove:
+			p.Op = "remove"
+		default:
+			p.Op = "replace"
+			p.Value = c.New
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func runDiff(fs *flag.FlagSet, args []string) error {
+	asJSON := fs.Bool("json", false, "print differences as a JSON Patch")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return errUsage
+	}
+	a, err := readDocument(fs.Arg(0), nil)
+	if err != nil {
+		return err
+	}
+	b, err := readDocument(fs.Arg(1), nil)
+	if err != nil {
+		return err
+	}
+	changes := graphml.Diff(a, b)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(jsonPatch(changes)); err != nil {
+			return err
+		}
+	} else {
+		if len(changes) != 0 {
+			fmt.Printf("--- %s\n+++ %s\n", fs.Arg(0), fs.Arg(1))
+		}
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+	}
+	// same as diff(1), exit with a non-zero code if files are different
+	if len(changes) != 0 {
+		return errSilent
+	}
+	return nil
+}
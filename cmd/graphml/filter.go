@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/dennwc/graphml"
+)
+
+var cmdFilter = &command{
+	name:  "filter",
+	usage: "[file]",
+	short: "extract nodes and edges matching a selector",
+	run:   runFilter,
+}
+
+// keepAttrs removes keys with attribute names (or IDs) not in the list, together with their data.
+func keepAttrs(doc *graphml.Document, names []string) {
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+	removed := make(map[string]bool)
+	keys := doc.Keys[:0]
+	for _, k := range doc.Keys {
+		if keep[k.Name] || keep[k.ID] {
+			keys = append(keys, k)
+		} else {
+			removed[k.ID] = true
+		}
+	}
+	doc.Keys = keys
+	filter := func(data []graphml.Data) []graphml.Data {
+		out := data[:0]
+		for _, d := range data {
+			if !removed[d.Key] {
+				out = append(out, d)
+			}
+		}
+		return out
+	}
+	doc.Data = filter(doc.Data)
+	var visit func(graphs []graphml.Graph)
+	visit = func(graphs []graphml.Graph) {
+		for i := range graphs {
+			g := &graphs[i]
+			g.Data = filter(g.Data)
+			for j := range g.Nodes {
+				n := &g.Nodes[j]
+				n.Data = filter(n.Data)
+				visit(n.Graphs)
+			}
+			for j := range g.Edges {
+				e := &g.Edges[j]
+				e.Data = filter(e.Data)
+			}
+		}
+	}
+	visit(doc.Graphs)
+}
+
+func runFilter(fs *flag.FlagSet, args []string) error {
+	var (
+		nodes    = fs.String("nodes", "", "selector for nodes to keep, for example 'label~=\"auth.*\"'")
+		edges    = fs.String("edges", "", "selector for edges to keep, for example 'weight>=0.5'")
+		out      = fs.String("o", "-", "output file")
+		compress = fs.Bool("z", false, "compress the output with gzip (implied by .gz extension)")
+		attrs    listFlag
+	)
+	fs.Var(&attrs, "keep-attrs", "comma-separated list of attributes to keep (all by default)")
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		return errUsage
+	}
+	in := "-"
+	if fs.NArg() == 1 {
+		in = fs.Arg(0)
+	}
+	var ns, es *graphml.Selector
+	var err error
+	if *nodes != "" {
+		if ns, err = graphml.ParseSelector(*nodes); err != nil {
+			return err
+		}
+	}
+	if *edges != "" {
+		if es, err = graphml.ParseSelector(*edges); err != nil {
+			return err
+		}
+	}
+	doc, err := readDocument(in, nil)
+	if err != nil {
+		return err
+	}
+	doc = doc.Filter(ns, es)
+	if len(attrs) != 0 {
+		keepAttrs(doc, attrs)
+	}
+	return writeDocument(*out, doc, *compress, nil)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dennwc/graphml"
+)
+
+var cmdFmt = &command{
+	name:  "fmt",
+	usage: "[file ...]",
+	short: "reformat GraphML files in a canonical way",
+	run:   runFmt,
+}
+
+// kindOrder is an order of keys in formatted documents. Keys without a kind apply to all elements.
+var kindOrder = map[graphml.Kind]int{
+	"":                    0,
+	graphml.KindAll:       0,
+	graphml.KindGraphML:   1,
+	graphml.KindGraph:     2,
+	graphml.KindNode:      3,
+	graphml.KindEdge:      4,
+	graphml.KindHyperEdge: 5,
+	graphml.KindPort:      6,
+	graphml.KindEndpoint:  7,
+}
+
+// kindRank returns a position of keys of a given kind in formatted documents. Unknown kinds go last.
+func kindRank(kind graphml.Kind) int {
+	if r, ok := kindOrder[kind]; ok {
+		return r
+	}
+	return len(kindOrder)
+}
+
+// sortKeys orders keys by the kind of elements they apply to, then by attribute name and ID.
+// Keys for all elements go first.
+func sortKeys(keys []graphml.Key) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := &keys[i], &keys[j]
+		if ka, kb := kindRank(a.For), kindRank(b.For); ka != kb {
+			return ka < kb
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+}
+
+// formatDocument decodes a document and writes it back in a canonical form:
+// keys are sorted, elements are indented and attributes are sorted by name.
+func formatDocument(r io.Reader) ([]byte, error) {
+	doc, err := graphml.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	sortKeys(doc.Keys)
+	var buf bytes.Buffer
+	if err = graphml.EncodeWith(&buf, doc, &graphml.Options{Profile: graphml.ProfileYEd}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func runFmt(fs *flag.FlagSet, args []string) error {
+	var (
+		write = fs.Bool("w", false, "write the result to the source file instead of stdout")
+		list  = fs.Bool("l", false, "list files whose formatting differs")
+	)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		if *write {
+			return fmt.Errorf("cannot use -w with stdin")
+		}
+		out, err := formatDocument(os.Stdin)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	failed := false
+	for _, path := range fs.Args() {
+		if err := fmtFile(path, *write, *list); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+		}
+	}
+	if failed {
+		return errSilent
+	}
+	return nil
+}
+
+func fmtFile(path string, write, list bool) error {
+	r, err := openInput(path)
+	if err != nil {
+		return err
+	}
+	src, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	out, err := formatDocument(bytes.NewReader(src))
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	changed := !bytes.Equal(src, out)
+	if list && changed {
+		fmt.Println(path)
+	}
+	if write {
+		if changed {
+			return replaceFile(path, out)
+		}
+		return nil
+	}
+	if !list {
+		_, err = os.Stdout.Write(out)
+	}
+	return err
+}
+
+// replaceFile atomically replaces the content of the file. The data is compressed if the file has .gz extension.
+func replaceFile(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	var w io.WriteCloser = f
+	if isGzip(path) {
+		w = &gzipWriter{Writer: gzip.NewWriter(f), w: f}
+	}
+	_, err = w.Write(data)
+	if err2 := w.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		if fi, err2 := os.Stat(path); err2 == nil {
+			err = os.Chmod(tmp, fi.Mode())
+		}
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+const extGz = ".gz"
+
+// isGzip checks if the file name has a gzip extension.
+func isGzip(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), extGz)
+}
+
+// openInput opens a file for reading, or returns stdin for "-". Files with .gz extension are decompressed.
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !isGzip(path) {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &gzipReader{Reader: zr, f: f}, nil
+}
+
+type gzipReader struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (r *gzipReader) Close() error {
+	err := r.Reader.Close()
+	if err2 := r.f.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// createOutput creates a file for writing, or returns stdout for "-".
+// The output is compressed if compress is set or the file has .gz extension.
+func createOutput(path string, compress bool) (io.WriteCloser, error) {
+	var w io.WriteCloser
+	if path == "-" {
+		w = nopWriteCloser{os.Stdout}
+	} else {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		w = f
+	}
+	if !compress && !isGzip(path) {
+		return w, nil
+	}
+	return &gzipWriter{Writer: gzip.NewWriter(w), w: w}, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+type gzipWriter struct {
+	*gzip.Writer
+	w io.WriteCloser
+}
+
+func (w *gzipWriter) Close() error {
+	err := w.Writer.Close()
+	if err2 := w.w.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// readDocument decodes a GraphML document from a file, or from stdin for "-".
+func readDocument(path string, opt *graphml.Options) (*graphml.Document, error) {
+	r, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	doc, err := graphml.DecodeWith(r, opt)
+	if err != nil && path != "-" {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return doc, err
+}
+
+// writeDocument encodes a GraphML document to a file, or to stdout for "-".
+func writeDocument(path string, doc *graphml.Document, compress bool, opt *graphml.Options) error {
+	w, err := createOutput(path, compress)
+	if err != nil {
+		return err
+	}
+	if err = graphml.EncodeWith(w, doc, opt); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// baseExt returns a lower-case extension of the file, ignoring the gzip extension.
+func baseExt(path string) string {
+	path = strings.ToLower(path)
+	if isGzip(path) {
+		path = strings.TrimSuffix(path, extGz)
+	}
+	return filepath.Ext(path)
+}
+
+// mapFlag is a repeatable flag with key=value pairs.
+type mapFlag map[string]string
+
+func (m mapFlag) String() string {
+	var parts []string
+	for k, v := range m {
+		parts = append(parts, k+"="+v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (m mapFlag) Set(s string) error {
+	for _, kv := range strings.Split(s, ",") {
+		i := strings.IndexByte(kv, '=')
+		if i <= 0 {
+			return fmt.Errorf("expected key=value, got %q", kv)
+		}
+		m[kv[:i]] = kv[i+1:]
+	}
+	return nil
+}
+
+// listFlag is a repeatable flag with comma-separated values.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
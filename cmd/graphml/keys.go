@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dennwc/graphml"
+)
+
+var cmdKeys = &command{
+	name:  "keys",
+	usage: "[file]",
+	short: "list or migrate keys of a GraphML file",
+	run:   runKeys,
+}
+
+// defaultText returns a text of the key default value, or the content encoded as XML.
+func defaultText(toks []xml.Token) string {
+	if toks == nil {
+		return "-"
+	}
+	var sb strings.Builder
+	enc := xml.NewEncoder(&sb)
+	for _, t := range toks {
+		if err := enc.EncodeToken(t); err != nil {
+			return "?"
+		}
+	}
+	enc.Flush()
+	return sb.String()
+}
+
+func printKeys(doc *graphml.Document) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tFOR\tNAME\tTYPE\tDEFAULT\tUSED\tSAMPLES")
+	for _, u := range doc.KeyUsage() {
+		k := u.Key
+		if k == nil {
+			fmt.Fprintf(tw, "%s\t-\t(undeclared)\t-\t-\t%d\t%s\n", u.ID, u.Total(), samplesText(u.Samples))
+			continue
+		}
+		name, typ := k.Name, k.Type
+		if name == "" && k.YFilesType != "" {
+			name = "(yfiles " + k.YFilesType + ")"
+		}
+		if name == "" {
+			name = "-"
+		}
+		if typ == "" {
+			typ = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", k.ID, k.For, name, typ, defaultText(k.Default), u.Total(), samplesText(u.Samples))
+	}
+	return tw.Flush()
+}
+
+// samplesText returns a short list of quoted sample values.
+func samplesText(samples []string) string {
+	if len(samples) == 0 {
+		return "-"
+	}
+	out := make([]string, 0, len(samples))
+	for _, s := range samples {
+		if len(s) > 20 {
+			s = s[:17] + "..."
+		}
+		out = append(out, strconv.Quote(s))
+	}
+	return strings.Join(out, ", ")
+}
+
+func runKeys(fs *flag.FlagSet, args []string) error {
+	var (
+		kind     = fs.String("for", "", "kind of elements for --rename and --retype (all kinds by default)")
+		write    = fs.Bool("w", false, "write the result to the source file")
+		out      = fs.String("o", "", "write the result to a given file")
+		compress = fs.Bool("z", false, "compress the output with gzip (implied by .gz extension)")
+		rename   = mapFlag{}
+		retype   = mapFlag{}
+	)
+	fs.Var(rename, "rename", "rename attributes, as old=new pairs")
+	fs.Var(retype, "retype", "change types of attributes and convert their values, as name=type pairs")
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		return errUsage
+	}
+	in := "-"
+	if fs.NArg() == 1 {
+		in = fs.Arg(0)
+	}
+	doc, err := readDocument(in, nil)
+	if err != nil {
+		return err
+	}
+	if len(rename) == 0 && len(retype) == 0 {
+		return printKeys(doc)
+	}
+	var k graphml.Kind
+	if *kind != "" {
+		if k, err = graphml.ParseKind(*kind); err != nil {
+			return err
+		}
+	}
+	for name, s := range retype {
+		typ, err := graphml.ParseAttrType(s)
+		if err != nil {
+			return err
+		}
+		n, err := doc.RetypeAttr(k, name, typ)
+		if err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("attribute %q is not declared", name)
+		}
+	}
+	for from, to := range rename {
+		n, err := doc.RenameAttr(k, from, to)
+		if err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("attribute %q is not declared", from)
+		}
+	}
+	if *write {
+		if in == "-" {
+			return fmt.Errorf("cannot use -w with stdin")
+		}
+		*out = in
+	} else if *out == "" {
+		*out = "-"
+	}
+	return writeDocument(*out, doc, *compress, nil)
+}
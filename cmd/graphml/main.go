@@ -0,0 +1,111 @@
+// Command graphml is a command-line tool for working with GraphML files.
+//
+// Usage:
+//
+//	graphml <command> [flags] [arguments]
+//
+// Commands:
+//
+//	convert   convert graphs between GraphML and other formats
+//	validate  check GraphML files for errors
+//	fmt       reformat GraphML files in a canonical way
+//	stats     print statistics of GraphML files
+//	merge     merge multiple GraphML files into one
+//	diff      show differences between two GraphML files
+//	filter    extract nodes and edges matching a selector
+//	anonymize remove identifying information from a GraphML file
+//	split     write each graph or connected component to a separate file
+//	cat       concatenate GraphML files into one document with separate graphs
+//	render    render a GraphML file to SVG or PNG
+//	keys      list or migrate keys of a GraphML file
+//
+// Run "graphml <command> -h" for details about a command. Compressed GraphML files (.graphml.gz)
+// are supported by all commands, and "-" can be used to read from stdin or to write to stdout.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// command is a subcommand of the tool.
+type command struct {
+	name  string
+	usage string // arguments of the command
+	short string // one-line description
+	run   func(fs *flag.FlagSet, args []string) error
+}
+
+var commands = []*command{
+	cmdConvert,
+	cmdValidate,
+	cmdFmt,
+	cmdStats,
+	cmdMerge,
+	cmdDiff,
+	cmdFilter,
+	cmdAnonymize,
+	cmdSplit,
+	cmdCat,
+	cmdRender,
+	cmdKeys,
+}
+
+// errUsage is returned by commands when arguments are invalid. It makes the tool print usage of the command.
+var errUsage = errors.New("invalid arguments")
+
+// errSilent is returned by commands that already reported the problem, but must exit with a non-zero code.
+var errSilent = errors.New("")
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: graphml <command> [flags] [arguments]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.short)
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run runs a command with given arguments and returns an exit code: 0 on success, 1 if the command failed
+// and 2 if arguments are invalid.
+func run(args []string) int {
+	if len(args) < 1 {
+		usage()
+		return 2
+	}
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage()
+		return 0
+	}
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		fs := flag.NewFlagSet(c.name, flag.ExitOnError)
+		fs.Usage = func() {
+			fmt.Fprintf(os.Stderr, "usage: graphml %s [flags] %s\n\n%s\n\nflags:\n", c.name, c.usage, c.short)
+			fs.PrintDefaults()
+		}
+		err := c.run(fs, args[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, errUsage):
+			fs.Usage()
+			return 2
+		case errors.Is(err, errSilent):
+			return 1
+		default:
+			fmt.Fprintln(os.Stderr, "graphml "+c.name+":", err)
+			return 1
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command: %q\n", name)
+	usage()
+	return 2
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dennwc/graphml"
+)
+
+const testDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="label" attr.type="string"/>
+<key id="d1" for="edge" attr.name="weight" attr.type="double"/>
+<graph id="G" edgedefault="directed">
+	<node id="n0"><data key="d0">A</data></node>
+	<node id="n1"><data key="d0">B</data></node>
+	<node id="n2"/>
+	<edge id="e0" source="n0" target="n1"><data key="d1">1.5</data></edge>
+	<edge id="e1" source="n1" target="n2"/>
+</graph>
+</graphml>`
+
+// writeTemp writes a file to a temporary directory of the test and returns its path.
+func writeTemp(t testing.TB, name, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+	return path
+}
+
+// readStatsOf decodes a GraphML file and returns its statistics.
+func readStatsOf(t testing.TB, path string) *graphml.Stats {
+	doc, err := readDocument(path, nil)
+	require.NoError(t, err)
+	return doc.Stats()
+}
+
+func TestRunExitCodes(t *testing.T) {
+	valid := writeTemp(t, "valid.graphml", testDoc)
+	require.Equal(t, 2, run(nil))
+	require.Equal(t, 2, run([]string{"unknown"}))
+	require.Equal(t, 0, run([]string{"help"}))
+	require.Equal(t, 2, run([]string{"validate"}))
+	require.Equal(t, 0, run([]string{"validate", valid}))
+	require.Equal(t, 1, run([]string{"validate", filepath.Join(t.TempDir(), "missing.graphml")}))
+	require.Equal(t, 1, run([]string{"convert", valid, filepath.Join(t.TempDir(), "out.unknown")}))
+}
+
+func TestValidate(t *testing.T) {
+	dangling := writeTemp(t, "dangling.graphml", `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<graph id="G" edgedefault="directed"><node id="n0"/><edge source="n0" target="n1"/></graph>
+</graphml>`)
+	// duplicate attribute names are reported as warnings, unused keys as infos
+	warnings := writeTemp(t, "warnings.graphml", `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="label" attr.type="string"/>
+<key id="d1" for="node" attr.name="label" attr.type="string"/>
+<key id="d2" for="edge" attr.name="weight" attr.type="double"/>
+<graph id="G" edgedefault="directed"><node id="n0"><data key="d0">a</data><data key="d1">b</data></node></graph>
+</graphml>`)
+
+	for _, c := range []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"dangling", []string{dangling}, 1},
+		{"dangling ignored", []string{"--ignore", "edge-endpoints", dangling}, 0},
+		{"dangling other rules", []string{"--rules", "unique-ids,unused-keys", dangling}, 0},
+		{"warnings", []string{warnings}, 0},
+		{"warnings strict", []string{"--strict", warnings}, 1},
+		{"warnings strict json", []string{"--strict", "--json", warnings}, 1},
+		{"infos strict", []string{"--strict", "--ignore", "duplicate-keys", warnings}, 0},
+		{"unknown rule", []string{"--rules", "no-such-rule", warnings}, 1},
+		{"multiple files", []string{warnings, dangling}, 1},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.code, run(append([]string{"validate"}, c.args...)))
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	in := writeTemp(t, "in.graphml", testDoc)
+	exp := readStatsOf(t, in)
+	for _, ext := range []string{".gml", ".dot", ".gexf", ".json", ".yaml", ".graphml.gz"} {
+		t.Run(ext, func(t *testing.T) {
+			dir := t.TempDir()
+			mid := filepath.Join(dir, "graph"+ext)
+			out := filepath.Join(dir, "out.graphml")
+			require.Equal(t, 0, run([]string{"convert", in, mid}))
+			require.Equal(t, 0, run([]string{"convert", mid, out}))
+			got := readStatsOf(t, out)
+			require.Equal(t, exp.Nodes, got.Nodes)
+			require.Equal(t, exp.Edges, got.Edges)
+		})
+	}
+}
+
+func TestConvertCSV(t *testing.T) {
+	in := writeTemp(t, "in.graphml", testDoc)
+	dir := t.TempDir()
+	nodes := filepath.Join(dir, "t.csv")
+	require.Equal(t, 0, run([]string{"convert", in, nodes}))
+	require.FileExists(t, nodes)
+	require.FileExists(t, filepath.Join(dir, "t_edges.csv"))
+
+	out := filepath.Join(dir, "out.graphml")
+	require.Equal(t, 0, run([]string{"convert", nodes, out}))
+	got := readStatsOf(t, out)
+	require.Equal(t, 3, got.Nodes)
+	require.Equal(t, 2, got.Edges)
+
+	// an explicit edge table
+	edges := filepath.Join(dir, "e.csv")
+	require.Equal(t, 0, run([]string{"convert", "-edges", edges, in, nodes}))
+	require.FileExists(t, edges)
+
+	// CSV cannot be written to stdout without an edge table
+	require.Equal(t, 1, run([]string{"convert", "-to", "csv", in, "-"}))
+	require.Equal(t, "", edgeTable("-"))
+	require.Equal(t, "dir/t_edges.csv.gz", edgeTable("dir/t.csv.gz"))
+}
+
+func TestKeys(t *testing.T) {
+	in := writeTemp(t, "in.graphml", testDoc)
+	out := filepath.Join(t.TempDir(), "out.graphml")
+	require.Equal(t, 0, run([]string{"keys", "-rename", "label=name", "-retype", "weight=float", "-o", out, in}))
+	doc, err := readDocument(out, nil)
+	require.NoError(t, err)
+	require.Equal(t, "name", doc.Keys[0].Name)
+	require.Equal(t, string(graphml.TypeFloat), doc.Keys[1].Type)
+
+	require.Equal(t, 1, run([]string{"keys", "-rename", "missing=x", "-o", out, in}))
+	require.Equal(t, 1, run([]string{"keys", "-retype", "weight=bool", "-o", out, in}))
+}
+
+func TestFmt(t *testing.T) {
+	path := writeTemp(t, "in.graphml", testDoc)
+	require.Equal(t, 0, run([]string{"fmt", "-w", path}))
+	first, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 0, run([]string{"fmt", "-w", path}))
+	second, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(first), string(second))
+
+	bad := writeTemp(t, "bad.graphml", "<graphml")
+	require.Equal(t, 1, run([]string{"fmt", "-l", bad}))
+}
+
+func TestSortKeys(t *testing.T) {
+	keys := []graphml.Key{
+		graphml.NewKey("custom", "d0", "x", ""),
+		graphml.NewKey(graphml.KindPort, "d1", "side", graphml.TypeString),
+		graphml.NewKey(graphml.KindEdge, "d2", "weight", graphml.TypeDouble),
+		graphml.NewKey(graphml.KindGraphML, "d3", "author", graphml.TypeString),
+		graphml.NewKey(graphml.KindAll, "d4", "label", graphml.TypeString),
+		graphml.NewKey(graphml.KindNode, "d5", "color", graphml.TypeString),
+		graphml.NewKey("", "d6", "comment", graphml.TypeString),
+	}
+	sortKeys(keys)
+	var ids []string
+	for _, k := range keys {
+		ids = append(ids, k.ID)
+	}
+	require.Equal(t, []string{"d6", "d4", "d3", "d5", "d2", "d1", "d0"}, ids)
+}
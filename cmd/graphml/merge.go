@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/dennwc/graphml"
+)
+
+var cmdMerge = &command{
+	name:  "merge",
+	usage: "file [file ...]",
+	short: "merge multiple GraphML files into one",
+	run:   runMerge,
+}
+
+var mergeConflicts = map[string]graphml.MergeConflict{
+	"rename": graphml.MergeRename,
+	"merge":  graphml.MergeCombine,
+	"error":  graphml.MergeError,
+}
+
+func runMerge(fs *flag.FlagSet, args []string) error {
+	var (
+		out      = fs.String("o", "-", "output file")
+		compress = fs.Bool("z", false, "compress the output with gzip (implied by .gz extension)")
+		conflict = fs.String("on-conflict", "rename", "how to resolve elements with the same ID: rename, merge or error")
+	)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	mode, ok := mergeConflicts[*conflict]
+	if !ok {
+		return fmt.Errorf("unknown conflict strategy: %q", *conflict)
+	}
+	docs := make([]*graphml.Document, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		doc, err := readDocument(path, nil)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+	doc, err := graphml.Merge(docs, &graphml.MergeOptions{OnConflict: mode})
+	if err != nil {
+		return err
+	}
+	return writeDocument(*out, doc, *compress, nil)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/dennwc/graphml"
+	"github.com/dennwc/graphml/convert"
+)
+
+var cmdRender = &command{
+	name:  "render",
+	usage: "[file]",
+	short: "render a GraphML file to SVG or PNG",
+	run:   runRender,
+}
+
+// renderPNG renders the document with a Graphviz program at a given path. Node labels are taken from the given attribute.
+func renderPNG(w io.Writer, doc *graphml.Document, path string, label string) error {
+	var dot bytes.Buffer
+	if err := convert.ToDOT(&dot, doc, &convert.DOTOptions{Attrs: map[string]string{label: "label"}}); err != nil {
+		return err
+	}
+	var errOut bytes.Buffer
+	cmd := exec.Command(path, "-Tpng")
+	cmd.Stdin = &dot
+	cmd.Stdout = w
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("graphviz: %v: %s", err, strings.TrimSpace(errOut.String()))
+	}
+	return nil
+}
+
+func runRender(fs *flag.FlagSet, args []string) error {
+	var (
+		out    = fs.String("o", "-", "output file")
+		format = fs.String("format", "", "output format: svg or png (detected from the file extension, svg by default)")
+		label  = fs.String("label", "label", "node attribute used for labels")
+		layout = fs.String("layout", "", "layout engine: builtin, or a Graphviz program (dot, neato, fdp, sfdp, circo, twopi); existing node positions are used by default")
+		width  = fs.Float64("width", 0, "width of the SVG image")
+		height = fs.Float64("height", 0, "height of the SVG image")
+	)
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		return errUsage
+	}
+	in := "-"
+	if fs.NArg() == 1 {
+		in = fs.Arg(0)
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(baseExt(*out), ".")
+		if *format != "png" {
+			*format = "svg"
+		}
+	}
+	if *format != "svg" && *format != "png" {
+		return fmt.Errorf("unsupported format: %q", *format)
+	}
+	engine := convert.LayoutEngine(*layout)
+	if engine == "builtin" {
+		engine = convert.LayoutBuiltin
+	}
+	var graphviz string
+	if *format == "png" {
+		if engine == convert.LayoutBuiltin {
+			engine = convert.LayoutDot
+		}
+		var err error
+		if graphviz, err = exec.LookPath(string(engine)); err != nil {
+			return fmt.Errorf("PNG output requires Graphviz: %v", err)
+		}
+	}
+	doc, err := readDocument(in, nil)
+	if err != nil {
+		return err
+	}
+	w, err := createOutput(*out, false)
+	if err != nil {
+		return err
+	}
+	if *format == "png" {
+		err = renderPNG(w, doc, graphviz, *label)
+	} else {
+		if *layout != "" {
+			err = convert.Layout(doc, engine)
+		}
+		if err == nil {
+			err = convert.RenderSVG(w, doc, &convert.SVGOptions{Label: *label, Width: *width, Height: *height})
+		}
+	}
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+var cmdSplit = &command{
+	name:  "split",
+	usage: "[file]",
+	short: "write each graph or connected component to a separate file",
+	run:   runSplit,
+}
+
+// outputPattern returns a default pattern for output files of split: "name-%d.graphml" for "name.graphml".
+func outputPattern(in string) string {
+	if in == "-" {
+		return "graph-%d" + graphml.Ext
+	}
+	base := filepath.Base(in)
+	if isGzip(base) {
+		base = strings.TrimSuffix(base, base[len(base)-len(extGz):])
+	}
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	return base + "-%d" + graphml.Ext
+}
+
+func runSplit(fs *flag.FlagSet, args []string) error {
+	var (
+		by       = fs.String("by", "graph", "how to split the document: graph or component")
+		out      = fs.String("o", "", "pattern for output files, %d is replaced with the file number (input-%d.graphml by default)")
+		compress = fs.Bool("z", false, "compress the output with gzip (implied by .gz extension)")
+	)
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		return errUsage
+	}
+	in := "-"
+	if fs.NArg() == 1 {
+		in = fs.Arg(0)
+	}
+	pattern := *out
+	if pattern == "" {
+		pattern = outputPattern(in)
+	} else if !strings.Contains(pattern, "%d") {
+		return fmt.Errorf("output pattern must contain %%d: %q", pattern)
+	}
+	doc, err := readDocument(in, nil)
+	if err != nil {
+		return err
+	}
+	var docs []*graphml.Document
+	switch *by {
+	case "graph":
+		docs = doc.SplitGraphs()
+	case "component":
+		docs = doc.SplitComponents()
+	default:
+		return fmt.Errorf("unknown split mode: %q", *by)
+	}
+	for i, d := range docs {
+		d.PruneUnusedKeys()
+		path := strings.Replace(pattern, "%d", fmt.Sprint(i+1), 1)
+		if err = writeDocument(path, d, *compress, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/dennwc/graphml"
+)
+
+var cmdStats = &command{
+	name:  "stats",
+	usage: "file [file ...]",
+	short: "print statistics of GraphML files",
+	run:   runStats,
+}
+
+// fileStats is a summary of a single file.
+type fileStats struct {
+	File string `json:"file"`
+	// Size is a size of the file. For compressed files, it is the compressed size.
+	Size int64 `json:"size"`
+	// Bytes is a size of the GraphML document.
+	Bytes int64 `json:"bytes"`
+	*graphml.Stats
+}
+
+// countingReader counts bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func readStats(path string) (*fileStats, error) {
+	r, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	cr := &countingReader{r: r}
+	doc, err := graphml.Decode(cr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	st := &fileStats{File: path, Size: cr.n, Bytes: cr.n, Stats: doc.Stats()}
+	if path != "-" {
+		if fi, err := os.Stat(path); err == nil {
+			st.Size = fi.Size()
+		}
+	}
+	return st, nil
+}
+
+func (st *fileStats) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
+	fmt.Fprintf(tw, "%s\n", st.File)
+	if st.Size != st.Bytes {
+		fmt.Fprintf(tw, "  size:\t%d (%d uncompressed)\n", st.Size, st.Bytes)
+	} else {
+		fmt.Fprintf(tw, "  size:\t%d\n", st.Size)
+	}
+	fmt.Fprintf(tw, "  graphs:\t%d (max depth %d)\n", st.Graphs, st.MaxDepth)
+	fmt.Fprintf(tw, "  nodes:\t%d (%d isolated)\n", st.Nodes, st.Isolated)
+	fmt.Fprintf(tw, "  edges:\t%d (%d directed, %d undirected, %d self-loops)\n", st.Edges, st.Directed, st.Undirected, st.SelfLoops)
+	fmt.Fprintf(tw, "  degree:\tavg %.2f, max %d\n", st.AvgDegree, st.MaxDegree)
+	fmt.Fprintf(tw, "  keys:\t%d\n", st.Keys)
+	ids := make([]string, 0, len(st.KeyUsage))
+	for id := range st.KeyUsage {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(tw, "    %s:\t%d\n", id, st.KeyUsage[id])
+	}
+	if len(st.Degrees) != 0 {
+		fmt.Fprintf(tw, "  degree distribution:\n")
+		for d, n := range st.Degrees {
+			if n != 0 {
+				fmt.Fprintf(tw, "    %d:\t%d\n", d, n)
+			}
+		}
+	}
+	tw.Flush()
+}
+
+func runStats(fs *flag.FlagSet, args []string) error {
+	asJSON := fs.Bool("json", false, "print statistics in JSON")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	var all []*fileStats
+	for _, path := range fs.Args() {
+		st, err := readStats(path)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			all = append(all, st)
+			continue
+		}
+		st.print(os.Stdout)
+	}
+	if !*asJSON {
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(all)
+}
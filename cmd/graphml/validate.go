@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+var cmdValidate = &command{
+	name:  "validate",
+	usage: "file [file ...]",
+	short: "check GraphML files for errors",
+	run:   runValidate,
+}
+
+// fileReport is a validation report of a single file, as printed in JSON mode.
+type fileReport struct {
+	File string `json:"file"`
+	*graphml.Report
+	// Error is set if the file cannot be decoded.
+	Error string `json:"error,omitempty"`
+}
+
+// selectRules returns validation rules with given names, or all default rules if no names are given.
+func selectRules(names []string) ([]graphml.Rule, error) {
+	if len(names) == 0 {
+		return graphml.DefaultRules(), nil
+	}
+	out := make([]graphml.Rule, 0, len(names))
+	for _, name := range names {
+		r, ok := graphml.LookupRule(name)
+		if !ok {
+			var known []string
+			for _, r := range graphml.DefaultRules() {
+				known = append(known, r.Name())
+			}
+			return nil, fmt.Errorf("unknown rule %q, available rules: %s", name, strings.Join(known, ", "))
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func runValidate(fs *flag.FlagSet, args []string) error {
+	var (
+		strict  = fs.Bool("strict", false, "treat warnings as errors")
+		verbose = fs.Bool("v", false, "also print informational findings")
+		asJSON  = fs.Bool("json", false, "print reports in JSON")
+		enable  listFlag
+		ignore  listFlag
+	)
+	fs.Var(&enable, "rules", "comma-separated list of rules to run (all by default)")
+	fs.Var(&ignore, "ignore", "comma-separated list of rules to skip")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	rules, err := selectRules(enable)
+	if err != nil {
+		return err
+	}
+	policy := &graphml.Policy{Ignore: make(map[string]bool)}
+	if !*verbose && !*asJSON {
+		minSeverity := graphml.SeverityWarning
+		policy.MinSeverity = &minSeverity
+	}
+	for _, name := range ignore {
+		policy.Ignore[name] = true
+	}
+
+	failed := false
+	var reports []fileReport
+	for _, path := range fs.Args() {
+		var findings []graphml.Finding
+		_, err := readDocument(path, &graphml.Options{Validator: &graphml.Validator{
+			Rules:  rules,
+			Policy: policy,
+			Report: func(f graphml.Finding) error {
+				if *strict && f.Severity == graphml.SeverityWarning {
+					f.Severity = graphml.SeverityError
+				}
+				findings = append(findings, f)
+				return nil
+			},
+		}})
+		rep := graphml.NewReport(findings)
+		if err != nil || !rep.Valid {
+			failed = true
+		}
+		if *asJSON {
+			fr := fileReport{File: path, Report: rep}
+			if err != nil {
+				fr.Error = err.Error()
+			}
+			reports = append(reports, fr)
+			continue
+		}
+		for _, f := range findings {
+			if f.Line != 0 {
+				fmt.Printf("%s:%v\n", path, f)
+			} else {
+				fmt.Printf("%s: %v\n", path, f)
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return err
+		}
+	}
+	if failed {
+		return errSilent
+	}
+	return nil
+}
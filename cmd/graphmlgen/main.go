@@ -0,0 +1,64 @@
+// Command graphmlgen generates Go code for typed access to attributes declared in a GraphML file.
+//
+// Usage:
+//
+//	graphmlgen [-pkg name] [-o output.go] input.graphml
+//
+// Compressed inputs (.graphml.gz) are supported. It can be used with go:generate:
+//
+//	//go:generate graphmlgen -pkg model -o attrs_gen.go schema.graphml
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dennwc/graphml"
+	"github.com/dennwc/graphml/graphmlgen"
+)
+
+func main() {
+	var (
+		pkg = flag.String("pkg", "", "name of the generated package (defaults to the package in the current directory, or main)")
+		out = flag.String("o", "", "output file (defaults to stdout)")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: graphmlgen [flags] input.graphml\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *out, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkg string) error {
+	abs, err := filepath.Abs(in)
+	if err != nil {
+		return err
+	}
+	doc, err := graphml.DecodeFS(os.DirFS(filepath.Dir(abs)), filepath.Base(abs))
+	if err != nil {
+		return err
+	}
+	if pkg == "" {
+		pkg = os.Getenv("GOPACKAGE")
+	}
+	var buf bytes.Buffer
+	if err = graphmlgen.Generate(&buf, doc.Keys, &graphmlgen.Options{Package: pkg}); err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(out, buf.Bytes(), 0644)
+}
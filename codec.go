@@ -0,0 +1,160 @@
+package graphml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Codec converts XML content of a foreign namespace found inside Data to and from typed values.
+//
+// Codecs are registered for a namespace with RegisterCodec. The decoder calls the codec for data elements
+// that contain a single XML element from this namespace, and stores the result on Data (see Data.Value).
+// If the codec fails, the decoder keeps raw XML tokens of the data and reports a warning (see WarnCodecFailed).
+// Data.SetValue uses the codec to serialize a typed value back to XML tokens of the data.
+type Codec interface {
+	// DecodeData decodes a typed value from the XML element.
+	DecodeData(dec *xml.Decoder, start xml.StartElement) (interface{}, error)
+	// EncodeData writes a typed value as one or more XML elements.
+	EncodeData(enc *xml.Encoder, v interface{}) error
+}
+
+var codecs struct {
+	sync.RWMutex
+	byNS map[string]Codec
+}
+
+// RegisterCodec registers a codec for a given XML namespace. It replaces any codec registered previously.
+// A nil codec unregisters the namespace.
+func RegisterCodec(ns string, c Codec) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	if c == nil {
+		delete(codecs.byNS, ns)
+		return
+	}
+	if codecs.byNS == nil {
+		codecs.byNS = make(map[string]Codec)
+	}
+	codecs.byNS[ns] = c
+}
+
+// LookupCodec returns a codec registered for a given XML namespace.
+func LookupCodec(ns string) (Codec, bool) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	c, ok := codecs.byNS[ns]
+	return c, ok
+}
+
+// Value returns a typed value decoded from the data by a registered codec, or nil if there is none.
+func (d *Data) Value() interface{} {
+	return d.value
+}
+
+// SetValue sets a typed value of the data and replaces XML tokens of the data with the value serialized by a codec
+// registered for a given namespace. A nil value resets the typed value, but keeps the tokens.
+//
+// Decoded values are not written back automatically, thus SetValue must be called after modifying them.
+// Values are not copied by Document.Clone, thus it's not safe to modify them after freezing the document.
+func (d *Data) SetValue(ns string, v interface{}) error {
+	if v == nil {
+		d.value = nil
+		return nil
+	}
+	c, ok := LookupCodec(ns)
+	if !ok {
+		return ErrNoCodec{Namespace: ns}
+	}
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	if err := c.EncodeData(enc, v); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	var toks []xml.Token
+	dec := xml.NewDecoder(&buf)
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		toks = append(toks, xml.CopyToken(t))
+	}
+	d.Data, d.value = toks, v
+	return nil
+}
+
+// DecodeValue decodes a typed value from XML tokens of the data again, if it contains a single element
+// from a namespace with a registered codec. It must be called after modifying tokens of the data directly.
+func (d *Data) DecodeValue() error {
+	d.value = nil
+	return d.decodeValue()
+}
+
+// decodeValue decodes a typed value of the data, if it contains a single element from a namespace with a codec.
+func (d *Data) decodeValue() error {
+	var (
+		start xml.StartElement
+		found bool
+		depth int
+	)
+	for _, t := range d.Data {
+		switch t := t.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				if found {
+					return nil
+				}
+				start, found = t, true
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		default:
+			if depth == 0 && !canSkip(t) {
+				return nil
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	c, ok := LookupCodec(start.Name.Space)
+	if !ok {
+		return nil
+	}
+	dec := xml.NewTokenDecoder(d.Reader())
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if st, ok := t.(xml.StartElement); ok {
+			start = st
+			break
+		}
+	}
+	v, err := decodeCodec(c, dec, start)
+	if err != nil {
+		return fmt.Errorf("cannot decode %v: %w", start.Name, err)
+	}
+	d.value = v
+	return nil
+}
+
+// decodeCodec calls the codec, converting panics to errors, so that malformed data cannot crash the decoder.
+func decodeCodec(c Codec, dec *xml.Decoder, start xml.StartElement) (v interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			v, err = nil, fmt.Errorf("codec panic: %v", r)
+		}
+	}()
+	return c.DecodeData(dec, start)
+}
@@ -0,0 +1,127 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// CompactStats is a number of elements and attributes removed by Document.Compact.
+type CompactStats struct {
+	Keys   int
+	Data   int
+	Graphs int
+	Attrs  int
+}
+
+// Total returns the total number of removed elements and attributes.
+func (st CompactStats) Total() int {
+	return st.Keys + st.Data + st.Graphs + st.Attrs
+}
+
+func (st CompactStats) String() string {
+	return "removed " + strings.Join([]string{
+		plural(st.Keys, "key"),
+		plural(st.Data, "data element"),
+		plural(st.Graphs, "nested graph"),
+		plural(st.Attrs, "duplicate attribute"),
+	}, ", ")
+}
+
+// Compact removes redundant content from the document and reports how much was removed:
+//
+//   - data elements with no content and no attributes, unless their key has a default value
+//     (an empty element overrides the default, so it is kept);
+//   - nested graphs without an ID, nodes, edges and data (empty groups written by yEd have graph IDs and are kept);
+//   - duplicates of unrecognized attributes with the same name, the first one is kept;
+//   - keys that have no default and are not referenced by any data (see PruneUnusedKeys).
+//
+// Keys are pruned last, so keys referenced only by removed data elements are removed as well.
+func (doc *Document) Compact() CompactStats {
+	var st CompactStats
+	st.Attrs += dedupAttrs(&doc.Attrs)
+	for i := range doc.Keys {
+		st.Attrs += dedupAttrs(&doc.Keys[i].Unrecognized)
+	}
+	compactData := func(kind Kind, data *[]Data) {
+		out := (*data)[:0]
+		for _, d := range *data {
+			st.Attrs += dedupAttrs(&d.Unrecognized)
+			if d.isEmpty() {
+				if k := doc.dataKey(kind, d.Key); k == nil || k.Default == nil {
+					st.Data++
+					continue
+				}
+			}
+			out = append(out, d)
+		}
+		*data = out
+	}
+	compactData(KindGraphML, &doc.Data)
+	var visit func(graphs []Graph)
+	visit = func(graphs []Graph) {
+		for i := range graphs {
+			g := &graphs[i]
+			st.Attrs += dedupAttrs(&g.Unrecognized)
+			compactData(KindGraph, &g.Data)
+			for j := range g.Nodes {
+				n := &g.Nodes[j]
+				st.Attrs += dedupAttrs(&n.Unrecognized)
+				compactData(KindNode, &n.Data)
+				visit(n.Graphs)
+				sub := n.Graphs[:0]
+				for _, sg := range n.Graphs {
+					if sg.ID == "" && len(sg.Nodes) == 0 && len(sg.Edges) == 0 && len(sg.Data) == 0 {
+						st.Graphs++
+						continue
+					}
+					sub = append(sub, sg)
+				}
+				if len(sub) == 0 {
+					sub = nil
+				}
+				n.Graphs = sub
+			}
+			for j := range g.Edges {
+				e := &g.Edges[j]
+				st.Attrs += dedupAttrs(&e.Unrecognized)
+				compactData(KindEdge, &e.Data)
+			}
+		}
+	}
+	visit(doc.Graphs)
+	st.Keys = doc.PruneUnusedKeys()
+	return st
+}
+
+// isEmpty checks if the data element has no content, no typed value and no attributes other than the key.
+func (d *Data) isEmpty() bool {
+	if d.value != nil || len(d.Unrecognized) != 0 {
+		return false
+	}
+	for _, t := range d.Data {
+		if c, ok := t.(xml.CharData); !ok || len(c) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// dedupAttrs removes attributes with the same name as one of the previous attributes.
+// It returns the number of removed attributes.
+func dedupAttrs(attrs *[]xml.Attr) int {
+	if len(*attrs) < 2 {
+		return 0
+	}
+	seen := make(map[xml.Name]struct{}, len(*attrs))
+	out := (*attrs)[:0]
+	for _, a := range *attrs {
+		if _, ok := seen[a.Name]; ok {
+			continue
+		}
+		seen[a.Name] = struct{}{}
+		out = append(out, a)
+	}
+	n := len(*attrs) - len(out)
+	*attrs = out
+	return n
+}
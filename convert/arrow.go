@@ -0,0 +1,370 @@
+package convert
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+// fbValue is a value of a flatbuffers table field.
+// It is either an inline scalar or a reference to another object.
+type fbValue struct {
+	scalar []byte
+	ref    fbObject
+}
+
+// fbObject is an object that can be written to a flatbuffer.
+type fbObject interface {
+	// writeTo appends the object to the buffer and returns its position.
+	writeTo(b *fbBuilder) int
+}
+
+// fbBuilder writes flatbuffers front-to-back, placing all the children after their parents.
+// This is enough to encode small metadata messages without code generation.
+type fbBuilder struct {
+	buf []byte
+}
+
+func (b *fbBuilder) pad(align int) {
+	for len(b.buf)%align != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *fbBuilder) u32(v uint32) {
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, v)
+}
+
+// ref writes a child object and patches the offset at pos to point to it.
+func (b *fbBuilder) ref(pos int, obj fbObject) {
+	p := obj.writeTo(b)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(p-pos))
+}
+
+// fbFinish returns a flatbuffer with a given root table.
+func fbFinish(root fbObject) []byte {
+	b := &fbBuilder{}
+	b.u32(0)
+	b.ref(0, root)
+	b.pad(8)
+	return b.buf
+}
+
+func fbBool(v bool) fbValue {
+	if v {
+		return fbValue{scalar: []byte{1}}
+	}
+	return fbValue{scalar: []byte{0}}
+}
+
+func fbUint8(v uint8) fbValue { return fbValue{scalar: []byte{v}} }
+
+func fbInt16(v int16) fbValue {
+	return fbValue{scalar: binary.LittleEndian.AppendUint16(nil, uint16(v))}
+}
+
+func fbInt32(v int32) fbValue {
+	return fbValue{scalar: binary.LittleEndian.AppendUint32(nil, uint32(v))}
+}
+
+func fbInt64(v int64) fbValue {
+	return fbValue{scalar: binary.LittleEndian.AppendUint64(nil, uint64(v))}
+}
+
+func fbRef(obj fbObject) fbValue { return fbValue{ref: obj} }
+
+// fbTable is a flatbuffers table. Field IDs are indexes in the slice; zero values are omitted.
+type fbTable []fbValue
+
+func (t fbTable) writeTo(b *fbBuilder) int {
+	type slot struct {
+		id   int
+		size int
+		off  int
+	}
+	var slots []slot
+	for id, v := range t {
+		switch {
+		case v.ref != nil:
+			slots = append(slots, slot{id: id, size: 4})
+		case v.scalar != nil:
+			slots = append(slots, slot{id: id, size: len(v.scalar)})
+		}
+	}
+	// place larger fields first to keep them aligned
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].size > slots[j].size
+	})
+	align, size := 4, 4
+	for i := range slots {
+		s := &slots[i]
+		if s.size > align {
+			align = s.size
+		}
+		for size%s.size != 0 {
+			size++
+		}
+		s.off = size
+		size += s.size
+	}
+	// vtable goes first
+	b.pad(2)
+	vt := len(b.buf)
+	offs := make([]uint16, len(t))
+	for _, s := range slots {
+		offs[s.id] = uint16(s.off)
+	}
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(4+2*len(t)))
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(size))
+	for _, o := range offs {
+		b.buf = binary.LittleEndian.AppendUint16(b.buf, o)
+	}
+	b.pad(align)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(int32(pos-vt)))
+	for _, s := range slots {
+		if v := t[s.id]; v.scalar != nil {
+			copy(b.buf[pos+s.off:], v.scalar)
+		}
+	}
+	for _, s := range slots {
+		if v := t[s.id]; v.ref != nil {
+			b.ref(pos+s.off, v.ref)
+		}
+	}
+	return pos
+}
+
+// fbString is a flatbuffers string.
+type fbString string
+
+func (s fbString) writeTo(b *fbBuilder) int {
+	b.pad(4)
+	pos := len(b.buf)
+	b.u32(uint32(len(s)))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
+
+// fbTables is a vector of flatbuffers tables.
+type fbTables []fbObject
+
+func (v fbTables) writeTo(b *fbBuilder) int {
+	b.pad(4)
+	pos := len(b.buf)
+	b.u32(uint32(len(v)))
+	b.buf = append(b.buf, make([]byte, 4*len(v))...)
+	for i, obj := range v {
+		b.ref(pos+4+4*i, obj)
+	}
+	return pos
+}
+
+// fbStructs is a vector of flatbuffers structs with 8 byte alignment.
+type fbStructs [][]byte
+
+func (v fbStructs) writeTo(b *fbBuilder) int {
+	for (len(b.buf)+4)%8 != 0 {
+		b.buf = append(b.buf, 0)
+	}
+	pos := len(b.buf)
+	b.u32(uint32(len(v)))
+	for _, s := range v {
+		b.buf = append(b.buf, s...)
+	}
+	return pos
+}
+
+const (
+	arrowMagic     = "ARROW1"
+	arrowVersionV5 = 4
+
+	arrowHeaderSchema      = 1
+	arrowHeaderRecordBatch = 3
+
+	arrowTypeInt           = 2
+	arrowTypeFloatingPoint = 3
+	arrowTypeUtf8          = 5
+	arrowTypeBool          = 6
+)
+
+// arrowType returns an Arrow type union for a GraphML attribute type.
+func arrowType(typ string) (uint8, fbObject) {
+	switch typ {
+	case "boolean":
+		return arrowTypeBool, fbTable{}
+	case "int":
+		return arrowTypeInt, fbTable{fbInt32(32), fbBool(true)}
+	case "long":
+		return arrowTypeInt, fbTable{fbInt32(64), fbBool(true)}
+	case "float":
+		return arrowTypeFloatingPoint, fbTable{fbInt16(1)}
+	case "double":
+		return arrowTypeFloatingPoint, fbTable{fbInt16(2)}
+	}
+	return arrowTypeUtf8, fbTable{}
+}
+
+func arrowSchema(t *table) fbObject {
+	var fields fbTables
+	for _, c := range t.Cols {
+		typ, val := arrowType(c.Type)
+		fields = append(fields, fbTable{
+			fbRef(fbString(c.Name)),
+			fbBool(!c.Required),
+			fbUint8(typ),
+			fbRef(val),
+			{},
+			fbRef(fbTables{}),
+		})
+	}
+	return fbTable{fbInt16(0), fbRef(fields)}
+}
+
+func arrowMessage(typ uint8, header fbObject, bodyLen int) []byte {
+	return fbFinish(fbTable{
+		fbInt16(arrowVersionV5),
+		fbUint8(typ),
+		fbRef(header),
+		fbInt64(int64(bodyLen)),
+	})
+}
+
+// arrowBody encodes columns of the table as a record batch body.
+func arrowBody(t *table) (body []byte, nodes, buffers fbStructs) {
+	addBuffer := func(data []byte) {
+		off := len(body)
+		body = append(body, data...)
+		for len(body)%8 != 0 {
+			body = append(body, 0)
+		}
+		buf := binary.LittleEndian.AppendUint64(nil, uint64(off))
+		buffers = append(buffers, binary.LittleEndian.AppendUint64(buf, uint64(len(data))))
+	}
+	bitmap := func(n int, bit func(i int) bool) []byte {
+		out := make([]byte, (n+7)/8)
+		for i := 0; i < n; i++ {
+			if bit(i) {
+				out[i/8] |= 1 << uint(i%8)
+			}
+		}
+		return out
+	}
+	for _, c := range t.Cols {
+		nulls := c.Nulls()
+		node := binary.LittleEndian.AppendUint64(nil, uint64(t.Rows))
+		nodes = append(nodes, binary.LittleEndian.AppendUint64(node, uint64(nulls)))
+		if nulls == 0 {
+			addBuffer(nil)
+		} else {
+			addBuffer(bitmap(t.Rows, func(i int) bool { return c.Valid[i] }))
+		}
+		var data []byte
+		switch c.Type {
+		case "boolean":
+			addBuffer(bitmap(t.Rows, func(i int) bool {
+				v, _ := strconv.ParseBool(c.Values[i])
+				return v
+			}))
+			continue
+		case "int":
+			for _, s := range c.Values {
+				v, _ := strconv.ParseInt(s, 10, 32)
+				data = binary.LittleEndian.AppendUint32(data, uint32(v))
+			}
+		case "long":
+			for _, s := range c.Values {
+				v, _ := strconv.ParseInt(s, 10, 64)
+				data = binary.LittleEndian.AppendUint64(data, uint64(v))
+			}
+		case "float":
+			for _, s := range c.Values {
+				v, _ := strconv.ParseFloat(s, 32)
+				data = binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(v)))
+			}
+		case "double":
+			for _, s := range c.Values {
+				v, _ := strconv.ParseFloat(s, 64)
+				data = binary.LittleEndian.AppendUint64(data, math.Float64bits(v))
+			}
+		default:
+			offs := binary.LittleEndian.AppendUint32(nil, 0)
+			for _, s := range c.Values {
+				data = append(data, s...)
+				offs = binary.LittleEndian.AppendUint32(offs, uint32(len(data)))
+			}
+			addBuffer(offs)
+		}
+		addBuffer(data)
+	}
+	return body, nodes, buffers
+}
+
+// writeArrow writes the table as an Arrow IPC file with a single record batch.
+func writeArrow(w io.Writer, t *table) error {
+	bw := bufio.NewWriter(w)
+	off := 0
+	write := func(p []byte) {
+		bw.Write(p)
+		off += len(p)
+	}
+	// writeMessage writes an encapsulated IPC message and returns its block for the footer.
+	writeMessage := func(meta, body []byte) []byte {
+		block := binary.LittleEndian.AppendUint64(nil, uint64(off))
+		prefix := binary.LittleEndian.AppendUint32(nil, 0xFFFFFFFF)
+		prefix = binary.LittleEndian.AppendUint32(prefix, uint32(len(meta)))
+		write(prefix)
+		write(meta)
+		write(body)
+		block = binary.LittleEndian.AppendUint32(block, uint32(len(prefix)+len(meta)))
+		block = append(block, 0, 0, 0, 0)
+		return binary.LittleEndian.AppendUint64(block, uint64(len(body)))
+	}
+	write([]byte(arrowMagic + "\x00\x00"))
+	schema := arrowSchema(t)
+	writeMessage(arrowMessage(arrowHeaderSchema, schema, 0), nil)
+	body, nodes, buffers := arrowBody(t)
+	batch := fbTable{fbInt64(int64(t.Rows)), fbRef(nodes), fbRef(buffers)}
+	block := writeMessage(arrowMessage(arrowHeaderRecordBatch, batch, len(body)), body)
+	// end of stream marker
+	write([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0})
+	footer := fbFinish(fbTable{
+		fbInt16(arrowVersionV5),
+		fbRef(schema),
+		fbRef(fbStructs{}),
+		fbRef(fbStructs{block}),
+	})
+	write(footer)
+	write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	write([]byte(arrowMagic))
+	return bw.Flush()
+}
+
+// ToArrow writes nodes and edges of all graphs in the document as two Arrow IPC files.
+//
+// The node table has id and graph columns, followed by one column for each node attribute.
+// The edge table has id, graph, source, target and directed columns, followed by edge attributes.
+// Column types are derived from GraphML keys, missing attributes are stored as nulls.
+// The graph column stores an ID of the graph that contains the element, thus nested graphs are flattened.
+// Either of writers may be nil, in which case the corresponding table is not written.
+func ToArrow(nodes, edges io.Writer, doc *graphml.Document) error {
+	nt, et := graphTables(doc)
+	if nodes != nil {
+		if err := writeArrow(nodes, nt); err != nil {
+			return err
+		}
+	}
+	if edges != nil {
+		if err := writeArrow(edges, et); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+// Package convert implements conversion of GraphML documents to and from other graph formats.
+package convert
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+type kindID struct {
+	kind graphml.Kind
+	id   string
+}
+
+// keyIndex caches key lookups of graphml.Document.KeyFor.
+type keyIndex struct {
+	doc  *graphml.Document
+	keys map[kindID]*graphml.Key
+}
+
+func newKeyIndex(doc *graphml.Document) *keyIndex {
+	return &keyIndex{doc: doc, keys: make(map[kindID]*graphml.Key)}
+}
+
+// Lookup finds a key definition for a given element kind, see graphml.Document.KeyFor.
+func (ki *keyIndex) Lookup(kind graphml.Kind, id string) *graphml.Key {
+	kid := kindID{kind: kind, id: id}
+	k, ok := ki.keys[kid]
+	if !ok {
+		k, _ = ki.doc.KeyFor(kind, id)
+		ki.keys[kid] = k
+	}
+	return k
+}
+
+// Name returns a name of the attribute, or key ID if the name is not set.
+func (ki *keyIndex) Name(kind graphml.Kind, id string) string {
+	if k := ki.Lookup(kind, id); k != nil && k.Name != "" {
+		return k.Name
+	}
+	return id
+}
+
+// attr is a simple named attribute value.
+type attr struct {
+	Name  string
+	Value string
+	Key   *graphml.Key
+}
+
+// Attrs returns all simple data values of an element, resolving attribute names.
+func (ki *keyIndex) Attrs(kind graphml.Kind, data []graphml.Data) []attr {
+	var out []attr
+	for _, d := range data {
+		v, ok := dataText(d)
+		if !ok {
+			continue
+		}
+		out = append(out, attr{Name: ki.Name(kind, d.Key), Value: v, Key: ki.Lookup(kind, d.Key)})
+	}
+	return out
+}
+
+// Attr returns a value of a simple attribute with a given name.
+func (ki *keyIndex) Attr(kind graphml.Kind, data []graphml.Data, name string) (string, bool) {
+	for _, a := range ki.Attrs(kind, data) {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// dataText returns a text value of the data element.
+// It returns false if data contains anything other than text.
+func dataText(d graphml.Data) (string, bool) {
+	return graphml.TokensText(d.Data)
+}
+
+// newDocument creates an empty GraphML document with a standard header.
+func newDocument() *graphml.Document {
+	return &graphml.Document{
+		Instr: xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)},
+		Attrs: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: graphml.Namespace}},
+	}
+}
+
+// textData creates a data element with a text value.
+func textData(key, value string) graphml.Data {
+	return graphml.NewStringData(key, value)
+}
+
+// idSet tracks element IDs used in a document.
+type idSet struct {
+	ids  map[string]struct{}
+	next map[string]int
+}
+
+func newIDSet() idSet {
+	return idSet{ids: make(map[string]struct{}), next: make(map[string]int)}
+}
+
+// Reserve marks an ID as used. It returns false if the ID is already in use.
+func (s idSet) Reserve(id string) bool {
+	if _, ok := s.ids[id]; ok {
+		return false
+	}
+	s.ids[id] = struct{}{}
+	return true
+}
+
+// Unique generates and reserves a new unique ID with a given prefix.
+func (s idSet) Unique(prefix string) string {
+	for {
+		i := s.next[prefix]
+		s.next[prefix] = i + 1
+		id := prefix + strconv.Itoa(i)
+		if s.Reserve(id) {
+			return id
+		}
+	}
+}
+
+type keyInfo struct {
+	typ    string  // explicit type, if any
+	def    *string // default value, if any
+	isInt  bool
+	isNum  bool
+	isBool bool
+	long   bool
+}
+
+// keyBuilder declares GraphML keys for attributes of imported documents.
+type keyBuilder struct {
+	keys  []graphml.Key
+	info  []keyInfo
+	names map[kindID]int
+}
+
+func newKeyBuilder() *keyBuilder {
+	return &keyBuilder{names: make(map[kindID]int)}
+}
+
+func (b *keyBuilder) key(kind graphml.Kind, name string) int {
+	if i, ok := b.names[kindID{kind: kind, id: name}]; ok {
+		return i
+	}
+	i := len(b.keys)
+	b.names[kindID{kind: kind, id: name}] = i
+	b.keys = append(b.keys, graphml.NewKey(kind, "d"+strconv.Itoa(i), name, ""))
+	b.info = append(b.info, keyInfo{isInt: true, isNum: true, isBool: true})
+	return i
+}
+
+// Declare declares a key with an explicit type and returns its ID.
+func (b *keyBuilder) Declare(kind graphml.Kind, name, typ string) string {
+	i := b.key(kind, name)
+	b.info[i].typ = typ
+	return b.keys[i].ID
+}
+
+// SetDefault sets a default value for the key.
+func (b *keyBuilder) SetDefault(kind graphml.Kind, name, value string) {
+	i := b.key(kind, name)
+	b.info[i].def = &value
+}
+
+// Data creates a data element for a given attribute, declaring the key if necessary.
+// The key type is inferred from all values, unless it was declared explicitly.
+func (b *keyBuilder) Data(kind graphml.Kind, name, value string) graphml.Data {
+	i := b.key(kind, name)
+	inf := &b.info[i]
+	if inf.typ == "" {
+		if inf.isInt {
+			if _, err := strconv.ParseInt(value, 10, 32); err != nil {
+				if _, err = strconv.ParseInt(value, 10, 64); err == nil {
+					inf.long = true
+				} else {
+					inf.isInt = false
+				}
+			}
+		}
+		if inf.isNum {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				inf.isNum = false
+			}
+		}
+		if inf.isBool && value != "true" && value != "false" {
+			inf.isBool = false
+		}
+	}
+	return textData(b.keys[i].ID, value)
+}
+
+// Keys returns all declared keys.
+func (b *keyBuilder) Keys() []graphml.Key {
+	keys := make([]graphml.Key, len(b.keys))
+	for i, k := range b.keys {
+		inf := b.info[i]
+		switch {
+		case inf.typ != "":
+			k.Type = inf.typ
+		case inf.isBool:
+			k.Type = "boolean"
+		case inf.isInt && inf.long:
+			k.Type = "long"
+		case inf.isInt:
+			k.Type = "int"
+		case inf.isNum:
+			k.Type = "double"
+		default:
+			k.Type = "string"
+		}
+		if inf.def != nil {
+			k.Default = []xml.Token{xml.CharData(*inf.def)}
+		}
+		keys[i] = k
+	}
+	return keys
+}
+
+// undirectedAttr marks an edge as undirected in a directed graph.
+var undirectedAttr = directedAttr(false)
+
+// directedAttr returns a GraphML attribute that overrides the default direction of an edge.
+func directedAttr(v bool) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: "directed"}, Value: strconv.FormatBool(v)}
+}
+
+// edgeDirected reports if the edge is directed, taking the default of the graph into account.
+func edgeDirected(g *graphml.Graph, e *graphml.Edge) bool {
+	return e.EffectiveDirection(g) == graphml.EdgeDirected
+}
+
+// walkGraph calls fn for the graph and all graphs nested into its nodes.
+func walkGraph(g *graphml.Graph, fn func(g *graphml.Graph)) {
+	fn(g)
+	for i := range g.Nodes {
+		for j := range g.Nodes[i].Graphs {
+			walkGraph(&g.Nodes[i].Graphs[j], fn)
+		}
+	}
+}
@@ -0,0 +1,1400 @@
+package convert
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dennwc/graphml"
+	"github.com/stretchr/testify/require"
+)
+
+const testDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="label" attr.type="string"/>
+  <key id="d1" for="edge" attr.name="weight" attr.type="double"/>
+  <graph id="G" edgedefault="directed">
+    <node id="n0"><data key="d0">first</data></node>
+    <node id="n1"><data key="d0">second "node"</data>
+      <graph id="n1:" edgedefault="directed">
+        <node id="n1::n0"/>
+      </graph>
+    </node>
+    <edge id="e0" source="n0" target="n1"><data key="d1">1.5</data></edge>
+  </graph>
+</graphml>`
+
+func decodeTestDoc(t testing.TB) *graphml.Document {
+	doc, err := graphml.Decode(strings.NewReader(testDoc))
+	require.NoError(t, err)
+	return doc
+}
+
+func TestToDOT(t *testing.T) {
+	doc := decodeTestDoc(t)
+	buf := new(bytes.Buffer)
+	err := ToDOT(buf, doc, nil)
+	require.NoError(t, err)
+	require.Equal(t, `digraph "G" {
+	"n0" [label="first"];
+	"n1" [label="second \"node\""];
+	subgraph "cluster_n1:" {
+		label="second \"node\"";
+		"n1::n0";
+	}
+	"n0" -> "n1" [weight="1.5"];
+}
+`, buf.String())
+
+	// edges with a direction different from the graph
+	g := &doc.Graphs[0]
+	g.Edges[0].Unrecognized = append(g.Edges[0].Unrecognized, xml.Attr{Name: xml.Name{Local: "directed"}, Value: "false"})
+	g.Nodes[1].Graphs[0].EdgeDefault = graphml.EdgeUndirected
+	g.Nodes[1].Graphs[0].Edges = []graphml.Edge{{Source: "n1::n0", Target: "n1::n0"}}
+	buf.Reset()
+	require.NoError(t, ToDOT(buf, doc, nil))
+	require.Contains(t, buf.String(), `"n0" -> "n1" [weight="1.5", dir=none];`)
+	require.Contains(t, buf.String(), `"n1::n0" -> "n1::n0" [dir=none];`)
+
+	g.EdgeDefault = graphml.EdgeUndirected
+	g.Edges[0].Unrecognized[len(g.Edges[0].Unrecognized)-1].Value = "true"
+	buf.Reset()
+	require.NoError(t, ToDOT(buf, doc, nil))
+	require.Contains(t, buf.String(), `graph "G" {`)
+	require.Contains(t, buf.String(), `"n0" -- "n1" [weight="1.5", dir=forward];`)
+	require.Contains(t, buf.String(), `"n1::n0" -- "n1::n0";`)
+}
+
+func TestFromDOT(t *testing.T) {
+	doc, err := FromDOT(strings.NewReader(`
+// comment
+digraph G {
+	node [shape=box]
+	a [label="A" + " node", weight=2];
+	a -> b -> {c d} [weight=1.5];
+	subgraph cluster_x {
+		label = "group";
+		e;
+	}
+	/* multi-line
+	   comment */
+	e -> a:p1:n;
+}
+`))
+	require.NoError(t, err)
+	require.Len(t, doc.Graphs, 1)
+	g := doc.Graphs[0]
+	require.Equal(t, "G", g.ID)
+	require.Equal(t, graphml.EdgeDirected, g.EdgeDefault)
+
+	var ids []string
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	require.Equal(t, []string{"a", "b", "c", "d", "x"}, ids)
+	require.Len(t, g.Edges, 4)
+	require.Equal(t, "e", g.Edges[3].Source)
+
+	x := g.Nodes[4]
+	require.Len(t, x.Graphs, 1)
+	require.Equal(t, "x:", x.Graphs[0].ID)
+	require.Equal(t, "e", x.Graphs[0].Nodes[0].ID)
+
+	keys := newKeyIndex(doc)
+	label, _ := keys.Attr(graphml.KindNode, g.Nodes[0].Data, "label")
+	require.Equal(t, "A node", label)
+	shape, _ := keys.Attr(graphml.KindNode, g.Nodes[1].Data, "shape")
+	require.Equal(t, "box", shape)
+
+	types := make(map[string]string)
+	for _, k := range doc.Keys {
+		types[string(k.For)+"/"+k.Name] = k.Type
+	}
+	require.Equal(t, map[string]string{
+		"node/shape":  "string",
+		"node/label":  "string",
+		"node/weight": "int",
+		"edge/weight": "double",
+		"graph/label": "string",
+	}, types)
+
+	// document must be valid
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.Encode(buf, doc))
+	_, err = graphml.Decode(buf)
+	require.NoError(t, err)
+}
+
+func TestFromDOTClusters(t *testing.T) {
+	doc, err := FromDOT(strings.NewReader(`
+strict graph {
+	subgraph cluster_ { a }
+	subgraph cluster_ { b; a -- b [color=red] }
+	subgraph cluster_ { }
+	subgraph cluster_y { c }
+	cluster_y;
+	z; cluster_z;
+	subgraph cluster_z { d }
+	b -- a [weight=2];
+	a -- b;
+	c -> c;
+}
+`))
+	require.NoError(t, err)
+	g := doc.Graphs[0]
+	var ids []string
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	require.Equal(t, []string{"cluster_", "y", "cluster_y", "z", "cluster_z", "cluster_z_1"}, ids)
+	require.Len(t, g.Nodes[0].Graphs, 1)
+	sub := g.Nodes[0].Graphs[0]
+	require.Len(t, sub.Nodes, 2)
+	require.Len(t, sub.Edges, 1)
+	require.Len(t, g.Edges, 1)
+
+	keys := newKeyIndex(doc)
+	color, _ := keys.Attr(graphml.KindEdge, sub.Edges[0].Data, "color")
+	require.Equal(t, "red", color)
+	weight, _ := keys.Attr(graphml.KindEdge, sub.Edges[0].Data, "weight")
+	require.Equal(t, "2", weight)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.Encode(buf, doc))
+	_, err = graphml.Decode(buf)
+	require.NoError(t, err)
+}
+
+func TestDOTRoundtrip(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToDOT(buf, decodeTestDoc(t), nil))
+	exp := buf.String()
+
+	doc, err := FromDOT(buf)
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, ToDOT(buf, doc, nil))
+	require.Equal(t, exp, buf.String())
+}
+
+func TestToGEXF(t *testing.T) {
+	doc := decodeTestDoc(t)
+	doc.Keys = append(doc.Keys, graphml.NewKey(graphml.KindNode, "d2", "kind", "int"))
+	doc.Keys[2].Default = []xml.Token{xml.CharData("3")}
+	doc.Graphs[0].Nodes[0].Data = append(doc.Graphs[0].Nodes[0].Data, textData("d2", "5"))
+
+	buf := new(bytes.Buffer)
+	err := ToGEXF(buf, doc)
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://gexf.net/1.3" xmlns:viz="http://gexf.net/1.3/viz" version="1.3">
+  <meta>
+    <creator>github.com/dennwc/graphml</creator>
+  </meta>
+  <graph defaultedgetype="directed" mode="static">
+    <attributes class="node" mode="static">
+      <attribute id="d2" title="kind" type="integer">
+        <default>3</default>
+      </attribute>
+    </attributes>
+    <nodes>
+      <node id="n0" label="first">
+        <attvalues>
+          <attvalue for="d2" value="5"></attvalue>
+        </attvalues>
+      </node>
+      <node id="n1" label="second &#34;node&#34;">
+        <nodes>
+          <node id="n1::n0"></node>
+        </nodes>
+      </node>
+    </nodes>
+    <edges>
+      <edge id="e0" source="n0" target="n1" weight="1.5"></edge>
+    </edges>
+  </graph>
+</gexf>`, buf.String())
+}
+
+func TestFromGEXF(t *testing.T) {
+	doc, err := FromGEXF(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://www.gexf.net/1.2draft" xmlns:viz="http://www.gexf.net/1.2draft/viz" version="1.2">
+  <graph mode="dynamic" defaultedgetype="undirected">
+    <attributes class="node">
+      <attribute id="0" title="url" type="string"/>
+      <attribute id="1" title="indegree" type="integer">
+        <default>0</default>
+      </attribute>
+    </attributes>
+    <nodes>
+      <node id="a" label="A" start="2009-01-01">
+        <attvalues>
+          <attvalue for="0" value="http://example.com"/>
+          <attvalue for="1" value="1" start="2009-01-01" end="2009-02-01"/>
+          <attvalue for="1" value="2" start="2009-02-01"/>
+        </attvalues>
+        <viz:color r="255" g="0" b="0"/>
+        <viz:position x="1.5" y="-2" z="0"/>
+        <spells>
+          <spell start="2009-01-01" end="2009-01-15"/>
+          <spell start="2009-01-30"/>
+        </spells>
+        <nodes>
+          <node id="a1"/>
+        </nodes>
+      </node>
+      <node id="b" label="B"/>
+      <node id="b1" pid="b"/>
+    </nodes>
+    <edges>
+      <edge source="a" target="b" weight="2.5" type="directed"/>
+    </edges>
+  </graph>
+</gexf>`))
+	require.NoError(t, err)
+	require.Len(t, doc.Graphs, 1)
+	g := doc.Graphs[0]
+	require.Equal(t, graphml.EdgeUndirected, g.EdgeDefault)
+	require.Len(t, g.Nodes, 2)
+	require.Equal(t, "a1", g.Nodes[0].Graphs[0].Nodes[0].ID)
+	require.Equal(t, "b1", g.Nodes[1].Graphs[0].Nodes[0].ID)
+
+	keys := newKeyIndex(doc)
+	attrs := make(map[string]string)
+	for _, a := range keys.Attrs(graphml.KindNode, g.Nodes[0].Data) {
+		attrs[a.Name] = a.Value
+	}
+	require.Equal(t, map[string]string{
+		"label":    "A",
+		"url":      "http://example.com",
+		"indegree": "2",
+		"r":        "255", "g": "0", "b": "0",
+		"x": "1.5", "y": "-2", "z": "0",
+		"start":  "2009-01-01",
+		"spells": "[2009-01-01,2009-01-15];[2009-01-30,]",
+	}, attrs)
+	k := keys.Lookup(graphml.KindNode, doc.Keys[1].ID)
+	require.Equal(t, "indegree", k.Name)
+	require.Equal(t, "int", k.Type)
+	require.Equal(t, []xml.Token{xml.CharData("0")}, k.Default)
+
+	e := g.Edges[0]
+	w, _ := keys.Attr(graphml.KindEdge, e.Data, "weight")
+	require.Equal(t, "2.5", w)
+	require.Equal(t, []xml.Attr{{Name: xml.Name{Local: "directed"}, Value: "true"}}, e.Unrecognized)
+}
+
+func TestGEXFRoundtrip(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToGEXF(buf, decodeTestDoc(t)))
+	exp := buf.String()
+
+	doc, err := FromGEXF(buf)
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, ToGEXF(buf, doc))
+	require.Equal(t, exp, buf.String())
+}
+
+func TestFromGML(t *testing.T) {
+	doc, err := FromGML(strings.NewReader(`Creator "test"
+# comment
+graph [
+  directed 1
+  name "test &quot;graph&quot;"
+  node [ id 1 label "A" graphics [ x 1.5 y 2 ] ]
+  node [ id 2 label "B" isGroup 1 ]
+  node [ id 3 label "C" gid 2 weight 3 ]
+  edge [ source 1 target 3 weight 0.5 ]
+]`))
+	require.NoError(t, err)
+	g := doc.Graphs[0]
+	require.Equal(t, graphml.EdgeDirected, g.EdgeDefault)
+	require.Len(t, g.Nodes, 2)
+	require.Equal(t, "n1", g.Nodes[0].ID)
+	require.Equal(t, "n3", g.Nodes[1].Graphs[0].Nodes[0].ID)
+	require.Equal(t, "n3", g.Edges[0].Target)
+
+	keys := newKeyIndex(doc)
+	name, _ := keys.Attr(graphml.KindGraph, g.Data, "name")
+	require.Equal(t, `test "graph"`, name)
+	x, _ := keys.Attr(graphml.KindNode, g.Nodes[0].Data, "graphics.x")
+	require.Equal(t, "1.5", x)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToGML(buf, doc))
+	require.Equal(t, `Creator "github.com/dennwc/graphml"
+graph [
+  directed 1
+  name "test &quot;graph&quot;"
+  node [
+    id 0
+    label "A"
+    graphics [
+      x 1.5
+      y 2
+    ]
+  ]
+  node [
+    id 1
+    isGroup 1
+    label "B"
+  ]
+  node [
+    id 2
+    gid 1
+    label "C"
+    weight 3
+  ]
+  edge [
+    source 0
+    target 2
+    weight 0.5
+  ]
+]
+`, buf.String())
+}
+
+func TestPajek(t *testing.T) {
+	const src = `*Vertices 3
+1 "A" 0.1 0.2
+2 "B node"
+3 "C"
+*Arcs
+1 2 1.5
+*Edges
+2 3
+`
+	doc, err := FromPajek(strings.NewReader("% comment\n" + src))
+	require.NoError(t, err)
+	g := doc.Graphs[0]
+	require.Len(t, g.Nodes, 3)
+	require.Len(t, g.Edges, 2)
+	require.True(t, edgeDirected(&g, &g.Edges[0]))
+	require.False(t, edgeDirected(&g, &g.Edges[1]))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToPajek(buf, doc))
+	require.Equal(t, src, buf.String())
+}
+
+func TestTGF(t *testing.T) {
+	const src = `1 January
+2 March
+3
+#
+1 2 Label of edge
+2 3
+`
+	doc, err := FromTGF(strings.NewReader(src))
+	require.NoError(t, err)
+	g := doc.Graphs[0]
+	require.Len(t, g.Nodes, 3)
+	require.Len(t, g.Edges, 2)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToTGF(buf, doc))
+	require.Equal(t, src, buf.String())
+}
+
+func TestToCSV(t *testing.T) {
+	doc := decodeTestDoc(t)
+	nodes, edges := new(bytes.Buffer), new(bytes.Buffer)
+	require.NoError(t, ToCSV(nodes, edges, doc, nil))
+	require.Equal(t, `id,label
+n0,first
+n1,"second ""node"""
+n1::n0,
+`, nodes.String())
+	require.Equal(t, `source,target,id,weight
+n0,n1,e0,1.5
+`, edges.String())
+}
+
+func TestFromCSV(t *testing.T) {
+	doc, err := FromCSV(strings.NewReader(`name;size;kind
+a;1;x
+b;2.5;
+`), strings.NewReader(`from;to;weight
+a;b;1
+b;c;2
+`), &CSVMapping{
+		CSVOptions: CSVOptions{Comma: ';'},
+		ID:         "name",
+		Source:     "from", Target: "to",
+		Types: map[string]string{"weight": "double"},
+	})
+	require.NoError(t, err)
+	g := doc.Graphs[0]
+	require.Len(t, g.Nodes, 3)
+	require.Equal(t, "c", g.Nodes[2].ID)
+	require.Len(t, g.Nodes[1].Data, 1)
+	require.Equal(t, "e0", g.Edges[0].ID)
+
+	types := make(map[string]string)
+	for _, k := range doc.Keys {
+		types[string(k.For)+"/"+k.Name] = k.Type
+	}
+	require.Equal(t, map[string]string{
+		"node/size":   "double",
+		"node/kind":   "string",
+		"edge/weight": "double",
+	}, types)
+}
+
+func TestJGF(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToJGF(buf, decodeTestDoc(t)))
+	exp := `{
+  "graph": {
+    "id": "G",
+    "directed": true,
+    "nodes": {
+      "n0": {
+        "label": "first"
+      },
+      "n1": {
+        "label": "second \"node\""
+      },
+      "n1::n0": {}
+    },
+    "edges": [
+      {
+        "id": "e0",
+        "source": "n0",
+        "target": "n1",
+        "metadata": {
+          "weight": 1.5
+        }
+      }
+    ]
+  }
+}
+`
+	require.Equal(t, exp, buf.String())
+
+	doc, err := FromJGF(buf)
+	require.NoError(t, err)
+	require.Len(t, doc.Graphs[0].Nodes, 3)
+	buf.Reset()
+	require.NoError(t, ToJGF(buf, doc))
+	require.Equal(t, exp, buf.String())
+}
+
+func TestToCytoscapeJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToCytoscapeJSON(buf, decodeTestDoc(t)))
+	require.Equal(t, `{
+  "elements": {
+    "nodes": [
+      {
+        "data": {
+          "id": "n0",
+          "label": "first"
+        }
+      },
+      {
+        "data": {
+          "id": "n1",
+          "label": "second \"node\""
+        }
+      },
+      {
+        "data": {
+          "id": "n1::n0",
+          "parent": "n1"
+        }
+      }
+    ],
+    "edges": [
+      {
+        "data": {
+          "id": "e0",
+          "source": "n0",
+          "target": "n1",
+          "weight": 1.5
+        }
+      }
+    ]
+  }
+}
+`, buf.String())
+}
+
+func TestToD3(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToD3(buf, decodeTestDoc(t), &D3Options{NumericIDs: true}))
+	require.Equal(t, `{
+  "nodes": [
+    {
+      "id": 0,
+      "name": "n0",
+      "label": "first"
+    },
+    {
+      "id": 1,
+      "name": "n1",
+      "label": "second \"node\""
+    },
+    {
+      "id": 2,
+      "name": "n1::n0"
+    }
+  ],
+  "links": [
+    {
+      "source": 0,
+      "target": 1,
+      "weight": 1.5
+    }
+  ]
+}
+`, buf.String())
+}
+
+func TestToGraphSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToGraphSON(buf, decodeTestDoc(t), GraphSONv3))
+	require.Equal(t, `{"id":"n0","label":"vertex","outE":{"edge":[{"id":"e0","inV":"n1","properties":{"weight":{"@type":"g:Double","@value":1.5}}}]},"properties":{"label":[{"id":{"@type":"g:Int64","@value":1},"value":"first"}]}}
+{"id":"n1","label":"vertex","inE":{"edge":[{"id":"e0","outV":"n0","properties":{"weight":{"@type":"g:Double","@value":1.5}}}]},"properties":{"label":[{"id":{"@type":"g:Int64","@value":2},"value":"second \"node\""}]}}
+{"id":"n1::n0","label":"vertex"}
+`, buf.String())
+}
+
+func TestToNeo4jCSV(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ToNeo4jCSV(dir, decodeTestDoc(t)))
+
+	data, err := os.ReadFile(filepath.Join(dir, Neo4jNodesFile))
+	require.NoError(t, err)
+	require.Equal(t, `id:ID,label:string,:LABEL
+n0,first,Node
+n1,"second ""node""",Node
+n1::n0,,Node
+`, string(data))
+
+	data, err = os.ReadFile(filepath.Join(dir, Neo4jRelationshipsFile))
+	require.NoError(t, err)
+	require.Equal(t, `:START_ID,:END_ID,:TYPE,id:string,weight:double
+n0,n1,RELATED_TO,e0,1.5
+`, string(data))
+}
+
+func TestToPlantUML(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToPlantUML(buf, decodeTestDoc(t), nil))
+	require.Equal(t, `@startuml
+component "first" as n0
+package "second 'node'" as n1 {
+  component "n1::n0" as n1__n0
+}
+n0 --> n1
+@enduml
+`, buf.String())
+}
+
+func TestToD2(t *testing.T) {
+	doc := decodeTestDoc(t)
+	g := &doc.Graphs[0]
+	g.Edges = append(g.Edges, graphml.Edge{Source: "n1::n0", Target: "n0"})
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToD2(buf, doc))
+	require.Equal(t, `n0: "first"
+n1: "second \"node\"" {
+  "n1::n0"
+}
+n0 -> n1
+n1."n1::n0" -> n0
+`, buf.String())
+}
+
+func TestToAdjacencyMatrix(t *testing.T) {
+	doc := decodeTestDoc(t)
+	g := &doc.Graphs[0]
+	g.Edges = append(g.Edges, graphml.Edge{Source: "n1::n0", Target: "n0"})
+	m, err := ToAdjacencyMatrix(g, "d1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"n0", "n1", "n1::n0"}, m.Nodes)
+	require.Equal(t, [][]float64{
+		{0, 1.5, 0},
+		{0, 0, 0},
+		{1, 0, 0},
+	}, m.Dense())
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, m.WriteCSV(buf))
+	require.Equal(t, `,n0,n1,n1::n0
+n0,0,1.5,0
+n1,0,0,0
+n1::n0,1,0,0
+`, buf.String())
+}
+
+func TestLEDA(t *testing.T) {
+	const src = `LEDA.GRAPH
+string
+int
+-1
+3
+|{v1}|
+|{v2}|
+|{}|
+2
+1 2 0 |{5}|
+2 3 0 |{7}|
+`
+	doc, err := FromLEDA(strings.NewReader("# comment\n"+src), nil)
+	require.NoError(t, err)
+	g := doc.Graphs[0]
+	require.Len(t, g.Nodes, 3)
+	require.Equal(t, "n3", g.Edges[1].Target)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToLEDA(buf, doc, nil))
+	require.Equal(t, src, buf.String())
+}
+
+func TestDL(t *testing.T) {
+	doc, err := FromDL(strings.NewReader(`DL n=3
+format = fullmatrix
+labels:
+a,b,"c d"
+data:
+0 1 0
+1 0 2
+0 2 0
+`))
+	require.NoError(t, err)
+	g := doc.Graphs[0]
+	require.Equal(t, graphml.EdgeUndirected, g.EdgeDefault)
+	require.Len(t, g.Nodes, 3)
+	require.Len(t, g.Edges, 2)
+	require.Equal(t, "n2", g.Edges[1].Source)
+	require.Equal(t, "n3", g.Edges[1].Target)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToDL(buf, doc, DLEdgeList))
+	require.Equal(t, `dl n=3
+format = edgelist1
+labels:
+a,b,"c d"
+data:
+1 2 1
+2 1 1
+2 3 2
+3 2 2
+`, buf.String())
+
+	doc, err = FromDL(strings.NewReader(`dl n=2 format=edgelist1
+labels embedded
+data:
+x y
+y x 3
+`))
+	require.NoError(t, err)
+	g = doc.Graphs[0]
+	require.Equal(t, graphml.EdgeDirected, g.EdgeDefault)
+	require.Len(t, g.Nodes, 2)
+	require.Len(t, g.Edges, 2)
+}
+
+func TestGDF(t *testing.T) {
+	doc := decodeTestDoc(t)
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToGDF(buf, doc))
+	require.Equal(t, `nodedef>name VARCHAR,label VARCHAR
+n0,first
+n1,'second "node"'
+n1::n0,
+edgedef>node1 VARCHAR,node2 VARCHAR,directed BOOLEAN,weight DOUBLE
+n0,n1,true,1.5
+`, buf.String())
+
+	doc, err := FromGDF(strings.NewReader(`nodedef>name VARCHAR,label VARCHAR,size INT default 3
+s1,'Site, number 1',10
+s2,Site 2,
+edgedef>node1 VARCHAR,node2 VARCHAR,directed BOOLEAN,weight DOUBLE
+s1,s2,false,1.0
+s2,s3,true,2.5
+`))
+	require.NoError(t, err)
+	g := doc.Graphs[0]
+	require.Equal(t, graphml.EdgeUndirected, g.EdgeDefault)
+	require.Len(t, g.Nodes, 3)
+	require.Equal(t, "Site, number 1", string(g.Nodes[0].Data[0].Data[0].(xml.CharData)))
+	require.Len(t, g.Nodes[1].Data, 1)
+	require.Len(t, g.Edges, 2)
+	require.Equal(t, []xml.Attr{directedAttr(true)}, g.Edges[1].Unrecognized)
+	require.Equal(t, "int", doc.Keys[1].Type)
+	require.Equal(t, []xml.Token{xml.CharData("3")}, doc.Keys[1].Default)
+}
+
+func TestYAML(t *testing.T) {
+	doc := decodeTestDoc(t)
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToYAML(buf, doc))
+	require.Equal(t, `keys:
+  - id: d0
+    for: node
+    name: label
+    type: string
+  - id: d1
+    for: edge
+    name: weight
+    type: double
+graphs:
+  - id: G
+    directed: true
+    nodes:
+      - id: n0
+        data:
+          label: first
+      - id: n1
+        data:
+          label: second "node"
+        graphs:
+          - id: 'n1:'
+            directed: true
+            nodes:
+              - id: n1::n0
+    edges:
+      - id: e0
+        source: n0
+        target: n1
+        data:
+          weight: 1.5
+`, buf.String())
+
+	doc2, err := FromYAML(buf)
+	require.NoError(t, err)
+	require.Equal(t, doc.Keys, doc2.Keys)
+	require.Equal(t, doc.Graphs, doc2.Graphs)
+
+	doc2, err = FromYAML(strings.NewReader(`
+graphs:
+  - nodes:
+      - id: a
+        data: {size: 2, name: "x"}
+      - id: b
+        data: {size: 2.5}
+    edges:
+      - {source: a, target: b, directed: false}
+`))
+	require.NoError(t, err)
+	require.Equal(t, []graphml.Key{
+		graphml.NewKey(graphml.KindNode, "d0", "size", "double"),
+		graphml.NewKey(graphml.KindNode, "d1", "name", "string"),
+	}, doc2.Keys)
+	require.Equal(t, []xml.Attr{undirectedAttr}, doc2.Graphs[0].Edges[0].Unrecognized)
+}
+
+func TestToSQL(t *testing.T) {
+	doc := decodeTestDoc(t)
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToSQL(buf, doc, SQLSQLite))
+	require.Equal(t, `CREATE TABLE nodes (
+	id TEXT PRIMARY KEY,
+	graph TEXT,
+	"label" TEXT
+);
+CREATE TABLE edges (
+	id TEXT,
+	graph TEXT,
+	source TEXT NOT NULL,
+	target TEXT NOT NULL,
+	directed BOOLEAN NOT NULL,
+	"weight" REAL
+);
+BEGIN;
+INSERT INTO nodes VALUES ('n0', 'G', 'first');
+INSERT INTO nodes VALUES ('n1', 'G', 'second "node"');
+INSERT INTO nodes VALUES ('n1::n0', 'n1:', NULL);
+INSERT INTO edges VALUES ('e0', 'G', 'n0', 'n1', 1, 1.5);
+COMMIT;
+`, buf.String())
+}
+
+// columnarTestDoc returns the test document with an extra edge that has no ID and no weight,
+// so that tables have nulls in both fixed-size and variable-size columns.
+func columnarTestDoc(t testing.TB) *graphml.Document {
+	doc := decodeTestDoc(t)
+	doc.Graphs[0].Edges = append(doc.Graphs[0].Edges, graphml.Edge{Source: "n1", Target: "n0"})
+	return doc
+}
+
+// columnarTable is a table decoded from an Arrow or Parquet file by tests.
+type columnarTable struct {
+	// Fields are column names and types, with "?" suffix for nullable columns.
+	Fields []string
+	// Cols are column values, with nil for nulls.
+	Cols [][]interface{}
+}
+
+func (t *columnarTable) column(name string) []interface{} {
+	for i, f := range t.Fields {
+		if strings.TrimSuffix(f[:strings.IndexByte(f, ':')], "?") == name {
+			return t.Cols[i]
+		}
+	}
+	return nil
+}
+
+// fbReader reads flatbuffers tables.
+type fbReader struct {
+	buf []byte
+	pos int
+}
+
+func fbRoot(buf []byte) fbReader {
+	return fbReader{buf: buf, pos: int(binary.LittleEndian.Uint32(buf))}
+}
+
+// field returns a position of a table field, or 0 if it is not set.
+func (r fbReader) field(id int) int {
+	vt := r.pos - int(int32(binary.LittleEndian.Uint32(r.buf[r.pos:])))
+	if 4+2*id >= int(binary.LittleEndian.Uint16(r.buf[vt:])) {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(r.buf[vt+4+2*id:]))
+	if off == 0 {
+		return 0
+	}
+	return r.pos + off
+}
+
+func (r fbReader) deref(p int) int {
+	return p + int(binary.LittleEndian.Uint32(r.buf[p:]))
+}
+
+func (r fbReader) uint8(id int) int {
+	if p := r.field(id); p != 0 {
+		return int(r.buf[p])
+	}
+	return 0
+}
+
+func (r fbReader) int16(id int) int {
+	if p := r.field(id); p != 0 {
+		return int(int16(binary.LittleEndian.Uint16(r.buf[p:])))
+	}
+	return 0
+}
+
+func (r fbReader) int32(id int) int {
+	if p := r.field(id); p != 0 {
+		return int(int32(binary.LittleEndian.Uint32(r.buf[p:])))
+	}
+	return 0
+}
+
+func (r fbReader) table(id int) fbReader {
+	return fbReader{buf: r.buf, pos: r.deref(r.field(id))}
+}
+
+func (r fbReader) string(id int) string {
+	p := r.deref(r.field(id))
+	n := int(binary.LittleEndian.Uint32(r.buf[p:]))
+	return string(r.buf[p+4 : p+4+n])
+}
+
+// vector returns a position of the first element and the number of elements of a vector.
+func (r fbReader) vector(id int) (int, int) {
+	p := r.deref(r.field(id))
+	return p + 4, int(binary.LittleEndian.Uint32(r.buf[p:]))
+}
+
+// decodeArrow decodes an Arrow IPC file with a single record batch.
+func decodeArrow(t testing.TB, data []byte) *columnarTable {
+	require.Equal(t, arrowMagic+"\x00\x00", string(data[:8]))
+	require.Equal(t, arrowMagic, string(data[len(data)-6:]))
+	n := int(binary.LittleEndian.Uint32(data[len(data)-10:]))
+	footer := fbRoot(data[len(data)-10-n : len(data)-10])
+	require.Equal(t, arrowVersionV5, footer.int16(0))
+
+	out := &columnarTable{}
+	schema := footer.table(1)
+	fields, nf := schema.vector(1)
+	for i := 0; i < nf; i++ {
+		f := fbReader{buf: footer.buf, pos: footer.deref(fields + 4*i)}
+		var typ string
+		switch tt := f.table(3); f.uint8(2) {
+		case arrowTypeUtf8:
+			typ = "utf8"
+		case arrowTypeBool:
+			typ = "bool"
+		case arrowTypeInt:
+			typ = "int" + strconv.Itoa(tt.int32(0))
+		case arrowTypeFloatingPoint:
+			typ = "float" + strconv.Itoa(16<<tt.int16(0))
+		default:
+			t.Fatalf("unexpected type: %d", f.uint8(2))
+		}
+		name := f.string(0)
+		if f.uint8(1) != 0 {
+			name += "?"
+		}
+		out.Fields = append(out.Fields, name+":"+typ)
+	}
+
+	blocks, nb := footer.vector(3)
+	require.Equal(t, 1, nb)
+	off := int(binary.LittleEndian.Uint64(footer.buf[blocks:]))
+	metaLen := int(binary.LittleEndian.Uint32(footer.buf[blocks+8:]))
+	bodyLen := int(binary.LittleEndian.Uint64(footer.buf[blocks+16:]))
+	require.Equal(t, uint32(0xFFFFFFFF), binary.LittleEndian.Uint32(data[off:]))
+	msg := fbRoot(data[off+8 : off+metaLen])
+	require.Equal(t, arrowHeaderRecordBatch, msg.uint8(1))
+	body := data[off+metaLen : off+metaLen+bodyLen]
+	batch := msg.table(2)
+	rows := int(int64(binary.LittleEndian.Uint64(batch.buf[batch.field(0):])))
+	nodes, nn := batch.vector(1)
+	require.Equal(t, nf, nn)
+	buffers, _ := batch.vector(2)
+	next := func() []byte {
+		p := buffers
+		buffers += 16
+		o := int(binary.LittleEndian.Uint64(batch.buf[p:]))
+		return body[o : o+int(binary.LittleEndian.Uint64(batch.buf[p+8:]))]
+	}
+	bit := func(b []byte, i int) bool { return b[i/8]&(1<<uint(i%8)) != 0 }
+	for i, f := range out.Fields {
+		require.Equal(t, rows, int(binary.LittleEndian.Uint64(batch.buf[nodes+16*i:])))
+		nulls := int(binary.LittleEndian.Uint64(batch.buf[nodes+16*i+8:]))
+		valid := next()
+		col := make([]interface{}, rows)
+		typ := f[strings.IndexByte(f, ':')+1:]
+		var offs []byte
+		if typ == "utf8" {
+			offs = next()
+		}
+		vals := next()
+		for j := range col {
+			if nulls != 0 && !bit(valid, j) {
+				nulls--
+				continue
+			}
+			switch typ {
+			case "utf8":
+				col[j] = string(vals[binary.LittleEndian.Uint32(offs[4*j:]):binary.LittleEndian.Uint32(offs[4*j+4:])])
+			case "bool":
+				col[j] = bit(vals, j)
+			case "int32":
+				col[j] = int64(int32(binary.LittleEndian.Uint32(vals[4*j:])))
+			case "int64":
+				col[j] = int64(binary.LittleEndian.Uint64(vals[8*j:]))
+			case "float32":
+				col[j] = float64(math.Float32frombits(binary.LittleEndian.Uint32(vals[4*j:])))
+			case "float64":
+				col[j] = math.Float64frombits(binary.LittleEndian.Uint64(vals[8*j:]))
+			}
+		}
+		require.Zero(t, nulls, f)
+		out.Cols = append(out.Cols, col)
+	}
+	return out
+}
+
+func TestToArrow(t *testing.T) {
+	doc := columnarTestDoc(t)
+	nodes, edges := new(bytes.Buffer), new(bytes.Buffer)
+	require.NoError(t, ToArrow(nodes, edges, doc))
+
+	nt := decodeArrow(t, nodes.Bytes())
+	require.Equal(t, []string{"id:utf8", "graph?:utf8", "label?:utf8"}, nt.Fields)
+	require.Equal(t, []interface{}{"n0", "n1", "n1::n0"}, nt.column("id"))
+	require.Equal(t, []interface{}{"G", "G", "n1:"}, nt.column("graph"))
+	require.Equal(t, []interface{}{"first", `second "node"`, nil}, nt.column("label"))
+
+	et := decodeArrow(t, edges.Bytes())
+	require.Equal(t, []string{"id?:utf8", "graph?:utf8", "source:utf8", "target:utf8", "directed:bool", "weight?:float64"}, et.Fields)
+	require.Equal(t, []interface{}{"e0", nil}, et.column("id"))
+	require.Equal(t, []interface{}{"n0", "n1"}, et.column("source"))
+	require.Equal(t, []interface{}{true, true}, et.column("directed"))
+	require.Equal(t, []interface{}{1.5, nil}, et.column("weight"))
+}
+
+// thriftReader decodes structures encoded with the Thrift compact protocol.
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// Struct decodes a struct as a map of field IDs to values.
+func (r *thriftReader) Struct() map[int]interface{} {
+	out := make(map[int]interface{})
+	last := 0
+	for {
+		b := r.byte()
+		if b == 0 {
+			return out
+		}
+		id := last + int(b>>4)
+		if b>>4 == 0 {
+			id = int(r.zigzag())
+		}
+		last = id
+		out[id] = r.value(b & 0xF)
+	}
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.uvarint())
+		r.pos += n
+		return string(r.buf[r.pos-n : r.pos])
+	case thriftList:
+		h := r.byte()
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = r.value(h & 0xF)
+		}
+		return list
+	case thriftStruct:
+		return r.Struct()
+	}
+	panic("unexpected thrift type: " + strconv.Itoa(int(typ)))
+}
+
+// decodeParquet decodes a Parquet file with a single row group written with PLAIN encoding.
+func decodeParquet(t testing.TB, data []byte) *columnarTable {
+	require.Equal(t, parquetMagic, string(data[:4]))
+	require.Equal(t, parquetMagic, string(data[len(data)-4:]))
+	n := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	r := &thriftReader{buf: data[len(data)-8-n : len(data)-8]}
+	meta := r.Struct()
+	require.Equal(t, n, r.pos)
+	rows := int(meta[3].(int64))
+
+	types := map[int64]string{parquetBoolean: "bool", parquetInt32: "int32", parquetInt64: "int64", parquetFloat: "float32", parquetDouble: "float64", parquetByteArray: "utf8"}
+	out := &columnarTable{}
+	schema := meta[2].([]interface{})
+	require.Equal(t, int64(len(schema)-1), schema[0].(map[int]interface{})[5])
+	for _, e := range schema[1:] {
+		e := e.(map[int]interface{})
+		name := e[4].(string)
+		if e[3] == int64(parquetOptional) {
+			name += "?"
+		}
+		out.Fields = append(out.Fields, name+":"+types[e[1].(int64)])
+	}
+
+	groups := meta[4].([]interface{})
+	require.Len(t, groups, 1)
+	chunks := groups[0].(map[int]interface{})[1].([]interface{})
+	require.Len(t, chunks, len(out.Fields))
+	for i, f := range out.Fields {
+		cm := chunks[i].(map[int]interface{})[3].(map[int]interface{})
+		require.Equal(t, int64(rows), cm[5])
+		r := &thriftReader{buf: data, pos: int(cm[9].(int64))}
+		hdr := r.Struct()
+		require.Equal(t, int64(0), hdr[1]) // DATA_PAGE
+		page := data[r.pos : r.pos+int(hdr[3].(int64))]
+		valid := make([]bool, rows)
+		if strings.HasSuffix(f[:strings.IndexByte(f, ':')], "?") {
+			// definition levels with a single bit-packed run
+			n := int(binary.LittleEndian.Uint32(page))
+			lr := &thriftReader{buf: page[4 : 4+n]}
+			h := lr.uvarint()
+			require.Equal(t, uint64(1), h&1)
+			for j := range valid {
+				valid[j] = lr.buf[lr.pos+j/8]&(1<<uint(j%8)) != 0
+			}
+			page = page[4+n:]
+		} else {
+			for j := range valid {
+				valid[j] = true
+			}
+		}
+		col := make([]interface{}, rows)
+		k := 0 // index of non-null values
+		for j := range col {
+			if !valid[j] {
+				continue
+			}
+			switch f[strings.IndexByte(f, ':')+1:] {
+			case "utf8":
+				n := int(binary.LittleEndian.Uint32(page))
+				col[j] = string(page[4 : 4+n])
+				page = page[4+n:]
+			case "bool":
+				col[j] = page[k/8]&(1<<uint(k%8)) != 0
+			case "int32":
+				col[j] = int64(int32(binary.LittleEndian.Uint32(page[4*k:])))
+			case "int64":
+				col[j] = int64(binary.LittleEndian.Uint64(page[8*k:]))
+			case "float32":
+				col[j] = float64(math.Float32frombits(binary.LittleEndian.Uint32(page[4*k:])))
+			case "float64":
+				col[j] = math.Float64frombits(binary.LittleEndian.Uint64(page[8*k:]))
+			}
+			k++
+		}
+		out.Cols = append(out.Cols, col)
+	}
+	return out
+}
+
+func TestToParquet(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ToParquet(dir, columnarTestDoc(t)))
+	read := func(name string) *columnarTable {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return decodeParquet(t, data)
+	}
+
+	nt := read(ParquetNodesFile)
+	require.Equal(t, []string{"id:utf8", "graph?:utf8", "label?:utf8"}, nt.Fields)
+	require.Equal(t, []interface{}{"n0", "n1", "n1::n0"}, nt.column("id"))
+	require.Equal(t, []interface{}{"first", `second "node"`, nil}, nt.column("label"))
+
+	et := read(ParquetEdgesFile)
+	require.Equal(t, []string{"id?:utf8", "graph?:utf8", "source:utf8", "target:utf8", "directed:bool", "weight?:float64"}, et.Fields)
+	require.Equal(t, []interface{}{"e0", nil}, et.column("id"))
+	require.Equal(t, []interface{}{"n1", "n0"}, et.column("target"))
+	require.Equal(t, []interface{}{true, true}, et.column("directed"))
+	require.Equal(t, []interface{}{1.5, nil}, et.column("weight"))
+}
+
+func TestToTurtle(t *testing.T) {
+	doc := decodeTestDoc(t)
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToTurtle(buf, doc, "http://example.org/", nil))
+	require.Equal(t, `<http://example.org/node/n0>
+	<http://example.org/attr/label> "first" ;
+	<http://example.org/edge/e0> <http://example.org/node/n1> .
+<http://example.org/node/n1>
+	<http://example.org/attr/label> "second \"node\"" .
+<http://example.org/edge/e0>
+	<http://example.org/attr/weight> "1.5"^^<http://www.w3.org/2001/XMLSchema#double> .
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, ToTurtle(buf, doc, "http://example.org/", &TurtleOptions{Predicate: "weight"}))
+	require.Contains(t, buf.String(), "<http://example.org/rel/1.5> <http://example.org/node/n1> .")
+}
+
+func TestRenderSVG(t *testing.T) {
+	doc := decodeTestDoc(t)
+	buf := new(bytes.Buffer)
+	require.NoError(t, RenderSVG(buf, doc, nil))
+	var v struct {
+		Circles []struct{} `xml:"g>circle"`
+		Lines   []struct{} `xml:"g>line"`
+		Texts   []string   `xml:"g>text"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &v))
+	require.Len(t, v.Circles, 3)
+	require.Len(t, v.Lines, 1)
+	require.Equal(t, []string{"first", `second "node"`, "n1::n0"}, v.Texts)
+}
+
+func TestGraph6(t *testing.T) {
+	// Petersen graph from nauty examples and sparse6 example from the format description
+	doc, err := FromGraph6(strings.NewReader(">>graph6<<IheA@GUAo\n:Fa@x^\n"))
+	require.NoError(t, err)
+	require.Len(t, doc.Graphs, 2)
+	require.Len(t, doc.Graphs[0].Nodes, 10)
+	require.Len(t, doc.Graphs[0].Edges, 15)
+	require.Len(t, doc.Graphs[1].Nodes, 7)
+	require.Len(t, doc.Graphs[1].Edges, 4)
+	require.Equal(t, "g1n0", doc.Graphs[1].Edges[0].Source)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToGraph6(buf, doc))
+	require.Equal(t, "IheA@GUAo\nFw??G\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, ToSparse6(buf, &graphml.Document{Graphs: doc.Graphs[1:]}))
+	require.Equal(t, ":Fa@x^\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, ToSparse6(buf, doc))
+	doc2, err := FromGraph6(buf)
+	require.NoError(t, err)
+	require.Equal(t, doc.Graphs, doc2.Graphs)
+}
+
+func TestMatrixMarket(t *testing.T) {
+	doc, err := FromMatrixMarket(strings.NewReader(`%%MatrixMarket matrix coordinate real symmetric
+% comment
+3 3 3
+1 1 2.5
+2 1 1
+3 2 -1
+`))
+	require.NoError(t, err)
+	g := doc.Graphs[0]
+	require.Equal(t, graphml.EdgeUndirected, g.EdgeDefault)
+	require.Len(t, g.Nodes, 3)
+	require.Len(t, g.Edges, 3)
+	require.Equal(t, "n3", g.Edges[2].Source)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToMatrixMarket(buf, doc))
+	require.Equal(t, `%%MatrixMarket matrix coordinate real symmetric
+3 3 3
+1 1 2.5
+2 1 1
+3 2 -1
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, ToMatrixMarket(buf, decodeTestDoc(t)))
+	require.Equal(t, `%%MatrixMarket matrix coordinate real general
+3 3 1
+1 2 1.5
+`, buf.String())
+}
+
+func TestDIMACS(t *testing.T) {
+	const src = `p max 3 2
+n 1 s
+n 3 t
+a 1 2 5
+a 2 3 3.5
+`
+	doc, err := FromDIMACS(strings.NewReader("c max flow\n" + src))
+	require.NoError(t, err)
+	g := doc.Graphs[0]
+	require.Equal(t, graphml.EdgeDirected, g.EdgeDefault)
+	require.Len(t, g.Nodes, 3)
+	require.Len(t, g.Edges, 2)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToDIMACS(buf, doc, DIMACSMaxFlow))
+	require.Equal(t, src, buf.String())
+
+	buf.Reset()
+	require.NoError(t, ToDIMACS(buf, doc, DIMACSEdge))
+	require.Equal(t, "p edge 3 2\ne 1 2\ne 2 3\n", buf.String())
+
+	doc, err = FromDIMACS(buf)
+	require.NoError(t, err)
+	require.Equal(t, graphml.EdgeUndirected, doc.Graphs[0].EdgeDefault)
+}
+
+func TestToQuads(t *testing.T) {
+	doc := decodeTestDoc(t)
+	buf := new(bytes.Buffer)
+	require.NoError(t, ToQuads(buf, doc, nil))
+	require.Equal(t, `<urn:graphml:node/n0> <urn:graphml:attr/label> "first" <urn:graphml:graph/G> .
+<urn:graphml:node/n1> <urn:graphml:attr/label> "second \"node\"" <urn:graphml:graph/G> .
+<urn:graphml:node/n0> <urn:graphml:edge/e0> <urn:graphml:node/n1> <urn:graphml:graph/G> .
+<urn:graphml:edge/e0> <urn:graphml:attr/weight> "1.5"^^<http://www.w3.org/2001/XMLSchema#double> <urn:graphml:graph/G> .
+`, buf.String())
+}
+
+func TestLayout(t *testing.T) {
+	doc := decodeTestDoc(t)
+	require.NoError(t, Layout(doc, LayoutEngine("graphml-no-such-layout")))
+	keys := newKeyIndex(doc)
+	var pos [][2]float64
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for _, n := range g.Nodes {
+				x, ok := keys.Attr(graphml.KindNode, n.Data, "x")
+				require.True(t, ok)
+				y, ok := keys.Attr(graphml.KindNode, n.Data, "y")
+				require.True(t, ok)
+				pos = append(pos, [2]float64{parseFloat(t, x), parseFloat(t, y)})
+			}
+		})
+	}
+	require.Len(t, pos, 3)
+	require.NotEqual(t, pos[0], pos[1])
+	require.Equal(t, "x", doc.Keys[2].ID)
+
+	// second run replaces existing positions
+	require.NoError(t, Layout(doc, LayoutBuiltin))
+	require.Len(t, doc.Keys, 4)
+	require.Len(t, doc.Graphs[0].Nodes[0].Data, 3)
+
+	pos, err := parsePlain(strings.NewReader(`graph 1 2.5 3.5
+node n1 1.25 3 0.75 0.5 n1 solid ellipse black lightgrey
+node n0 1.25 0.5 0.75 0.5 n0 solid ellipse black lightgrey
+edge n0 n1 4 1.25 2.6 1.25 2.2 1.25 1.8 1.25 1.4 solid black
+stop
+`), 2)
+	require.NoError(t, err)
+	require.Equal(t, [][2]float64{{90, 216}, {90, 36}}, pos)
+}
+
+func parseFloat(t testing.TB, s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	require.NoError(t, err)
+	return f
+}
+
+func TestToXLSX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.xlsx")
+	require.NoError(t, ToXLSX(path, decodeTestDoc(t)))
+
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer zr.Close()
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = string(data)
+	}
+	require.Contains(t, files, "[Content_Types].xml")
+	require.Contains(t, files["xl/workbook.xml"], `<sheet name="Nodes" sheetId="1" r:id="rId1"/><sheet name="Edges" sheetId="2" r:id="rId2"/>`)
+	require.Contains(t, files["xl/worksheets/sheet1.xml"], `<c r="C3" t="inlineStr"><is><t xml:space="preserve">second &#34;node&#34;</t></is></c>`)
+	require.Contains(t, files["xl/worksheets/sheet2.xml"], `<c r="E2" t="b"><v>1</v></c><c r="F2"><v>1.5</v></c>`)
+	for name, data := range files {
+		if strings.HasSuffix(name, ".xml") || strings.HasSuffix(name, ".rels") {
+			dec := xml.NewDecoder(strings.NewReader(data))
+			for {
+				_, err := dec.Token()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err, name)
+			}
+		}
+	}
+
+	require.Equal(t, "A", xlsxColumn(0))
+	require.Equal(t, "Z", xlsxColumn(25))
+	require.Equal(t, "AA", xlsxColumn(26))
+	require.Equal(t, "BA", xlsxColumn(52))
+}
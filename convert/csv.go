@@ -0,0 +1,355 @@
+package convert
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// CSVOptions controls the conversion to and from CSV.
+type CSVOptions struct {
+	// Comma is a field delimiter. Defaults to ','.
+	Comma rune
+	// Attrs is a list of attribute names (or key IDs, if the name is not set) to export.
+	// All attributes are exported by default.
+	Attrs []string
+}
+
+func (opt *CSVOptions) comma() rune {
+	if opt == nil || opt.Comma == 0 {
+		return ','
+	}
+	return opt.Comma
+}
+
+// csvColumns returns keys that should be exported for a given kind.
+func (opt *CSVOptions) columns(doc *graphml.Document, kind graphml.Kind) []*graphml.Key {
+	var cols []*graphml.Key
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if k.For != kind && k.For != graphml.KindAll {
+			continue
+		}
+		if opt != nil && opt.Attrs != nil {
+			name := keyName(k)
+			found := false
+			for _, a := range opt.Attrs {
+				if a == name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		cols = append(cols, k)
+	}
+	return cols
+}
+
+// attrColumns returns keys for a given kind, skipping attributes that clash with reserved column names.
+func attrColumns(doc *graphml.Document, kind graphml.Kind, reserved ...string) []*graphml.Key {
+	var out []*graphml.Key
+keys:
+	for _, k := range (*CSVOptions)(nil).columns(doc, kind) {
+		for _, name := range reserved {
+			if strings.EqualFold(keyName(k), name) {
+				continue keys
+			}
+		}
+		out = append(out, k)
+	}
+	return out
+}
+
+// keyName returns the name of the attribute, or key ID if the name is not set.
+func keyName(k *graphml.Key) string {
+	if k.Name != "" {
+		return k.Name
+	}
+	return k.ID
+}
+
+// csvValues returns values of data for each column. Default key values are used for missing data.
+func csvValues(cols []*graphml.Key, data []graphml.Data) []string {
+	out := make([]string, len(cols))
+	for i, k := range cols {
+		if k.Default != nil {
+			out[i], _ = graphml.TokensText(k.Default)
+		}
+		for _, d := range data {
+			if d.Key == k.ID {
+				if v, ok := dataText(d); ok {
+					out[i] = v
+				}
+				break
+			}
+		}
+	}
+	return out
+}
+
+// attrValue returns a text value of the attribute, or its default value if the data is missing.
+func attrValue(k *graphml.Key, data []graphml.Data) (string, bool) {
+	for _, d := range data {
+		if d.Key == k.ID {
+			if v, ok := dataText(d); ok {
+				return v, true
+			}
+			break
+		}
+	}
+	if k.Default != nil {
+		return graphml.TokensText(k.Default)
+	}
+	return "", false
+}
+
+// ToCSV writes nodes and edges of all graphs in the document as two CSV tables.
+//
+// The first column of the node table is a node ID, followed by one column for each node attribute.
+// The edge table starts with source, target and id columns, followed by edge attributes.
+// Columns are named after attributes, with a key ID used for attributes without a name.
+// Either of writers may be nil, in which case the corresponding table is not written.
+func ToCSV(nodes, edges io.Writer, doc *graphml.Document, opt *CSVOptions) error {
+	var graphs []*graphml.Graph
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			graphs = append(graphs, g)
+		})
+	}
+	if nodes != nil {
+		cols := opt.columns(doc, graphml.KindNode)
+		cw := csv.NewWriter(nodes)
+		cw.Comma = opt.comma()
+		header := []string{"id"}
+		for _, k := range cols {
+			header = append(header, keyName(k))
+		}
+		cw.Write(header)
+		for _, g := range graphs {
+			for _, n := range g.Nodes {
+				cw.Write(append([]string{n.ID}, csvValues(cols, n.Data)...))
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+	if edges != nil {
+		cols := opt.columns(doc, graphml.KindEdge)
+		cw := csv.NewWriter(edges)
+		cw.Comma = opt.comma()
+		header := []string{"source", "target", "id"}
+		for _, k := range cols {
+			header = append(header, keyName(k))
+		}
+		cw.Write(header)
+		for _, g := range graphs {
+			for _, e := range g.Edges {
+				cw.Write(append([]string{e.Source, e.Target, e.ID}, csvValues(cols, e.Data)...))
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CSVMapping describes how CSV columns are converted to GraphML.
+type CSVMapping struct {
+	CSVOptions
+
+	// ID is a name of the node ID column. Defaults to "id".
+	ID string
+	// Source and Target are names of edge endpoint columns. Default to "source" and "target".
+	Source, Target string
+	// EdgeID is a name of the edge ID column. Defaults to "id". Edge IDs are generated if the column is missing.
+	EdgeID string
+	// Names maps column names to attribute names. Column names are used as-is by default.
+	Names map[string]string
+	// Types maps column names to GraphML attribute types. Types of other columns are inferred from values.
+	Types map[string]string
+	// Undirected sets the default direction of edges to undirected.
+	Undirected bool
+}
+
+func (m *CSVMapping) column(name, def string) string {
+	if name == "" {
+		return def
+	}
+	return name
+}
+
+func (m *CSVMapping) attrName(col string) string {
+	if name, ok := m.Names[col]; ok {
+		return name
+	}
+	return col
+}
+
+func (m *CSVMapping) skip(col string) bool {
+	if m.Attrs == nil {
+		return false
+	}
+	for _, a := range m.Attrs {
+		if a == col || a == m.attrName(col) {
+			return false
+		}
+	}
+	return true
+}
+
+// csvTable reads a CSV table with a header.
+func csvTable(r io.Reader, comma rune, fnc func(header, row []string) error) ([]string, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return header, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if err = fnc(header, row); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// FromCSV builds a GraphML document from node and edge CSV tables with headers.
+//
+// Each column, except for IDs and edge endpoints, is converted to a GraphML attribute with a key declared
+// for it. Attribute types can be set explicitly in the mapping, or are inferred from the values otherwise.
+// Empty cells are skipped. Nodes referenced by edges, but missing from the node table are created
+// automatically. Either of readers may be nil.
+func FromCSV(nodes, edges io.Reader, m *CSVMapping) (*graphml.Document, error) {
+	if m == nil {
+		m = &CSVMapping{}
+	}
+	comma := m.CSVOptions.comma()
+	keys := newKeyBuilder()
+	ids := newIDSet()
+	g := graphml.Graph{EdgeDefault: graphml.EdgeDirected}
+	if m.Undirected {
+		g.EdgeDefault = graphml.EdgeUndirected
+	}
+	addData := func(kind graphml.Kind, data []graphml.Data, col, val string) []graphml.Data {
+		if val == "" || m.skip(col) {
+			return data
+		}
+		name := m.attrName(col)
+		if typ, ok := m.Types[col]; ok {
+			keys.Declare(kind, name, typ)
+		}
+		return append(data, keys.Data(kind, name, val))
+	}
+	if nodes != nil {
+		idCol := m.column(m.ID, "id")
+		_, err := csvTable(nodes, comma, func(header, row []string) error {
+			var n graphml.Node
+			for i, v := range row {
+				if i >= len(header) {
+					break
+				}
+				if header[i] == idCol {
+					n.ID = v
+				} else {
+					n.Data = addData(graphml.KindNode, n.Data, header[i], v)
+				}
+			}
+			if n.ID == "" {
+				return fmt.Errorf("csv: node without an id")
+			}
+			if !ids.Reserve(n.ID) {
+				return fmt.Errorf("csv: duplicate node: %q", n.ID)
+			}
+			g.Nodes = append(g.Nodes, n)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var list []graphml.Edge
+	if edges != nil {
+		srcCol, dstCol := m.column(m.Source, "source"), m.column(m.Target, "target")
+		idCol := m.column(m.EdgeID, "id")
+		header, err := csvTable(edges, comma, func(header, row []string) error {
+			var e graphml.Edge
+			for i, v := range row {
+				if i >= len(header) {
+					break
+				}
+				switch header[i] {
+				case srcCol:
+					e.Source = v
+				case dstCol:
+					e.Target = v
+				case idCol:
+					e.ID = v
+				default:
+					e.Data = addData(graphml.KindEdge, e.Data, header[i], v)
+				}
+			}
+			if e.Source == "" || e.Target == "" {
+				return fmt.Errorf("csv: edge without source or target")
+			}
+			list = append(list, e)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if header != nil && (!hasColumn(header, srcCol) || !hasColumn(header, dstCol)) {
+			return nil, fmt.Errorf("csv: edge table must have %q and %q columns", srcCol, dstCol)
+		}
+	}
+	for _, e := range list {
+		for _, id := range []string{e.Source, e.Target} {
+			if ids.Reserve(id) {
+				var n graphml.Node
+				n.ID = id
+				g.Nodes = append(g.Nodes, n)
+			}
+		}
+	}
+	for _, e := range list {
+		if e.ID != "" && !ids.Reserve(e.ID) {
+			return nil, fmt.Errorf("csv: duplicate edge: %q", e.ID)
+		}
+	}
+	for _, e := range list {
+		if e.ID == "" {
+			e.ID = ids.Unique("e")
+		}
+		g.Edges = append(g.Edges, e)
+	}
+	doc := newDocument()
+	doc.Graphs = []graphml.Graph{g}
+	doc.Keys = keys.Keys()
+	return doc, nil
+}
+
+func hasColumn(header []string, col string) bool {
+	for _, h := range header {
+		if h == col {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package convert
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+// ToCytoscapeJSON writes the document as Cytoscape.js elements JSON.
+//
+// Simple attributes are inlined into data objects of nodes and edges, using JSON types matching the key types.
+// Graphs nested into nodes are converted to compound nodes (by setting the parent field of child nodes).
+// Node x and y attributes are used as the node position. Elements of all graphs are written together.
+func ToCytoscapeJSON(w io.Writer, doc *graphml.Document) error {
+	keys := newKeyIndex(doc)
+	var (
+		data  jsonObject
+		nodes = []jsonObject{}
+		edges = []jsonObject{}
+	)
+	var walk func(parent string, g *graphml.Graph)
+	walk = func(parent string, g *graphml.Graph) {
+		for i := range g.Nodes {
+			n := &g.Nodes[i]
+			d := jsonObject{{Name: "id", Value: n.ID}}
+			if parent != "" {
+				d.Set("parent", parent)
+			}
+			for _, f := range jsonAttrs(keys, graphml.KindNode, n.Data, "id", "parent") {
+				d.Set(f.Name, f.Value)
+			}
+			el := jsonObject{{Name: "data", Value: d}}
+			if pos := cytoscapePosition(keys, n); pos != nil {
+				el.Set("position", pos)
+			}
+			nodes = append(nodes, el)
+			for j := range n.Graphs {
+				walk(n.ID, &n.Graphs[j])
+			}
+		}
+		for i := range g.Edges {
+			e := &g.Edges[i]
+			var d jsonObject
+			if e.ID != "" {
+				d.Set("id", e.ID)
+			}
+			d.Set("source", e.Source)
+			d.Set("target", e.Target)
+			for _, f := range jsonAttrs(keys, graphml.KindEdge, e.Data, "id", "source", "target") {
+				d.Set(f.Name, f.Value)
+			}
+			edges = append(edges, jsonObject{{Name: "data", Value: d}})
+		}
+	}
+	for i := range doc.Graphs {
+		g := &doc.Graphs[i]
+		for _, f := range jsonAttrs(keys, graphml.KindGraph, g.Data) {
+			data.Set(f.Name, f.Value)
+		}
+		walk("", g)
+	}
+	var out jsonObject
+	if len(data) != 0 {
+		out.Set("data", data)
+	}
+	out.Set("elements", jsonObject{
+		{Name: "nodes", Value: nodes},
+		{Name: "edges", Value: edges},
+	})
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func cytoscapePosition(keys *keyIndex, n *graphml.Node) jsonObject {
+	xs, ok1 := keys.Attr(graphml.KindNode, n.Data, "x")
+	ys, ok2 := keys.Attr(graphml.KindNode, n.Data, "y")
+	if !ok1 || !ok2 {
+		return nil
+	}
+	x, err1 := strconv.ParseFloat(xs, 64)
+	y, err2 := strconv.ParseFloat(ys, 64)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	return jsonObject{{Name: "x", Value: x}, {Name: "y", Value: y}}
+}
@@ -0,0 +1,115 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// ToD2 writes the document as a Terrastruct D2 diagram.
+//
+// Nodes and edges are labeled with the label attribute, if it is set. Graphs nested into nodes are written as
+// containers, and edges refer to nested nodes by their full path.
+func ToD2(w io.Writer, doc *graphml.Document) error {
+	bw := bufio.NewWriter(w)
+	e := &d2Encoder{w: bw, keys: newKeyIndex(doc), paths: make(map[string]string)}
+	for i := range doc.Graphs {
+		e.collectPaths("", &doc.Graphs[i])
+	}
+	for i := range doc.Graphs {
+		e.writeNodes(&doc.Graphs[i])
+	}
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], e.writeEdges)
+	}
+	return bw.Flush()
+}
+
+type d2Encoder struct {
+	w     *bufio.Writer
+	keys  *keyIndex
+	paths map[string]string
+	depth int
+}
+
+func (e *d2Encoder) line(format string, args ...interface{}) {
+	e.w.WriteString(strings.Repeat("  ", e.depth))
+	fmt.Fprintf(e.w, format, args...)
+	e.w.WriteByte('\n')
+}
+
+// d2Key quotes a D2 key, if necessary.
+func d2Key(s string) string {
+	for _, r := range s {
+		if r != '_' && r != '-' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+			return d2String(s)
+		}
+	}
+	if s == "" || strings.Contains(s, "--") || strings.HasPrefix(s, "-") {
+		return d2String(s)
+	}
+	return s
+}
+
+func d2String(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func (e *d2Encoder) collectPaths(prefix string, g *graphml.Graph) {
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		p := prefix + d2Key(n.ID)
+		if _, ok := e.paths[n.ID]; !ok {
+			e.paths[n.ID] = p
+		}
+		for j := range n.Graphs {
+			e.collectPaths(p+".", &n.Graphs[j])
+		}
+	}
+}
+
+func (e *d2Encoder) path(id string) string {
+	if p, ok := e.paths[id]; ok {
+		return p
+	}
+	return d2Key(id)
+}
+
+func (e *d2Encoder) writeNodes(g *graphml.Graph) {
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		line := d2Key(n.ID)
+		if label, ok := e.keys.Attr(graphml.KindNode, n.Data, "label"); ok {
+			line += ": " + d2String(label)
+		}
+		if len(n.Graphs) == 0 {
+			e.line("%s", line)
+			continue
+		}
+		e.line("%s {", line)
+		e.depth++
+		for j := range n.Graphs {
+			e.writeNodes(&n.Graphs[j])
+		}
+		e.depth--
+		e.line("}")
+	}
+}
+
+func (e *d2Encoder) writeEdges(g *graphml.Graph) {
+	for i := range g.Edges {
+		ed := &g.Edges[i]
+		arrow := "--"
+		if edgeDirected(g, ed) {
+			arrow = "->"
+		}
+		line := e.path(ed.Source) + " " + arrow + " " + e.path(ed.Target)
+		if label, ok := e.keys.Attr(graphml.KindEdge, ed.Data, "label"); ok {
+			line += ": " + d2String(label)
+		}
+		e.line("%s", line)
+	}
+}
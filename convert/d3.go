@@ -0,0 +1,83 @@
+package convert
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/dennwc/graphml"
+)
+
+// D3Options controls the conversion to D3 node-link JSON.
+type D3Options struct {
+	// NumericIDs replaces node IDs with indexes of nodes in the array, and makes links refer to nodes by index.
+	// Original GraphML node IDs are stored in the name field, unless nodes have a name attribute.
+	NumericIDs bool
+}
+
+// ToD3 writes the document as a node-link JSON used by D3 force layouts.
+//
+// Simple attributes are inlined into node and link objects, using JSON types matching the key types.
+// Nodes of all graphs, including nested ones, are written to a single nodes array.
+func ToD3(w io.Writer, doc *graphml.Document, opt *D3Options) error {
+	if opt == nil {
+		opt = &D3Options{}
+	}
+	keys := newKeyIndex(doc)
+	var (
+		nodes = []jsonObject{}
+		links = []jsonObject{}
+		index = make(map[string]int)
+	)
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for _, n := range g.Nodes {
+				if _, ok := index[n.ID]; ok {
+					continue
+				}
+				index[n.ID] = len(nodes)
+				attrs := jsonAttrs(keys, graphml.KindNode, n.Data, "id")
+				var obj jsonObject
+				if opt.NumericIDs {
+					obj.Set("id", len(nodes))
+					if _, ok := keys.Attr(graphml.KindNode, n.Data, "name"); !ok {
+						obj.Set("name", n.ID)
+					}
+				} else {
+					obj.Set("id", n.ID)
+				}
+				obj = append(obj, attrs...)
+				nodes = append(nodes, obj)
+			}
+		})
+	}
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for _, e := range g.Edges {
+				var obj jsonObject
+				if opt.NumericIDs {
+					src, ok1 := index[e.Source]
+					dst, ok2 := index[e.Target]
+					if !ok1 || !ok2 {
+						continue
+					}
+					obj.Set("source", src)
+					obj.Set("target", dst)
+				} else {
+					obj.Set("source", e.Source)
+					obj.Set("target", e.Target)
+				}
+				for _, f := range jsonAttrs(keys, graphml.KindEdge, e.Data, "source", "target") {
+					obj.Set(f.Name, f.Value)
+				}
+				links = append(links, obj)
+			}
+		})
+	}
+	out := jsonObject{
+		{Name: "nodes", Value: nodes},
+		{Name: "links", Value: links},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
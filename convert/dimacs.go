@@ -0,0 +1,251 @@
+package convert
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// DIMACSProblem is a type of a DIMACS graph problem.
+type DIMACSProblem string
+
+const (
+	// DIMACSEdge is an undirected graph format used for clique and coloring problems ("p edge").
+	DIMACSEdge = DIMACSProblem("edge")
+	// DIMACSShortestPath is a shortest path problem with arc weights ("p sp").
+	DIMACSShortestPath = DIMACSProblem("sp")
+	// DIMACSMaxFlow is a maximum flow problem with arc capacities and source and sink nodes ("p max").
+	DIMACSMaxFlow = DIMACSProblem("max")
+	// DIMACSMinCost is a minimum cost flow problem with node supplies and arc bounds and costs ("p min").
+	DIMACSMinCost = DIMACSProblem("min")
+)
+
+// arcAttrs returns names of arc attributes for each problem, together with their default values.
+func (p DIMACSProblem) arcAttrs() [][2]string {
+	switch p {
+	case DIMACSShortestPath:
+		return [][2]string{{"weight", "1"}}
+	case DIMACSMaxFlow:
+		return [][2]string{{"capacity", "1"}}
+	case DIMACSMinCost:
+		return [][2]string{{"low", "0"}, {"capacity", "1"}, {"cost", "0"}}
+	}
+	return nil
+}
+
+// nodeAttr returns a name of the node attribute for the problem.
+func (p DIMACSProblem) nodeAttr() string {
+	switch p {
+	case DIMACSMaxFlow:
+		return "terminal"
+	case DIMACSMinCost:
+		return "supply"
+	}
+	return ""
+}
+
+// ToDIMACS writes the graph in one of the DIMACS formats.
+//
+// Nodes are numbered starting from 1. Arc values are taken from attributes: weight for shortest path problems,
+// capacity for maximum flow, and low, capacity and cost for minimum cost flow problems. Node lines are written from
+// the terminal attribute ("s" or "t") for maximum flow, and from the supply attribute for minimum cost flow.
+// Undirected edges are written as two arcs, except for the edge format. Nested graphs are flattened.
+//
+// DIMACS supports only one graph per file, thus an error is returned for documents with multiple graphs.
+func ToDIMACS(w io.Writer, doc *graphml.Document, problem DIMACSProblem) error {
+	if len(doc.Graphs) > 1 {
+		return errors.New("dimacs: only one graph per document is supported")
+	}
+	switch problem {
+	case DIMACSEdge, DIMACSShortestPath, DIMACSMaxFlow, DIMACSMinCost:
+	default:
+		return fmt.Errorf("dimacs: unsupported problem: %q", problem)
+	}
+	keys := newKeyIndex(doc)
+	var (
+		nodes []*graphml.Node
+		index = make(map[string]int)
+		lines []string
+	)
+	if len(doc.Graphs) != 0 {
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for i := range g.Nodes {
+				n := &g.Nodes[i]
+				if _, ok := index[n.ID]; !ok {
+					nodes = append(nodes, n)
+					index[n.ID] = len(nodes)
+				}
+			}
+		})
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for i := range g.Edges {
+				e := &g.Edges[i]
+				src, ok1 := index[e.Source]
+				dst, ok2 := index[e.Target]
+				if !ok1 || !ok2 {
+					continue
+				}
+				if problem == DIMACSEdge {
+					lines = append(lines, fmt.Sprintf("e %d %d", src, dst))
+					continue
+				}
+				vals := ""
+				for _, a := range problem.arcAttrs() {
+					v, ok := keys.Attr(graphml.KindEdge, e.Data, a[0])
+					if _, err := strconv.ParseFloat(v, 64); !ok || err != nil {
+						v = a[1]
+					}
+					vals += " " + v
+				}
+				lines = append(lines, fmt.Sprintf("a %d %d%s", src, dst, vals))
+				if !edgeDirected(g, e) && src != dst {
+					lines = append(lines, fmt.Sprintf("a %d %d%s", dst, src, vals))
+				}
+			}
+		})
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p %s %d %d\n", problem, len(nodes), len(lines))
+	if name := problem.nodeAttr(); name != "" {
+		for i, n := range nodes {
+			if v, ok := keys.Attr(graphml.KindNode, n.Data, name); ok && v != "" {
+				fmt.Fprintf(bw, "n %d %s\n", i+1, v)
+			}
+		}
+	}
+	for _, l := range lines {
+		bw.WriteString(l + "\n")
+	}
+	return bw.Flush()
+}
+
+// FromDIMACS reads a graph in one of the DIMACS formats and converts it to GraphML.
+//
+// The problem type is detected from the problem line. Nodes get "n<number>" IDs and edges get "e<number>" IDs.
+// Graphs in the edge format are undirected, while other problems produce directed graphs. Arc values and node
+// lines are stored in attributes, as described in ToDIMACS.
+func FromDIMACS(r io.Reader) (*graphml.Document, error) {
+	var (
+		problem DIMACSProblem
+		g       graphml.Graph
+		keys    = newKeyBuilder()
+		line    int
+	)
+	nodeIndex := func(s string) (int, error) {
+		i, err := strconv.Atoi(s)
+		if err != nil || i < 1 || i > len(g.Nodes) {
+			return 0, fmt.Errorf("dimacs: line %d: invalid node: %q", line, s)
+		}
+		return i - 1, nil
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line++
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if fields[0] == "p" {
+			if problem != "" {
+				return nil, fmt.Errorf("dimacs: line %d: duplicate problem line", line)
+			}
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("dimacs: line %d: invalid problem line", line)
+			}
+			problem = DIMACSProblem(fields[1])
+			switch problem {
+			case "col", "clq":
+				problem = DIMACSEdge
+			case DIMACSEdge, DIMACSShortestPath, DIMACSMaxFlow, DIMACSMinCost:
+			default:
+				return nil, fmt.Errorf("dimacs: line %d: unsupported problem: %q", line, fields[1])
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("dimacs: line %d: invalid number of nodes: %q", line, fields[2])
+			}
+			g.EdgeDefault = graphml.EdgeDirected
+			if problem == DIMACSEdge {
+				g.EdgeDefault = graphml.EdgeUndirected
+			}
+			for _, a := range problem.arcAttrs() {
+				keys.Declare(graphml.KindEdge, a[0], "double")
+			}
+			switch problem {
+			case DIMACSMaxFlow:
+				keys.Declare(graphml.KindNode, "terminal", "string")
+			case DIMACSMinCost:
+				keys.Declare(graphml.KindNode, "supply", "double")
+			}
+			for i := 1; i <= n; i++ {
+				var node graphml.Node
+				node.ID = "n" + strconv.Itoa(i)
+				g.Nodes = append(g.Nodes, node)
+			}
+			continue
+		}
+		if problem == "" {
+			return nil, fmt.Errorf("dimacs: line %d: expected problem line", line)
+		}
+		switch fields[0] {
+		case "n":
+			name := problem.nodeAttr()
+			if name == "" || len(fields) != 3 {
+				return nil, fmt.Errorf("dimacs: line %d: unexpected node line", line)
+			}
+			i, err := nodeIndex(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			if name == "supply" {
+				if _, err = strconv.ParseFloat(fields[2], 64); err != nil {
+					return nil, fmt.Errorf("dimacs: line %d: invalid supply: %q", line, fields[2])
+				}
+			}
+			n := &g.Nodes[i]
+			n.Data = append(n.Data, keys.Data(graphml.KindNode, name, fields[2]))
+		case "e", "a":
+			attrs := problem.arcAttrs()
+			if (fields[0] == "e") != (problem == DIMACSEdge) || len(fields) != 3+len(attrs) {
+				return nil, fmt.Errorf("dimacs: line %d: unexpected edge line", line)
+			}
+			src, err := nodeIndex(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			dst, err := nodeIndex(fields[2])
+			if err != nil {
+				return nil, err
+			}
+			var e graphml.Edge
+			e.ID = "e" + strconv.Itoa(len(g.Edges))
+			e.Source, e.Target = g.Nodes[src].ID, g.Nodes[dst].ID
+			for i, a := range attrs {
+				v := fields[3+i]
+				if _, err = strconv.ParseFloat(v, 64); err != nil {
+					return nil, fmt.Errorf("dimacs: line %d: invalid %s: %q", line, a[0], v)
+				}
+				e.Data = append(e.Data, keys.Data(graphml.KindEdge, a[0], v))
+			}
+			g.Edges = append(g.Edges, e)
+		default:
+			return nil, fmt.Errorf("dimacs: line %d: unexpected line type: %q", line, fields[0])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if problem == "" {
+		return nil, errors.New("dimacs: problem line is missing")
+	}
+	doc := newDocument()
+	doc.Graphs = []graphml.Graph{g}
+	doc.Keys = keys.Keys()
+	return doc, nil
+}
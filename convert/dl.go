@@ -0,0 +1,436 @@
+package convert
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// DLFormat is a data format of UCINET DL files.
+type DLFormat string
+
+const (
+	// DLFullMatrix stores the graph as an adjacency matrix.
+	DLFullMatrix = DLFormat("fullmatrix")
+	// DLEdgeList stores the graph as a list of edges (edgelist1).
+	DLEdgeList = DLFormat("edgelist1")
+)
+
+// ToDL writes the document in UCINET DL format.
+//
+// Vertices are labeled with the label attribute, or with a GraphML node ID if the attribute is not set.
+// Edge values are taken from the weight attribute and default to 1. Undirected edges are written in both
+// directions. Nested graphs are flattened. If format is not set, DLFullMatrix is used.
+//
+// DL supports only one network per file, thus an error is returned for documents with multiple graphs.
+func ToDL(w io.Writer, doc *graphml.Document, format DLFormat) error {
+	if len(doc.Graphs) > 1 {
+		return errors.New("dl: only one graph per document is supported")
+	}
+	if format == "" {
+		format = DLFullMatrix
+	}
+	if format != DLFullMatrix && format != DLEdgeList {
+		return fmt.Errorf("dl: unsupported format: %q", format)
+	}
+	keys := newKeyIndex(doc)
+	type dlEdge struct {
+		src, dst int
+		val      string
+	}
+	var (
+		nodes []*graphml.Node
+		ids   = make(map[string]int)
+		edges []dlEdge
+	)
+	if len(doc.Graphs) != 0 {
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for i := range g.Nodes {
+				n := &g.Nodes[i]
+				if _, ok := ids[n.ID]; !ok {
+					nodes = append(nodes, n)
+					ids[n.ID] = len(nodes) - 1
+				}
+			}
+		})
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for i := range g.Edges {
+				e := &g.Edges[i]
+				src, ok1 := ids[e.Source]
+				dst, ok2 := ids[e.Target]
+				if !ok1 || !ok2 {
+					continue
+				}
+				val := "1"
+				if v, ok := keys.Attr(graphml.KindEdge, e.Data, "weight"); ok {
+					if _, err := strconv.ParseFloat(v, 64); err == nil {
+						val = v
+					}
+				}
+				edges = append(edges, dlEdge{src: src, dst: dst, val: val})
+				if !edgeDirected(g, e) && src != dst {
+					edges = append(edges, dlEdge{src: dst, dst: src, val: val})
+				}
+			}
+		})
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "dl n=%d\n", len(nodes))
+	fmt.Fprintf(bw, "format = %s\n", format)
+	if len(nodes) != 0 {
+		bw.WriteString("labels:\n")
+		for i, n := range nodes {
+			label, ok := keys.Attr(graphml.KindNode, n.Data, "label")
+			if !ok {
+				label = n.ID
+			}
+			if i != 0 {
+				bw.WriteString(",")
+			}
+			bw.WriteString(dlQuote(label))
+		}
+		bw.WriteString("\n")
+	}
+	bw.WriteString("data:\n")
+	switch format {
+	case DLFullMatrix:
+		m := make([][]string, len(nodes))
+		for i := range m {
+			m[i] = make([]string, len(nodes))
+			for j := range m[i] {
+				m[i][j] = "0"
+			}
+		}
+		for _, e := range edges {
+			m[e.src][e.dst] = e.val
+		}
+		for _, row := range m {
+			bw.WriteString(strings.Join(row, " "))
+			bw.WriteString("\n")
+		}
+	case DLEdgeList:
+		for _, e := range edges {
+			fmt.Fprintf(bw, "%d %d %s\n", e.src+1, e.dst+1, e.val)
+		}
+	}
+	return bw.Flush()
+}
+
+// dlQuote quotes a DL label if necessary.
+func dlQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t,=\"") {
+		return s
+	}
+	return `"` + strings.Replace(s, `"`, `'`, -1) + `"`
+}
+
+type dlToken struct {
+	text   string
+	line   int
+	quoted bool
+}
+
+// dlTokens splits DL input into tokens separated by whitespace, commas and equal signs.
+func dlTokens(r io.Reader) ([]dlToken, error) {
+	var out []dlToken
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	line := 0
+	for sc.Scan() {
+		line++
+		var (
+			cur strings.Builder
+			inQ bool
+			has bool
+			quo bool
+		)
+		flush := func() {
+			if has {
+				out = append(out, dlToken{text: cur.String(), line: line, quoted: quo})
+				cur.Reset()
+				has, quo = false, false
+			}
+		}
+		for _, r := range sc.Text() {
+			switch {
+			case r == '"':
+				inQ = !inQ
+				has, quo = true, true
+			case inQ:
+				cur.WriteRune(r)
+			case r == ' ' || r == '\t' || r == '\r' || r == ',':
+				flush()
+			case r == '=':
+				flush()
+				out = append(out, dlToken{text: "=", line: line})
+			default:
+				cur.WriteRune(r)
+				has = true
+			}
+		}
+		flush()
+	}
+	return out, sc.Err()
+}
+
+// isKeyword reports if the token starts a new DL section.
+func (t dlToken) isKeyword() bool {
+	return !t.quoted && strings.HasSuffix(t.text, ":")
+}
+
+// FromDL reads a network in UCINET DL format and converts it to GraphML.
+//
+// Both fullmatrix and edgelist1 formats are supported, with labels given in a separate section or embedded
+// into data. Vertices get "n<number>" IDs and labels are stored in the label attribute. Edge values other
+// than 1 are stored in the weight attribute. A graph is undirected if it is given as a symmetric matrix.
+func FromDL(r io.Reader) (*graphml.Document, error) {
+	toks, err := dlTokens(r)
+	if err != nil {
+		return nil, err
+	}
+	pos := 0
+	errorf := func(format string, args ...interface{}) error {
+		line := 0
+		if pos < len(toks) {
+			line = toks[pos].line
+		} else if len(toks) != 0 {
+			line = toks[len(toks)-1].line
+		}
+		return fmt.Errorf("dl: line %d: %s", line, fmt.Sprintf(format, args...))
+	}
+	if len(toks) == 0 || strings.ToLower(toks[0].text) != "dl" {
+		return nil, errorf("expected DL header")
+	}
+	pos++
+	var (
+		n        = -1
+		format   = DLFullMatrix
+		labels   []string
+		embedded bool
+	)
+header:
+	for {
+		if pos >= len(toks) {
+			return nil, errorf("expected data section")
+		}
+		t := toks[pos]
+		name := strings.ToLower(t.text)
+		if pos+2 < len(toks) && toks[pos+1].text == "=" {
+			val := toks[pos+2].text
+			switch name {
+			case "n":
+				n, err = strconv.Atoi(val)
+				if err != nil || n < 0 {
+					return nil, errorf("invalid number of nodes: %q", val)
+				}
+			case "format":
+				format = DLFormat(strings.ToLower(val))
+				if format != DLFullMatrix && format != DLEdgeList {
+					return nil, errorf("unsupported format: %q", val)
+				}
+			case "nr", "nc", "nm":
+				return nil, errorf("unsupported parameter: %q", t.text)
+			}
+			pos += 3
+			continue
+		}
+		pos++
+		switch name {
+		case "data:":
+			break header
+		case "labels":
+			if pos < len(toks) && strings.HasPrefix(strings.ToLower(toks[pos].text), "embedded") {
+				embedded = true
+				pos++
+				continue
+			}
+			return nil, errorf("unexpected token: %q", t.text)
+		case "labels:":
+			for pos < len(toks) && !toks[pos].isKeyword() {
+				labels = append(labels, toks[pos].text)
+				pos++
+			}
+		default:
+			return nil, errorf("unexpected token: %q", t.text)
+		}
+	}
+	if n < 0 && !(embedded && format == DLEdgeList) {
+		return nil, errorf("number of nodes is not set")
+	}
+	if len(labels) > n && n >= 0 {
+		return nil, errorf("too many labels: %d", len(labels))
+	}
+	keys := newKeyBuilder()
+	keys.Declare(graphml.KindNode, "label", "string")
+	var g graphml.Graph
+	byLabel := make(map[string]string)
+	addNode := func(label string) string {
+		id := "n" + strconv.Itoa(len(g.Nodes)+1)
+		var node graphml.Node
+		node.ID = id
+		if label != "" {
+			node.Data = append(node.Data, keys.Data(graphml.KindNode, "label", label))
+			byLabel[label] = id
+		}
+		g.Nodes = append(g.Nodes, node)
+		return id
+	}
+	type dlEdge struct {
+		src, dst string
+		val      string
+	}
+	var edges []dlEdge
+	data := toks[pos:]
+	switch format {
+	case DLFullMatrix:
+		if embedded {
+			if len(data) < n {
+				return nil, errorf("expected %d column labels", n)
+			}
+			labels = nil
+			for _, t := range data[:n] {
+				labels = append(labels, t.text)
+			}
+			data = data[n:]
+		}
+		for i := 0; i < n; i++ {
+			label := ""
+			if i < len(labels) {
+				label = labels[i]
+			}
+			addNode(label)
+		}
+		row := n
+		if embedded {
+			row++
+		}
+		if len(data) != n*row {
+			return nil, errorf("expected %d matrix values, got %d", n*row, len(data))
+		}
+		vals := make([][]float64, n)
+		for i := 0; i < n; i++ {
+			cells := data[i*row : (i+1)*row]
+			if embedded {
+				cells = cells[1:]
+			}
+			vals[i] = make([]float64, n)
+			for j, c := range cells {
+				v, err := strconv.ParseFloat(c.text, 64)
+				if err != nil {
+					return nil, fmt.Errorf("dl: line %d: invalid value: %q", c.line, c.text)
+				}
+				vals[i][j] = v
+			}
+		}
+		g.EdgeDefault = graphml.EdgeUndirected
+		for i := 0; i < n && g.EdgeDefault == graphml.EdgeUndirected; i++ {
+			for j := 0; j < i; j++ {
+				if vals[i][j] != vals[j][i] {
+					g.EdgeDefault = graphml.EdgeDirected
+					break
+				}
+			}
+		}
+		for i := 0; i < n; i++ {
+			j := 0
+			if g.EdgeDefault == graphml.EdgeUndirected {
+				// the matrix is symmetric, use only the upper triangle
+				j = i
+			}
+			for ; j < n; j++ {
+				if vals[i][j] != 0 {
+					val := strconv.FormatFloat(vals[i][j], 'g', -1, 64)
+					edges = append(edges, dlEdge{src: g.Nodes[i].ID, dst: g.Nodes[j].ID, val: val})
+				}
+			}
+		}
+	case DLEdgeList:
+		g.EdgeDefault = graphml.EdgeDirected
+		if !embedded {
+			for i := 0; i < n; i++ {
+				label := ""
+				if i < len(labels) {
+					label = labels[i]
+				}
+				addNode(label)
+			}
+		}
+		nodeID := func(t dlToken) (string, error) {
+			if embedded {
+				if id, ok := byLabel[t.text]; ok {
+					return id, nil
+				}
+				if n >= 0 && len(g.Nodes) >= n {
+					return "", fmt.Errorf("dl: line %d: too many nodes", t.line)
+				}
+				return addNode(t.text), nil
+			}
+			i, err := strconv.Atoi(t.text)
+			if err != nil || i < 1 || i > n {
+				return "", fmt.Errorf("dl: line %d: invalid node number: %q", t.line, t.text)
+			}
+			return "n" + t.text, nil
+		}
+		for i := 0; i < len(data); {
+			line := data[i].line
+			j := i
+			for j < len(data) && data[j].line == line {
+				j++
+			}
+			fields := data[i:j]
+			i = j
+			if len(fields) < 2 || len(fields) > 3 {
+				return nil, fmt.Errorf("dl: line %d: expected two nodes and an optional value", line)
+			}
+			src, err := nodeID(fields[0])
+			if err != nil {
+				return nil, err
+			}
+			dst, err := nodeID(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			val := "1"
+			if len(fields) > 2 {
+				val = fields[2].text
+				if _, err := strconv.ParseFloat(val, 64); err != nil {
+					return nil, fmt.Errorf("dl: line %d: invalid value: %q", line, val)
+				}
+			}
+			edges = append(edges, dlEdge{src: src, dst: dst, val: val})
+		}
+		if embedded && n > len(g.Nodes) {
+			for len(g.Nodes) < n {
+				addNode("")
+			}
+		}
+	}
+	valued := false
+	for _, e := range edges {
+		if v, _ := strconv.ParseFloat(e.val, 64); v != 1 {
+			valued = true
+			break
+		}
+	}
+	if valued {
+		keys.Declare(graphml.KindEdge, "weight", "double")
+	}
+	for i, de := range edges {
+		var e graphml.Edge
+		e.ID = "e" + strconv.Itoa(i)
+		e.Source, e.Target = de.src, de.dst
+		if valued {
+			e.Data = append(e.Data, keys.Data(graphml.KindEdge, "weight", de.val))
+		}
+		g.Edges = append(g.Edges, e)
+	}
+	doc := newDocument()
+	doc.Graphs = []graphml.Graph{g}
+	doc.Keys = keys.Keys()
+	return doc, nil
+}
@@ -0,0 +1,175 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// DefaultDOTAttrs is a default set of GraphML attributes exported to DOT.
+var DefaultDOTAttrs = map[string]string{
+	"label":  "label",
+	"weight": "weight",
+	"color":  "color",
+}
+
+// DOTOptions controls the conversion to DOT.
+type DOTOptions struct {
+	// Attrs maps GraphML attribute names (or key IDs, if the name is not set) to DOT attribute names.
+	// If not set, DefaultDOTAttrs is used.
+	Attrs map[string]string
+	// AllAttrs exports all simple GraphML attributes with their original names.
+	AllAttrs bool
+}
+
+// ToDOT writes all graphs of the document in Graphviz DOT format.
+//
+// Graphs nested into nodes are written as clusters (subgraphs with a "cluster_" prefix).
+// The type of the DOT graph is defined by the default edge direction of the top-level graph. Edges with
+// a different direction (see graphml.Edge.EffectiveDirection) get "dir=none" or "dir=forward" attribute.
+func ToDOT(w io.Writer, doc *graphml.Document, opt *DOTOptions) error {
+	if opt == nil {
+		opt = &DOTOptions{}
+	}
+	attrs := opt.Attrs
+	if attrs == nil && !opt.AllAttrs {
+		attrs = DefaultDOTAttrs
+	}
+	bw := bufio.NewWriter(w)
+	d := &dotEncoder{w: bw, keys: newKeyIndex(doc), attrs: attrs, all: opt.AllAttrs}
+	for i := range doc.Graphs {
+		d.encodeGraph(&doc.Graphs[i])
+	}
+	return bw.Flush()
+}
+
+type dotEncoder struct {
+	w     *bufio.Writer
+	keys  *keyIndex
+	attrs map[string]string
+	all   bool
+
+	depth    int
+	directed bool // the top-level graph is a digraph
+	edgeOp   string
+	clusters int
+}
+
+func (d *dotEncoder) line(format string, args ...interface{}) {
+	d.w.WriteString(strings.Repeat("\t", d.depth))
+	fmt.Fprintf(d.w, format, args...)
+	d.w.WriteByte('\n')
+}
+
+// dotID quotes a string as a DOT identifier.
+func dotID(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
+
+// dotName returns an identifier as-is if it does not require quoting.
+func dotName(s string) string {
+	for i, r := range s {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(i > 0 && r >= '0' && r <= '9') {
+			return dotID(s)
+		}
+	}
+	if s == "" {
+		return dotID(s)
+	}
+	return s
+}
+
+func (d *dotEncoder) attrList(kind graphml.Kind, data []graphml.Data) []string {
+	var out []string
+	for _, a := range d.keys.Attrs(kind, data) {
+		name := a.Name
+		if !d.all {
+			var ok bool
+			name, ok = d.attrs[a.Name]
+			if !ok {
+				continue
+			}
+		}
+		out = append(out, dotName(name)+"="+dotID(a.Value))
+	}
+	return out
+}
+
+func (d *dotEncoder) encodeGraph(g *graphml.Graph) {
+	typ := "graph"
+	d.edgeOp = "--"
+	d.directed = g.IsDirected()
+	if d.directed {
+		typ = "digraph"
+		d.edgeOp = "->"
+	}
+	if g.ID != "" {
+		d.line("%s %s {", typ, dotID(g.ID))
+	} else {
+		d.line("%s {", typ)
+	}
+	d.depth++
+	d.encodeBody(g)
+	d.depth--
+	d.line("}")
+}
+
+func (d *dotEncoder) encodeBody(g *graphml.Graph) {
+	for _, a := range d.attrList(graphml.KindGraph, g.Data) {
+		d.line("%s;", a)
+	}
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		attrs := d.attrList(graphml.KindNode, n.Data)
+		if len(attrs) != 0 {
+			d.line("%s [%s];", dotID(n.ID), strings.Join(attrs, ", "))
+		} else {
+			d.line("%s;", dotID(n.ID))
+		}
+		for j := range n.Graphs {
+			d.encodeCluster(n, &n.Graphs[j])
+		}
+	}
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		attrs := d.attrList(graphml.KindEdge, e.Data)
+		// DOT graphs have a single edge type, edges with a different direction only change the arrows
+		if dir := e.EffectiveDirection(g) == graphml.EdgeDirected; dir != d.directed {
+			if dir {
+				attrs = append(attrs, "dir=forward")
+			} else {
+				attrs = append(attrs, "dir=none")
+			}
+		}
+		if len(attrs) != 0 {
+			d.line("%s %s %s [%s];", dotID(e.Source), d.edgeOp, dotID(e.Target), strings.Join(attrs, ", "))
+		} else {
+			d.line("%s %s %s;", dotID(e.Source), d.edgeOp, dotID(e.Target))
+		}
+	}
+}
+
+func (d *dotEncoder) encodeCluster(n *graphml.Node, g *graphml.Graph) {
+	id := g.ID
+	if id == "" {
+		d.clusters++
+		id = fmt.Sprintf("%s_%d", n.ID, d.clusters)
+	}
+	d.line("subgraph %s {", dotID("cluster_"+id))
+	d.depth++
+	if _, ok := d.keys.Attr(graphml.KindGraph, g.Data, "label"); !ok {
+		// use node label for the cluster
+		if label, ok := d.keys.Attr(graphml.KindNode, n.Data, "label"); ok {
+			d.line("label=%s;", dotID(label))
+		}
+	}
+	d.encodeBody(g)
+	d.depth--
+	d.line("}")
+}
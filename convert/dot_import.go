@@ -0,0 +1,811 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dennwc/graphml"
+)
+
+// FromDOT reads all graphs from a Graphviz DOT file and converts them to a GraphML document.
+//
+// Attributes are converted to data elements, declaring GraphML keys for them automatically.
+// Clusters (subgraphs with a "cluster" name prefix) are converted to nested graphs, while other
+// subgraphs are merged into the parent graph. Statements of a reopened subgraph are added to the existing one.
+// Duplicate edges of strict graphs are merged. Ports are ignored.
+func FromDOT(r io.Reader) (*graphml.Document, error) {
+	p := &dotParser{
+		lex:  &dotLexer{r: bufio.NewReader(r), line: 1},
+		keys: newKeyBuilder(),
+	}
+	var graphs []*dotGraph
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return nil, err
+		} else if t.typ == dotEOF {
+			break
+		}
+		g, err := p.parseGraph()
+		if err != nil {
+			return nil, err
+		}
+		graphs = append(graphs, g)
+	}
+	doc := newDocument()
+	ids := newIDSet()
+	for _, g := range graphs {
+		g.reserveIDs(ids)
+	}
+	for _, g := range graphs {
+		doc.Graphs = append(doc.Graphs, g.build(p.keys, ids))
+	}
+	doc.Keys = p.keys.Keys()
+	return doc, nil
+}
+
+type dotTokenType int
+
+const (
+	dotEOF = dotTokenType(iota)
+	dotIdent
+	dotPunct
+)
+
+type dotToken struct {
+	typ    dotTokenType
+	val    string
+	quoted bool // string or HTML identifier
+	line   int
+}
+
+// keyword checks if the token is an unquoted keyword. Keywords are case-insensitive.
+func (t dotToken) keyword(s string) bool {
+	return t.typ == dotIdent && !t.quoted && strings.EqualFold(t.val, s)
+}
+
+func (t dotToken) punct(s string) bool {
+	return t.typ == dotPunct && t.val == s
+}
+
+func (t dotToken) String() string {
+	if t.typ == dotEOF {
+		return "EOF"
+	}
+	return strconv.Quote(t.val)
+}
+
+type dotLexer struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (l *dotLexer) read() (rune, error) {
+	r, _, err := l.r.ReadRune()
+	if r == '\n' {
+		l.line++
+	}
+	return r, err
+}
+
+func (l *dotLexer) unread(r rune) {
+	if r == '\n' {
+		l.line--
+	}
+	l.r.UnreadRune()
+}
+
+func (l *dotLexer) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("dot: line %d: %s", l.line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips whitespaces and comments.
+func (l *dotLexer) skipSpace() error {
+	lineStart := l.line == 1
+	for {
+		r, err := l.read()
+		if err != nil {
+			return err
+		}
+		switch {
+		case r == '\n':
+			lineStart = true
+			continue
+		case unicode.IsSpace(r):
+			continue
+		case r == '#' && lineStart:
+			// preprocessor output line
+			if _, err := l.r.ReadString('\n'); err != nil {
+				return err
+			}
+			l.line++
+			continue
+		case r == '/':
+			r2, err := l.read()
+			if err != nil {
+				return err
+			}
+			if r2 == '/' {
+				if _, err := l.r.ReadString('\n'); err != nil {
+					return err
+				}
+				l.line++
+				lineStart = true
+				continue
+			} else if r2 == '*' {
+				prev := rune(0)
+				for {
+					r, err = l.read()
+					if err == io.EOF {
+						return l.errorf("unterminated comment")
+					} else if err != nil {
+						return err
+					}
+					if prev == '*' && r == '/' {
+						break
+					}
+					prev = r
+				}
+				continue
+			}
+			l.unread(r2)
+			return l.errorf("unexpected character: %q", r)
+		}
+		l.unread(r)
+		return nil
+	}
+}
+
+func isDotIDRune(r rune) bool {
+	return r == '_' || r == '.' || r >= 0x80 || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (l *dotLexer) Next() (dotToken, error) {
+	if err := l.skipSpace(); err == io.EOF {
+		return dotToken{typ: dotEOF, line: l.line}, nil
+	} else if err != nil {
+		return dotToken{}, err
+	}
+	line := l.line
+	r, err := l.read()
+	if err != nil {
+		return dotToken{}, err
+	}
+	switch r {
+	case '{', '}', '[', ']', ';', ',', '=', ':', '+':
+		return dotToken{typ: dotPunct, val: string(r), line: line}, nil
+	case '-':
+		r2, err := l.read()
+		if err != nil && err != io.EOF {
+			return dotToken{}, err
+		}
+		if r2 == '>' || r2 == '-' {
+			return dotToken{typ: dotPunct, val: "-" + string(r2), line: line}, nil
+		}
+		if err == nil {
+			l.unread(r2)
+		}
+		// negative number
+		s, err := l.readWhile(isDotIDRune)
+		if err != nil {
+			return dotToken{}, err
+		}
+		return dotToken{typ: dotIdent, val: "-" + s, line: line}, nil
+	case '"':
+		s, err := l.readString()
+		if err != nil {
+			return dotToken{}, err
+		}
+		return dotToken{typ: dotIdent, val: s, quoted: true, line: line}, nil
+	case '<':
+		s, err := l.readHTML()
+		if err != nil {
+			return dotToken{}, err
+		}
+		return dotToken{typ: dotIdent, val: s, quoted: true, line: line}, nil
+	}
+	if !isDotIDRune(r) {
+		return dotToken{}, l.errorf("unexpected character: %q", r)
+	}
+	l.unread(r)
+	s, err := l.readWhile(isDotIDRune)
+	if err != nil {
+		return dotToken{}, err
+	}
+	return dotToken{typ: dotIdent, val: s, line: line}, nil
+}
+
+func (l *dotLexer) readWhile(fnc func(r rune) bool) (string, error) {
+	var sb strings.Builder
+	for {
+		r, err := l.read()
+		if err == io.EOF {
+			return sb.String(), nil
+		} else if err != nil {
+			return "", err
+		}
+		if !fnc(r) {
+			l.unread(r)
+			return sb.String(), nil
+		}
+		sb.WriteRune(r)
+	}
+}
+
+// readString reads the rest of a double-quoted string. Escaped quotes are unescaped, other escape sequences are preserved.
+func (l *dotLexer) readString() (string, error) {
+	var sb strings.Builder
+	for {
+		r, err := l.read()
+		if err == io.EOF {
+			return "", l.errorf("unterminated string")
+		} else if err != nil {
+			return "", err
+		}
+		switch r {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			r2, err := l.read()
+			if err == io.EOF {
+				return "", l.errorf("unterminated string")
+			} else if err != nil {
+				return "", err
+			}
+			switch r2 {
+			case '"':
+				sb.WriteRune('"')
+			case '\n':
+				// line continuation
+			default:
+				sb.WriteRune(r)
+				sb.WriteRune(r2)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// readHTML reads the rest of an HTML string, including nested angle brackets.
+func (l *dotLexer) readHTML() (string, error) {
+	var sb strings.Builder
+	depth := 1
+	for {
+		r, err := l.read()
+		if err == io.EOF {
+			return "", l.errorf("unterminated HTML string")
+		} else if err != nil {
+			return "", err
+		}
+		switch r {
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth == 0 {
+				return sb.String(), nil
+			}
+		}
+		sb.WriteRune(r)
+	}
+}
+
+type dotParser struct {
+	lex  *dotLexer
+	tok  *dotToken
+	keys *keyBuilder
+}
+
+func (p *dotParser) peek() (dotToken, error) {
+	if p.tok == nil {
+		t, err := p.lex.Next()
+		if err != nil {
+			return dotToken{}, err
+		}
+		p.tok = &t
+	}
+	return *p.tok, nil
+}
+
+func (p *dotParser) next() (dotToken, error) {
+	t, err := p.peek()
+	p.tok = nil
+	return t, err
+}
+
+func (p *dotParser) errorf(t dotToken, format string, args ...interface{}) error {
+	return fmt.Errorf("dot: line %d: %s", t.line, fmt.Sprintf(format, args...))
+}
+
+func (p *dotParser) expect(punct string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if !t.punct(punct) {
+		return p.errorf(t, "expected %q, got %v", punct, t)
+	}
+	return nil
+}
+
+// ident reads an identifier, including concatenated strings ("a" + "b").
+func (p *dotParser) ident() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if t.typ != dotIdent {
+		return "", p.errorf(t, "expected identifier, got %v", t)
+	}
+	s := t.val
+	for t.quoted {
+		t2, err := p.peek()
+		if err != nil {
+			return "", err
+		}
+		if !t2.punct("+") {
+			break
+		}
+		p.next()
+		t, err = p.next()
+		if err != nil {
+			return "", err
+		}
+		if t.typ != dotIdent || !t.quoted {
+			return "", p.errorf(t, "expected string, got %v", t)
+		}
+		s += t.val
+	}
+	return s, nil
+}
+
+type dotAttr struct {
+	name, value string
+}
+
+// dotAttrs is an ordered set of attributes.
+type dotAttrs []dotAttr
+
+func (a dotAttrs) set(name, value string) dotAttrs {
+	for i := range a {
+		if a[i].name == name {
+			a[i].value = value
+			return a
+		}
+	}
+	return append(a, dotAttr{name: name, value: value})
+}
+
+func (a dotAttrs) merge(b dotAttrs) dotAttrs {
+	for _, v := range b {
+		a = a.set(v.name, v.value)
+	}
+	return a
+}
+
+func (a dotAttrs) clone() dotAttrs {
+	return append(dotAttrs{}, a...)
+}
+
+type dotNode struct {
+	id     string
+	attrs  dotAttrs
+	graphs []*dotGraph
+}
+
+type dotEdge struct {
+	source, target string
+	attrs          dotAttrs
+}
+
+type dotGraph struct {
+	id       string
+	directed bool
+	attrs    dotAttrs
+	nodes    []*dotNode
+	edges    []dotEdge
+
+	// node and cluster indexes are shared by all subgraphs of the top-level graph
+	index    map[string]*dotNode
+	clusters map[string]*dotGraph
+	// edgeIndex is set for strict graphs, it is shared by all subgraphs of the top-level graph as well
+	edgeIndex map[[2]string]dotEdgeRef
+}
+
+// dotEdgeRef is a position of an edge in a (sub)graph.
+type dotEdgeRef struct {
+	graph *dotGraph
+	i     int
+}
+
+// addEdge adds an edge to the graph. In strict graphs, attributes of duplicate edges are merged
+// into the first edge instead.
+func (g *dotGraph) addEdge(e dotEdge) {
+	if g.edgeIndex == nil {
+		g.edges = append(g.edges, e)
+		return
+	}
+	k := [2]string{e.source, e.target}
+	if !g.directed && k[0] > k[1] {
+		k[0], k[1] = k[1], k[0]
+	}
+	if ref, ok := g.edgeIndex[k]; ok {
+		prev := &ref.graph.edges[ref.i]
+		prev.attrs = prev.attrs.merge(e.attrs)
+		return
+	}
+	g.edgeIndex[k] = dotEdgeRef{graph: g, i: len(g.edges)}
+	g.edges = append(g.edges, e)
+}
+
+// dotScope holds default attributes for the current (sub)graph.
+type dotScope struct {
+	graph *dotGraph
+	node  dotAttrs
+	edge  dotAttrs
+
+	mentioned []string // IDs of nodes mentioned in this scope
+}
+
+func (p *dotParser) parseGraph() (*dotGraph, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	g := &dotGraph{index: make(map[string]*dotNode), clusters: make(map[string]*dotGraph)}
+	if t.keyword("strict") {
+		// strict graphs have no multi-edges
+		g.edgeIndex = make(map[[2]string]dotEdgeRef)
+		t, err = p.next()
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case t.keyword("graph"):
+	case t.keyword("digraph"):
+		g.directed = true
+	default:
+		return nil, p.errorf(t, "expected graph, got %v", t)
+	}
+	if t, err = p.peek(); err != nil {
+		return nil, err
+	} else if t.typ == dotIdent {
+		if g.id, err = p.ident(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if err := p.parseStmts(&dotScope{graph: g}); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// parseStmts parses statements until the closing brace.
+func (p *dotParser) parseStmts(s *dotScope) error {
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return err
+		}
+		switch {
+		case t.typ == dotEOF:
+			return p.errorf(t, "unexpected EOF")
+		case t.punct("}"):
+			p.next()
+			return nil
+		case t.punct(";"):
+			p.next()
+			continue
+		}
+		if err := p.parseStmt(s); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *dotParser) parseAttrList() (dotAttrs, error) {
+	var attrs dotAttrs
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !t.punct("[") {
+			return attrs, nil
+		}
+		p.next()
+		for {
+			t, err = p.peek()
+			if err != nil {
+				return nil, err
+			}
+			if t.punct("]") {
+				p.next()
+				break
+			} else if t.punct(",") || t.punct(";") {
+				p.next()
+				continue
+			}
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expect("="); err != nil {
+				return nil, err
+			}
+			val, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			attrs = attrs.set(name, val)
+		}
+	}
+}
+
+func (p *dotParser) parseStmt(s *dotScope) error {
+	t, err := p.peek()
+	if err != nil {
+		return err
+	}
+	switch {
+	case t.keyword("graph"), t.keyword("node"), t.keyword("edge"):
+		p.next()
+		attrs, err := p.parseAttrList()
+		if err != nil {
+			return err
+		}
+		switch {
+		case t.keyword("graph"):
+			s.graph.attrs = s.graph.attrs.merge(attrs)
+		case t.keyword("node"):
+			s.node = s.node.merge(attrs)
+		case t.keyword("edge"):
+			s.edge = s.edge.merge(attrs)
+		}
+		return nil
+	}
+	// node, edge, subgraph or graph attribute
+	left, err := p.parseOperand(s)
+	if err != nil {
+		return err
+	}
+	t, err = p.peek()
+	if err != nil {
+		return err
+	}
+	if t.punct("=") && left.node != "" {
+		p.next()
+		val, err := p.ident()
+		if err != nil {
+			return err
+		}
+		s.graph.attrs = s.graph.attrs.set(left.node, val)
+		return nil
+	}
+	if !t.punct("->") && !t.punct("--") {
+		if left.node != "" {
+			attrs, err := p.parseAttrList()
+			if err != nil {
+				return err
+			}
+			n := p.declareNode(s, left.node)
+			n.attrs = n.attrs.merge(attrs)
+		}
+		return nil
+	}
+	// edge statement
+	if left.node != "" {
+		p.declareNode(s, left.node)
+	}
+	operands := []dotOperand{left}
+	for {
+		t, err = p.peek()
+		if err != nil {
+			return err
+		}
+		if !t.punct("->") && !t.punct("--") {
+			break
+		}
+		p.next()
+		op, err := p.parseOperand(s)
+		if err != nil {
+			return err
+		}
+		if op.node != "" {
+			p.declareNode(s, op.node)
+		}
+		operands = append(operands, op)
+	}
+	attrs, err := p.parseAttrList()
+	if err != nil {
+		return err
+	}
+	attrs = s.edge.clone().merge(attrs)
+	for i := 1; i < len(operands); i++ {
+		for _, src := range operands[i-1].nodes() {
+			for _, dst := range operands[i].nodes() {
+				s.graph.addEdge(dotEdge{source: src, target: dst, attrs: attrs.clone()})
+			}
+		}
+	}
+	return nil
+}
+
+// dotOperand is either a node ID or a subgraph.
+type dotOperand struct {
+	node string
+	sub  []string
+}
+
+func (o dotOperand) nodes() []string {
+	if o.node != "" {
+		return []string{o.node}
+	}
+	return o.sub
+}
+
+func (p *dotParser) parseOperand(s *dotScope) (dotOperand, error) {
+	t, err := p.peek()
+	if err != nil {
+		return dotOperand{}, err
+	}
+	if t.keyword("subgraph") || t.punct("{") {
+		nodes, err := p.parseSubgraph(s)
+		return dotOperand{sub: nodes}, err
+	}
+	id, err := p.ident()
+	if err != nil {
+		return dotOperand{}, err
+	}
+	// skip port and compass point
+	for i := 0; i < 2; i++ {
+		if t, err = p.peek(); err != nil {
+			return dotOperand{}, err
+		} else if !t.punct(":") {
+			break
+		}
+		p.next()
+		if _, err = p.ident(); err != nil {
+			return dotOperand{}, err
+		}
+	}
+	return dotOperand{node: id}, nil
+}
+
+// parseSubgraph parses a subgraph and returns IDs of all nodes mentioned in it.
+func (p *dotParser) parseSubgraph(s *dotScope) ([]string, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	var id string
+	if t.keyword("subgraph") {
+		if t, err = p.peek(); err != nil {
+			return nil, err
+		} else if t.typ == dotIdent {
+			if id, err = p.ident(); err != nil {
+				return nil, err
+			}
+		}
+		if err = p.expect("{"); err != nil {
+			return nil, err
+		}
+	}
+	sub := &dotScope{
+		graph: s.graph,
+		node:  s.node.clone(),
+		edge:  s.edge.clone(),
+	}
+	if strings.HasPrefix(id, "cluster") {
+		if g := s.graph.clusters[id]; g != nil {
+			// reopened cluster, statements are added to the existing one
+			sub.graph = g
+		} else {
+			sub.graph = &dotGraph{
+				directed:  s.graph.directed,
+				index:     s.graph.index,
+				clusters:  s.graph.clusters,
+				edgeIndex: s.graph.edgeIndex,
+			}
+			s.graph.clusters[id] = sub.graph
+			p.addCluster(s.graph, sub.graph, id)
+		}
+	}
+	if err := p.parseStmts(sub); err != nil {
+		return nil, err
+	}
+	s.mentioned = append(s.mentioned, sub.mentioned...)
+	return sub.mentioned, nil
+}
+
+// addCluster adds a nested graph for a cluster, creating a new node for it if necessary.
+func (p *dotParser) addCluster(parent, g *dotGraph, name string) {
+	id := strings.TrimPrefix(strings.TrimPrefix(name, "cluster"), "_")
+	if strings.HasSuffix(id, ":") {
+		// nested graph of an existing node (see ToDOT)
+		if n := parent.index[strings.TrimSuffix(id, ":")]; n != nil {
+			g.id = id
+			n.graphs = append(n.graphs, g)
+			return
+		}
+	}
+	if id == "" || parent.index[id] != nil {
+		id = name
+		for i := 1; parent.index[id] != nil; i++ {
+			id = name + "_" + strconv.Itoa(i)
+		}
+	}
+	n := &dotNode{id: id, graphs: []*dotGraph{g}}
+	g.id = id + ":"
+	parent.index[id] = n
+	parent.nodes = append(parent.nodes, n)
+}
+
+// declareNode adds a node to the current graph, if it was not declared before.
+func (p *dotParser) declareNode(s *dotScope, id string) *dotNode {
+	s.mentioned = append(s.mentioned, id)
+	n := s.graph.index[id]
+	if n == nil {
+		n = &dotNode{id: id, attrs: s.node.clone()}
+		s.graph.index[id] = n
+		s.graph.nodes = append(s.graph.nodes, n)
+	}
+	return n
+}
+
+func (g *dotGraph) reserveIDs(ids idSet) {
+	for _, n := range g.nodes {
+		ids.Reserve(n.id)
+		for _, sub := range n.graphs {
+			sub.reserveIDs(ids)
+		}
+	}
+}
+
+func (g *dotGraph) build(keys *keyBuilder, ids idSet) graphml.Graph {
+	var out graphml.Graph
+	if g.id != "" {
+		if ids.Reserve(g.id) {
+			out.ID = g.id
+		} else {
+			out.ID = ids.Unique(g.id + "_")
+		}
+	}
+	if g.directed {
+		out.EdgeDefault = graphml.EdgeDirected
+	} else {
+		out.EdgeDefault = graphml.EdgeUndirected
+	}
+	for _, a := range g.attrs {
+		out.Data = append(out.Data, keys.Data(graphml.KindGraph, a.name, a.value))
+	}
+	for _, n := range g.nodes {
+		gn := graphml.Node{}
+		gn.ID = n.id
+		for _, a := range n.attrs {
+			gn.Data = append(gn.Data, keys.Data(graphml.KindNode, a.name, a.value))
+		}
+		for _, sub := range n.graphs {
+			gn.Graphs = append(gn.Graphs, sub.build(keys, ids))
+		}
+		out.Nodes = append(out.Nodes, gn)
+	}
+	for _, e := range g.edges {
+		ge := graphml.Edge{Source: e.source, Target: e.target}
+		ge.ID = ids.Unique("e")
+		for _, a := range e.attrs {
+			ge.Data = append(ge.Data, keys.Data(graphml.KindEdge, a.name, a.value))
+		}
+		out.Edges = append(out.Edges, ge)
+	}
+	return out
+}
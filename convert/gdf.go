@@ -0,0 +1,287 @@
+package convert
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// gdfTypes maps GraphML attribute types to GDF column types.
+var gdfTypes = map[string]string{
+	"boolean": "BOOLEAN",
+	"int":     "INTEGER",
+	"long":    "BIGINT",
+	"float":   "FLOAT",
+	"double":  "DOUBLE",
+	"string":  "VARCHAR",
+}
+
+// ToGDF writes the document in GDF format, used by GUESS and Gephi.
+//
+// Each node and edge attribute is written as a typed column, with defaults taken from GraphML keys.
+// Attributes that clash with the predefined columns (name, node1, node2, directed) are skipped.
+// Nested graphs are flattened.
+//
+// GDF supports only one graph per file, thus an error is returned for documents with multiple graphs.
+func ToGDF(w io.Writer, doc *graphml.Document) error {
+	if len(doc.Graphs) > 1 {
+		return errors.New("gdf: only one graph per document is supported")
+	}
+	header := func(cols []*graphml.Key) string {
+		var sb strings.Builder
+		for _, k := range cols {
+			typ, ok := gdfTypes[k.Type]
+			if !ok {
+				typ = "VARCHAR"
+			}
+			sb.WriteString(",")
+			sb.WriteString(keyName(k) + " " + typ)
+			if k.Default != nil {
+				if def, ok := graphml.TokensText(k.Default); ok {
+					sb.WriteString(" default " + gdfQuote(def))
+				}
+			}
+		}
+		return sb.String()
+	}
+	row := func(vals ...string) string {
+		for i, v := range vals {
+			vals[i] = gdfQuote(v)
+		}
+		return strings.Join(vals, ",")
+	}
+	bw := bufio.NewWriter(w)
+	ncols := attrColumns(doc, graphml.KindNode, "name")
+	ecols := attrColumns(doc, graphml.KindEdge, "node1", "node2", "directed")
+	bw.WriteString("nodedef>name VARCHAR" + header(ncols) + "\n")
+	if len(doc.Graphs) != 0 {
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for _, n := range g.Nodes {
+				bw.WriteString(row(append([]string{n.ID}, csvValues(ncols, n.Data)...)...) + "\n")
+			}
+		})
+	}
+	bw.WriteString("edgedef>node1 VARCHAR,node2 VARCHAR,directed BOOLEAN" + header(ecols) + "\n")
+	if len(doc.Graphs) != 0 {
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for i := range g.Edges {
+				e := &g.Edges[i]
+				vals := append([]string{e.Source, e.Target, strconv.FormatBool(edgeDirected(g, e))}, csvValues(ecols, e.Data)...)
+				bw.WriteString(row(vals...) + "\n")
+			}
+		})
+	}
+	return bw.Flush()
+}
+
+// gdfQuote quotes a GDF value if necessary.
+func gdfQuote(s string) string {
+	if !strings.ContainsAny(s, `,'"`) && strings.TrimSpace(s) == s {
+		return s
+	}
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	return `"` + strings.Replace(s, `"`, `'`, -1) + `"`
+}
+
+// gdfSplit splits a GDF line to comma-separated fields. Fields may be quoted with single or double quotes.
+func gdfSplit(line string) []string {
+	var (
+		out []string
+		cur strings.Builder
+		q   rune
+	)
+	for _, r := range line {
+		switch {
+		case q != 0 && r == q:
+			q = 0
+		case q != 0:
+			cur.WriteRune(r)
+		case r == '\'' || r == '"':
+			q = r
+		case r == ',':
+			out = append(out, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	return append(out, strings.TrimSpace(cur.String()))
+}
+
+type gdfColumn struct {
+	name string
+	typ  string
+}
+
+// gdfHeader parses column definitions of a nodedef> or edgedef> line.
+func gdfHeader(kind graphml.Kind, keys *keyBuilder, defs string) ([]gdfColumn, error) {
+	var cols []gdfColumn
+	for _, def := range gdfSplit(defs) {
+		fields := strings.Fields(def)
+		if len(fields) == 0 {
+			return nil, errors.New("empty column definition")
+		}
+		col := gdfColumn{name: fields[0], typ: "string"}
+		if len(fields) > 1 {
+			typ := strings.ToUpper(fields[1])
+			if i := strings.IndexByte(typ, '('); i >= 0 {
+				typ = typ[:i]
+			}
+			switch typ {
+			case "VARCHAR", "CHAR", "TEXT", "STRING":
+				col.typ = "string"
+			case "BOOLEAN", "BOOL":
+				col.typ = "boolean"
+			case "INT", "INTEGER", "TINYINT", "SMALLINT":
+				col.typ = "int"
+			case "BIGINT", "LONG":
+				col.typ = "long"
+			case "FLOAT":
+				col.typ = "float"
+			case "DOUBLE", "REAL":
+				col.typ = "double"
+			default:
+				return nil, fmt.Errorf("unsupported column type: %q", fields[1])
+			}
+		}
+		cols = append(cols, col)
+		switch col.name {
+		case "name", "node1", "node2", "directed":
+			continue
+		}
+		keys.Declare(kind, col.name, col.typ)
+		if len(fields) > 3 && strings.EqualFold(fields[2], "default") {
+			keys.SetDefault(kind, col.name, strings.Join(fields[3:], " "))
+		}
+	}
+	return cols, nil
+}
+
+// FromGDF reads a graph in GDF format and converts it to GraphML.
+//
+// Typed columns are converted to GraphML keys, including their default values. The name column is used as
+// a node ID, and the node1 and node2 columns as edge endpoints. Edges are undirected unless the directed column
+// says otherwise. Nodes referenced by edges, but missing from the node section are created automatically.
+func FromGDF(r io.Reader) (*graphml.Document, error) {
+	keys := newKeyBuilder()
+	ids := newIDSet()
+	var (
+		g       graphml.Graph
+		kind    graphml.Kind
+		cols    []gdfColumn
+		edges   []graphml.Edge
+		directs []bool
+		line    int
+	)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		lower := strings.ToLower(text)
+		if strings.HasPrefix(lower, "nodedef>") || strings.HasPrefix(lower, "edgedef>") {
+			kind = graphml.KindNode
+			if strings.HasPrefix(lower, "edgedef>") {
+				kind = graphml.KindEdge
+			}
+			var err error
+			cols, err = gdfHeader(kind, keys, text[len("nodedef>"):])
+			if err != nil {
+				return nil, fmt.Errorf("gdf: line %d: %v", line, err)
+			}
+			continue
+		}
+		if kind == "" {
+			return nil, fmt.Errorf("gdf: line %d: expected nodedef> header", line)
+		}
+		vals := gdfSplit(text)
+		var (
+			data     []graphml.Data
+			id       string
+			src, dst string
+			directed bool
+		)
+		for i, v := range vals {
+			if i >= len(cols) {
+				break
+			}
+			switch col := cols[i]; {
+			case kind == graphml.KindNode && col.name == "name":
+				id = v
+			case kind == graphml.KindEdge && col.name == "node1":
+				src = v
+			case kind == graphml.KindEdge && col.name == "node2":
+				dst = v
+			case kind == graphml.KindEdge && col.name == "directed":
+				directed, _ = strconv.ParseBool(v)
+			case v == "" || col.name == "name" || col.name == "node1" || col.name == "node2" || col.name == "directed":
+			default:
+				data = append(data, keys.Data(kind, col.name, v))
+			}
+		}
+		if kind == graphml.KindNode {
+			if id == "" {
+				return nil, fmt.Errorf("gdf: line %d: node without a name", line)
+			}
+			if !ids.Reserve(id) {
+				return nil, fmt.Errorf("gdf: line %d: duplicate node: %q", line, id)
+			}
+			var n graphml.Node
+			n.ID = id
+			n.Data = data
+			g.Nodes = append(g.Nodes, n)
+			continue
+		}
+		if src == "" || dst == "" {
+			return nil, fmt.Errorf("gdf: line %d: edge without node1 or node2", line)
+		}
+		var e graphml.Edge
+		e.Source, e.Target = src, dst
+		e.Data = data
+		edges = append(edges, e)
+		directs = append(directs, directed)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	g.EdgeDefault = graphml.EdgeUndirected
+	if len(edges) != 0 {
+		g.EdgeDefault = graphml.EdgeDirected
+		for _, d := range directs {
+			if !d {
+				g.EdgeDefault = graphml.EdgeUndirected
+				break
+			}
+		}
+	}
+	for _, e := range edges {
+		for _, id := range []string{e.Source, e.Target} {
+			if ids.Reserve(id) {
+				var n graphml.Node
+				n.ID = id
+				g.Nodes = append(g.Nodes, n)
+			}
+		}
+	}
+	for i, e := range edges {
+		e.ID = ids.Unique("e")
+		if directs[i] && g.EdgeDefault == graphml.EdgeUndirected {
+			e.Unrecognized = append(e.Unrecognized, directedAttr(true))
+		}
+		g.Edges = append(g.Edges, e)
+	}
+	doc := newDocument()
+	doc.Graphs = []graphml.Graph{g}
+	doc.Keys = keys.Keys()
+	return doc, nil
+}
@@ -0,0 +1,508 @@
+package convert
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+const (
+	// GEXFNamespace is a namespace of GEXF 1.3 format.
+	GEXFNamespace = "http://gexf.net/1.3"
+	// GEXFVizNamespace is a namespace of GEXF visualization extension.
+	GEXFVizNamespace = "http://gexf.net/1.3/viz"
+)
+
+// gexfTypes maps GraphML attribute types to GEXF types.
+var gexfTypes = map[string]string{
+	"boolean": "boolean",
+	"int":     "integer",
+	"long":    "long",
+	"float":   "float",
+	"double":  "double",
+	"string":  "string",
+}
+
+// gexfSpecial is a set of GraphML attributes that are stored as GEXF element attributes or viz extensions.
+var gexfSpecial = map[graphml.Kind]map[string]bool{
+	graphml.KindNode: {
+		"label": true,
+		"r":     true, "g": true, "b": true,
+		"x": true, "y": true, "z": true,
+		"size": true,
+	},
+	graphml.KindEdge: {
+		"label":  true,
+		"weight": true,
+	},
+}
+
+// ToGEXF writes the document in GEXF 1.3 format used by Gephi.
+//
+// Node and edge keys are converted to GEXF attribute declarations, preserving types and defaults.
+// Attributes named label and weight are written as GEXF element attributes, while r, g, b, x, y, z and size
+// node attributes are written as viz extensions. Nested graphs are converted to GEXF node hierarchy.
+//
+// GEXF supports only one graph per file, thus an error is returned for documents with multiple graphs.
+func ToGEXF(w io.Writer, doc *graphml.Document) error {
+	if len(doc.Graphs) > 1 {
+		return errors.New("gexf: only one graph per document is supported")
+	}
+	e := &gexfEncoder{xw: newXMLWriter(w), keys: newKeyIndex(doc), ids: newIDSet()}
+	xw := e.xw
+	xw.Header()
+	xw.Start("gexf", "xmlns", GEXFNamespace, "xmlns:viz", GEXFVizNamespace, "version", "1.3")
+	xw.Start("meta")
+	xw.TextElem("creator", "github.com/dennwc/graphml")
+	xw.End("meta")
+	var g *graphml.Graph
+	if len(doc.Graphs) != 0 {
+		g = &doc.Graphs[0]
+	} else {
+		g = &graphml.Graph{}
+	}
+	typ := string(g.EdgeDefault)
+	if typ == "" {
+		typ = string(graphml.EdgeDirected)
+	}
+	xw.Start("graph", "defaultedgetype", typ, "mode", "static")
+	e.writeAttrs(doc, graphml.KindNode)
+	e.writeAttrs(doc, graphml.KindEdge)
+	for _, gr := range doc.Graphs {
+		e.reserveIDs(gr)
+	}
+	xw.Start("nodes")
+	e.writeNodes(g)
+	xw.End("nodes")
+	xw.Start("edges")
+	for _, ed := range e.edges {
+		e.writeEdge(ed)
+	}
+	xw.End("edges")
+	xw.End("graph")
+	xw.End("gexf")
+	return xw.Close()
+}
+
+type gexfEncoder struct {
+	xw    *xmlWriter
+	keys  *keyIndex
+	ids   idSet
+	edges []*graphml.Edge
+}
+
+func (e *gexfEncoder) reserveIDs(g graphml.Graph) {
+	for _, n := range g.Nodes {
+		e.ids.Reserve(n.ID)
+		for _, sub := range n.Graphs {
+			e.reserveIDs(sub)
+		}
+	}
+	for _, ed := range g.Edges {
+		if ed.ID != "" {
+			e.ids.Reserve(ed.ID)
+		}
+	}
+}
+
+func (e *gexfEncoder) writeAttrs(doc *graphml.Document, kind graphml.Kind) {
+	started := false
+	for _, k := range doc.Keys {
+		if k.For != kind && k.For != graphml.KindAll {
+			continue
+		}
+		name := k.Name
+		if name == "" {
+			name = k.ID
+		}
+		if gexfSpecial[kind][name] {
+			continue
+		}
+		if !started {
+			e.xw.Start("attributes", "class", string(kind), "mode", "static")
+			started = true
+		}
+		typ, ok := gexfTypes[k.Type]
+		if !ok {
+			typ = "string"
+		}
+		e.xw.Start("attribute", "id", k.ID, "title", name, "type", typ)
+		if k.Default != nil {
+			if def, ok := graphml.TokensText(k.Default); ok {
+				e.xw.TextElem("default", def)
+			}
+		}
+		e.xw.End("attribute")
+	}
+	if started {
+		e.xw.End("attributes")
+	}
+}
+
+// writeValues writes attvalues for the element and returns special attributes.
+func (e *gexfEncoder) writeValues(kind graphml.Kind, data []graphml.Data) {
+	started := false
+	for _, d := range data {
+		if gexfSpecial[kind][e.keys.Name(kind, d.Key)] {
+			continue
+		}
+		v, ok := dataText(d)
+		if !ok {
+			continue
+		}
+		if !started {
+			e.xw.Start("attvalues")
+			started = true
+		}
+		e.xw.Elem("attvalue", "for", d.Key, "value", v)
+	}
+	if started {
+		e.xw.End("attvalues")
+	}
+}
+
+func (e *gexfEncoder) writeNodes(g *graphml.Graph) {
+	for i := range g.Edges {
+		e.edges = append(e.edges, &g.Edges[i])
+	}
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		attrs := []string{"id", n.ID}
+		if label, ok := e.keys.Attr(graphml.KindNode, n.Data, "label"); ok {
+			attrs = append(attrs, "label", label)
+		}
+		e.xw.Start("node", attrs...)
+		e.writeValues(graphml.KindNode, n.Data)
+		e.writeViz(n)
+		if len(n.Graphs) != 0 {
+			e.xw.Start("nodes")
+			for j := range n.Graphs {
+				e.writeNodes(&n.Graphs[j])
+			}
+			e.xw.End("nodes")
+		}
+		e.xw.End("node")
+	}
+}
+
+func (e *gexfEncoder) writeViz(n *graphml.Node) {
+	get := func(name string) (string, bool) {
+		v, ok := e.keys.Attr(graphml.KindNode, n.Data, name)
+		if !ok {
+			return "", false
+		}
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return "", false
+		}
+		return v, true
+	}
+	r, ok1 := get("r")
+	g, ok2 := get("g")
+	b, ok3 := get("b")
+	if ok1 && ok2 && ok3 {
+		e.xw.Elem("viz:color", "r", r, "g", g, "b", b)
+	}
+	x, ok1 := get("x")
+	y, ok2 := get("y")
+	if ok1 && ok2 {
+		attrs := []string{"x", x, "y", y}
+		if z, ok := get("z"); ok {
+			attrs = append(attrs, "z", z)
+		}
+		e.xw.Elem("viz:position", attrs...)
+	}
+	if size, ok := get("size"); ok {
+		e.xw.Elem("viz:size", "value", size)
+	}
+}
+
+func (e *gexfEncoder) writeEdge(ed *graphml.Edge) {
+	id := ed.ID
+	if id == "" {
+		id = e.ids.Unique("e")
+	}
+	attrs := []string{"id", id, "source", ed.Source, "target", ed.Target}
+	if label, ok := e.keys.Attr(graphml.KindEdge, ed.Data, "label"); ok {
+		attrs = append(attrs, "label", label)
+	}
+	if w, ok := e.keys.Attr(graphml.KindEdge, ed.Data, "weight"); ok {
+		attrs = append(attrs, "weight", w)
+	}
+	e.xw.Start("edge", attrs...)
+	e.writeValues(graphml.KindEdge, ed.Data)
+	e.xw.End("edge")
+}
+
+type gexfDocument struct {
+	Graph gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class string          `xml:"class,attr"`
+	Attrs []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID      string  `xml:"id,attr"`
+	Title   string  `xml:"title,attr"`
+	Type    string  `xml:"type,attr"`
+	Default *string `xml:"default"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	ID    string `xml:"id,attr"` // GEXF 1.1
+	Value string `xml:"value,attr"`
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+type gexfSpell struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+type gexfObject struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	Start     string         `xml:"start,attr"`
+	End       string         `xml:"end,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+	Spells    []gexfSpell    `xml:"spells>spell"`
+	Color     *struct {
+		R string `xml:"r,attr"`
+		G string `xml:"g,attr"`
+		B string `xml:"b,attr"`
+	} `xml:"color"`
+}
+
+type gexfNode struct {
+	gexfObject
+	PID      string `xml:"pid,attr"`
+	Position *struct {
+		X string `xml:"x,attr"`
+		Y string `xml:"y,attr"`
+		Z string `xml:"z,attr"`
+	} `xml:"position"`
+	Size *struct {
+		Value string `xml:"value,attr"`
+	} `xml:"size"`
+	Nodes []gexfNode `xml:"nodes>node"`
+}
+
+type gexfEdge struct {
+	gexfObject
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Type   string `xml:"type,attr"`
+	Weight string `xml:"weight,attr"`
+}
+
+// graphmlTypes maps GEXF attribute types to GraphML types.
+var graphmlTypes = map[string]string{
+	"boolean": "boolean",
+	"integer": "int",
+	"long":    "long",
+	"float":   "float",
+	"double":  "double",
+}
+
+// FromGEXF reads a GEXF document and converts it to GraphML.
+//
+// GEXF attributes are converted to GraphML keys, preserving types and defaults. Labels, edge weights
+// and viz extensions (color, position and size) are converted to the attributes with the same names as
+// ToGEXF expects: label, weight, r, g, b, x, y, z and size. Node hierarchy is converted to nested graphs.
+//
+// For dynamic graphs, the start and end of the element lifetime are stored in start and end attributes,
+// while spells are stored in a spells attribute as a list of intervals. Only the last value of a dynamic
+// attribute is preserved.
+func FromGEXF(r io.Reader) (*graphml.Document, error) {
+	var gd gexfDocument
+	if err := xml.NewDecoder(r).Decode(&gd); err != nil {
+		return nil, err
+	}
+	d := &gexfDecoder{
+		keys:  newKeyBuilder(),
+		names: make(map[kindID]string),
+		nodes: make(map[string]*gexfTreeNode),
+	}
+	for _, attrs := range gd.Graph.Attributes {
+		kind := graphml.Kind(attrs.Class)
+		if kind != graphml.KindNode && kind != graphml.KindEdge {
+			continue
+		}
+		for _, a := range attrs.Attrs {
+			name := a.Title
+			if name == "" {
+				name = a.ID
+			}
+			typ, ok := graphmlTypes[a.Type]
+			if !ok {
+				typ = "string"
+			}
+			d.names[kindID{kind: kind, id: a.ID}] = name
+			d.keys.Declare(kind, name, typ)
+			if a.Default != nil {
+				d.keys.SetDefault(kind, name, *a.Default)
+			}
+		}
+	}
+	g := graphml.Graph{EdgeDefault: graphml.EdgeDirected}
+	if gd.Graph.DefaultEdgeType == "undirected" {
+		g.EdgeDefault = graphml.EdgeUndirected
+	}
+	var roots []*gexfTreeNode
+	d.addNodes(&roots, "", gd.Graph.Nodes)
+	// attach nodes declared with pid
+	top := roots[:0]
+	for _, n := range roots {
+		if p := d.nodes[n.pid]; n.pid != "" && p != nil {
+			p.children = append(p.children, n)
+		} else {
+			top = append(top, n)
+		}
+	}
+	for _, n := range top {
+		g.Nodes = append(g.Nodes, d.buildNode(n))
+	}
+	for _, e := range gd.Graph.Edges {
+		ge := graphml.Edge{Source: e.Source, Target: e.Target}
+		ge.ID = e.ID
+		switch {
+		case e.Type == "undirected" && g.EdgeDefault != graphml.EdgeUndirected:
+			ge.Unrecognized = append(ge.Unrecognized, undirectedAttr)
+		case (e.Type == "directed" || e.Type == "mutual") && g.EdgeDefault != graphml.EdgeDirected:
+			ge.Unrecognized = append(ge.Unrecognized, directedAttr(true))
+		}
+		ge.Data = d.objectData(graphml.KindEdge, &e.gexfObject)
+		if e.Weight != "" {
+			d.keys.Declare(graphml.KindEdge, "weight", "double")
+			ge.Data = append(ge.Data, d.keys.Data(graphml.KindEdge, "weight", e.Weight))
+		}
+		g.Edges = append(g.Edges, ge)
+	}
+	doc := newDocument()
+	doc.Graphs = []graphml.Graph{g}
+	doc.Keys = d.keys.Keys()
+	return doc, nil
+}
+
+type gexfTreeNode struct {
+	*gexfNode
+	pid      string
+	children []*gexfTreeNode
+}
+
+type gexfDecoder struct {
+	keys  *keyBuilder
+	names map[kindID]string
+	nodes map[string]*gexfTreeNode
+}
+
+func (d *gexfDecoder) addNodes(out *[]*gexfTreeNode, pid string, nodes []gexfNode) {
+	for i := range nodes {
+		n := &gexfTreeNode{gexfNode: &nodes[i], pid: nodes[i].PID}
+		d.nodes[n.ID] = n
+		if pid != "" {
+			d.nodes[pid].children = append(d.nodes[pid].children, n)
+		} else {
+			*out = append(*out, n)
+		}
+		d.addNodes(out, n.ID, n.Nodes)
+	}
+}
+
+func (d *gexfDecoder) objectData(kind graphml.Kind, o *gexfObject) []graphml.Data {
+	var out []graphml.Data
+	add := func(name, typ, value string) {
+		d.keys.Declare(kind, name, typ)
+		out = append(out, d.keys.Data(kind, name, value))
+	}
+	if o.Label != "" {
+		add("label", "string", o.Label)
+	}
+	// take the last value for dynamic attributes
+	var (
+		names  []string
+		values = make(map[string]string)
+	)
+	for _, v := range o.AttValues {
+		id := v.For
+		if id == "" {
+			id = v.ID
+		}
+		name, ok := d.names[kindID{kind: kind, id: id}]
+		if !ok {
+			name = id
+		}
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		values[name] = v.Value
+	}
+	for _, name := range names {
+		out = append(out, d.keys.Data(kind, name, values[name]))
+	}
+	if o.Color != nil {
+		add("r", "int", o.Color.R)
+		add("g", "int", o.Color.G)
+		add("b", "int", o.Color.B)
+	}
+	if o.Start != "" {
+		add("start", "string", o.Start)
+	}
+	if o.End != "" {
+		add("end", "string", o.End)
+	}
+	if len(o.Spells) != 0 {
+		var sb strings.Builder
+		for i, s := range o.Spells {
+			if i != 0 {
+				sb.WriteString(";")
+			}
+			sb.WriteString("[" + s.Start + "," + s.End + "]")
+		}
+		add("spells", "string", sb.String())
+	}
+	return out
+}
+
+func (d *gexfDecoder) buildNode(n *gexfTreeNode) graphml.Node {
+	var gn graphml.Node
+	gn.ID = n.ID
+	gn.Data = d.objectData(graphml.KindNode, &n.gexfObject)
+	add := func(name, value string) {
+		d.keys.Declare(graphml.KindNode, name, "float")
+		gn.Data = append(gn.Data, d.keys.Data(graphml.KindNode, name, value))
+	}
+	if p := n.Position; p != nil {
+		add("x", p.X)
+		add("y", p.Y)
+		if p.Z != "" {
+			add("z", p.Z)
+		}
+	}
+	if n.Size != nil {
+		add("size", n.Size.Value)
+	}
+	if len(n.children) != 0 {
+		sub := graphml.Graph{}
+		sub.ID = n.ID + ":"
+		for _, c := range n.children {
+			sub.Nodes = append(sub.Nodes, d.buildNode(c))
+		}
+		gn.Graphs = append(gn.Graphs, sub)
+	}
+	return gn
+}
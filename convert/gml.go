@@ -0,0 +1,508 @@
+package convert
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dennwc/graphml"
+)
+
+// gmlEscape escapes a string for GML. GML strings use HTML entities for escaping.
+var gmlEscape = strings.NewReplacer(`&`, `&amp;`, `"`, `&quot;`)
+
+// gmlUnescape reverses gmlEscape.
+var gmlUnescape = strings.NewReplacer(`&quot;`, `"`, `&lt;`, `<`, `&gt;`, `>`, `&amp;`, `&`)
+
+// ToGML writes the document in GML (Graph Modelling Language) format.
+//
+// GML requires integer node IDs, thus nodes are renumbered. Node label is written from the label attribute,
+// or from GraphML node ID if the attribute is not set. Attributes with dotted names (like "graphics.x") are
+// written as nested lists. Nested graphs are flattened, using isGroup and gid attributes, the same way yEd does.
+//
+// GML supports only one graph per file, thus an error is returned for documents with multiple graphs.
+func ToGML(w io.Writer, doc *graphml.Document) error {
+	if len(doc.Graphs) > 1 {
+		return errors.New("gml: only one graph per document is supported")
+	}
+	bw := bufio.NewWriter(w)
+	e := &gmlEncoder{w: bw, keys: newKeyIndex(doc), ids: make(map[string]int)}
+	e.line(`Creator "github.com/dennwc/graphml"`)
+	e.line("graph [")
+	e.depth++
+	if len(doc.Graphs) != 0 {
+		g := &doc.Graphs[0]
+		if g.IsDirected() {
+			e.line("directed 1")
+		} else {
+			e.line("directed 0")
+		}
+		e.writeAttrs(graphml.KindGraph, g.Data)
+		e.numberNodes(g)
+		e.writeNodes(g, -1)
+		e.writeEdges(g)
+	}
+	e.depth--
+	e.line("]")
+	return bw.Flush()
+}
+
+type gmlEncoder struct {
+	w     *bufio.Writer
+	keys  *keyIndex
+	ids   map[string]int
+	depth int
+}
+
+func (e *gmlEncoder) line(format string, args ...interface{}) {
+	e.w.WriteString(strings.Repeat("  ", e.depth))
+	fmt.Fprintf(e.w, format, args...)
+	e.w.WriteByte('\n')
+}
+
+func (e *gmlEncoder) numberNodes(g *graphml.Graph) {
+	for _, n := range g.Nodes {
+		if _, ok := e.ids[n.ID]; !ok {
+			e.ids[n.ID] = len(e.ids)
+		}
+		for i := range n.Graphs {
+			e.numberNodes(&n.Graphs[i])
+		}
+	}
+}
+
+// gmlName converts an attribute name to a valid GML key.
+func gmlName(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		if r == '_' || (r < 0x80 && unicode.IsLetter(r)) || (i > 0 && r < 0x80 && unicode.IsDigit(r)) {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	if sb.Len() == 0 {
+		return "_"
+	}
+	return sb.String()
+}
+
+func (e *gmlEncoder) value(a attr) string {
+	typ := ""
+	if a.Key != nil {
+		typ = a.Key.Type
+	}
+	switch typ {
+	case "int", "long":
+		if _, err := strconv.ParseInt(a.Value, 10, 64); err == nil {
+			return a.Value
+		}
+	case "float", "double":
+		if f, err := strconv.ParseFloat(a.Value, 64); err == nil {
+			s := strconv.FormatFloat(f, 'g', -1, 64)
+			if !strings.ContainsAny(s, ".eEnN") {
+				s += ".0"
+			}
+			return s
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(a.Value); err == nil {
+			if b {
+				return "1"
+			}
+			return "0"
+		}
+	}
+	return `"` + gmlEscape.Replace(a.Value) + `"`
+}
+
+// writeAttrs writes all simple attributes of an element, except the ones listed in skip.
+func (e *gmlEncoder) writeAttrs(kind graphml.Kind, data []graphml.Data, skip ...string) {
+	var attrs []attr
+loop:
+	for _, a := range e.keys.Attrs(kind, data) {
+		for _, s := range skip {
+			if a.Name == s {
+				continue loop
+			}
+		}
+		attrs = append(attrs, a)
+	}
+	e.writeEntries(attrs)
+}
+
+// writeEntries writes attributes as key-value pairs. Dotted names are written as nested lists.
+func (e *gmlEncoder) writeEntries(attrs []attr) {
+	var (
+		lists []string
+		sub   = make(map[string][]attr)
+	)
+	for _, a := range attrs {
+		if i := strings.IndexByte(a.Name, '.'); i > 0 && i < len(a.Name)-1 {
+			name := a.Name[:i]
+			if _, ok := sub[name]; !ok {
+				lists = append(lists, name)
+			}
+			a.Name = a.Name[i+1:]
+			sub[name] = append(sub[name], a)
+			continue
+		}
+		e.line("%s %s", gmlName(a.Name), e.value(a))
+	}
+	for _, name := range lists {
+		e.line("%s [", gmlName(name))
+		e.depth++
+		e.writeEntries(sub[name])
+		e.depth--
+		e.line("]")
+	}
+}
+
+func (e *gmlEncoder) writeNodes(g *graphml.Graph, gid int) {
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		e.line("node [")
+		e.depth++
+		e.line("id %d", e.ids[n.ID])
+		if _, ok := e.keys.Attr(graphml.KindNode, n.Data, "label"); !ok {
+			e.line(`label "%s"`, gmlEscape.Replace(n.ID))
+		}
+		if len(n.Graphs) != 0 {
+			e.line("isGroup 1")
+		}
+		if gid >= 0 {
+			e.line("gid %d", gid)
+		}
+		e.writeAttrs(graphml.KindNode, n.Data, "id", "isGroup", "gid")
+		e.depth--
+		e.line("]")
+		for j := range n.Graphs {
+			e.writeNodes(&n.Graphs[j], e.ids[n.ID])
+		}
+	}
+}
+
+func (e *gmlEncoder) writeEdges(g *graphml.Graph) {
+	for i := range g.Edges {
+		ed := &g.Edges[i]
+		src, ok1 := e.ids[ed.Source]
+		dst, ok2 := e.ids[ed.Target]
+		if !ok1 || !ok2 {
+			continue
+		}
+		e.line("edge [")
+		e.depth++
+		e.line("source %d", src)
+		e.line("target %d", dst)
+		e.writeAttrs(graphml.KindEdge, ed.Data, "source", "target")
+		e.depth--
+		e.line("]")
+	}
+	for i := range g.Nodes {
+		for j := range g.Nodes[i].Graphs {
+			e.writeEdges(&g.Nodes[i].Graphs[j])
+		}
+	}
+}
+
+type gmlType int
+
+const (
+	gmlInt = gmlType(iota)
+	gmlReal
+	gmlString
+	gmlList
+)
+
+type gmlValue struct {
+	typ  gmlType
+	str  string
+	list []gmlPair
+}
+
+type gmlPair struct {
+	key string
+	val gmlValue
+}
+
+type gmlParser struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (p *gmlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("gml: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *gmlParser) read() (rune, error) {
+	r, _, err := p.r.ReadRune()
+	if r == '\n' {
+		p.line++
+	}
+	return r, err
+}
+
+func (p *gmlParser) unread(r rune) {
+	if r == '\n' {
+		p.line--
+	}
+	p.r.UnreadRune()
+}
+
+// token reads the next token: a key, a value or a bracket. Strings are returned with quotes.
+func (p *gmlParser) token() (string, error) {
+	var r rune
+	for {
+		var err error
+		r, err = p.read()
+		if err != nil {
+			return "", err
+		}
+		if r == '#' {
+			if _, err = p.r.ReadString('\n'); err != nil {
+				return "", err
+			}
+			p.line++
+			continue
+		}
+		if !unicode.IsSpace(r) {
+			break
+		}
+	}
+	switch r {
+	case '[', ']':
+		return string(r), nil
+	case '"':
+		var sb strings.Builder
+		sb.WriteRune(r)
+		for {
+			r, err := p.read()
+			if err == io.EOF {
+				return "", p.errorf("unterminated string")
+			} else if err != nil {
+				return "", err
+			}
+			sb.WriteRune(r)
+			if r == '"' {
+				return sb.String(), nil
+			}
+		}
+	}
+	var sb strings.Builder
+	sb.WriteRune(r)
+	for {
+		r, err := p.read()
+		if err == io.EOF {
+			return sb.String(), nil
+		} else if err != nil {
+			return "", err
+		}
+		if unicode.IsSpace(r) || r == '[' || r == ']' || r == '"' {
+			p.unread(r)
+			return sb.String(), nil
+		}
+		sb.WriteRune(r)
+	}
+}
+
+// parseList parses key-value pairs until the closing bracket (or EOF, for the top level).
+func (p *gmlParser) parseList(top bool) ([]gmlPair, error) {
+	var out []gmlPair
+	for {
+		key, err := p.token()
+		if err == io.EOF && top {
+			return out, nil
+		} else if err == io.EOF {
+			return nil, p.errorf("unexpected EOF")
+		} else if err != nil {
+			return nil, err
+		}
+		if key == "]" && !top {
+			return out, nil
+		}
+		if key == "[" || key == "]" || strings.HasPrefix(key, `"`) {
+			return nil, p.errorf("expected key, got %q", key)
+		}
+		val, err := p.token()
+		if err == io.EOF {
+			return nil, p.errorf("unexpected EOF")
+		} else if err != nil {
+			return nil, err
+		}
+		var v gmlValue
+		switch {
+		case val == "[":
+			v.typ = gmlList
+			v.list, err = p.parseList(false)
+			if err != nil {
+				return nil, err
+			}
+		case val == "]":
+			return nil, p.errorf("expected value for %q", key)
+		case strings.HasPrefix(val, `"`):
+			v.typ = gmlString
+			v.str = gmlUnescape.Replace(val[1 : len(val)-1])
+		default:
+			if _, err := strconv.ParseInt(val, 10, 64); err == nil {
+				v.typ = gmlInt
+			} else if _, err := strconv.ParseFloat(val, 64); err == nil {
+				v.typ = gmlReal
+			} else {
+				// some writers do not quote strings
+				v.typ = gmlString
+			}
+			v.str = val
+		}
+		out = append(out, gmlPair{key: key, val: v})
+	}
+}
+
+// FromGML reads a graph in GML (Graph Modelling Language) format and converts it to GraphML.
+//
+// Numeric node IDs are prefixed with "n" to form valid GraphML IDs. Scalar attributes are converted to
+// GraphML data with int, double or string type, while nested lists are flattened to dotted attribute names
+// (like "graphics.x"). Node groups defined with gid attributes are converted to nested graphs.
+func FromGML(r io.Reader) (*graphml.Document, error) {
+	p := &gmlParser{r: bufio.NewReader(r), line: 1}
+	top, err := p.parseList(true)
+	if err != nil {
+		return nil, err
+	}
+	var gl []gmlPair
+	found := false
+	for _, kv := range top {
+		if kv.key == "graph" && kv.val.typ == gmlList {
+			gl, found = kv.val.list, true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.New("gml: no graph found")
+	}
+	d := &gmlDecoder{keys: newKeyBuilder(), ids: newIDSet()}
+	g := graphml.Graph{EdgeDefault: graphml.EdgeUndirected}
+	type gmlNode struct {
+		node graphml.Node
+		gid  string
+	}
+	var (
+		nodes []*gmlNode
+		byID  = make(map[string]*gmlNode)
+		edges []gmlValue
+	)
+	for _, kv := range gl {
+		switch kv.key {
+		case "directed":
+			if kv.val.str == "1" {
+				g.EdgeDefault = graphml.EdgeDirected
+			}
+		case "node":
+			n := &gmlNode{}
+			for _, a := range kv.val.list {
+				switch a.key {
+				case "id":
+					n.node.ID = d.nodeID(a.val.str)
+				case "gid":
+					n.gid = d.nodeID(a.val.str)
+				default:
+					n.node.Data = d.appendData(n.node.Data, graphml.KindNode, a.key, a.val)
+				}
+			}
+			if n.node.ID == "" {
+				return nil, errors.New("gml: node without id")
+			}
+			if !d.ids.Reserve(n.node.ID) {
+				return nil, fmt.Errorf("gml: duplicate node id: %q", n.node.ID)
+			}
+			nodes = append(nodes, n)
+			byID[n.node.ID] = n
+		case "edge":
+			edges = append(edges, kv.val)
+		default:
+			g.Data = d.appendData(g.Data, graphml.KindGraph, kv.key, kv.val)
+		}
+	}
+	for _, ev := range edges {
+		var e graphml.Edge
+		for _, a := range ev.list {
+			switch a.key {
+			case "source":
+				e.Source = d.nodeID(a.val.str)
+			case "target":
+				e.Target = d.nodeID(a.val.str)
+			default:
+				e.Data = d.appendData(e.Data, graphml.KindEdge, a.key, a.val)
+			}
+		}
+		e.ID = d.ids.Unique("e")
+		g.Edges = append(g.Edges, e)
+	}
+	// build group hierarchy
+	children := make(map[string][]*gmlNode)
+	var roots []*gmlNode
+	for _, n := range nodes {
+		if byID[n.gid] != nil && n.gid != n.node.ID {
+			children[n.gid] = append(children[n.gid], n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+	var build func(n *gmlNode, path map[string]bool) graphml.Node
+	build = func(n *gmlNode, path map[string]bool) graphml.Node {
+		out := n.node
+		sub := children[n.node.ID]
+		if len(sub) == 0 || path[n.node.ID] {
+			return out
+		}
+		path[n.node.ID] = true
+		ng := graphml.Graph{EdgeDefault: g.EdgeDefault}
+		ng.ID = d.ids.Unique(n.node.ID + ":")
+		for _, c := range sub {
+			ng.Nodes = append(ng.Nodes, build(c, path))
+		}
+		delete(path, n.node.ID)
+		out.Graphs = append(out.Graphs, ng)
+		return out
+	}
+	for _, n := range roots {
+		g.Nodes = append(g.Nodes, build(n, make(map[string]bool)))
+	}
+	doc := newDocument()
+	doc.Graphs = []graphml.Graph{g}
+	doc.Keys = d.keys.Keys()
+	return doc, nil
+}
+
+type gmlDecoder struct {
+	keys *keyBuilder
+	ids  idSet
+}
+
+func (d *gmlDecoder) nodeID(s string) string {
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return "n" + s
+	}
+	return s
+}
+
+func (d *gmlDecoder) appendData(data []graphml.Data, kind graphml.Kind, name string, v gmlValue) []graphml.Data {
+	switch v.typ {
+	case gmlList:
+		for _, a := range v.list {
+			data = d.appendData(data, kind, name+"."+a.key, a.val)
+		}
+		return data
+	case gmlInt:
+		return append(data, d.keys.Data(kind, name, v.str))
+	case gmlReal:
+		d.keys.Declare(kind, name, "double")
+		return append(data, d.keys.Data(kind, name, v.str))
+	default:
+		d.keys.Declare(kind, name, "string")
+		return append(data, d.keys.Data(kind, name, v.str))
+	}
+}
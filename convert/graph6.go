@@ -0,0 +1,345 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// g6Graph is an unlabeled graph topology used by graph6 and sparse6 formats.
+type g6Graph struct {
+	n     int
+	edges [][2]int
+}
+
+// g6Graphs converts each top-level graph of the document to unlabeled topology.
+func g6Graphs(doc *graphml.Document) []g6Graph {
+	var out []g6Graph
+	for i := range doc.Graphs {
+		index := make(map[string]int)
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for _, n := range g.Nodes {
+				if _, ok := index[n.ID]; !ok {
+					index[n.ID] = len(index)
+				}
+			}
+		})
+		gr := g6Graph{n: len(index)}
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for _, e := range g.Edges {
+				src, ok1 := index[e.Source]
+				dst, ok2 := index[e.Target]
+				if ok1 && ok2 {
+					gr.edges = append(gr.edges, [2]int{src, dst})
+				}
+			}
+		})
+		out = append(out, gr)
+	}
+	return out
+}
+
+// g6Size encodes the number of vertices.
+func g6Size(n int) []byte {
+	switch {
+	case n <= 62:
+		return []byte{byte(n + 63)}
+	case n <= 258047:
+		return []byte{126, byte(n>>12&63 + 63), byte(n>>6&63 + 63), byte(n&63 + 63)}
+	}
+	out := []byte{126, 126}
+	for s := 30; s >= 0; s -= 6 {
+		out = append(out, byte(n>>uint(s)&63+63))
+	}
+	return out
+}
+
+// g6Bits packs bits into printable graph6 characters, padding the last one with a given bit.
+func g6Bits(bits []bool, pad bool) []byte {
+	for len(bits)%6 != 0 {
+		bits = append(bits, pad)
+	}
+	out := make([]byte, 0, len(bits)/6)
+	for i := 0; i < len(bits); i += 6 {
+		var c byte
+		for _, b := range bits[i : i+6] {
+			c <<= 1
+			if b {
+				c |= 1
+			}
+		}
+		out = append(out, c+63)
+	}
+	return out
+}
+
+// ToGraph6 writes each graph of the document as a line in graph6 format.
+//
+// Graph6 stores only the topology of simple undirected graphs: node IDs, attributes, edge directions,
+// self-loops and parallel edges are discarded. Use ToSparse6 for graphs with loops or parallel edges.
+// Nested graphs are flattened.
+func ToGraph6(w io.Writer, doc *graphml.Document) error {
+	bw := bufio.NewWriter(w)
+	for _, g := range g6Graphs(doc) {
+		adj := make(map[[2]int]bool)
+		for _, e := range g.edges {
+			u, v := e[0], e[1]
+			if u > v {
+				u, v = v, u
+			}
+			adj[[2]int{u, v}] = true
+		}
+		var bits []bool
+		for v := 1; v < g.n; v++ {
+			for u := 0; u < v; u++ {
+				bits = append(bits, adj[[2]int{u, v}])
+			}
+		}
+		bw.Write(g6Size(g.n))
+		bw.Write(g6Bits(bits, false))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// ToSparse6 writes each graph of the document as a line in sparse6 format.
+//
+// Sparse6 stores only the topology of undirected graphs, but unlike graph6 it supports self-loops and
+// parallel edges. Node IDs, attributes and edge directions are discarded. Nested graphs are flattened.
+func ToSparse6(w io.Writer, doc *graphml.Document) error {
+	bw := bufio.NewWriter(w)
+	for _, g := range g6Graphs(doc) {
+		k := 1
+		for 1<<uint(k) < g.n {
+			k++
+		}
+		edges := make([][2]int, len(g.edges))
+		for i, e := range g.edges {
+			u, v := e[0], e[1]
+			if u > v {
+				u, v = v, u
+			}
+			edges[i] = [2]int{v, u}
+		}
+		sort.Slice(edges, func(i, j int) bool {
+			if edges[i][0] != edges[j][0] {
+				return edges[i][0] < edges[j][0]
+			}
+			return edges[i][1] < edges[j][1]
+		})
+		var bits []bool
+		enc := func(x int) {
+			for i := k - 1; i >= 0; i-- {
+				bits = append(bits, x>>uint(i)&1 == 1)
+			}
+		}
+		cur := 0
+		for _, e := range edges {
+			v, u := e[0], e[1]
+			switch v {
+			case cur:
+				bits = append(bits, false)
+				enc(u)
+			case cur + 1:
+				cur++
+				bits = append(bits, true)
+				enc(u)
+			default:
+				cur = v
+				bits = append(bits, true)
+				enc(v)
+				bits = append(bits, false)
+				enc(u)
+			}
+		}
+		if pad := (6 - len(bits)%6) % 6; k < 6 && g.n == 1<<uint(k) && pad >= k && cur < g.n-1 {
+			// padding could be decoded as an extra edge to the last vertex
+			bits = append(bits, false)
+		}
+		bw.WriteByte(':')
+		bw.Write(g6Size(g.n))
+		bw.Write(g6Bits(bits, true))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// g6Reader reads 6-bit values from graph6 and sparse6 data.
+type g6Reader struct {
+	data []byte
+}
+
+func (r *g6Reader) next() (int, bool) {
+	if len(r.data) == 0 {
+		return 0, false
+	}
+	c := int(r.data[0]) - 63
+	r.data = r.data[1:]
+	return c, c >= 0 && c < 64
+}
+
+func (r *g6Reader) size() (int, error) {
+	c, ok := r.next()
+	if !ok {
+		return 0, fmt.Errorf("invalid size")
+	} else if c < 63 {
+		return c, nil
+	}
+	digits := 3
+	if len(r.data) != 0 && r.data[0] == 126 {
+		r.data = r.data[1:]
+		digits = 6
+	}
+	n := 0
+	for i := 0; i < digits; i++ {
+		c, ok = r.next()
+		if !ok {
+			return 0, fmt.Errorf("invalid size")
+		}
+		n = n<<6 | c
+	}
+	return n, nil
+}
+
+func parseGraph6(line string) (g6Graph, error) {
+	r := &g6Reader{data: []byte(line)}
+	n, err := r.size()
+	if err != nil {
+		return g6Graph{}, err
+	}
+	g := g6Graph{n: n}
+	var c, left int
+	for v := 1; v < n; v++ {
+		for u := 0; u < v; u++ {
+			if left == 0 {
+				var ok bool
+				if c, ok = r.next(); !ok {
+					return g6Graph{}, fmt.Errorf("unexpected end of data")
+				}
+				left = 6
+			}
+			left--
+			if c>>uint(left)&1 == 1 {
+				g.edges = append(g.edges, [2]int{u, v})
+			}
+		}
+	}
+	return g, nil
+}
+
+func parseSparse6(line string) (g6Graph, error) {
+	r := &g6Reader{data: []byte(line)}
+	n, err := r.size()
+	if err != nil {
+		return g6Graph{}, err
+	}
+	g := g6Graph{n: n}
+	k := 1
+	for 1<<uint(k) < n {
+		k++
+	}
+	var d, dl int
+	v := 0
+	for {
+		if dl < 1 {
+			var ok bool
+			if d, ok = r.next(); !ok {
+				break
+			}
+			dl = 6
+		}
+		dl--
+		b := d >> uint(dl) & 1
+		x, xl := d&(1<<uint(dl)-1), dl
+		for xl < k {
+			var ok bool
+			if d, ok = r.next(); !ok {
+				return g, nil
+			}
+			dl = 6
+			x = x<<6 | d
+			xl += 6
+		}
+		x >>= uint(xl - k)
+		dl = xl - k
+		d &= 1<<uint(dl) - 1
+		if b == 1 {
+			v++
+		}
+		if x >= n || v >= n {
+			break
+		} else if x > v {
+			v = x
+		} else {
+			g.edges = append(g.edges, [2]int{x, v})
+		}
+	}
+	return g, nil
+}
+
+// FromGraph6 reads graphs in graph6 or sparse6 format, one graph per line, and converts them to GraphML.
+//
+// Sparse6 lines are detected by a ':' prefix. Optional ">>graph6<<" and ">>sparse6<<" headers are skipped.
+// All graphs are undirected. For a single graph, nodes get "n<number>" IDs and edges get "e<number>" IDs.
+// If there are multiple graphs, IDs are prefixed with "g<number>" of the graph.
+func FromGraph6(r io.Reader) (*graphml.Document, error) {
+	var graphs []g6Graph
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<24)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		text = strings.TrimPrefix(text, ">>graph6<<")
+		text = strings.TrimPrefix(text, ">>sparse6<<")
+		if text == "" {
+			continue
+		}
+		var (
+			g   g6Graph
+			err error
+		)
+		if strings.HasPrefix(text, ":") {
+			g, err = parseSparse6(text[1:])
+		} else {
+			g, err = parseGraph6(text)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("graph6: line %d: %v", line, err)
+		}
+		graphs = append(graphs, g)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	doc := newDocument()
+	for i, gr := range graphs {
+		prefix := ""
+		if len(graphs) > 1 {
+			prefix = "g" + strconv.Itoa(i)
+		}
+		g := graphml.Graph{EdgeDefault: graphml.EdgeUndirected}
+		g.ID = prefix
+		if g.ID == "" {
+			g.ID = "G"
+		}
+		for j := 0; j < gr.n; j++ {
+			var n graphml.Node
+			n.ID = prefix + "n" + strconv.Itoa(j)
+			g.Nodes = append(g.Nodes, n)
+		}
+		for j, e := range gr.edges {
+			var ge graphml.Edge
+			ge.ID = prefix + "e" + strconv.Itoa(j)
+			ge.Source, ge.Target = g.Nodes[e[0]].ID, g.Nodes[e[1]].ID
+			g.Edges = append(g.Edges, ge)
+		}
+		doc.Graphs = append(doc.Graphs, g)
+	}
+	return doc, nil
+}
@@ -0,0 +1,177 @@
+package convert
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dennwc/graphml"
+)
+
+// GraphSONVersion is a version of TinkerPop GraphSON format.
+type GraphSONVersion int
+
+const (
+	GraphSONv1 = GraphSONVersion(1)
+	GraphSONv2 = GraphSONVersion(2)
+	GraphSONv3 = GraphSONVersion(3)
+)
+
+// Attributes used by TinkerPop GraphML writer to store vertex and edge labels.
+const (
+	graphsonVertexLabel = "labelV"
+	graphsonEdgeLabel   = "labelE"
+)
+
+// ToGraphSON writes the document in TinkerPop GraphSON adjacency list format, one vertex per line,
+// as expected by GraphSONReader and bulk loaders of Gremlin-compatible databases.
+//
+// Vertex and edge labels are taken from labelV and labelE attributes (the same ones TinkerPop uses in GraphML),
+// and default to "vertex" and "edge". All other simple attributes are written as properties.
+// Versions 2 and 3 write typed values according to key types. Nested graphs are flattened.
+func ToGraphSON(w io.Writer, doc *graphml.Document, version GraphSONVersion) error {
+	if version < GraphSONv1 || version > GraphSONv3 {
+		return fmt.Errorf("graphson: unsupported version: %d", version)
+	}
+	e := &graphsonEncoder{keys: newKeyIndex(doc), typed: version >= GraphSONv2}
+	var (
+		order []string
+		verts = make(map[string]*graphsonVertex)
+	)
+	vertex := func(id string) *graphsonVertex {
+		v := verts[id]
+		if v == nil {
+			v = &graphsonVertex{id: id, label: "vertex"}
+			verts[id] = v
+			order = append(order, id)
+		}
+		return v
+	}
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for _, n := range g.Nodes {
+				v := vertex(n.ID)
+				if label, ok := e.keys.Attr(graphml.KindNode, n.Data, graphsonVertexLabel); ok {
+					v.label = label
+				}
+				for _, a := range e.keys.Attrs(graphml.KindNode, n.Data) {
+					if a.Name == graphsonVertexLabel {
+						continue
+					}
+					e.propID++
+					v.props.Set(a.Name, []jsonObject{{
+						{Name: "id", Value: e.long(int64(e.propID))},
+						{Name: "value", Value: e.value(jsonValue(a))},
+					}})
+				}
+			}
+		})
+	}
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for _, ed := range g.Edges {
+				label := "edge"
+				if l, ok := e.keys.Attr(graphml.KindEdge, ed.Data, graphsonEdgeLabel); ok {
+					label = l
+				}
+				var props jsonObject
+				for _, a := range e.keys.Attrs(graphml.KindEdge, ed.Data) {
+					if a.Name != graphsonEdgeLabel {
+						props.Set(a.Name, e.value(jsonValue(a)))
+					}
+				}
+				id := ed.ID
+				if id == "" {
+					id = ed.Source + "->" + ed.Target
+				}
+				out := jsonObject{{Name: "id", Value: id}, {Name: "inV", Value: ed.Target}}
+				in := jsonObject{{Name: "id", Value: id}, {Name: "outV", Value: ed.Source}}
+				if len(props) != 0 {
+					out.Set("properties", props)
+					in.Set("properties", props)
+				}
+				vertex(ed.Source).addEdge(true, label, out)
+				vertex(ed.Target).addEdge(false, label, in)
+			}
+		})
+	}
+	bw := bufio.NewWriter(w)
+	for _, id := range order {
+		v := verts[id]
+		obj := jsonObject{{Name: "id", Value: id}, {Name: "label", Value: v.label}}
+		if len(v.outE) != 0 {
+			obj.Set("outE", v.outE)
+		}
+		if len(v.inE) != 0 {
+			obj.Set("inE", v.inE)
+		}
+		if len(v.props) != 0 {
+			obj.Set("properties", v.props)
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		bw.Write(data)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+type graphsonVertex struct {
+	id    string
+	label string
+	outE  jsonObject
+	inE   jsonObject
+	props jsonObject
+}
+
+func (v *graphsonVertex) addEdge(out bool, label string, e jsonObject) {
+	list := &v.inE
+	if out {
+		list = &v.outE
+	}
+	for i := range *list {
+		if (*list)[i].Name == label {
+			(*list)[i].Value = append((*list)[i].Value.([]jsonObject), e)
+			return
+		}
+	}
+	*list = append(*list, jsonField{Name: label, Value: []jsonObject{e}})
+}
+
+type graphsonEncoder struct {
+	keys   *keyIndex
+	typed  bool
+	propID int
+}
+
+// long returns an int64 value, with a GraphSON type, if necessary.
+func (e *graphsonEncoder) long(v int64) interface{} {
+	if !e.typed {
+		return v
+	}
+	return jsonObject{{Name: "@type", Value: "g:Int64"}, {Name: "@value", Value: v}}
+}
+
+// value wraps a JSON value with a GraphSON type, if necessary.
+func (e *graphsonEncoder) value(v interface{}) interface{} {
+	if !e.typed {
+		return v
+	}
+	var typ string
+	switch v := v.(type) {
+	case int64:
+		if v >= -1<<31 && v < 1<<31 {
+			typ = "g:Int32"
+		} else {
+			typ = "g:Int64"
+		}
+	case json.Number:
+		typ = "g:Double"
+	default:
+		return v
+	}
+	return jsonObject{{Name: "@type", Value: typ}, {Name: "@value", Value: v}}
+}
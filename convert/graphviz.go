@@ -0,0 +1,196 @@
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// LayoutEngine is a name of a Graphviz layout program.
+type LayoutEngine string
+
+const (
+	// LayoutBuiltin is a pure-Go force-directed layout that does not require Graphviz.
+	LayoutBuiltin = LayoutEngine("")
+	LayoutDot     = LayoutEngine("dot")
+	LayoutNeato   = LayoutEngine("neato")
+	LayoutFDP     = LayoutEngine("fdp")
+	LayoutSFDP    = LayoutEngine("sfdp")
+	LayoutCirco   = LayoutEngine("circo")
+	LayoutTwopi   = LayoutEngine("twopi")
+)
+
+// layoutIterations is a number of iterations of the built-in layout.
+const layoutIterations = 300
+
+// Layout computes node positions and stores them in x and y node attributes, declaring the keys if necessary.
+//
+// The graph is laid out by a given Graphviz program, which must be available in PATH. If it's not available
+// (or LayoutBuiltin is requested), a simple built-in force-directed layout is used instead. Coordinates are
+// in points, with the Y axis pointing down, as expected by RenderSVG and yEd. Nested graphs are flattened.
+func Layout(doc *graphml.Document, engine LayoutEngine) error {
+	var (
+		nodes []*graphml.Node
+		index = make(map[string]int)
+		edges [][2]int
+	)
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for j := range g.Nodes {
+				n := &g.Nodes[j]
+				if _, ok := index[n.ID]; !ok {
+					index[n.ID] = len(nodes)
+					nodes = append(nodes, n)
+				}
+			}
+		})
+	}
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for j := range g.Edges {
+				e := &g.Edges[j]
+				src, ok1 := index[e.Source]
+				dst, ok2 := index[e.Target]
+				if ok1 && ok2 {
+					edges = append(edges, [2]int{src, dst})
+				}
+			}
+		})
+	}
+	var pos [][2]float64
+	if engine != LayoutBuiltin {
+		if path, err := exec.LookPath(string(engine)); err == nil {
+			pos, err = graphvizLayout(path, len(nodes), edges)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if pos == nil {
+		pos = forceLayout(len(nodes), edges, layoutIterations)
+		scale := 50 * math.Sqrt(float64(len(nodes)))
+		for i := range pos {
+			pos[i][0] *= scale
+			pos[i][1] *= scale
+		}
+	}
+	xKey, yKey := layoutKey(doc, "x"), layoutKey(doc, "y")
+	for i, n := range nodes {
+		n.Data = setData(n.Data, xKey, strconv.FormatFloat(pos[i][0], 'f', 2, 64))
+		n.Data = setData(n.Data, yKey, strconv.FormatFloat(pos[i][1], 'f', 2, 64))
+	}
+	return nil
+}
+
+// layoutKey finds a node key for an attribute with a given name, or declares a new one.
+func layoutKey(doc *graphml.Document, name string) string {
+	ids := newIDSet()
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if (k.For == graphml.KindNode || k.For == graphml.KindAll) && keyName(k) == name {
+			return k.ID
+		}
+		ids.Reserve(k.ID)
+	}
+	id := name
+	if !ids.Reserve(id) {
+		id = ids.Unique("d")
+	}
+	doc.Keys = append(doc.Keys, graphml.NewKey(graphml.KindNode, id, name, "double"))
+	return id
+}
+
+// setData sets a text value for a given key, replacing an existing data element, if any.
+func setData(data []graphml.Data, key, value string) []graphml.Data {
+	for i := range data {
+		if data[i].Key == key {
+			data[i] = textData(key, value)
+			return data
+		}
+	}
+	return append(data, textData(key, value))
+}
+
+// graphvizLayout runs a Graphviz program and returns node positions.
+func graphvizLayout(path string, n int, edges [][2]int) ([][2]float64, error) {
+	var in bytes.Buffer
+	in.WriteString("digraph {\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&in, "\tn%d;\n", i)
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&in, "\tn%d -> n%d;\n", e[0], e[1])
+	}
+	in.WriteString("}\n")
+	var out, errOut bytes.Buffer
+	cmd := exec.Command(path, "-Tplain")
+	cmd.Stdin = &in
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("graphviz: %v: %s", err, strings.TrimSpace(errOut.String()))
+	}
+	return parsePlain(&out, n)
+}
+
+// parsePlain reads node positions from the Graphviz plain output format.
+// Nodes must be named "n<index>". Positions are converted to points, with the Y axis pointing down.
+func parsePlain(r io.Reader, n int) ([][2]float64, error) {
+	pos := make([][2]float64, n)
+	seen := make([]bool, n)
+	height := 0.0
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	line := 0
+	for sc.Scan() {
+		line++
+		fields := splitFields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "graph":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("graphviz: line %d: invalid graph line", line)
+			}
+			var err error
+			height, err = strconv.ParseFloat(fields[3], 64)
+			if err != nil {
+				return nil, fmt.Errorf("graphviz: line %d: invalid height: %q", line, fields[3])
+			}
+		case "node":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("graphviz: line %d: invalid node line", line)
+			}
+			i, err := strconv.Atoi(strings.TrimPrefix(fields[1], "n"))
+			if err != nil || i < 0 || i >= n {
+				return nil, fmt.Errorf("graphviz: line %d: unexpected node: %q", line, fields[1])
+			}
+			x, err1 := strconv.ParseFloat(fields[2], 64)
+			y, err2 := strconv.ParseFloat(fields[3], 64)
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("graphviz: line %d: invalid node position", line)
+			}
+			pos[i] = [2]float64{x * 72, (height - y) * 72}
+			seen[i] = true
+		case "stop":
+			for i, ok := range seen {
+				if !ok {
+					return nil, fmt.Errorf("graphviz: no position for node %d", i)
+				}
+			}
+			return pos, nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("graphviz: unexpected end of output")
+}
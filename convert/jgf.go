@@ -0,0 +1,247 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/dennwc/graphml"
+)
+
+// ToJGF writes the document in JSON Graph Format (version 2).
+//
+// Simple attributes are written as metadata fields with JSON types matching the key types, except for the label
+// attribute, which is written as a label field. Nested graphs are flattened. Documents with a single graph
+// are written as a "graph" object, while documents with multiple graphs use a "graphs" array.
+func ToJGF(w io.Writer, doc *graphml.Document) error {
+	keys := newKeyIndex(doc)
+	var graphs []jsonObject
+	for i := range doc.Graphs {
+		graphs = append(graphs, jgfGraph(keys, &doc.Graphs[i]))
+	}
+	var out jsonObject
+	if len(graphs) == 1 {
+		out.Set("graph", graphs[0])
+	} else {
+		out.Set("graphs", graphs)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func jgfGraph(keys *keyIndex, g *graphml.Graph) jsonObject {
+	var out jsonObject
+	if g.ID != "" {
+		out.Set("id", g.ID)
+	}
+	out.Set("directed", g.IsDirected())
+	if label, ok := keys.Attr(graphml.KindGraph, g.Data, "label"); ok {
+		out.Set("label", label)
+	}
+	if meta := jsonAttrs(keys, graphml.KindGraph, g.Data, "label"); len(meta) != 0 {
+		out.Set("metadata", meta)
+	}
+	nodes := jsonObject{}
+	edges := []jsonObject{}
+	walkGraph(g, func(sub *graphml.Graph) {
+		for _, n := range sub.Nodes {
+			var obj jsonObject
+			if label, ok := keys.Attr(graphml.KindNode, n.Data, "label"); ok {
+				obj.Set("label", label)
+			}
+			if meta := jsonAttrs(keys, graphml.KindNode, n.Data, "label"); len(meta) != 0 {
+				obj.Set("metadata", meta)
+			}
+			if obj == nil {
+				obj = jsonObject{}
+			}
+			nodes.Set(n.ID, obj)
+		}
+		for i := range sub.Edges {
+			e := &sub.Edges[i]
+			var obj jsonObject
+			if e.ID != "" {
+				obj.Set("id", e.ID)
+			}
+			obj.Set("source", e.Source)
+			obj.Set("target", e.Target)
+			if dir := edgeDirected(sub, e); dir != (g.IsDirected()) {
+				obj.Set("directed", dir)
+			}
+			if label, ok := keys.Attr(graphml.KindEdge, e.Data, "label"); ok {
+				obj.Set("label", label)
+			}
+			if meta := jsonAttrs(keys, graphml.KindEdge, e.Data, "label"); len(meta) != 0 {
+				obj.Set("metadata", meta)
+			}
+			edges = append(edges, obj)
+		}
+	})
+	out.Set("nodes", nodes)
+	out.Set("edges", edges)
+	return out
+}
+
+type jgfDocument struct {
+	Graph  json.RawMessage   `json:"graph"`
+	Graphs []json.RawMessage `json:"graphs"`
+}
+
+type jgfGraphJSON struct {
+	ID       string          `json:"id"`
+	Label    *string         `json:"label"`
+	Directed *bool           `json:"directed"`
+	Metadata json.RawMessage `json:"metadata"`
+	Nodes    json.RawMessage `json:"nodes"`
+	Edges    []jgfEdgeJSON   `json:"edges"`
+}
+
+type jgfNodeJSON struct {
+	ID       string          `json:"id"` // JGF version 1
+	Label    *string         `json:"label"`
+	Metadata json.RawMessage `json:"metadata"`
+}
+
+type jgfEdgeJSON struct {
+	ID       string          `json:"id"`
+	Source   string          `json:"source"`
+	Target   string          `json:"target"`
+	Relation *string         `json:"relation"`
+	Directed *bool           `json:"directed"`
+	Label    *string         `json:"label"`
+	Metadata json.RawMessage `json:"metadata"`
+}
+
+// FromJGF reads a document in JSON Graph Format and converts it to GraphML.
+//
+// Both version 1 (nodes as an array) and version 2 (nodes as an object) are supported. Labels and metadata fields
+// are converted to GraphML attributes, with keys declared automatically. Nested metadata objects and arrays are
+// stored as JSON strings.
+func FromJGF(r io.Reader) (*graphml.Document, error) {
+	var jd jgfDocument
+	if err := json.NewDecoder(r).Decode(&jd); err != nil {
+		return nil, err
+	}
+	raws := jd.Graphs
+	if len(jd.Graph) != 0 {
+		raws = append([]json.RawMessage{jd.Graph}, raws...)
+	}
+	d := &jgfDecoder{keys: newKeyBuilder(), ids: newIDSet()}
+	doc := newDocument()
+	for _, raw := range raws {
+		var jg jgfGraphJSON
+		if err := json.Unmarshal(raw, &jg); err != nil {
+			return nil, err
+		}
+		g, err := d.graph(&jg)
+		if err != nil {
+			return nil, err
+		}
+		doc.Graphs = append(doc.Graphs, *g)
+	}
+	doc.Keys = d.keys.Keys()
+	return doc, nil
+}
+
+type jgfDecoder struct {
+	keys *keyBuilder
+	ids  idSet
+}
+
+func (d *jgfDecoder) data(kind graphml.Kind, label *string, meta json.RawMessage) ([]graphml.Data, error) {
+	var out []graphml.Data
+	if label != nil {
+		d.keys.Declare(kind, "label", "string")
+		out = append(out, d.keys.Data(kind, "label", *label))
+	}
+	if len(meta) == 0 || bytes.Equal(meta, []byte("null")) {
+		return out, nil
+	}
+	fields, err := jsonFields(meta)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		out = append(out, jsonData(d.keys, kind, f.Name, f.Value))
+	}
+	return out, nil
+}
+
+func (d *jgfDecoder) graph(jg *jgfGraphJSON) (*graphml.Graph, error) {
+	var (
+		g   graphml.Graph
+		err error
+	)
+	if jg.ID != "" && d.ids.Reserve(jg.ID) {
+		g.ID = jg.ID
+	}
+	g.EdgeDefault = graphml.EdgeDirected
+	if jg.Directed != nil && !*jg.Directed {
+		g.EdgeDefault = graphml.EdgeUndirected
+	}
+	if g.Data, err = d.data(graphml.KindGraph, jg.Label, jg.Metadata); err != nil {
+		return nil, err
+	}
+	var nodes []jgfNodeJSON
+	switch n := bytes.TrimSpace(jg.Nodes); {
+	case len(n) == 0 || bytes.Equal(n, []byte("null")):
+	case n[0] == '[':
+		if err := json.Unmarshal(n, &nodes); err != nil {
+			return nil, err
+		}
+	default:
+		fields, err := jsonFields(n)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			var jn jgfNodeJSON
+			if err := json.Unmarshal(f.Value, &jn); err != nil {
+				return nil, err
+			}
+			jn.ID = f.Name
+			nodes = append(nodes, jn)
+		}
+	}
+	for _, jn := range nodes {
+		if jn.ID == "" {
+			return nil, errors.New("jgf: node without an id")
+		}
+		if !d.ids.Reserve(jn.ID) {
+			return nil, errors.New("jgf: duplicate id: " + jn.ID)
+		}
+		var n graphml.Node
+		n.ID = jn.ID
+		if n.Data, err = d.data(graphml.KindNode, jn.Label, jn.Metadata); err != nil {
+			return nil, err
+		}
+		g.Nodes = append(g.Nodes, n)
+	}
+	for _, je := range jg.Edges {
+		if je.ID != "" && !d.ids.Reserve(je.ID) {
+			return nil, errors.New("jgf: duplicate id: " + je.ID)
+		}
+	}
+	for _, je := range jg.Edges {
+		var e graphml.Edge
+		e.ID = je.ID
+		if e.ID == "" {
+			e.ID = d.ids.Unique("e")
+		}
+		e.Source, e.Target = je.Source, je.Target
+		if je.Directed != nil && *je.Directed != (g.IsDirected()) {
+			e.Unrecognized = append(e.Unrecognized, directedAttr(*je.Directed))
+		}
+		if e.Data, err = d.data(graphml.KindEdge, je.Label, je.Metadata); err != nil {
+			return nil, err
+		}
+		if je.Relation != nil {
+			d.keys.Declare(graphml.KindEdge, "relation", "string")
+			e.Data = append(e.Data, d.keys.Data(graphml.KindEdge, "relation", *je.Relation))
+		}
+		g.Edges = append(g.Edges, e)
+	}
+	return &g, nil
+}
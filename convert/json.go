@@ -0,0 +1,146 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+// jsonField is a single field of a JSON object.
+type jsonField struct {
+	Name  string
+	Value interface{}
+}
+
+// jsonObject is a JSON object that preserves the order of fields.
+type jsonObject []jsonField
+
+// Set adds or replaces a field.
+func (o *jsonObject) Set(name string, v interface{}) {
+	for i := range *o {
+		if (*o)[i].Name == name {
+			(*o)[i].Value = v
+			return
+		}
+	}
+	*o = append(*o, jsonField{Name: name, Value: v})
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		val, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonValue converts an attribute to a JSON value according to its declared type.
+func jsonValue(a attr) interface{} {
+	typ := ""
+	if a.Key != nil {
+		typ = a.Key.Type
+	}
+	switch typ {
+	case "int", "long":
+		if v, err := strconv.ParseInt(a.Value, 10, 64); err == nil {
+			return v
+		}
+	case "float", "double":
+		if v, err := strconv.ParseFloat(a.Value, 64); err == nil {
+			return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(a.Value); err == nil {
+			return v
+		}
+	}
+	return a.Value
+}
+
+// jsonAttrs converts all simple attributes to JSON fields, skipping the ones listed.
+func jsonAttrs(keys *keyIndex, kind graphml.Kind, data []graphml.Data, skip ...string) jsonObject {
+	var out jsonObject
+loop:
+	for _, a := range keys.Attrs(kind, data) {
+		for _, s := range skip {
+			if a.Name == s {
+				continue loop
+			}
+		}
+		out.Set(a.Name, jsonValue(a))
+	}
+	return out
+}
+
+// jsonFields decodes a JSON object, preserving the order of fields.
+func jsonFields(data json.RawMessage) ([]jsonRawField, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '{' {
+		return nil, fmt.Errorf("expected JSON object, got %v", t)
+	}
+	var out []jsonRawField
+	for dec.More() {
+		t, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := t.(string)
+		var v json.RawMessage
+		if err = dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		out = append(out, jsonRawField{Name: name, Value: v})
+	}
+	return out, nil
+}
+
+type jsonRawField struct {
+	Name  string
+	Value json.RawMessage
+}
+
+// jsonData converts a JSON value to a data element, declaring the key if necessary.
+// Objects and arrays are stored as JSON strings.
+func jsonData(keys *keyBuilder, kind graphml.Kind, name string, v json.RawMessage) graphml.Data {
+	v = bytes.TrimSpace(v)
+	switch {
+	case len(v) == 0 || bytes.Equal(v, []byte("null")):
+		keys.Declare(kind, name, "string")
+		return keys.Data(kind, name, "")
+	case v[0] == '"':
+		var s string
+		json.Unmarshal(v, &s)
+		keys.Declare(kind, name, "string")
+		return keys.Data(kind, name, s)
+	case bytes.Equal(v, []byte("true")) || bytes.Equal(v, []byte("false")):
+		keys.Declare(kind, name, "boolean")
+		return keys.Data(kind, name, string(v))
+	case v[0] == '{' || v[0] == '[':
+		keys.Declare(kind, name, "string")
+		return keys.Data(kind, name, string(v))
+	}
+	// number; type is inferred
+	return keys.Data(kind, name, string(v))
+}
@@ -0,0 +1,59 @@
+package convert
+
+import "math"
+
+// forceLayout computes node positions with the Fruchterman-Reingold algorithm.
+// Nodes start on a circle, thus the result is deterministic. Positions are in the [-1, 1] range.
+func forceLayout(n int, edges [][2]int, iterations int) [][2]float64 {
+	pos := make([][2]float64, n)
+	for i := range pos {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		pos[i] = [2]float64{math.Cos(a), math.Sin(a)}
+	}
+	if n < 2 {
+		return pos
+	}
+	k := 2 / math.Sqrt(float64(n))
+	disp := make([][2]float64, n)
+	temp := 0.2
+	for it := 0; it < iterations; it++ {
+		for i := range disp {
+			disp[i] = [2]float64{}
+		}
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				dx, dy := pos[i][0]-pos[j][0], pos[i][1]-pos[j][1]
+				d := math.Max(math.Hypot(dx, dy), 1e-6)
+				f := k * k / d
+				disp[i][0] += dx / d * f
+				disp[i][1] += dy / d * f
+				disp[j][0] -= dx / d * f
+				disp[j][1] -= dy / d * f
+			}
+		}
+		for _, e := range edges {
+			i, j := e[0], e[1]
+			if i == j {
+				continue
+			}
+			dx, dy := pos[i][0]-pos[j][0], pos[i][1]-pos[j][1]
+			d := math.Max(math.Hypot(dx, dy), 1e-6)
+			f := d * d / k
+			disp[i][0] -= dx / d * f
+			disp[i][1] -= dy / d * f
+			disp[j][0] += dx / d * f
+			disp[j][1] += dy / d * f
+		}
+		for i := range pos {
+			d := math.Hypot(disp[i][0], disp[i][1])
+			if d == 0 {
+				continue
+			}
+			step := math.Min(d, temp)
+			pos[i][0] = math.Max(-1, math.Min(1, pos[i][0]+disp[i][0]/d*step))
+			pos[i][1] = math.Max(-1, math.Min(1, pos[i][1]+disp[i][1]/d*step))
+		}
+		temp *= 1 - 1/float64(iterations)
+	}
+	return pos
+}
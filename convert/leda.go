@@ -0,0 +1,255 @@
+package convert
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// LEDAOptions selects attributes stored in parameters of LEDA nodes and edges.
+type LEDAOptions struct {
+	// NodeAttr is a node attribute name. Defaults to "label".
+	NodeAttr string
+	// EdgeAttr is an edge attribute name. Defaults to "weight".
+	EdgeAttr string
+}
+
+func (opt *LEDAOptions) names() (node, edge string) {
+	node, edge = "label", "weight"
+	if opt != nil && opt.NodeAttr != "" {
+		node = opt.NodeAttr
+	}
+	if opt != nil && opt.EdgeAttr != "" {
+		edge = opt.EdgeAttr
+	}
+	return node, edge
+}
+
+// ledaTypes maps GraphML attribute types to LEDA parameter types.
+var ledaTypes = map[string]string{
+	"int":    "int",
+	"long":   "int",
+	"float":  "float",
+	"double": "double",
+	"string": "string",
+}
+
+// ToLEDA writes the document in LEDA graph format (.gw).
+//
+// One node and one edge attribute can be stored in LEDA parameters, see LEDAOptions.
+// Nested graphs are flattened. LEDA supports only one graph per file, thus an error is returned
+// for documents with multiple graphs.
+func ToLEDA(w io.Writer, doc *graphml.Document, opt *LEDAOptions) error {
+	if len(doc.Graphs) > 1 {
+		return errors.New("leda: only one graph per document is supported")
+	}
+	nodeAttr, edgeAttr := opt.names()
+	keys := newKeyIndex(doc)
+	paramType := func(kind graphml.Kind, name string) string {
+		for i := range doc.Keys {
+			k := &doc.Keys[i]
+			if (k.For == kind || k.For == graphml.KindAll) && keyName(k) == name {
+				if t, ok := ledaTypes[k.Type]; ok {
+					return t
+				}
+				return "string"
+			}
+		}
+		return "void"
+	}
+	ntype, etype := paramType(graphml.KindNode, nodeAttr), paramType(graphml.KindEdge, edgeAttr)
+	param := func(kind graphml.Kind, typ, name string, data []graphml.Data) string {
+		if typ == "void" {
+			return "|{}|"
+		}
+		v, _ := keys.Attr(kind, data, name)
+		return "|{" + v + "}|"
+	}
+	var g graphml.Graph
+	if len(doc.Graphs) != 0 {
+		g = doc.Graphs[0]
+	}
+	var (
+		nodes []*graphml.Node
+		index = make(map[string]int)
+		edges []string
+	)
+	walkGraph(&g, func(g *graphml.Graph) {
+		for i := range g.Nodes {
+			n := &g.Nodes[i]
+			if _, ok := index[n.ID]; !ok {
+				nodes = append(nodes, n)
+				index[n.ID] = len(nodes)
+			}
+		}
+	})
+	walkGraph(&g, func(g *graphml.Graph) {
+		for _, e := range g.Edges {
+			src, ok1 := index[e.Source]
+			dst, ok2 := index[e.Target]
+			if ok1 && ok2 {
+				edges = append(edges, fmt.Sprintf("%d %d 0 %s", src, dst, param(graphml.KindEdge, etype, edgeAttr, e.Data)))
+			}
+		}
+	})
+	bw := bufio.NewWriter(w)
+	bw.WriteString("LEDA.GRAPH\n")
+	bw.WriteString(ntype + "\n")
+	bw.WriteString(etype + "\n")
+	if g.IsDirected() {
+		bw.WriteString("-1\n")
+	} else {
+		bw.WriteString("-2\n")
+	}
+	fmt.Fprintf(bw, "%d\n", len(nodes))
+	for _, n := range nodes {
+		bw.WriteString(param(graphml.KindNode, ntype, nodeAttr, n.Data) + "\n")
+	}
+	fmt.Fprintf(bw, "%d\n", len(edges))
+	for _, e := range edges {
+		bw.WriteString(e + "\n")
+	}
+	return bw.Flush()
+}
+
+// FromLEDA reads a graph in LEDA format (.gw) and converts it to GraphML.
+//
+// Nodes get "n<number>" IDs. Non-void node and edge parameters are stored in attributes selected by LEDAOptions.
+func FromLEDA(r io.Reader, opt *LEDAOptions) (*graphml.Document, error) {
+	nodeAttr, edgeAttr := opt.names()
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	line := 0
+	next := func() (string, error) {
+		for sc.Scan() {
+			line++
+			s := strings.TrimSpace(sc.Text())
+			if s == "" || strings.HasPrefix(s, "#") {
+				continue
+			}
+			return s, nil
+		}
+		if err := sc.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("leda: unexpected EOF")
+	}
+	errorf := func(format string, args ...interface{}) error {
+		return fmt.Errorf("leda: line %d: %s", line, fmt.Sprintf(format, args...))
+	}
+	s, err := next()
+	if err != nil {
+		return nil, err
+	} else if s != "LEDA.GRAPH" {
+		return nil, errorf("expected LEDA.GRAPH header")
+	}
+	ntype, err := next()
+	if err != nil {
+		return nil, err
+	}
+	etype, err := next()
+	if err != nil {
+		return nil, err
+	}
+	g := graphml.Graph{EdgeDefault: graphml.EdgeDirected}
+	s, err = next()
+	if err != nil {
+		return nil, err
+	}
+	switch s {
+	case "-1":
+		s, err = next()
+	case "-2":
+		g.EdgeDefault = graphml.EdgeUndirected
+		s, err = next()
+	}
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return nil, errorf("invalid number of nodes: %q", s)
+	}
+	keys := newKeyBuilder()
+	paramKey := func(kind graphml.Kind, typ, name string) bool {
+		switch typ {
+		case "void":
+			return false
+		case "int", "float", "double", "string":
+		default:
+			typ = "string"
+		}
+		keys.Declare(kind, name, typ)
+		return true
+	}
+	hasNode, hasEdge := paramKey(graphml.KindNode, ntype, nodeAttr), paramKey(graphml.KindEdge, etype, edgeAttr)
+	for i := 1; i <= n; i++ {
+		s, err = next()
+		if err != nil {
+			return nil, err
+		}
+		var node graphml.Node
+		node.ID = "n" + strconv.Itoa(i)
+		if v, ok := ledaParam(s); !ok {
+			return nil, errorf("invalid node parameter: %q", s)
+		} else if hasNode {
+			node.Data = append(node.Data, keys.Data(graphml.KindNode, nodeAttr, v))
+		}
+		g.Nodes = append(g.Nodes, node)
+	}
+	s, err = next()
+	if err != nil {
+		return nil, err
+	}
+	m, err := strconv.Atoi(s)
+	if err != nil || m < 0 {
+		return nil, errorf("invalid number of edges: %q", s)
+	}
+	for i := 0; i < m; i++ {
+		s, err = next()
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(s)
+		if len(fields) < 4 {
+			return nil, errorf("invalid edge: %q", s)
+		}
+		var ids [2]int
+		for j := range ids {
+			ids[j], err = strconv.Atoi(fields[j])
+			if err != nil || ids[j] < 1 || ids[j] > n {
+				return nil, errorf("invalid node index: %q", fields[j])
+			}
+		}
+		var e graphml.Edge
+		e.ID = "e" + strconv.Itoa(i)
+		e.Source, e.Target = "n"+fields[0], "n"+fields[1]
+		rest := s
+		if j := strings.Index(s, "|{"); j >= 0 {
+			rest = s[j:]
+		}
+		if v, ok := ledaParam(rest); !ok {
+			return nil, errorf("invalid edge parameter: %q", rest)
+		} else if hasEdge {
+			e.Data = append(e.Data, keys.Data(graphml.KindEdge, edgeAttr, v))
+		}
+		g.Edges = append(g.Edges, e)
+	}
+	doc := newDocument()
+	doc.Graphs = []graphml.Graph{g}
+	doc.Keys = keys.Keys()
+	return doc, nil
+}
+
+// ledaParam extracts a value from a |{...}| parameter.
+func ledaParam(s string) (string, bool) {
+	if !strings.HasPrefix(s, "|{") || !strings.HasSuffix(s, "}|") || len(s) < 4 {
+		return "", false
+	}
+	return s[2 : len(s)-2], true
+}
@@ -0,0 +1,130 @@
+package convert
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+// MatrixEntry is a non-zero value of a sparse matrix.
+type MatrixEntry struct {
+	Row, Col int
+	Value    float64
+}
+
+// AdjacencyMatrix is an adjacency matrix of a graph in a sparse form.
+type AdjacencyMatrix struct {
+	// Nodes is an order of nodes: i-th row and column of the matrix correspond to Nodes[i].
+	Nodes []string
+	// Entries are non-zero values of the matrix, sorted by row and column.
+	Entries []MatrixEntry
+}
+
+// Size returns the number of rows (and columns) of the matrix.
+func (m *AdjacencyMatrix) Size() int {
+	return len(m.Nodes)
+}
+
+// Dense returns the matrix in a dense form.
+func (m *AdjacencyMatrix) Dense() [][]float64 {
+	n := len(m.Nodes)
+	buf := make([]float64, n*n)
+	out := make([][]float64, n)
+	for i := range out {
+		out[i] = buf[i*n : (i+1)*n]
+	}
+	for _, e := range m.Entries {
+		out[e.Row][e.Col] = e.Value
+	}
+	return out
+}
+
+// WriteCSV writes the matrix in a dense form as CSV. The first row and column contain node IDs.
+func (m *AdjacencyMatrix) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{""}, m.Nodes...))
+	for i, row := range m.Dense() {
+		rec := make([]string, 0, len(row)+1)
+		rec = append(rec, m.Nodes[i])
+		for _, v := range row {
+			rec = append(rec, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		cw.Write(rec)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ToAdjacencyMatrix builds an adjacency matrix of the graph, including all nested graphs.
+//
+// Edge weights are taken from the data with a given key ID, or are set to 1 if the key is empty or edge has no data
+// for it. Weights of parallel edges are summed. Undirected edges are added to the matrix in both directions.
+func ToAdjacencyMatrix(g *graphml.Graph, weightKey string) (*AdjacencyMatrix, error) {
+	m := &AdjacencyMatrix{}
+	index := make(map[string]int)
+	walkGraph(g, func(g *graphml.Graph) {
+		for _, n := range g.Nodes {
+			if _, ok := index[n.ID]; !ok {
+				index[n.ID] = len(m.Nodes)
+				m.Nodes = append(m.Nodes, n.ID)
+			}
+		}
+	})
+	type cell struct{ row, col int }
+	values := make(map[cell]float64)
+	var err error
+	walkGraph(g, func(g *graphml.Graph) {
+		for i := range g.Edges {
+			e := &g.Edges[i]
+			src, ok1 := index[e.Source]
+			dst, ok2 := index[e.Target]
+			if !ok1 || !ok2 {
+				if err == nil {
+					err = fmt.Errorf("edge %s -> %s references unknown node", e.Source, e.Target)
+				}
+				return
+			}
+			w := 1.0
+			if weightKey != "" {
+				for _, d := range e.Data {
+					if d.Key != weightKey {
+						continue
+					}
+					s, ok := dataText(d)
+					if ok {
+						w, err = strconv.ParseFloat(s, 64)
+					}
+					if !ok || err != nil {
+						err = fmt.Errorf("edge %s -> %s: invalid weight: %q", e.Source, e.Target, s)
+						return
+					}
+					break
+				}
+			}
+			values[cell{src, dst}] += w
+			if src != dst && !edgeDirected(g, e) {
+				values[cell{dst, src}] += w
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for c, v := range values {
+		if v != 0 {
+			m.Entries = append(m.Entries, MatrixEntry{Row: c.row, Col: c.col, Value: v})
+		}
+	}
+	sort.Slice(m.Entries, func(i, j int) bool {
+		a, b := m.Entries[i], m.Entries[j]
+		if a.Row != b.Row {
+			return a.Row < b.Row
+		}
+		return a.Col < b.Col
+	})
+	return m, nil
+}
@@ -0,0 +1,234 @@
+package convert
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// ToMatrixMarket writes the graph as a sparse matrix in MatrixMarket coordinate format.
+//
+// Each edge is written as a matrix entry, with rows and columns numbered in the order of nodes.
+// Values are taken from the weight attribute, and the matrix is written as a pattern if no edge has a weight.
+// Undirected graphs are written as symmetric matrices. Nested graphs are flattened.
+//
+// MatrixMarket supports only one matrix per file, thus an error is returned for documents with multiple graphs.
+func ToMatrixMarket(w io.Writer, doc *graphml.Document) error {
+	if len(doc.Graphs) > 1 {
+		return errors.New("mtx: only one graph per document is supported")
+	}
+	keys := newKeyIndex(doc)
+	type entry struct {
+		row, col int
+		val      string
+	}
+	var (
+		index   = make(map[string]int)
+		entries []entry
+		field   = "pattern"
+		sym     = len(doc.Graphs) != 0 && !doc.Graphs[0].IsDirected()
+	)
+	if len(doc.Graphs) != 0 {
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for _, n := range g.Nodes {
+				if _, ok := index[n.ID]; !ok {
+					index[n.ID] = len(index) + 1
+				}
+			}
+		})
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for i := range g.Edges {
+				e := &g.Edges[i]
+				src, ok1 := index[e.Source]
+				dst, ok2 := index[e.Target]
+				if !ok1 || !ok2 {
+					continue
+				}
+				if edgeDirected(g, e) {
+					sym = false
+				}
+				val := ""
+				for _, a := range keys.Attrs(graphml.KindEdge, e.Data) {
+					if a.Name != "weight" {
+						continue
+					}
+					if _, err := strconv.ParseFloat(a.Value, 64); err == nil {
+						val = a.Value
+						if a.Key != nil && (a.Key.Type == "int" || a.Key.Type == "long") {
+							if field == "pattern" {
+								field = "integer"
+							}
+						} else {
+							field = "real"
+						}
+					}
+					break
+				}
+				entries = append(entries, entry{row: src, col: dst, val: val})
+				if !edgeDirected(g, e) && src != dst {
+					entries = append(entries, entry{row: dst, col: src, val: val})
+				}
+			}
+		})
+	}
+	symmetry := "general"
+	if sym {
+		symmetry = "symmetric"
+		// keep only the lower triangle
+		out := entries[:0]
+		for _, e := range entries {
+			if e.row >= e.col {
+				out = append(out, e)
+			}
+		}
+		entries = out
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%%%%MatrixMarket matrix coordinate %s %s\n", field, symmetry)
+	fmt.Fprintf(bw, "%d %d %d\n", len(index), len(index), len(entries))
+	for _, e := range entries {
+		switch {
+		case field == "pattern":
+			fmt.Fprintf(bw, "%d %d\n", e.row, e.col)
+		case e.val == "":
+			fmt.Fprintf(bw, "%d %d 1\n", e.row, e.col)
+		default:
+			fmt.Fprintf(bw, "%d %d %s\n", e.row, e.col, e.val)
+		}
+	}
+	return bw.Flush()
+}
+
+// FromMatrixMarket reads a sparse matrix in MatrixMarket coordinate format and converts it to a graph.
+//
+// Each entry becomes an edge, and rows and columns are mapped to nodes with "n<number>" IDs. Values of real and
+// integer matrices are stored in the weight attribute. Symmetric matrices are converted to undirected graphs,
+// and entries of skew-symmetric matrices are mirrored with a negated value. Complex matrices are not supported.
+func FromMatrixMarket(r io.Reader) (*graphml.Document, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	line := 0
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("mtx: empty input")
+	}
+	line++
+	header := strings.Fields(strings.ToLower(sc.Text()))
+	if len(header) != 5 || header[0] != "%%matrixmarket" || header[1] != "matrix" {
+		return nil, errors.New("mtx: expected %%MatrixMarket matrix header")
+	}
+	if header[2] != "coordinate" {
+		return nil, fmt.Errorf("mtx: unsupported format: %q", header[2])
+	}
+	field, symmetry := header[3], header[4]
+	switch field {
+	case "real", "double", "integer", "pattern":
+	default:
+		return nil, fmt.Errorf("mtx: unsupported field: %q", field)
+	}
+	switch symmetry {
+	case "general", "symmetric", "skew-symmetric":
+	default:
+		return nil, fmt.Errorf("mtx: unsupported symmetry: %q", symmetry)
+	}
+	keys := newKeyBuilder()
+	switch field {
+	case "real", "double":
+		keys.Declare(graphml.KindEdge, "weight", "double")
+	case "integer":
+		keys.Declare(graphml.KindEdge, "weight", "long")
+	}
+	g := graphml.Graph{EdgeDefault: graphml.EdgeDirected}
+	if symmetry == "symmetric" {
+		g.EdgeDefault = graphml.EdgeUndirected
+	}
+	size := -1
+	addEdge := func(src, dst int, val string) {
+		var e graphml.Edge
+		e.ID = "e" + strconv.Itoa(len(g.Edges))
+		e.Source, e.Target = "n"+strconv.Itoa(src), "n"+strconv.Itoa(dst)
+		if field != "pattern" {
+			e.Data = append(e.Data, keys.Data(graphml.KindEdge, "weight", val))
+		}
+		g.Edges = append(g.Edges, e)
+	}
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "%") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if size < 0 {
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("mtx: line %d: expected matrix size", line)
+			}
+			var dims [3]int
+			for i, f := range fields {
+				v, err := strconv.Atoi(f)
+				if err != nil || v < 0 {
+					return nil, fmt.Errorf("mtx: line %d: invalid matrix size: %q", line, f)
+				}
+				dims[i] = v
+			}
+			size = dims[0]
+			if dims[1] > size {
+				size = dims[1]
+			}
+			for i := 1; i <= size; i++ {
+				var n graphml.Node
+				n.ID = "n" + strconv.Itoa(i)
+				g.Nodes = append(g.Nodes, n)
+			}
+			continue
+		}
+		want := 3
+		if field == "pattern" {
+			want = 2
+		}
+		if len(fields) != want {
+			return nil, fmt.Errorf("mtx: line %d: expected %d fields", line, want)
+		}
+		var idx [2]int
+		for i := range idx {
+			v, err := strconv.Atoi(fields[i])
+			if err != nil || v < 1 || v > size {
+				return nil, fmt.Errorf("mtx: line %d: invalid index: %q", line, fields[i])
+			}
+			idx[i] = v
+		}
+		val := ""
+		if field != "pattern" {
+			val = fields[2]
+			if _, err := strconv.ParseFloat(val, 64); err != nil {
+				return nil, fmt.Errorf("mtx: line %d: invalid value: %q", line, val)
+			}
+		}
+		addEdge(idx[0], idx[1], val)
+		if symmetry == "skew-symmetric" && idx[0] != idx[1] {
+			if strings.HasPrefix(val, "-") {
+				val = val[1:]
+			} else {
+				val = "-" + strings.TrimPrefix(val, "+")
+			}
+			addEdge(idx[1], idx[0], val)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		return nil, errors.New("mtx: matrix size is not set")
+	}
+	doc := newDocument()
+	doc.Graphs = []graphml.Graph{g}
+	doc.Keys = keys.Keys()
+	return doc, nil
+}
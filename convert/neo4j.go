@@ -0,0 +1,106 @@
+package convert
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+
+	"github.com/dennwc/graphml"
+)
+
+// neo4jTypes maps GraphML attribute types to neo4j-admin import types.
+var neo4jTypes = map[string]string{
+	"boolean": "boolean",
+	"int":     "int",
+	"long":    "long",
+	"float":   "float",
+	"double":  "double",
+	"string":  "string",
+}
+
+// Files written by ToNeo4jCSV.
+const (
+	Neo4jNodesFile         = "nodes.csv"
+	Neo4jRelationshipsFile = "relationships.csv"
+)
+
+// ToNeo4jCSV writes nodes and edges of the document to the directory as CSV files accepted by
+// "neo4j-admin database import" (see Neo4jNodesFile and Neo4jRelationshipsFile).
+//
+// Columns are typed according to key types. Node labels and relationship types are taken from labelV and labelE
+// attributes (the same ones TinkerPop uses in GraphML), and default to "Node" and "RELATED_TO".
+// Nested graphs are flattened.
+func ToNeo4jCSV(dir string, doc *graphml.Document) error {
+	var graphs []*graphml.Graph
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			graphs = append(graphs, g)
+		})
+	}
+	header := func(cols []*graphml.Key) []string {
+		var out []string
+		for _, k := range cols {
+			typ, ok := neo4jTypes[k.Type]
+			if !ok {
+				typ = "string"
+			}
+			out = append(out, keyName(k)+":"+typ)
+		}
+		return out
+	}
+	columns := func(kind graphml.Kind, skip string) []*graphml.Key {
+		var cols []*graphml.Key
+		for _, k := range (*CSVOptions)(nil).columns(doc, kind) {
+			if keyName(k) != skip {
+				cols = append(cols, k)
+			}
+		}
+		return cols
+	}
+	keys := newKeyIndex(doc)
+
+	cols := columns(graphml.KindNode, graphsonVertexLabel)
+	err := writeCSVFile(filepath.Join(dir, Neo4jNodesFile), func(cw *csv.Writer) {
+		cw.Write(append(append([]string{"id:ID"}, header(cols)...), ":LABEL"))
+		for _, g := range graphs {
+			for _, n := range g.Nodes {
+				label, ok := keys.Attr(graphml.KindNode, n.Data, graphsonVertexLabel)
+				if !ok {
+					label = "Node"
+				}
+				cw.Write(append(append([]string{n.ID}, csvValues(cols, n.Data)...), label))
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	cols = columns(graphml.KindEdge, graphsonEdgeLabel)
+	return writeCSVFile(filepath.Join(dir, Neo4jRelationshipsFile), func(cw *csv.Writer) {
+		cw.Write(append([]string{":START_ID", ":END_ID", ":TYPE", "id:string"}, header(cols)...))
+		for _, g := range graphs {
+			for _, e := range g.Edges {
+				typ, ok := keys.Attr(graphml.KindEdge, e.Data, graphsonEdgeLabel)
+				if !ok {
+					typ = "RELATED_TO"
+				}
+				cw.Write(append([]string{e.Source, e.Target, typ, e.ID}, csvValues(cols, e.Data)...))
+			}
+		}
+	})
+}
+
+func writeCSVFile(path string, fnc func(cw *csv.Writer)) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cw := csv.NewWriter(f)
+	fnc(cw)
+	cw.Flush()
+	if err = cw.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,278 @@
+package convert
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// ToPajek writes the document in Pajek NET format.
+//
+// Vertices are numbered starting from 1 and labeled with the label attribute, or with a GraphML node ID if the
+// attribute is not set. Node coordinates are taken from x, y and z attributes, edge weights from the weight
+// attribute. Directed edges are written to *Arcs section and undirected ones to *Edges. Nested graphs are flattened.
+//
+// Pajek supports only one network per file, thus an error is returned for documents with multiple graphs.
+func ToPajek(w io.Writer, doc *graphml.Document) error {
+	if len(doc.Graphs) > 1 {
+		return errors.New("pajek: only one graph per document is supported")
+	}
+	keys := newKeyIndex(doc)
+	bw := bufio.NewWriter(w)
+	var (
+		nodes []*graphml.Node
+		ids   = make(map[string]int)
+		arcs  []string
+		edges []string
+	)
+	if len(doc.Graphs) != 0 {
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for i := range g.Nodes {
+				n := &g.Nodes[i]
+				if _, ok := ids[n.ID]; !ok {
+					nodes = append(nodes, n)
+					ids[n.ID] = len(nodes)
+				}
+			}
+		})
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for i := range g.Edges {
+				e := &g.Edges[i]
+				src, ok1 := ids[e.Source]
+				dst, ok2 := ids[e.Target]
+				if !ok1 || !ok2 {
+					continue
+				}
+				line := fmt.Sprintf("%d %d", src, dst)
+				if w, ok := keys.Attr(graphml.KindEdge, e.Data, "weight"); ok {
+					if _, err := strconv.ParseFloat(w, 64); err == nil {
+						line += " " + w
+					}
+				}
+				if edgeDirected(g, e) {
+					arcs = append(arcs, line)
+				} else {
+					edges = append(edges, line)
+				}
+			}
+		})
+	}
+	fmt.Fprintf(bw, "*Vertices %d\n", len(nodes))
+	for i, n := range nodes {
+		label, ok := keys.Attr(graphml.KindNode, n.Data, "label")
+		if !ok {
+			label = n.ID
+		}
+		line := fmt.Sprintf("%d %s", i+1, pajekQuote(label))
+		var coords []string
+		for _, name := range []string{"x", "y", "z"} {
+			v, ok := keys.Attr(graphml.KindNode, n.Data, name)
+			if !ok {
+				break
+			}
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				break
+			}
+			coords = append(coords, v)
+		}
+		if len(coords) >= 2 {
+			line += " " + strings.Join(coords, " ")
+		}
+		bw.WriteString(line + "\n")
+	}
+	if len(arcs) != 0 {
+		bw.WriteString("*Arcs\n")
+		for _, l := range arcs {
+			bw.WriteString(l + "\n")
+		}
+	}
+	if len(edges) != 0 {
+		bw.WriteString("*Edges\n")
+		for _, l := range edges {
+			bw.WriteString(l + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+func pajekQuote(s string) string {
+	return `"` + strings.Replace(s, `"`, `'`, -1) + `"`
+}
+
+// splitFields splits a line to whitespace-separated fields, keeping double-quoted strings as one field.
+func splitFields(line string) []string {
+	var (
+		out []string
+		cur strings.Builder
+		inQ bool
+		has bool
+	)
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQ = !inQ
+			has = true
+		case !inQ && (r == ' ' || r == '\t' || r == '\r'):
+			if has {
+				out = append(out, cur.String())
+				cur.Reset()
+				has = false
+			}
+		default:
+			cur.WriteRune(r)
+			has = true
+		}
+	}
+	if has {
+		out = append(out, cur.String())
+	}
+	return out
+}
+
+// FromPajek reads a network in Pajek NET format and converts it to GraphML.
+//
+// Vertices get "n<number>" IDs, while labels and coordinates are stored in label, x, y and z attributes.
+// Edge weights are stored in the weight attribute. Both list (*Arcs, *Edges) and adjacency list
+// (*Arcslist, *Edgeslist) sections are supported.
+func FromPajek(r io.Reader) (*graphml.Document, error) {
+	keys := newKeyBuilder()
+	keys.Declare(graphml.KindNode, "label", "string")
+	var (
+		g       graphml.Graph
+		section string
+		arcs    []graphml.Edge
+		edges   []graphml.Edge
+		ids     = newIDSet()
+		line    int
+	)
+	nodeID := func(s string) (string, error) {
+		if _, err := strconv.Atoi(s); err != nil {
+			return "", fmt.Errorf("pajek: line %d: invalid vertex number: %q", line, s)
+		}
+		return "n" + s, nil
+	}
+	addEdge := func(src, dst string, weight string) {
+		var e graphml.Edge
+		e.Source, e.Target = src, dst
+		if weight != "" {
+			keys.Declare(graphml.KindEdge, "weight", "double")
+			e.Data = append(e.Data, keys.Data(graphml.KindEdge, "weight", weight))
+		}
+		if section == "arcs" || section == "arcslist" {
+			arcs = append(arcs, e)
+		} else {
+			edges = append(edges, e)
+		}
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "%") {
+			continue
+		}
+		if strings.HasPrefix(text, "*") {
+			fields := strings.Fields(text)
+			section = strings.ToLower(strings.TrimPrefix(fields[0], "*"))
+			switch section {
+			case "network":
+				if len(fields) > 1 {
+					name := strings.TrimSpace(strings.TrimPrefix(text, fields[0]))
+					g.Data = append(g.Data, keys.Data(graphml.KindGraph, "name", name))
+				}
+			case "vertices", "arcs", "edges", "arcslist", "edgeslist":
+			default:
+				return nil, fmt.Errorf("pajek: line %d: unsupported section: %q", line, fields[0])
+			}
+			continue
+		}
+		fields := splitFields(text)
+		switch section {
+		case "vertices":
+			id, err := nodeID(fields[0])
+			if err != nil {
+				return nil, err
+			}
+			if !ids.Reserve(id) {
+				return nil, fmt.Errorf("pajek: line %d: duplicate vertex: %q", line, fields[0])
+			}
+			var n graphml.Node
+			n.ID = id
+			if len(fields) > 1 {
+				n.Data = append(n.Data, keys.Data(graphml.KindNode, "label", fields[1]))
+			}
+			for i, name := range []string{"x", "y", "z"} {
+				if len(fields) <= 2+i {
+					break
+				}
+				if _, err := strconv.ParseFloat(fields[2+i], 64); err != nil {
+					break
+				}
+				keys.Declare(graphml.KindNode, name, "double")
+				n.Data = append(n.Data, keys.Data(graphml.KindNode, name, fields[2+i]))
+			}
+			g.Nodes = append(g.Nodes, n)
+		case "arcs", "edges":
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("pajek: line %d: expected two vertices", line)
+			}
+			src, err := nodeID(fields[0])
+			if err != nil {
+				return nil, err
+			}
+			dst, err := nodeID(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			weight := ""
+			if len(fields) > 2 {
+				if _, err := strconv.ParseFloat(fields[2], 64); err == nil {
+					weight = fields[2]
+				}
+			}
+			addEdge(src, dst, weight)
+		case "arcslist", "edgeslist":
+			src, err := nodeID(fields[0])
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range fields[1:] {
+				dst, err := nodeID(f)
+				if err != nil {
+					return nil, err
+				}
+				addEdge(src, dst, "")
+			}
+		default:
+			return nil, fmt.Errorf("pajek: line %d: unexpected data outside of a section", line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	g.EdgeDefault = graphml.EdgeDirected
+	if len(arcs) == 0 && len(edges) != 0 {
+		g.EdgeDefault = graphml.EdgeUndirected
+	}
+	for _, e := range arcs {
+		e.ID = ids.Unique("e")
+		g.Edges = append(g.Edges, e)
+	}
+	for _, e := range edges {
+		e.ID = ids.Unique("e")
+		if g.IsDirected() {
+			e.Unrecognized = append(e.Unrecognized, undirectedAttr)
+		}
+		g.Edges = append(g.Edges, e)
+	}
+	doc := newDocument()
+	doc.Graphs = []graphml.Graph{g}
+	doc.Keys = keys.Keys()
+	return doc, nil
+}
@@ -0,0 +1,325 @@
+package convert
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+// thriftWriter encodes structures with the Thrift compact protocol used by Parquet metadata.
+type thriftWriter struct {
+	buf  []byte
+	last []int // last field ID for each nested struct
+}
+
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+func (w *thriftWriter) varint(v uint64) {
+	w.buf = binary.AppendUvarint(w.buf, v)
+}
+
+func (w *thriftWriter) field(id int, typ byte) {
+	last := &w.last[len(w.last)-1]
+	if d := id - *last; d > 0 && d <= 15 {
+		w.buf = append(w.buf, byte(d<<4)|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.varint(uint64((id << 1) ^ (id >> 15)))
+	}
+	*last = id
+}
+
+// Begin starts a struct. If id is not zero, the struct is written as a field.
+func (w *thriftWriter) Begin(id int) {
+	if id != 0 {
+		w.field(id, thriftStruct)
+	}
+	w.last = append(w.last, 0)
+}
+
+// End finishes a struct.
+func (w *thriftWriter) End() {
+	w.buf = append(w.buf, 0)
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *thriftWriter) I32(id int, v int32) {
+	w.field(id, thriftI32)
+	w.varint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+func (w *thriftWriter) I64(id int, v int64) {
+	w.field(id, thriftI64)
+	w.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) String(id int, s string) {
+	w.field(id, thriftBinary)
+	w.varint(uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// List starts a list field with a given element type and size.
+func (w *thriftWriter) List(id int, typ byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n<<4)|typ)
+	} else {
+		w.buf = append(w.buf, 0xF0|typ)
+		w.varint(uint64(n))
+	}
+}
+
+// ListI32 writes i32 elements of a list.
+func (w *thriftWriter) ListI32(vals ...int32) {
+	for _, v := range vals {
+		w.varint(uint64(uint32((v << 1) ^ (v >> 31))))
+	}
+}
+
+// ListString writes string elements of a list.
+func (w *thriftWriter) ListString(vals ...string) {
+	for _, s := range vals {
+		w.varint(uint64(len(s)))
+		w.buf = append(w.buf, s...)
+	}
+}
+
+const (
+	parquetMagic = "PAR1"
+
+	parquetBoolean   = 0
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetFloat     = 4
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetUTF8 = 0
+)
+
+// parquetType returns a physical Parquet type for a GraphML attribute type.
+func parquetType(typ string) int32 {
+	switch typ {
+	case "boolean":
+		return parquetBoolean
+	case "int":
+		return parquetInt32
+	case "long":
+		return parquetInt64
+	case "float":
+		return parquetFloat
+	case "double":
+		return parquetDouble
+	}
+	return parquetByteArray
+}
+
+// parquetPage encodes a column as a data page with PLAIN encoding.
+func parquetPage(c *tableColumn) []byte {
+	var data []byte
+	if !c.Required {
+		// definition levels, using bit-packed runs of the RLE/bit-packing hybrid encoding
+		n := (len(c.Valid) + 7) / 8
+		levels := binary.AppendUvarint(nil, uint64(n<<1|1))
+		levels = append(levels, parquetBits(len(c.Valid), func(i int) bool { return c.Valid[i] })...)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(levels)))
+		data = append(data, levels...)
+	}
+	var vals []string
+	for i, v := range c.Values {
+		if c.Valid[i] {
+			vals = append(vals, v)
+		}
+	}
+	switch c.Type {
+	case "boolean":
+		data = append(data, parquetBits(len(vals), func(i int) bool {
+			v, _ := strconv.ParseBool(vals[i])
+			return v
+		})...)
+	case "int":
+		for _, s := range vals {
+			v, _ := strconv.ParseInt(s, 10, 32)
+			data = binary.LittleEndian.AppendUint32(data, uint32(v))
+		}
+	case "long":
+		for _, s := range vals {
+			v, _ := strconv.ParseInt(s, 10, 64)
+			data = binary.LittleEndian.AppendUint64(data, uint64(v))
+		}
+	case "float":
+		for _, s := range vals {
+			v, _ := strconv.ParseFloat(s, 32)
+			data = binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(v)))
+		}
+	case "double":
+		for _, s := range vals {
+			v, _ := strconv.ParseFloat(s, 64)
+			data = binary.LittleEndian.AppendUint64(data, math.Float64bits(v))
+		}
+	default:
+		for _, s := range vals {
+			data = binary.LittleEndian.AppendUint32(data, uint32(len(s)))
+			data = append(data, s...)
+		}
+	}
+	return data
+}
+
+// parquetBits packs booleans into bytes, starting from the least significant bit.
+func parquetBits(n int, bit func(i int) bool) []byte {
+	out := make([]byte, (n+7)/8)
+	for i := 0; i < n; i++ {
+		if bit(i) {
+			out[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return out
+}
+
+// writeParquet writes the table as a Parquet file with a single row group.
+func writeParquet(w io.Writer, t *table) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(parquetMagic)
+	off := int64(len(parquetMagic))
+	type chunk struct {
+		offset int64
+		size   int64
+	}
+	var chunks []chunk
+	if t.Rows != 0 {
+		for _, c := range t.Cols {
+			page := parquetPage(c)
+			hdr := &thriftWriter{}
+			hdr.Begin(0)
+			hdr.I32(1, 0) // DATA_PAGE
+			hdr.I32(2, int32(len(page)))
+			hdr.I32(3, int32(len(page)))
+			hdr.Begin(5)
+			hdr.I32(1, int32(t.Rows))
+			hdr.I32(2, parquetPlain)
+			hdr.I32(3, parquetRLE)
+			hdr.I32(4, parquetRLE)
+			hdr.End()
+			hdr.End()
+			bw.Write(hdr.buf)
+			bw.Write(page)
+			size := int64(len(hdr.buf) + len(page))
+			chunks = append(chunks, chunk{offset: off, size: size})
+			off += size
+		}
+	}
+	meta := &thriftWriter{}
+	meta.Begin(0)
+	meta.I32(1, 1) // version
+	meta.List(2, thriftStruct, len(t.Cols)+1)
+	meta.Begin(0)
+	meta.String(4, "schema")
+	meta.I32(5, int32(len(t.Cols)))
+	meta.End()
+	for _, c := range t.Cols {
+		meta.Begin(0)
+		meta.I32(1, parquetType(c.Type))
+		if c.Required {
+			meta.I32(3, parquetRequired)
+		} else {
+			meta.I32(3, parquetOptional)
+		}
+		meta.String(4, c.Name)
+		if parquetType(c.Type) == parquetByteArray {
+			meta.I32(6, parquetUTF8)
+			meta.Begin(10) // logical type
+			meta.Begin(1)  // string
+			meta.End()
+			meta.End()
+		}
+		meta.End()
+	}
+	meta.I64(3, int64(t.Rows))
+	if len(chunks) == 0 {
+		meta.List(4, thriftStruct, 0)
+	} else {
+		meta.List(4, thriftStruct, 1)
+		var total int64
+		meta.Begin(0)
+		meta.List(1, thriftStruct, len(chunks))
+		for i, ch := range chunks {
+			c := t.Cols[i]
+			total += ch.size
+			meta.Begin(0)
+			meta.I64(2, ch.offset)
+			meta.Begin(3)
+			meta.I32(1, parquetType(c.Type))
+			meta.List(2, thriftI32, 2)
+			meta.ListI32(parquetPlain, parquetRLE)
+			meta.List(3, thriftBinary, 1)
+			meta.ListString(c.Name)
+			meta.I32(4, 0) // uncompressed
+			meta.I64(5, int64(t.Rows))
+			meta.I64(6, ch.size)
+			meta.I64(7, ch.size)
+			meta.I64(9, ch.offset)
+			meta.End()
+			meta.End()
+		}
+		meta.I64(2, total)
+		meta.I64(3, int64(t.Rows))
+		meta.End()
+	}
+	meta.String(6, "github.com/dennwc/graphml")
+	meta.End()
+	bw.Write(meta.buf)
+	bw.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(meta.buf))))
+	bw.WriteString(parquetMagic)
+	return bw.Flush()
+}
+
+const (
+	// ParquetNodesFile is a name of the node table file written by ToParquet.
+	ParquetNodesFile = "nodes.parquet"
+	// ParquetEdgesFile is a name of the edge table file written by ToParquet.
+	ParquetEdgesFile = "edges.parquet"
+)
+
+// ToParquet writes nodes and edges of all graphs in the document as two Parquet files in a given directory
+// (see ParquetNodesFile and ParquetEdgesFile).
+//
+// Tables have the same columns as the ones written by ToArrow. Data is stored uncompressed in a single row group.
+func ToParquet(dir string, doc *graphml.Document) error {
+	nt, et := graphTables(doc)
+	if err := writeParquetFile(filepath.Join(dir, ParquetNodesFile), nt); err != nil {
+		return err
+	}
+	return writeParquetFile(filepath.Join(dir, ParquetEdgesFile), et)
+}
+
+func writeParquetFile(path string, t *table) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = writeParquet(f, t); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,142 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// PlantUMLStyle selects a kind of PlantUML diagram.
+type PlantUMLStyle int
+
+const (
+	// PlantUMLComponent generates a component diagram.
+	PlantUMLComponent = PlantUMLStyle(iota)
+	// PlantUMLObject generates an object diagram.
+	PlantUMLObject
+)
+
+// PlantUMLOptions controls the conversion to PlantUML.
+type PlantUMLOptions struct {
+	Style PlantUMLStyle
+}
+
+// ToPlantUML writes the document as a PlantUML diagram.
+//
+// Nodes and edges are labeled with the label attribute, if it is set. Graphs nested into nodes are written
+// as packages (for component diagrams) or as nested objects (for object diagrams).
+func ToPlantUML(w io.Writer, doc *graphml.Document, opt *PlantUMLOptions) error {
+	if opt == nil {
+		opt = &PlantUMLOptions{}
+	}
+	bw := bufio.NewWriter(w)
+	e := &umlEncoder{w: bw, keys: newKeyIndex(doc), style: opt.Style, aliases: make(map[string]string), used: newIDSet()}
+	e.line("@startuml")
+	for i := range doc.Graphs {
+		e.writeNodes(&doc.Graphs[i])
+	}
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], e.writeEdges)
+	}
+	e.line("@enduml")
+	return bw.Flush()
+}
+
+type umlEncoder struct {
+	w       *bufio.Writer
+	keys    *keyIndex
+	style   PlantUMLStyle
+	aliases map[string]string
+	used    idSet
+	depth   int
+}
+
+func (e *umlEncoder) line(format string, args ...interface{}) {
+	e.w.WriteString(strings.Repeat("  ", e.depth))
+	fmt.Fprintf(e.w, format, args...)
+	e.w.WriteByte('\n')
+}
+
+// alias returns a valid PlantUML identifier for a node.
+func (e *umlEncoder) alias(id string) string {
+	if a, ok := e.aliases[id]; ok {
+		return a
+	}
+	var sb strings.Builder
+	for _, r := range id {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	a := sb.String()
+	if a == "" || (a[0] >= '0' && a[0] <= '9') {
+		a = "n_" + a
+	}
+	if !e.used.Reserve(a) {
+		a = e.used.Unique(a + "_")
+	}
+	e.aliases[id] = a
+	return a
+}
+
+func umlQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, `'`, "\n", `\n`).Replace(s) + `"`
+}
+
+func (e *umlEncoder) writeNodes(g *graphml.Graph) {
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		label, ok := e.keys.Attr(graphml.KindNode, n.Data, "label")
+		if !ok {
+			label = n.ID
+		}
+		a := e.alias(n.ID)
+		switch {
+		case e.style == PlantUMLObject:
+			e.line("object %s as %s", umlQuote(label), a)
+		case len(n.Graphs) != 0:
+			e.line("package %s as %s {", umlQuote(label), a)
+		default:
+			e.line("component %s as %s", umlQuote(label), a)
+		}
+		if len(n.Graphs) == 0 {
+			continue
+		}
+		if e.style == PlantUMLObject {
+			// objects cannot be nested; connect children to the parent instead
+			for j := range n.Graphs {
+				e.writeNodes(&n.Graphs[j])
+				for _, c := range n.Graphs[j].Nodes {
+					e.line("%s *-- %s", a, e.alias(c.ID))
+				}
+			}
+			continue
+		}
+		e.depth++
+		for j := range n.Graphs {
+			e.writeNodes(&n.Graphs[j])
+		}
+		e.depth--
+		e.line("}")
+	}
+}
+
+func (e *umlEncoder) writeEdges(g *graphml.Graph) {
+	for i := range g.Edges {
+		ed := &g.Edges[i]
+		arrow := "--"
+		if edgeDirected(g, ed) {
+			arrow = "-->"
+		}
+		line := e.alias(ed.Source) + " " + arrow + " " + e.alias(ed.Target)
+		if label, ok := e.keys.Attr(graphml.KindEdge, ed.Data, "label"); ok {
+			line += " : " + strings.Replace(label, "\n", `\n`, -1)
+		}
+		e.line("%s", line)
+	}
+}
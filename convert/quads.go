@@ -0,0 +1,76 @@
+package convert
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/dennwc/graphml"
+)
+
+// QuadOptions controls the conversion to N-Quads.
+type QuadOptions struct {
+	// BaseIRI is a prefix of all generated IRIs. Defaults to "urn:graphml:".
+	BaseIRI string
+	// Predicate is an edge attribute name (or key ID, if the name is not set) used as a predicate of edge quads.
+	// Edge IDs are used by default, or if the attribute is not set for an edge.
+	Predicate string
+	// NoLabel disables writing graph IRIs as labels (the fourth element) of quads.
+	NoLabel bool
+}
+
+// ToQuads writes the document as N-Quads, which can be loaded into Cayley or other quad stores.
+//
+// IRIs of nodes, edges and attributes are generated the same way as in ToTurtle. Each quad is labeled with
+// "<baseIRI>graph/<id>" IRI of the graph that contains the element. Elements of graphs without an ID
+// inherit the label of the parent graph, if any.
+func ToQuads(w io.Writer, doc *graphml.Document, opt *QuadOptions) error {
+	if opt == nil {
+		opt = &QuadOptions{}
+	}
+	base := opt.BaseIRI
+	if base == "" {
+		base = "urn:graphml:"
+	}
+	t := rdfTerms{base: base, keys: newKeyIndex(doc)}
+	bw := bufio.NewWriter(w)
+	quad := func(s, p, o, label string) {
+		bw.WriteString(s + " " + p + " " + o)
+		if label != "" && !opt.NoLabel {
+			bw.WriteString(" " + label)
+		}
+		bw.WriteString(" .\n")
+	}
+	var writeGraph func(g *graphml.Graph, label string)
+	writeGraph = func(g *graphml.Graph, label string) {
+		if g.ID != "" {
+			label = t.iri("graph", g.ID)
+			for _, p := range t.attrs(graphml.KindGraph, g.Data) {
+				quad(label, p[0], p[1], label)
+			}
+		}
+		for i := range g.Nodes {
+			n := &g.Nodes[i]
+			for _, p := range t.attrs(graphml.KindNode, n.Data) {
+				quad(t.iri("node", n.ID), p[0], p[1], label)
+			}
+		}
+		for i := range g.Edges {
+			e := &g.Edges[i]
+			quad(t.iri("node", e.Source), t.predicate(e, opt.Predicate), t.iri("node", e.Target), label)
+			if e.ID != "" {
+				for _, p := range t.attrs(graphml.KindEdge, e.Data) {
+					quad(t.iri("edge", e.ID), p[0], p[1], label)
+				}
+			}
+		}
+		for i := range g.Nodes {
+			for j := range g.Nodes[i].Graphs {
+				writeGraph(&g.Nodes[i].Graphs[j], label)
+			}
+		}
+	}
+	for i := range doc.Graphs {
+		writeGraph(&doc.Graphs[i], "")
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,186 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// SQLDialect is a SQL dialect used for the export.
+type SQLDialect string
+
+const (
+	// SQLPostgres is a PostgreSQL dialect.
+	SQLPostgres = SQLDialect("postgres")
+	// SQLSQLite is an SQLite dialect.
+	SQLSQLite = SQLDialect("sqlite")
+)
+
+// sqlType returns a column type for a GraphML attribute type.
+func (d SQLDialect) sqlType(typ string) string {
+	switch typ {
+	case "boolean":
+		return "BOOLEAN"
+	case "int":
+		return "INTEGER"
+	case "long":
+		if d == SQLSQLite {
+			return "INTEGER"
+		}
+		return "BIGINT"
+	case "float":
+		return "REAL"
+	case "double":
+		if d == SQLSQLite {
+			return "REAL"
+		}
+		return "DOUBLE PRECISION"
+	}
+	return "TEXT"
+}
+
+// sqlValue returns a SQL literal for a value of a given GraphML type.
+func (d SQLDialect) sqlValue(typ, v string) (string, error) {
+	switch typ {
+	case "boolean":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return "", err
+		}
+		if d == SQLSQLite {
+			if b {
+				return "1", nil
+			}
+			return "0", nil
+		}
+		return strings.ToUpper(strconv.FormatBool(b)), nil
+	case "int", "long":
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return "", err
+		}
+		return v, nil
+	case "float", "double":
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return "", err
+		}
+		return v, nil
+	}
+	return sqlString(v), nil
+}
+
+// sqlString quotes a SQL string literal.
+func sqlString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// sqlIdent quotes a SQL identifier.
+func sqlIdent(s string) string {
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
+
+// ToSQL writes the document as SQL statements that create and fill nodes and edges tables.
+//
+// The nodes table has id and graph columns, followed by one column for each node attribute. The edges table
+// has id, graph, source, target and directed columns, followed by edge attributes. Column types and defaults
+// are taken from GraphML keys. Attributes that clash with predefined columns are skipped.
+// The graph column stores an ID of the graph that contains the element, thus nested graphs are flattened.
+func ToSQL(w io.Writer, doc *graphml.Document, dialect SQLDialect) error {
+	switch dialect {
+	case SQLPostgres, SQLSQLite:
+	default:
+		return fmt.Errorf("sql: unsupported dialect: %q", dialect)
+	}
+	ncols := attrColumns(doc, graphml.KindNode, "id", "graph")
+	ecols := attrColumns(doc, graphml.KindEdge, "id", "graph", "source", "target", "directed")
+
+	bw := bufio.NewWriter(w)
+	createTable := func(name string, fixed []string, cols []*graphml.Key) error {
+		lines := fixed
+		for _, k := range cols {
+			line := sqlIdent(keyName(k)) + " " + dialect.sqlType(k.Type)
+			if k.Default != nil {
+				if def, ok := graphml.TokensText(k.Default); ok {
+					v, err := dialect.sqlValue(k.Type, def)
+					if err != nil {
+						return fmt.Errorf("sql: invalid default value of %q: %v", keyName(k), err)
+					}
+					line += " DEFAULT " + v
+				}
+			}
+			lines = append(lines, line)
+		}
+		fmt.Fprintf(bw, "CREATE TABLE %s (\n\t%s\n);\n", name, strings.Join(lines, ",\n\t"))
+		return nil
+	}
+	if err := createTable("nodes", []string{
+		"id TEXT PRIMARY KEY",
+		"graph TEXT",
+	}, ncols); err != nil {
+		return err
+	}
+	if err := createTable("edges", []string{
+		"id TEXT",
+		"graph TEXT",
+		"source TEXT NOT NULL",
+		"target TEXT NOT NULL",
+		"directed BOOLEAN NOT NULL",
+	}, ecols); err != nil {
+		return err
+	}
+	insert := func(table string, fixed []string, cols []*graphml.Key, data []graphml.Data) error {
+		vals := fixed
+		for _, k := range cols {
+			v, ok := attrValue(k, data)
+			if !ok {
+				vals = append(vals, "NULL")
+				continue
+			}
+			s, err := dialect.sqlValue(k.Type, v)
+			if err != nil {
+				return fmt.Errorf("sql: invalid value of %q: %v", keyName(k), err)
+			}
+			vals = append(vals, s)
+		}
+		fmt.Fprintf(bw, "INSERT INTO %s VALUES (%s);\n", table, strings.Join(vals, ", "))
+		return nil
+	}
+	nullable := func(s string) string {
+		if s == "" {
+			return "NULL"
+		}
+		return sqlString(s)
+	}
+	bw.WriteString("BEGIN;\n")
+	var err error
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for _, n := range g.Nodes {
+				if err == nil {
+					err = insert("nodes", []string{sqlString(n.ID), nullable(g.ID)}, ncols, n.Data)
+				}
+			}
+		})
+	}
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for j := range g.Edges {
+				e := &g.Edges[j]
+				if err == nil {
+					dir, _ := dialect.sqlValue("boolean", strconv.FormatBool(edgeDirected(g, e)))
+					err = insert("edges", []string{
+						nullable(e.ID), nullable(g.ID), sqlString(e.Source), sqlString(e.Target), dir,
+					}, ecols, e.Data)
+				}
+			}
+		})
+	}
+	if err != nil {
+		return err
+	}
+	bw.WriteString("COMMIT;\n")
+	return bw.Flush()
+}
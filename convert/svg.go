@@ -0,0 +1,172 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+// SVGOptions controls rendering of SVG previews.
+type SVGOptions struct {
+	// Label is a node attribute name (or key ID, if the name is not set) used for labels. Defaults to "label".
+	// Node IDs are used for nodes without a label.
+	Label string
+	// Width and Height are dimensions of the image. Default to 800x600.
+	Width, Height float64
+	// NodeRadius is a radius of node circles. Defaults to 8.
+	NodeRadius float64
+	// Iterations is a number of iterations of the built-in layout. Defaults to 100.
+	Iterations int
+}
+
+func (opt SVGOptions) withDefaults() SVGOptions {
+	if opt.Label == "" {
+		opt.Label = "label"
+	}
+	if opt.Width <= 0 {
+		opt.Width = 800
+	}
+	if opt.Height <= 0 {
+		opt.Height = 600
+	}
+	if opt.NodeRadius <= 0 {
+		opt.NodeRadius = 8
+	}
+	if opt.Iterations <= 0 {
+		opt.Iterations = 100
+	}
+	return opt
+}
+
+// nodePositions returns coordinates stored in x and y attributes of nodes.
+// It returns false if any of the nodes has no coordinates.
+func nodePositions(keys *keyIndex, nodes []*graphml.Node) ([][2]float64, bool) {
+	pos := make([][2]float64, len(nodes))
+	for i, n := range nodes {
+		for j, name := range []string{"x", "y"} {
+			v, ok := keys.Attr(graphml.KindNode, n.Data, name)
+			if !ok {
+				return nil, false
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, false
+			}
+			pos[i][j] = f
+		}
+	}
+	return pos, true
+}
+
+// RenderSVG draws nodes and edges of the document as an SVG image.
+//
+// Node positions are taken from x and y attributes if all nodes have them. Otherwise, a simple built-in
+// force-directed layout is used. Node colors are taken from the color attribute. Nested graphs are flattened.
+// The renderer is intended for quick previews of small graphs.
+func RenderSVG(w io.Writer, doc *graphml.Document, opt *SVGOptions) error {
+	var o SVGOptions
+	if opt != nil {
+		o = *opt
+	}
+	o = o.withDefaults()
+	keys := newKeyIndex(doc)
+	var (
+		nodes    []*graphml.Node
+		index    = make(map[string]int)
+		edges    [][2]int
+		directed []bool
+	)
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for j := range g.Nodes {
+				n := &g.Nodes[j]
+				if _, ok := index[n.ID]; !ok {
+					index[n.ID] = len(nodes)
+					nodes = append(nodes, n)
+				}
+			}
+		})
+	}
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for j := range g.Edges {
+				e := &g.Edges[j]
+				src, ok1 := index[e.Source]
+				dst, ok2 := index[e.Target]
+				if ok1 && ok2 {
+					edges = append(edges, [2]int{src, dst})
+					directed = append(directed, edgeDirected(g, e))
+				}
+			}
+		})
+	}
+	pos, ok := nodePositions(keys, nodes)
+	if !ok {
+		pos = forceLayout(len(nodes), edges, o.Iterations)
+	}
+	// fit positions into the image
+	margin := o.NodeRadius + 24
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range pos {
+		minX, maxX = math.Min(minX, p[0]), math.Max(maxX, p[0])
+		minY, maxY = math.Min(minY, p[1]), math.Max(maxY, p[1])
+	}
+	scale := math.Min((o.Width-2*margin)/math.Max(maxX-minX, 1e-9), (o.Height-2*margin)/math.Max(maxY-minY, 1e-9))
+	for i, p := range pos {
+		pos[i] = [2]float64{
+			o.Width/2 + (p[0]-(minX+maxX)/2)*scale,
+			o.Height/2 + (p[1]-(minY+maxY)/2)*scale,
+		}
+	}
+	num := func(f float64) string {
+		return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %[1]s %[2]s">`+"\n",
+		num(o.Width), num(o.Height))
+	bw.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0L10,5L0,10z" fill="#555"/></marker></defs>` + "\n")
+	bw.WriteString(`<g stroke="#555" stroke-width="1">` + "\n")
+	for i, e := range edges {
+		p1, p2 := pos[e[0]], pos[e[1]]
+		if e[0] == e[1] {
+			r := o.NodeRadius
+			fmt.Fprintf(bw, `<circle cx="%s" cy="%s" r="%s" fill="none"/>`+"\n", num(p1[0]), num(p1[1]-r), num(r))
+			continue
+		}
+		// stop lines at node borders
+		dx, dy := p2[0]-p1[0], p2[1]-p1[1]
+		d := math.Max(math.Hypot(dx, dy), 1e-9)
+		ox, oy := dx/d*o.NodeRadius, dy/d*o.NodeRadius
+		marker := ""
+		if directed[i] {
+			marker = ` marker-end="url(#arrow)"`
+		}
+		fmt.Fprintf(bw, `<line x1="%s" y1="%s" x2="%s" y2="%s"%s/>`+"\n",
+			num(p1[0]+ox), num(p1[1]+oy), num(p2[0]-ox), num(p2[1]-oy), marker)
+	}
+	bw.WriteString("</g>\n")
+	bw.WriteString(`<g stroke="#333" stroke-width="1" font-family="sans-serif" font-size="12" text-anchor="middle">` + "\n")
+	for i, n := range nodes {
+		p := pos[i]
+		fill, ok := keys.Attr(graphml.KindNode, n.Data, "color")
+		if !ok {
+			fill = "#9cf"
+		}
+		label, ok := keys.Attr(graphml.KindNode, n.Data, o.Label)
+		if !ok {
+			label = n.ID
+		}
+		fmt.Fprintf(bw, `<circle cx="%s" cy="%s" r="%s" fill="%s"/>`+"\n",
+			num(p[0]), num(p[1]), num(o.NodeRadius), html.EscapeString(fill))
+		fmt.Fprintf(bw, `<text x="%s" y="%s" stroke="none">%s</text>`+"\n",
+			num(p[0]), num(p[1]+o.NodeRadius+14), html.EscapeString(label))
+	}
+	bw.WriteString("</g>\n</svg>\n")
+	return bw.Flush()
+}
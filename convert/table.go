@@ -0,0 +1,118 @@
+package convert
+
+import (
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+// tableColumn is a typed column of a node or edge table used by columnar formats.
+type tableColumn struct {
+	Name     string
+	Type     string // GraphML attribute type
+	Required bool   // column never contains nulls
+	Valid    []bool
+	Values   []string
+}
+
+// Append adds a value to the column. Values that cannot be parsed according to the column type are stored as nulls.
+func (c *tableColumn) Append(v string, ok bool) {
+	if ok {
+		var err error
+		switch c.Type {
+		case "boolean":
+			_, err = strconv.ParseBool(v)
+		case "int":
+			_, err = strconv.ParseInt(v, 10, 32)
+		case "long":
+			_, err = strconv.ParseInt(v, 10, 64)
+		case "float":
+			_, err = strconv.ParseFloat(v, 32)
+		case "double":
+			_, err = strconv.ParseFloat(v, 64)
+		}
+		ok = err == nil
+	}
+	if !ok {
+		v = ""
+	}
+	c.Valid = append(c.Valid, ok)
+	c.Values = append(c.Values, v)
+}
+
+// Nulls returns the number of nulls in the column.
+func (c *tableColumn) Nulls() int {
+	n := 0
+	for _, ok := range c.Valid {
+		if !ok {
+			n++
+		}
+	}
+	return n
+}
+
+// table is a columnar representation of nodes or edges.
+type table struct {
+	Rows int
+	Cols []*tableColumn
+}
+
+func newTable(fixed []*tableColumn, keys []*graphml.Key) *table {
+	t := &table{Cols: fixed}
+	for _, k := range keys {
+		typ := k.Type
+		switch typ {
+		case "boolean", "int", "long", "float", "double", "string":
+		default:
+			typ = "string"
+		}
+		t.Cols = append(t.Cols, &tableColumn{Name: keyName(k), Type: typ})
+	}
+	return t
+}
+
+// graphTables converts nodes and edges of the document to tables.
+//
+// The node table has id and graph columns, followed by node attributes. The edge table has id, graph,
+// source, target and directed columns, followed by edge attributes. Nested graphs are flattened.
+func graphTables(doc *graphml.Document) (nodes, edges *table) {
+	ncols := attrColumns(doc, graphml.KindNode, "id", "graph")
+	ecols := attrColumns(doc, graphml.KindEdge, "id", "graph", "source", "target", "directed")
+	nodes = newTable([]*tableColumn{
+		{Name: "id", Type: "string", Required: true},
+		{Name: "graph", Type: "string"},
+	}, ncols)
+	edges = newTable([]*tableColumn{
+		{Name: "id", Type: "string"},
+		{Name: "graph", Type: "string"},
+		{Name: "source", Type: "string", Required: true},
+		{Name: "target", Type: "string", Required: true},
+		{Name: "directed", Type: "boolean", Required: true},
+	}, ecols)
+	addAttrs := func(t *table, keys []*graphml.Key, data []graphml.Data) {
+		cols := t.Cols[len(t.Cols)-len(keys):]
+		for i, k := range keys {
+			cols[i].Append(attrValue(k, data))
+		}
+		t.Rows++
+	}
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			for _, n := range g.Nodes {
+				nodes.Cols[0].Append(n.ID, true)
+				nodes.Cols[1].Append(g.ID, g.ID != "")
+				addAttrs(nodes, ncols, n.Data)
+			}
+			for j := range g.Edges {
+				e := &g.Edges[j]
+				edges.Cols[0].Append(e.ID, e.ID != "")
+				edges.Cols[1].Append(g.ID, g.ID != "")
+				edges.Cols[2].Append(e.Source, true)
+				edges.Cols[3].Append(e.Target, true)
+				edges.Cols[4].Append(strconv.FormatBool(edgeDirected(g, e)), true)
+				addAttrs(edges, ecols, e.Data)
+			}
+		})
+	}
+	return nodes, edges
+}
@@ -0,0 +1,135 @@
+package convert
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// ToTGF writes the document in Trivial Graph Format.
+//
+// Nodes and edges are labeled with the label attribute, if it is set. Nested graphs are flattened.
+// Node IDs containing whitespaces are not supported by the format and will result in an error.
+//
+// TGF supports only one graph per file, thus an error is returned for documents with multiple graphs.
+func ToTGF(w io.Writer, doc *graphml.Document) error {
+	if len(doc.Graphs) > 1 {
+		return errors.New("tgf: only one graph per document is supported")
+	}
+	keys := newKeyIndex(doc)
+	bw := bufio.NewWriter(w)
+	var err error
+	writeLine := func(kind graphml.Kind, data []graphml.Data, ids ...string) {
+		for _, id := range ids {
+			if id == "" || strings.ContainsAny(id, " \t\r\n") {
+				if err == nil {
+					err = fmt.Errorf("tgf: invalid node id: %q", id)
+				}
+				return
+			}
+		}
+		line := strings.Join(ids, " ")
+		if label, ok := keys.Attr(kind, data, "label"); ok {
+			line += " " + strings.NewReplacer("\r", " ", "\n", " ").Replace(label)
+		}
+		bw.WriteString(line + "\n")
+	}
+	if len(doc.Graphs) != 0 {
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for _, n := range g.Nodes {
+				writeLine(graphml.KindNode, n.Data, n.ID)
+			}
+		})
+	}
+	bw.WriteString("#\n")
+	if len(doc.Graphs) != 0 {
+		walkGraph(&doc.Graphs[0], func(g *graphml.Graph) {
+			for _, e := range g.Edges {
+				writeLine(graphml.KindEdge, e.Data, e.Source, e.Target)
+			}
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// FromTGF reads a graph in Trivial Graph Format and converts it to GraphML.
+// Node and edge labels are stored in the label attribute. All edges are assumed to be directed.
+func FromTGF(r io.Reader) (*graphml.Document, error) {
+	keys := newKeyBuilder()
+	ids := newIDSet()
+	g := graphml.Graph{EdgeDefault: graphml.EdgeDirected}
+	var (
+		inEdges bool
+		line    int
+		edges   []graphml.Edge
+	)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		if text == "#" {
+			if inEdges {
+				return nil, fmt.Errorf("tgf: line %d: unexpected separator", line)
+			}
+			inEdges = true
+			continue
+		}
+		if !inEdges {
+			id, label := splitFirst(text)
+			if !ids.Reserve(id) {
+				return nil, fmt.Errorf("tgf: line %d: duplicate node: %q", line, id)
+			}
+			var n graphml.Node
+			n.ID = id
+			if label != "" {
+				keys.Declare(graphml.KindNode, "label", "string")
+				n.Data = append(n.Data, keys.Data(graphml.KindNode, "label", label))
+			}
+			g.Nodes = append(g.Nodes, n)
+			continue
+		}
+		src, rest := splitFirst(text)
+		dst, label := splitFirst(rest)
+		if dst == "" {
+			return nil, fmt.Errorf("tgf: line %d: expected two nodes", line)
+		}
+		var e graphml.Edge
+		e.Source, e.Target = src, dst
+		if label != "" {
+			keys.Declare(graphml.KindEdge, "label", "string")
+			e.Data = append(e.Data, keys.Data(graphml.KindEdge, "label", label))
+		}
+		edges = append(edges, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	for _, e := range edges {
+		e.ID = ids.Unique("e")
+		g.Edges = append(g.Edges, e)
+	}
+	doc := newDocument()
+	doc.Graphs = []graphml.Graph{g}
+	doc.Keys = keys.Keys()
+	return doc, nil
+}
+
+// splitFirst splits the first whitespace-separated field from the rest of the string.
+func splitFirst(s string) (string, string) {
+	i := strings.IndexAny(s, " \t")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i+1:])
+}
@@ -0,0 +1,144 @@
+package convert
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// TurtleOptions controls the conversion to RDF Turtle.
+type TurtleOptions struct {
+	// Predicate is an edge attribute name (or key ID, if the name is not set) used as a predicate of edge triples.
+	// Edge IDs are used by default, or if the attribute is not set for an edge.
+	Predicate string
+}
+
+// xsdTypes maps GraphML attribute types to XML Schema datatypes of RDF literals.
+var xsdTypes = map[string]string{
+	"boolean": "boolean",
+	"int":     "int",
+	"long":    "long",
+	"float":   "float",
+	"double":  "double",
+}
+
+const xsdNamespace = "http://www.w3.org/2001/XMLSchema#"
+
+// rdfTerms generates IRIs and literals for GraphML elements.
+type rdfTerms struct {
+	base string
+	keys *keyIndex
+}
+
+func (t rdfTerms) iri(kind, id string) string {
+	return "<" + t.base + kind + "/" + url.PathEscape(id) + ">"
+}
+
+func (t rdfTerms) literal(a attr) string {
+	s := `"` + rdfEscape(a.Value) + `"`
+	if a.Key != nil {
+		if typ, ok := xsdTypes[a.Key.Type]; ok {
+			s += "^^<" + xsdNamespace + typ + ">"
+		}
+	}
+	return s
+}
+
+// predicate returns a predicate IRI for an edge. It is taken from a given attribute, if it is set,
+// or is generated from the edge ID otherwise.
+func (t rdfTerms) predicate(e *graphml.Edge, attr string) string {
+	if v, ok := t.keys.Attr(graphml.KindEdge, e.Data, attr); attr != "" && ok {
+		return t.iri("rel", v)
+	} else if e.ID != "" {
+		return t.iri("edge", e.ID)
+	}
+	return "<" + t.base + "edge>"
+}
+
+// rdfEscape escapes a string for use in a quoted RDF literal.
+func rdfEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`, `"`, `\"`,
+		"\n", `\n`, "\r", `\r`, "\t", `\t`,
+	).Replace(s)
+}
+
+// attrs returns predicate-object pairs for simple attributes of an element.
+func (t rdfTerms) attrs(kind graphml.Kind, data []graphml.Data) [][2]string {
+	var out [][2]string
+	for _, a := range t.keys.Attrs(kind, data) {
+		out = append(out, [2]string{t.iri("attr", a.Name), t.literal(a)})
+	}
+	return out
+}
+
+// ToTurtle writes the document as RDF triples in Turtle format.
+//
+// Nodes are mapped to "<baseIRI>node/<id>" IRIs, and their attributes to triples with "<baseIRI>attr/<name>"
+// predicates and typed literals. Each edge is written as a triple connecting its source and target, with
+// "<baseIRI>edge/<id>" predicate, or "<baseIRI>rel/<value>" if a predicate attribute is set in options.
+// Attributes of edges and graphs with IDs are written for their IRIs. Nested graphs are flattened.
+// The base IRI usually ends with '/' or '#'.
+func ToTurtle(w io.Writer, doc *graphml.Document, baseIRI string, opt *TurtleOptions) error {
+	if opt == nil {
+		opt = &TurtleOptions{}
+	}
+	t := rdfTerms{base: baseIRI, keys: newKeyIndex(doc)}
+	bw := bufio.NewWriter(w)
+	subject := func(s string, pairs [][2]string) {
+		if len(pairs) == 0 {
+			return
+		}
+		bw.WriteString(s)
+		for i, p := range pairs {
+			if i != 0 {
+				bw.WriteString(" ;")
+			}
+			bw.WriteString("\n\t" + p[0] + " " + p[1])
+		}
+		bw.WriteString(" .\n")
+	}
+	var (
+		nodes []*graphml.Node
+		out   = make(map[string][][2]string)
+		edges []*graphml.Edge
+	)
+	for i := range doc.Graphs {
+		walkGraph(&doc.Graphs[i], func(g *graphml.Graph) {
+			if g.ID != "" {
+				subject(t.iri("graph", g.ID), t.attrs(graphml.KindGraph, g.Data))
+			}
+			for j := range g.Nodes {
+				nodes = append(nodes, &g.Nodes[j])
+			}
+			for j := range g.Edges {
+				e := &g.Edges[j]
+				pred := t.predicate(e, opt.Predicate)
+				out[e.Source] = append(out[e.Source], [2]string{pred, t.iri("node", e.Target)})
+				edges = append(edges, e)
+			}
+		})
+	}
+	for _, n := range nodes {
+		pairs := t.attrs(graphml.KindNode, n.Data)
+		pairs = append(pairs, out[n.ID]...)
+		delete(out, n.ID)
+		subject(t.iri("node", n.ID), pairs)
+	}
+	for _, e := range edges {
+		if pairs, ok := out[e.Source]; ok {
+			// edges from nodes that are not defined in the document
+			subject(t.iri("node", e.Source), pairs)
+			delete(out, e.Source)
+		}
+	}
+	for _, e := range edges {
+		if e.ID != "" {
+			subject(t.iri("edge", e.ID), t.attrs(graphml.KindEdge, e.Data))
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,167 @@
+package convert
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// ToXLSX writes nodes and edges of all graphs in the document as an Excel workbook with Nodes and Edges sheets.
+//
+// Sheets have the same columns as the tables written by ToArrow. Numeric and boolean attributes are written
+// as typed cells, while long integers that cannot be represented exactly by Excel are written as text.
+func ToXLSX(path string, doc *graphml.Document) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	nt, et := graphTables(doc)
+	if err = writeXLSX(f, []string{"Nodes", "Edges"}, []*table{nt, et}); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`%s</Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles declares a default cell style and a bold one used for headers.
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
+
+// xlsxMaxInt is the largest integer that can be stored in a numeric cell without losing precision.
+const xlsxMaxInt = 1<<53 - 1
+
+// writeXLSX writes tables as sheets of an Excel workbook.
+func writeXLSX(w io.Writer, names []string, tables []*table) error {
+	zw := zip.NewWriter(w)
+	file := func(name string, fnc func(w *bufio.Writer)) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		bw := bufio.NewWriter(f)
+		fnc(bw)
+		return bw.Flush()
+	}
+	var overrides, sheets, rels strings.Builder
+	for i, name := range names {
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(name), i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(names)+1)
+	parts := []struct {
+		name string
+		data string
+	}{
+		{"[Content_Types].xml", fmt.Sprintf(xlsxContentTypes, overrides.String())},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` +
+			sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() + `</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		data := p.data
+		if err := file(p.name, func(w *bufio.Writer) { w.WriteString(data) }); err != nil {
+			return err
+		}
+	}
+	for i, t := range tables {
+		err := file(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), func(w *bufio.Writer) {
+			writeXLSXSheet(w, t)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeXLSXSheet writes a table as a worksheet with a frozen header row.
+func writeXLSXSheet(w *bufio.Writer, t *table) {
+	w.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	w.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	w.WriteString(`<sheetData><row r="1">`)
+	for j, c := range t.Cols {
+		fmt.Fprintf(w, `<c r="%s1" s="1" t="inlineStr"><is><t>%s</t></is></c>`, xlsxColumn(j), xlsxEscape(c.Name))
+	}
+	w.WriteString(`</row>`)
+	for i := 0; i < t.Rows; i++ {
+		fmt.Fprintf(w, `<row r="%d">`, i+2)
+		for j, c := range t.Cols {
+			if !c.Valid[i] {
+				continue
+			}
+			ref := xlsxColumn(j) + strconv.Itoa(i+2)
+			v := c.Values[i]
+			switch c.Type {
+			case "boolean":
+				b, _ := strconv.ParseBool(v)
+				n := 0
+				if b {
+					n = 1
+				}
+				fmt.Fprintf(w, `<c r="%s" t="b"><v>%d</v></c>`, ref, n)
+				continue
+			case "int", "long":
+				if n, _ := strconv.ParseInt(v, 10, 64); n <= xlsxMaxInt && n >= -xlsxMaxInt {
+					fmt.Fprintf(w, `<c r="%s"><v>%d</v></c>`, ref, n)
+					continue
+				}
+			case "float", "double":
+				if f, _ := strconv.ParseFloat(v, 64); !math.IsNaN(f) && !math.IsInf(f, 0) {
+					fmt.Fprintf(w, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(f, 'g', -1, 64))
+					continue
+				}
+			}
+			fmt.Fprintf(w, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xlsxEscape(v))
+		}
+		w.WriteString(`</row>`)
+	}
+	w.WriteString(`</sheetData></worksheet>`)
+}
+
+// xlsxColumn returns a column name for a zero-based index: A, B, ..., Z, AA, AB, etc.
+func xlsxColumn(i int) string {
+	var buf []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		buf = append([]byte{byte('A' + (i-1)%26)}, buf...)
+	}
+	return string(buf)
+}
+
+func xlsxEscape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
@@ -0,0 +1,71 @@
+package convert
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// xmlWriter is a helper for writing XML-based formats.
+// It remembers the first error and ignores all writes after it.
+type xmlWriter struct {
+	enc *xml.Encoder
+	err error
+}
+
+func newXMLWriter(w io.Writer) *xmlWriter {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &xmlWriter{enc: enc}
+}
+
+func (w *xmlWriter) token(t xml.Token) {
+	if w.err == nil {
+		w.err = w.enc.EncodeToken(t)
+	}
+}
+
+// Header writes the XML declaration.
+func (w *xmlWriter) Header() {
+	w.token(xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)})
+	w.token(xml.CharData("\n"))
+}
+
+// Start opens an element. Attributes are passed as name-value pairs.
+func (w *xmlWriter) Start(name string, attrs ...string) {
+	t := xml.StartElement{Name: xml.Name{Local: name}}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		t.Attr = append(t.Attr, xml.Attr{Name: xml.Name{Local: attrs[i]}, Value: attrs[i+1]})
+	}
+	w.token(t)
+}
+
+// End closes an element.
+func (w *xmlWriter) End(name string) {
+	w.token(xml.EndElement{Name: xml.Name{Local: name}})
+}
+
+// Elem writes an element without children.
+func (w *xmlWriter) Elem(name string, attrs ...string) {
+	w.Start(name, attrs...)
+	w.End(name)
+}
+
+// TextElem writes an element with a text content.
+func (w *xmlWriter) TextElem(name, text string, attrs ...string) {
+	w.Start(name, attrs...)
+	w.Text(text)
+	w.End(name)
+}
+
+// Text writes a text node.
+func (w *xmlWriter) Text(s string) {
+	w.token(xml.CharData(s))
+}
+
+// Close flushes the writer and returns the first error, if any.
+func (w *xmlWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.enc.Flush()
+}
@@ -0,0 +1,307 @@
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dennwc/graphml"
+)
+
+type yamlDoc struct {
+	Keys   []yamlKey   `yaml:"keys,omitempty"`
+	Data   yamlData    `yaml:"data,omitempty"`
+	Graphs []yamlGraph `yaml:"graphs"`
+}
+
+type yamlKey struct {
+	ID      string     `yaml:"id"`
+	For     string     `yaml:"for,omitempty"`
+	Name    string     `yaml:"name,omitempty"`
+	Type    string     `yaml:"type,omitempty"`
+	Default *yaml.Node `yaml:"default,omitempty"`
+}
+
+type yamlGraph struct {
+	ID       string     `yaml:"id,omitempty"`
+	Directed *bool      `yaml:"directed,omitempty"`
+	Data     yamlData   `yaml:"data,omitempty"`
+	Nodes    []yamlNode `yaml:"nodes,omitempty"`
+	Edges    []yamlEdge `yaml:"edges,omitempty"`
+}
+
+type yamlNode struct {
+	ID     string      `yaml:"id"`
+	Data   yamlData    `yaml:"data,omitempty"`
+	Graphs []yamlGraph `yaml:"graphs,omitempty"`
+}
+
+type yamlEdge struct {
+	ID       string   `yaml:"id,omitempty"`
+	Source   string   `yaml:"source"`
+	Target   string   `yaml:"target"`
+	Directed *bool    `yaml:"directed,omitempty"`
+	Data     yamlData `yaml:"data,omitempty"`
+}
+
+// yamlData is a mapping of attribute names to values that preserves the order.
+type yamlData []yamlValue
+
+type yamlValue struct {
+	Name  string
+	Value *yaml.Node
+}
+
+func (d yamlData) MarshalYAML() (interface{}, error) {
+	m := &yaml.Node{Kind: yaml.MappingNode}
+	for _, v := range d {
+		k := new(yaml.Node)
+		k.SetString(v.Name)
+		m.Content = append(m.Content, k, v.Value)
+	}
+	return m, nil
+}
+
+func (d *yamlData) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return fmt.Errorf("yaml: line %d: data must be a mapping", n.Line)
+	}
+	*d = nil
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		*d = append(*d, yamlValue{Name: n.Content[i].Value, Value: n.Content[i+1]})
+	}
+	return nil
+}
+
+// yamlScalar converts a text value to a YAML scalar according to the GraphML type.
+func yamlScalar(typ, v string) *yaml.Node {
+	n := new(yaml.Node)
+	plain := false
+	switch typ {
+	case "boolean":
+		_, err := strconv.ParseBool(v)
+		plain = err == nil && (v == "true" || v == "false")
+	case "int", "long":
+		_, err := strconv.ParseInt(v, 10, 64)
+		plain = err == nil
+	case "float", "double":
+		_, err := strconv.ParseFloat(v, 64)
+		plain = err == nil
+	}
+	if plain {
+		n.Kind, n.Value = yaml.ScalarNode, v
+	} else {
+		n.SetString(v)
+	}
+	return n
+}
+
+// ToYAML writes the document as YAML.
+//
+// Keys are written as a list, while data of graphs, nodes and edges are written as mappings of attribute names
+// (or key IDs, if the name is not set) to values. Only simple text values are supported, other data is skipped.
+func ToYAML(w io.Writer, doc *graphml.Document) error {
+	keys := newKeyIndex(doc)
+	data := func(kind graphml.Kind, data []graphml.Data) yamlData {
+		var out yamlData
+		for _, a := range keys.Attrs(kind, data) {
+			typ := ""
+			if a.Key != nil {
+				typ = a.Key.Type
+			}
+			out = append(out, yamlValue{Name: a.Name, Value: yamlScalar(typ, a.Value)})
+		}
+		return out
+	}
+	var graphs func(list []graphml.Graph) []yamlGraph
+	graphs = func(list []graphml.Graph) []yamlGraph {
+		var out []yamlGraph
+		for i := range list {
+			g := &list[i]
+			yg := yamlGraph{ID: g.ID, Data: data(graphml.KindGraph, g.Data)}
+			if g.EdgeDefault != "" {
+				dir := g.IsDirected()
+				yg.Directed = &dir
+			}
+			for _, n := range g.Nodes {
+				yg.Nodes = append(yg.Nodes, yamlNode{
+					ID: n.ID, Data: data(graphml.KindNode, n.Data),
+					Graphs: graphs(n.Graphs),
+				})
+			}
+			for j := range g.Edges {
+				e := &g.Edges[j]
+				ye := yamlEdge{ID: e.ID, Source: e.Source, Target: e.Target, Data: data(graphml.KindEdge, e.Data)}
+				for _, a := range e.Unrecognized {
+					if a.Name.Local == "directed" {
+						dir := edgeDirected(g, e)
+						ye.Directed = &dir
+					}
+				}
+				yg.Edges = append(yg.Edges, ye)
+			}
+			out = append(out, yg)
+		}
+		return out
+	}
+	yd := yamlDoc{Data: data(graphml.KindGraphML, doc.Data), Graphs: graphs(doc.Graphs)}
+	for _, k := range doc.Keys {
+		yk := yamlKey{ID: k.ID, For: string(k.For), Name: k.Name, Type: k.Type}
+		if k.Default != nil {
+			if def, ok := graphml.TokensText(k.Default); ok {
+				yk.Default = yamlScalar(k.Type, def)
+			}
+		}
+		yd.Keys = append(yd.Keys, yk)
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(yd); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// yamlType returns a GraphML type for a YAML scalar.
+func yamlType(n *yaml.Node) graphml.AttrType {
+	switch n.ShortTag() {
+	case "!!bool":
+		return graphml.TypeBoolean
+	case "!!int":
+		if _, err := strconv.ParseInt(n.Value, 0, 32); err != nil {
+			return graphml.TypeLong
+		}
+		return graphml.TypeInt
+	case "!!float":
+		return graphml.TypeDouble
+	}
+	return graphml.TypeString
+}
+
+// yamlMergeType returns a type compatible with both of the given types.
+func yamlMergeType(a, b string) string {
+	switch {
+	case a == b:
+		return a
+	case (a == "int" || a == "long") && (b == "int" || b == "long"):
+		return "long"
+	case (a == "int" || a == "long" || a == "double") && (b == "int" || b == "long" || b == "double"):
+		return "double"
+	}
+	return "string"
+}
+
+// FromYAML reads a document in YAML format written by ToYAML.
+//
+// Attributes that are not declared in the keys section get new keys, with types inferred from the values.
+func FromYAML(r io.Reader) (*graphml.Document, error) {
+	var yd yamlDoc
+	if err := yaml.NewDecoder(r).Decode(&yd); err != nil && err != io.EOF {
+		return nil, err
+	}
+	doc := newDocument()
+	keyIDs := newIDSet()
+	byName := make(map[kindID]int)
+	for _, yk := range yd.Keys {
+		if yk.ID == "" {
+			return nil, errors.New("yaml: key without an id")
+		}
+		kind := graphml.Kind(yk.For)
+		if kind == "" {
+			kind = graphml.KindAll
+		}
+		k := graphml.NewKey(kind, yk.ID, yk.Name, graphml.AttrType(yk.Type))
+		k.For = graphml.Kind(yk.For)
+		if yk.Default != nil {
+			if yk.Default.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("yaml: line %d: default value must be a scalar", yk.Default.Line)
+			}
+			k.Default = textData("", yk.Default.Value).Data
+		}
+		keyIDs.Reserve(k.ID)
+		doc.Keys = append(doc.Keys, k)
+		byName[kindID{kind: kind, id: keyName(&k)}] = len(doc.Keys) - 1
+	}
+	declared := len(doc.Keys)
+	data := func(kind graphml.Kind, yd yamlData) ([]graphml.Data, error) {
+		var out []graphml.Data
+		for _, v := range yd {
+			if v.Value.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("yaml: line %d: value of %q must be a scalar", v.Value.Line, v.Name)
+			} else if v.Value.ShortTag() == "!!null" {
+				continue
+			}
+			i, ok := byName[kindID{kind: kind, id: v.Name}]
+			if !ok {
+				i, ok = byName[kindID{kind: graphml.KindAll, id: v.Name}]
+			}
+			if !ok {
+				// not declared, create a new key
+				k := graphml.NewKey(kind, keyIDs.Unique("d"), v.Name, yamlType(v.Value))
+				doc.Keys = append(doc.Keys, k)
+				i = len(doc.Keys) - 1
+				byName[kindID{kind: kind, id: v.Name}] = i
+			} else if i >= declared {
+				k := &doc.Keys[i]
+				k.Type = yamlMergeType(k.Type, string(yamlType(v.Value)))
+			}
+			out = append(out, textData(doc.Keys[i].ID, v.Value.Value))
+		}
+		return out, nil
+	}
+	var graphs func(list []yamlGraph) ([]graphml.Graph, error)
+	graphs = func(list []yamlGraph) ([]graphml.Graph, error) {
+		var out []graphml.Graph
+		for _, yg := range list {
+			var (
+				g   graphml.Graph
+				err error
+			)
+			g.ID = yg.ID
+			if yg.Directed != nil {
+				g.EdgeDefault = graphml.EdgeUndirected
+				if *yg.Directed {
+					g.EdgeDefault = graphml.EdgeDirected
+				}
+			}
+			if g.Data, err = data(graphml.KindGraph, yg.Data); err != nil {
+				return nil, err
+			}
+			for _, yn := range yg.Nodes {
+				var n graphml.Node
+				n.ID = yn.ID
+				if n.Data, err = data(graphml.KindNode, yn.Data); err != nil {
+					return nil, err
+				}
+				if n.Graphs, err = graphs(yn.Graphs); err != nil {
+					return nil, err
+				}
+				g.Nodes = append(g.Nodes, n)
+			}
+			for _, ye := range yg.Edges {
+				var e graphml.Edge
+				e.ID, e.Source, e.Target = ye.ID, ye.Source, ye.Target
+				if ye.Directed != nil {
+					e.Unrecognized = append(e.Unrecognized, directedAttr(*ye.Directed))
+				}
+				if e.Data, err = data(graphml.KindEdge, ye.Data); err != nil {
+					return nil, err
+				}
+				g.Edges = append(g.Edges, e)
+			}
+			out = append(out, g)
+		}
+		return out, nil
+	}
+	var err error
+	if doc.Data, err = data(graphml.KindGraphML, yd.Data); err != nil {
+		return nil, err
+	}
+	if doc.Graphs, err = graphs(yd.Graphs); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -3,10 +3,15 @@ package graphml
 import (
 	"bytes"
 	"encoding/xml"
-	"fmt"
 	"io"
+	"strconv"
+	"strings"
 )
 
+// MaxDepth is the maximal nesting level of graphs accepted by the decoder.
+// It protects the decoder (and recursive functions that process decoded documents) from exhausting the stack.
+const MaxDepth = 1000
+
 // Decode reads a GraphML document from the stream.
 func Decode(r io.Reader) (*Document, error) {
 	dec := xml.NewDecoder(r)
@@ -15,16 +20,35 @@ func Decode(r io.Reader) (*Document, error) {
 
 // DecodeFrom is similar to Decode, but allows to specify a custom XML decoder.
 func DecodeFrom(dec *xml.Decoder) (*Document, error) {
-	b := &docDecoder{
+	b := newDocDecoder()
+	if err := b.DecodeFrom(dec); err != nil {
+		return nil, err
+	}
+	return b.doc, nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler. It allows to decode GraphML documents embedded into other XML structures.
+//
+// The start element is treated as the root GraphML element, regardless of its name.
+func (doc *Document) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	b := newDocDecoder()
+	b.dec = dec
+	b.doc.Attrs = start.Copy().Attr
+	if err := b.decodeRoot(start); err != nil {
+		return b.wrapErr(err)
+	}
+	*doc = *b.doc
+	return nil
+}
+
+func newDocDecoder() *docDecoder {
+	return &docDecoder{
 		doc:     new(Document),
 		keysAll: make(map[string]Key),
 		keys:    make(map[docKey]Key),
 		ids:     make(map[string]struct{}),
+		path:    []decodeLevel{{}},
 	}
-	if err := b.DecodeFrom(dec); err != nil {
-		return nil, err
-	}
-	return b.doc, nil
 }
 
 func canSkip(t xml.Token) bool {
@@ -44,37 +68,133 @@ type docKey struct {
 	kind Kind
 }
 
+// decodeLevel is a state of a single element on the decoder stack.
+type decodeLevel struct {
+	name   string
+	index  int
+	counts [5]int // number of children, see levelSlot
+}
+
+// levelSlot returns an index of the child counter for elements with a given name.
+func levelSlot(name string) int {
+	switch name {
+	case "key":
+		return 0
+	case "graph":
+		return 1
+	case "node":
+		return 2
+	case "edge":
+		return 3
+	}
+	return 4
+}
+
 type docDecoder struct {
 	dec     *xml.Decoder
 	keysAll map[string]Key
 	keys    map[docKey]Key
 	ids     map[string]struct{}
 	lastID  int
+	depth   int // nesting level of graphs
+	// lax enables workarounds for documents written by other tools:
+	// elements without a namespace and data for undeclared keys are accepted
+	lax bool
+	// strict makes the decoder reject values of enumerated attributes that are not defined by the specification
+	strict bool
+	// sv validates elements as they are decoded, if set
+	sv *streamValidator
+	// path is a stack of elements being decoded; it is left as is when decoding fails
+	path []decodeLevel
+	// trace is called for each token and for errors, if set
+	trace func(ev TraceEvent)
+	// warn is called for problems accepted in lax mode, if set
+	warn func(w Warning)
+	// warnedNS and warnedKeys prevent repeated warnings
+	warnedNS   bool
+	warnedKeys map[docKey]struct{}
+	// positions records positions of elements, if set; pos is a position of the last token
+	positions PositionMap
+	pos       Position
+	stats     CodecStats
 
 	doc *Document
 }
 
-func (d *docDecoder) token() (xml.Token, error) {
-	return d.dec.Token()
+// isML checks if the element name belongs to GraphML namespace.
+func (d *docDecoder) isML(name xml.Name) bool {
+	if name.Space == Namespace {
+		return true
+	} else if !d.lax || name.Space != "" {
+		return false
+	}
+	if !d.warnedNS {
+		d.warnedNS = true
+		d.warnf(WarnMissingNamespace, "element %q has no GraphML namespace", name.Local)
+	}
+	return true
 }
-func (d *docDecoder) expectEnd(tok xml.Name) error {
-	for {
-		t, err := d.token()
-		if err == io.EOF {
-			return io.ErrUnexpectedEOF
-		} else if err != nil {
-			return err
-		} else if canSkip(t) {
-			continue
-		}
-		switch t := t.(type) {
-		case xml.EndElement:
-			if t.Name == tok {
-				return nil
-			}
+
+// enter must be called when an element starts. The element must be closed with leave.
+func (d *docDecoder) enter(name, id string) {
+	top := &d.path[len(d.path)-1]
+	slot := levelSlot(name)
+	i := top.counts[slot]
+	top.counts[slot]++
+	d.path = append(d.path, decodeLevel{name: name, index: i})
+	d.stats.count(name)
+	if d.positions != nil {
+		d.positions[d.pathString()] = d.pos
+	}
+	d.sv.enter(name, id)
+}
+func (d *docDecoder) leave() {
+	d.path = d.path[:len(d.path)-1]
+	d.sv.leave()
+}
+
+// pathString returns a path of the current element, for example graph[0]/node[132]/data[2].
+func (d *docDecoder) pathString() string {
+	var sb strings.Builder
+	for _, l := range d.path[1:] {
+		if sb.Len() != 0 {
+			sb.WriteByte('/')
 		}
-		return fmt.Errorf("unexpected token: %T: %#v", t, t)
+		sb.WriteString(l.name)
+		sb.WriteByte('[')
+		sb.WriteString(strconv.Itoa(l.index))
+		sb.WriteByte(']')
 	}
+	return sb.String()
+}
+
+// wrapErr adds a path and a position of the current element to the error.
+func (d *docDecoder) wrapErr(err error) error {
+	e := DecodeError{Path: d.pathString(), Offset: d.dec.InputOffset(), Err: err}
+	e.Line, e.Column = d.dec.InputPos()
+	if d.trace != nil {
+		d.trace(TraceEvent{Type: TraceError, Name: xml.Name{Local: d.path[len(d.path)-1].name}, Offset: e.Offset})
+	}
+	return e
+}
+
+func (d *docDecoder) token() (xml.Token, error) {
+	if d.sv != nil {
+		d.sv.setPos(d.dec.InputPos())
+	}
+	if d.positions != nil {
+		d.pos.Offset = d.dec.InputOffset()
+		d.pos.Line, d.pos.Column = d.dec.InputPos()
+	}
+	if d.trace == nil {
+		return d.dec.Token()
+	}
+	off := d.dec.InputOffset()
+	t, err := d.dec.Token()
+	if err == nil {
+		d.traceToken(t, off)
+	}
+	return t, err
 }
 func (d *docDecoder) startGraphML() (xml.StartElement, error) {
 	for {
@@ -91,33 +211,43 @@ func (d *docDecoder) startGraphML() (xml.StartElement, error) {
 			d.doc.Instr = t.Copy()
 			continue
 		case xml.StartElement:
-			if t.Name.Local == "graphml" && t.Name.Space == Namespace {
+			if t.Name.Local == "graphml" && d.isML(t.Name) {
 				d.doc.Attrs = t.Copy().Attr
 				return t, nil
 			}
+			return xml.StartElement{}, ErrUnexpectedElement{Name: t.Name}
 		}
-		return xml.StartElement{}, fmt.Errorf("unexpected token: %T: %#v", t, t)
+		return xml.StartElement{}, ErrUnexpectedToken{Token: xml.CopyToken(t)}
 	}
 }
 func (d *docDecoder) DecodeFrom(dec *xml.Decoder) error {
 	d.dec = dec
 	start, err := d.startGraphML()
+	if err == nil {
+		err = d.decodeRoot(start)
+	}
 	if err != nil {
-		return err
+		return d.wrapErr(err)
 	}
+	return nil
+}
+func (d *docDecoder) decodeRoot(start xml.StartElement) error {
 	for {
 		t, err := d.token()
 		if err == io.EOF {
 			return io.ErrUnexpectedEOF
 		} else if err != nil {
 			return err
+		} else if c, ok := t.(xml.Comment); ok {
+			d.doc.Comments = append(d.doc.Comments, c.Copy())
+			continue
 		} else if canSkip(t) {
 			continue
 		}
 		switch t := t.(type) {
 		case xml.StartElement:
-			if t.Name.Space != Namespace {
-				return fmt.Errorf("unexpected element: %v", t.Name)
+			if !d.isML(t.Name) {
+				return ErrUnexpectedElement{Name: t.Name}
 			}
 			switch t.Name.Local {
 			case "key":
@@ -126,10 +256,12 @@ func (d *docDecoder) DecodeFrom(dec *xml.Decoder) error {
 				}
 			case "graph":
 				g, err := d.decodeGraph(t)
+				if g != nil {
+					d.doc.Graphs = append(d.doc.Graphs, *g)
+				}
 				if err != nil {
 					return err
 				}
-				d.doc.Graphs = append(d.doc.Graphs, *g)
 			case "data":
 				data, err := d.decodeData(KindGraphML, t)
 				if err != nil {
@@ -137,7 +269,7 @@ func (d *docDecoder) DecodeFrom(dec *xml.Decoder) error {
 				}
 				d.doc.Data = append(d.doc.Data, *data)
 			default:
-				return fmt.Errorf("unknown element: %v", t.Name)
+				return ErrUnknownElement{Name: t.Name}
 			}
 			continue
 		case xml.EndElement:
@@ -145,7 +277,7 @@ func (d *docDecoder) DecodeFrom(dec *xml.Decoder) error {
 				return nil
 			}
 		}
-		return fmt.Errorf("unexpected token: %T: %#v", t, t)
+		return ErrUnexpectedToken{Token: xml.CopyToken(t)}
 	}
 }
 func (d *docDecoder) decodeKey(start xml.StartElement) error {
@@ -153,50 +285,102 @@ func (d *docDecoder) decodeKey(start xml.StartElement) error {
 	for _, a := range start.Attr {
 		k.addAttr(a)
 	}
+	d.enter("key", k.ID)
+	if d.strict {
+		if _, err := ParseKind(string(k.For)); err != nil {
+			return err
+		}
+	}
 	if k.For == "" {
 		k.For = KindAll
 	}
 	if k.For == KindAll {
 		if _, ok := d.keysAll[k.ID]; ok {
-			return fmt.Errorf("redefinition of key %q", k.ID)
+			return ErrDuplicateKey{ID: k.ID, For: k.For}
 		}
 		d.keysAll[k.ID] = k
 	} else {
 		dk := docKey{name: k.ID, kind: k.For}
 		if _, ok := d.keys[dk]; ok {
-			return fmt.Errorf("redefinition of key %q for %v", k.ID, k.For)
+			return ErrDuplicateKey{ID: k.ID, For: k.For}
 		}
 		d.keys[dk] = k
 	}
-	d.doc.Keys = append(d.doc.Keys, k)
-	if err := d.expectEnd(start.Name); err != nil {
-		return err
+	for {
+		t, err := d.token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		} else if canSkip(t) {
+			continue
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			if !d.isML(t.Name) || t.Name.Local != "default" {
+				return ErrUnexpectedElement{Name: t.Name}
+			}
+			k.Default, err = d.decodeRaw(t)
+			if err != nil {
+				return err
+			} else if k.Default == nil {
+				k.Default = []xml.Token{}
+			}
+			continue
+		case xml.EndElement:
+			if t.Name == start.Name {
+				if err := d.sv.key(&k); err != nil {
+					return err
+				}
+				d.leave()
+				d.doc.Keys = append(d.doc.Keys, k)
+				return nil
+			}
+		}
+		return ErrUnexpectedToken{Token: xml.CopyToken(t)}
 	}
-	return nil
 }
 func (d *docDecoder) addID(id string) (string, error) {
 	if id == "" {
 		return "", nil
 	}
 	if _, ok := d.ids[id]; ok {
-		return "", fmt.Errorf("redefinition of id %q", id)
+		return "", ErrDuplicateID{ID: id}
 	}
 	d.ids[id] = struct{}{}
 	return id, nil
 }
+
+// decodeGraph decodes a graph element. Like decodeNode and decodeEdge, it returns the element decoded so far
+// together with an error, unless the element itself is invalid.
 func (d *docDecoder) decodeGraph(start xml.StartElement) (*Graph, error) {
 	var g Graph
 	for _, a := range start.Attr {
 		g.addAttr(a)
 	}
+	d.enter("graph", g.ID)
+	if d.strict && g.EdgeDefault != "" {
+		if _, err := ParseEdgeDir(string(g.EdgeDefault)); err != nil {
+			return nil, err
+		}
+	}
 	var err error
 	g.ID, err = d.addID(g.ID)
 	if err != nil {
 		return nil, err
 	}
+	if d.depth >= MaxDepth {
+		return nil, ErrTooDeep
+	}
+	d.depth++
+	defer func() { d.depth-- }()
 	if err := d.decodeGraphNodes(&g, start); err != nil {
-		return nil, err
+		return &g, err
+	}
+	if err := d.sv.graph(&g); err != nil {
+		return &g, err
 	}
+	d.leave()
 	return &g, nil
 }
 func (d *docDecoder) decodeGraphNodes(g *Graph, start xml.StartElement) error {
@@ -211,8 +395,8 @@ func (d *docDecoder) decodeGraphNodes(g *Graph, start xml.StartElement) error {
 		}
 		switch t := t.(type) {
 		case xml.StartElement:
-			if t.Name.Space != Namespace {
-				return fmt.Errorf("unexpected element: %v", t.Name)
+			if !d.isML(t.Name) {
+				return ErrUnexpectedElement{Name: t.Name}
 			}
 			switch t.Name.Local {
 			case "data":
@@ -223,18 +407,22 @@ func (d *docDecoder) decodeGraphNodes(g *Graph, start xml.StartElement) error {
 				g.Data = append(g.Data, *data)
 			case "node":
 				n, err := d.decodeNode(t)
+				if n != nil {
+					g.Nodes = append(g.Nodes, *n)
+				}
 				if err != nil {
 					return err
 				}
-				g.Nodes = append(g.Nodes, *n)
 			case "edge":
 				e, err := d.decodeEdge(t)
+				if e != nil {
+					g.Edges = append(g.Edges, *e)
+				}
 				if err != nil {
 					return err
 				}
-				g.Edges = append(g.Edges, *e)
 			default:
-				return fmt.Errorf("unknown element: %v", t.Name)
+				return ErrUnknownElement{Name: t.Name}
 			}
 			continue
 		case xml.EndElement:
@@ -242,7 +430,7 @@ func (d *docDecoder) decodeGraphNodes(g *Graph, start xml.StartElement) error {
 				return nil
 			}
 		}
-		return fmt.Errorf("unexpected token: %T: %#v", t, t)
+		return ErrUnexpectedToken{Token: xml.CopyToken(t)}
 	}
 }
 func (d *docDecoder) decodeData(kind Kind, start xml.StartElement) (*Data, error) {
@@ -250,11 +438,39 @@ func (d *docDecoder) decodeData(kind Kind, start xml.StartElement) (*Data, error
 	for _, a := range start.Attr {
 		data.addAttr(a)
 	}
-	if _, ok := d.keys[docKey{name: data.Key, kind: kind}]; !ok {
-		if _, ok = d.keysAll[data.Key]; !ok {
-			return nil, fmt.Errorf("unexpected attr for %v: %q", kind, data.Key)
+	d.enter("data", "")
+	dk := docKey{name: data.Key, kind: kind}
+	if _, ok := d.keys[dk]; !ok {
+		if _, ok = d.keysAll[data.Key]; !ok && !d.lax {
+			return nil, ErrUnknownKey{Key: data.Key, Kind: kind}
+		} else if _, warned := d.warnedKeys[dk]; !ok && !warned {
+			if d.warnedKeys == nil {
+				d.warnedKeys = make(map[docKey]struct{})
+			}
+			d.warnedKeys[dk] = struct{}{}
+			d.warnf(WarnUndeclaredKey, "key %q is not declared for %v", data.Key, kind)
 		}
 	}
+	var err error
+	data.Data, err = d.decodeRaw(start)
+	if err != nil {
+		return nil, err
+	}
+	if err = data.decodeValue(); err != nil {
+		// the content is kept as raw tokens, as for namespaces without a codec
+		d.warnf(WarnCodecFailed, "%v", err)
+	}
+	if err = d.sv.data(kind, &data); err != nil {
+		return nil, err
+	}
+	d.leave()
+	return &data, nil
+}
+
+// decodeRaw reads all tokens until the end of the current element.
+func (d *docDecoder) decodeRaw(start xml.StartElement) ([]xml.Token, error) {
+	var out []xml.Token
+	depth := 0
 	for {
 		t, err := d.token()
 		if err == io.EOF {
@@ -263,13 +479,17 @@ func (d *docDecoder) decodeData(kind Kind, start xml.StartElement) (*Data, error
 			return nil, err
 		}
 		switch e := t.(type) {
+		case xml.StartElement:
+			depth++
 		case xml.EndElement:
-			if e.Name == start.Name {
-				return &data, nil
+			if depth == 0 && e.Name == start.Name {
+				return out, nil
 			}
+			depth--
 		}
 		t = xml.CopyToken(t)
-		data.Data = append(data.Data, t)
+		out = append(out, t)
+		d.stats.Tokens++
 	}
 }
 func (d *docDecoder) decodeNode(start xml.StartElement) (*Node, error) {
@@ -277,6 +497,7 @@ func (d *docDecoder) decodeNode(start xml.StartElement) (*Node, error) {
 	for _, a := range start.Attr {
 		n.addAttr(a)
 	}
+	d.enter("node", n.ID)
 	var err error
 	n.ID, err = d.addID(n.ID)
 	if err != nil {
@@ -285,40 +506,46 @@ func (d *docDecoder) decodeNode(start xml.StartElement) (*Node, error) {
 	for {
 		t, err := d.token()
 		if err == io.EOF {
-			return nil, io.ErrUnexpectedEOF
+			return &n, io.ErrUnexpectedEOF
 		} else if err != nil {
-			return nil, err
+			return &n, err
 		} else if canSkip(t) {
 			continue
 		}
 		switch t := t.(type) {
 		case xml.StartElement:
-			if t.Name.Space != Namespace {
-				return nil, fmt.Errorf("unexpected element: %v", t.Name)
+			if !d.isML(t.Name) {
+				return &n, ErrUnexpectedElement{Name: t.Name}
 			}
 			switch t.Name.Local {
 			case "data":
 				data, err := d.decodeData(KindNode, t)
 				if err != nil {
-					return nil, err
+					return &n, err
 				}
 				n.Data = append(n.Data, *data)
 			case "graph":
 				g, err := d.decodeGraph(t)
+				if g != nil {
+					n.Graphs = append(n.Graphs, *g)
+				}
 				if err != nil {
-					return nil, err
+					return &n, err
 				}
-				n.Graphs = append(n.Graphs, *g)
 			default:
-				return nil, fmt.Errorf("unknown element: %v", t.Name)
+				return &n, ErrUnknownElement{Name: t.Name}
 			}
 			continue
 		case xml.EndElement:
 			if t.Name == start.Name {
+				if err := d.sv.node(&n); err != nil {
+					return &n, err
+				}
+				d.leave()
 				return &n, nil
 			}
 		}
-		return nil, fmt.Errorf("unexpected token: %T: %#v", t, t)
+		return &n, ErrUnexpectedToken{Token: xml.CopyToken(t)}
 	}
 }
 func (d *docDecoder) decodeEdge(start xml.StartElement) (*Edge, error) {
@@ -326,6 +553,7 @@ func (d *docDecoder) decodeEdge(start xml.StartElement) (*Edge, error) {
 	for _, a := range start.Attr {
 		e.addAttr(a)
 	}
+	d.enter("edge", e.ID)
 	var err error
 	e.ID, err = d.addID(e.ID)
 	if err != nil {
@@ -334,33 +562,37 @@ func (d *docDecoder) decodeEdge(start xml.StartElement) (*Edge, error) {
 	for {
 		t, err := d.token()
 		if err == io.EOF {
-			return nil, io.ErrUnexpectedEOF
+			return &e, io.ErrUnexpectedEOF
 		} else if err != nil {
-			return nil, err
+			return &e, err
 		} else if canSkip(t) {
 			continue
 		}
 		switch t := t.(type) {
 		case xml.StartElement:
-			if t.Name.Space != Namespace {
-				return nil, fmt.Errorf("unexpected element: %v", t.Name)
+			if !d.isML(t.Name) {
+				return &e, ErrUnexpectedElement{Name: t.Name}
 			}
 			switch t.Name.Local {
 			case "data":
 				data, err := d.decodeData(KindEdge, t)
 				if err != nil {
-					return nil, err
+					return &e, err
 				}
 				e.Data = append(e.Data, *data)
 			default:
-				return nil, fmt.Errorf("unknown element: %v", t.Name)
+				return &e, ErrUnknownElement{Name: t.Name}
 			}
 			continue
 		case xml.EndElement:
 			if t.Name == start.Name {
+				if err := d.sv.edge(&e); err != nil {
+					return &e, err
+				}
+				d.leave()
 				return &e, nil
 			}
 		}
-		return nil, fmt.Errorf("unexpected token: %T: %#v", t, t)
+		return &e, ErrUnexpectedToken{Token: xml.CopyToken(t)}
 	}
 }
@@ -0,0 +1,284 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChangeOp is a type of a change between two documents.
+type ChangeOp string
+
+const (
+	ChangeAdd    = ChangeOp("add")
+	ChangeRemove = ChangeOp("remove")
+	ChangeModify = ChangeOp("change")
+)
+
+// Change is a single difference between two documents, see Diff.
+type Change struct {
+	Op ChangeOp `json:"op"`
+	// Element is a name of the changed element: "key", "graph", "node" or "edge".
+	Element string `json:"element"`
+	// ID identifies the element. Graphs and nodes without an ID are identified by their position, for example
+	// "G/node[3]", edges without an ID are identified as "source->target", keys are identified as "kind:name".
+	// See Diff for details.
+	ID string `json:"id"`
+	// Attr is a name of the changed attribute. It is empty if the whole element was added or removed.
+	Attr string `json:"attr,omitempty"`
+	// Old and New are values of the attribute. Old is empty for added attributes, New is empty for removed ones.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+	// Attrs are attributes of an added element.
+	Attrs map[string]string `json:"attrs,omitempty"`
+}
+
+func (c Change) String() string {
+	sign := "~"
+	switch c.Op {
+	case ChangeAdd:
+		sign = "+"
+	case ChangeRemove:
+		sign = "-"
+	}
+	if c.Attr == "" {
+		return sign + " " + c.Element + " " + c.ID
+	}
+	s := sign + " " + c.Element + " " + c.ID + " " + c.Attr + ": "
+	switch c.Op {
+	case ChangeAdd:
+		return s + strconv.Quote(c.New)
+	case ChangeRemove:
+		return s + strconv.Quote(c.Old)
+	}
+	return s + strconv.Quote(c.Old) + " -> " + strconv.Quote(c.New)
+}
+
+// diffElem is a flattened element of a document that is compared by Diff.
+type diffElem struct {
+	element, id string
+	names       []string // attribute names in document order
+	attrs       map[string]string
+}
+
+func (e *diffElem) set(name, value string) {
+	if _, ok := e.attrs[name]; !ok {
+		e.names = append(e.names, name)
+	}
+	e.attrs[name] = value
+}
+
+// setAttrs sets unrecognized XML attributes of the element. Their names are prefixed with "@"
+// to distinguish them from data attributes.
+func (e *diffElem) setAttrs(attrs []xml.Attr) {
+	for _, a := range attrs {
+		e.set("@"+formatName(a.Name), a.Value)
+	}
+}
+
+// diffStep returns a positional ID of an element without an ID, for example "G/node[3]".
+// Top-level graphs have no parent and are identified as "graph[1]".
+func diffStep(parent, name string, i int) string {
+	id := name + "[" + strconv.Itoa(i+1) + "]"
+	if parent != "" {
+		id = parent + "/" + id
+	}
+	return id
+}
+
+// Diff compares two documents and returns a list of added, removed and changed elements and attributes
+// needed to turn document a into document b.
+//
+// Graphs and nodes are matched by ID, or by their position in the parent element if they have no ID.
+// Edges are matched by ID, or by their source and target if they have no ID; parallel edges without an ID get
+// an occurrence suffix, for example "a->b#2". Keys are matched by the kind of elements and the attribute name
+// (or the key ID, if the name is not set), and data is compared by attribute names, thus documents that use
+// different key IDs for the same attributes are considered equal.
+// Changes of keys are reported for their type and default value. Nodes and edges also have a "graph" attribute
+// with the ID of their graph, and nested graphs have a "parent" attribute with the ID of their node.
+// Unrecognized XML attributes are compared as attributes with the "@" prefix, for example "@directed",
+// and attributes of data elements are compared as "name/@attr". The root element is reported as "graphml"
+// with an empty ID; besides its data and attributes, it has the XML declaration ("instr") and top-level
+// comments ("comment[1]", ...).
+func Diff(a, b *Document) []Change {
+	ea, eb := diffElems(a), diffElems(b)
+	type ref struct{ element, id string }
+	ib := make(map[ref]*diffElem, len(eb))
+	for _, e := range eb {
+		ib[ref{e.element, e.id}] = e
+	}
+	seen := make(map[ref]struct{}, len(ea))
+	var out []Change
+	for _, e1 := range ea {
+		r := ref{e1.element, e1.id}
+		seen[r] = struct{}{}
+		e2, ok := ib[r]
+		if !ok {
+			out = append(out, Change{Op: ChangeRemove, Element: e1.element, ID: e1.id})
+			continue
+		}
+		for _, name := range e1.names {
+			v1 := e1.attrs[name]
+			v2, ok := e2.attrs[name]
+			if !ok {
+				out = append(out, Change{Op: ChangeRemove, Element: e1.element, ID: e1.id, Attr: name, Old: v1})
+			} else if v1 != v2 {
+				out = append(out, Change{Op: ChangeModify, Element: e1.element, ID: e1.id, Attr: name, Old: v1, New: v2})
+			}
+		}
+		for _, name := range e2.names {
+			if _, ok := e1.attrs[name]; !ok {
+				out = append(out, Change{Op: ChangeAdd, Element: e1.element, ID: e1.id, Attr: name, New: e2.attrs[name]})
+			}
+		}
+	}
+	for _, e := range eb {
+		if _, ok := seen[ref{e.element, e.id}]; !ok {
+			c := Change{Op: ChangeAdd, Element: e.element, ID: e.id}
+			if len(e.attrs) != 0 {
+				c.Attrs = e.attrs
+			}
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Equal reports whether the documents are semantically equal, in other words, if Diff reports no changes for them.
+func Equal(a, b *Document) bool {
+	return len(Diff(a, b)) == 0
+}
+
+// diffName returns a name that identifies the key in Diff.
+func (k *Key) diffName() string {
+	if k.Name != "" {
+		return k.Name
+	} else if k.YFilesType != "" {
+		return k.YFilesType
+	}
+	return k.ID
+}
+
+// diffElems flattens the document into a list of elements with their attributes.
+func diffElems(doc *Document) []*diffElem {
+	var out []*diffElem
+	newElem := func(element, id string) *diffElem {
+		e := &diffElem{element: element, id: id, attrs: make(map[string]string)}
+		out = append(out, e)
+		return e
+	}
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		kind := k.For
+		if kind == "" {
+			kind = KindAll
+		}
+		e := newElem("key", string(kind)+":"+k.diffName())
+		if k.Type != "" {
+			e.set("attr.type", k.Type)
+		}
+		if k.YFilesType != "" {
+			e.set("yfiles.type", k.YFilesType)
+		}
+		if k.Default != nil {
+			e.set("default", tokensString(k.Default))
+		}
+		e.setAttrs(k.Unrecognized)
+	}
+	data := func(e *diffElem, kind Kind, data []Data) {
+		for _, d := range data {
+			name := d.Key
+			if k := doc.dataKey(kind, d.Key); k != nil {
+				name = k.diffName()
+			}
+			e.set(name, tokensString(d.Data))
+			for _, a := range d.Unrecognized {
+				e.set(name+"/@"+formatName(a.Name), a.Value)
+			}
+		}
+	}
+	edges := make(map[string]int)
+	var visit func(parent string, graphs []Graph)
+	visit = func(parent string, graphs []Graph) {
+		for i := range graphs {
+			g := &graphs[i]
+			gid := g.ID
+			if gid == "" {
+				gid = diffStep(parent, "graph", i)
+			}
+			e := newElem("graph", gid)
+			if parent != "" {
+				e.set("parent", parent)
+			}
+			if g.EdgeDefault != "" {
+				e.set("edgedefault", string(g.EdgeDefault))
+			}
+			e.setAttrs(g.Unrecognized)
+			data(e, KindGraph, g.Data)
+			for j := range g.Nodes {
+				n := &g.Nodes[j]
+				nid := n.ID
+				if nid == "" {
+					nid = diffStep(gid, "node", j)
+				}
+				e := newElem("node", nid)
+				e.set("graph", gid)
+				e.setAttrs(n.Unrecognized)
+				data(e, KindNode, n.Data)
+				visit(nid, n.Graphs)
+			}
+			for j := range g.Edges {
+				ed := &g.Edges[j]
+				id := ed.ID
+				if id == "" {
+					id = ed.Source + "->" + ed.Target
+					edges[id]++
+					if n := edges[id]; n > 1 {
+						id += "#" + strconv.Itoa(n)
+					}
+				}
+				e := newElem("edge", id)
+				e.set("graph", gid)
+				if ed.ID != "" {
+					e.set("source", ed.Source)
+					e.set("target", ed.Target)
+				}
+				e.setAttrs(ed.Unrecognized)
+				data(e, KindEdge, ed.Data)
+			}
+		}
+	}
+	visit("", doc.Graphs)
+	root := &diffElem{element: "graphml", attrs: make(map[string]string)}
+	if doc.Instr.Target != "" {
+		root.set("instr", doc.Instr.Target+" "+string(doc.Instr.Inst))
+	}
+	root.setAttrs(doc.Attrs)
+	for i, c := range doc.Comments {
+		root.set("comment["+strconv.Itoa(i+1)+"]", string(c))
+	}
+	data(root, KindGraphML, doc.Data)
+	if len(root.names) != 0 {
+		out = append(out, root)
+	}
+	return out
+}
+
+// tokensString returns the text of the XML content, or the content encoded as XML if it has any elements.
+func tokensString(toks []xml.Token) string {
+	if s, ok := TokensText(toks); ok {
+		return s
+	}
+	var sb strings.Builder
+	enc := xml.NewEncoder(&sb)
+	for _, t := range toks {
+		if err := enc.EncodeToken(t); err != nil {
+			return fmt.Sprint(toks)
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return fmt.Sprint(toks)
+	}
+	return sb.String()
+}
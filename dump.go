@@ -0,0 +1,168 @@
+package graphml
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DumpOptions controls the output of Document.Dump.
+type DumpOptions struct {
+	// MaxElements limits the number of nodes and edges listed for each graph. Zero means no limit.
+	MaxElements int
+	// MaxValueLen limits the length of attribute values, in characters. Longer values are truncated.
+	// Zero means no limit.
+	MaxValueLen int
+	// NoData omits attributes of elements.
+	NoData bool
+}
+
+// Dump writes a human-readable tree of the document: keys, graphs with node and edge counts, and nodes and edges
+// with their attributes. Attributes are printed by their names (see Key.Name), values are printed as text,
+// or as XML if they are not plain text. Nil options write the whole document.
+//
+// The format is meant for debugging and is not stable.
+func (doc *Document) Dump(w io.Writer, opt *DumpOptions) error {
+	if opt == nil {
+		opt = &DumpOptions{}
+	}
+	d := &dumper{w: bufio.NewWriter(w), doc: doc, opt: opt}
+	d.line(0, "graphml: "+plural(len(doc.Keys), "key")+", "+plural(len(doc.Graphs), "graph"))
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		var sb strings.Builder
+		sb.WriteString("key " + k.ID + ":")
+		kind := k.For
+		if kind == "" {
+			kind = KindAll
+		}
+		sb.WriteString(" for=" + string(kind))
+		if k.Name != "" {
+			sb.WriteString(" name=" + strconv.Quote(k.Name))
+		}
+		if k.Type != "" {
+			sb.WriteString(" type=" + k.Type)
+		}
+		if k.YFilesType != "" {
+			sb.WriteString(" yfiles.type=" + k.YFilesType)
+		}
+		if k.Default != nil {
+			sb.WriteString(" default=" + d.value(tokensString(k.Default)))
+		}
+		d.line(1, sb.String())
+	}
+	d.data(1, KindGraphML, doc.Data)
+	d.graphs(1, doc.Graphs)
+	return d.w.Flush()
+}
+
+// String returns a concise tree of the document, see Dump. Long lists of elements and long values are truncated.
+func (doc *Document) String() string {
+	var sb strings.Builder
+	_ = doc.Dump(&sb, &DumpOptions{MaxElements: 50, MaxValueLen: 60})
+	return sb.String()
+}
+
+type dumper struct {
+	w   *bufio.Writer
+	doc *Document
+	opt *DumpOptions
+}
+
+func (d *dumper) line(depth int, s string) {
+	for i := 0; i < depth; i++ {
+		d.w.WriteString("  ")
+	}
+	d.w.WriteString(s)
+	d.w.WriteByte('\n')
+}
+
+// value formats an attribute value, truncating it if necessary.
+func (d *dumper) value(s string) string {
+	if n := d.opt.MaxValueLen; n > 0 && utf8.RuneCountInString(s) > n {
+		s = string([]rune(s)[:n]) + "..."
+	}
+	return strconv.Quote(s)
+}
+
+// attrs formats data of an element as a list of name=value pairs.
+func (d *dumper) attrs(kind Kind, data []Data) string {
+	if d.opt.NoData {
+		return ""
+	}
+	var sb strings.Builder
+	for _, dt := range data {
+		name := dt.Key
+		if k := d.doc.dataKey(kind, dt.Key); k != nil {
+			name = k.diffName()
+		}
+		sb.WriteString(" " + name + "=" + d.value(tokensString(dt.Data)))
+	}
+	return sb.String()
+}
+
+// data writes data of the root element on a separate line.
+func (d *dumper) data(depth int, kind Kind, data []Data) {
+	if s := d.attrs(kind, data); s != "" {
+		d.line(depth, "data:"+s)
+	}
+}
+
+func (d *dumper) graphs(depth int, graphs []Graph) {
+	for i := range graphs {
+		g := &graphs[i]
+		name := "graph"
+		if g.ID != "" {
+			name += " " + g.ID
+		}
+		dir := "undirected"
+		if g.IsDirected() {
+			dir = "directed"
+		}
+		d.line(depth, name+": "+dir+", "+plural(len(g.Nodes), "node")+", "+plural(len(g.Edges), "edge")+d.attrs(KindGraph, g.Data))
+		for j := range g.Nodes {
+			if d.limit(depth+1, j, len(g.Nodes), "node") {
+				break
+			}
+			n := &g.Nodes[j]
+			d.line(depth+1, "node "+n.ID+d.attrs(KindNode, n.Data))
+			d.graphs(depth+2, n.Graphs)
+		}
+		for j := range g.Edges {
+			if d.limit(depth+1, j, len(g.Edges), "edge") {
+				break
+			}
+			e := &g.Edges[j]
+			s := "edge "
+			if e.ID != "" {
+				s += e.ID + " "
+			}
+			arrow := " -- "
+			if e.EffectiveDirection(g) == EdgeDirected {
+				arrow = " -> "
+			}
+			d.line(depth+1, s+e.Source+arrow+e.Target+d.attrs(KindEdge, e.Data))
+		}
+	}
+}
+
+// limit checks if the i-th element of a list exceeds the MaxElements limit. If so, it writes the number of
+// remaining elements.
+func (d *dumper) limit(depth, i, total int, name string) bool {
+	if d.opt.MaxElements <= 0 || i < d.opt.MaxElements {
+		return false
+	}
+	d.line(depth, "... "+plural(total-i, "more "+name))
+	return true
+}
+
+// plural formats a number of items.
+func plural(n int, name string) string {
+	s := strconv.Itoa(n) + " " + name
+	if n != 1 {
+		s += "s"
+	}
+	return s
+}
@@ -3,6 +3,8 @@ package graphml
 import (
 	"encoding/xml"
 	"io"
+	"sort"
+	"strings"
 )
 
 // Encode writes a GraphML document to the stream.
@@ -20,6 +22,34 @@ func EncodeTo(enc *xml.Encoder, doc *Document) error {
 	return enc.Flush()
 }
 
+// MarshalXML implements xml.Marshaler. It allows to embed GraphML documents into other XML structures.
+//
+// The document is written as a single element with a name from start (or "graphml", if it is empty).
+// Processing instruction of the document is not written. The GraphML namespace is declared on the element
+// if the document has no default namespace.
+func (doc *Document) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	name := mlName(start.Name.Local)
+	if name.Local == "" {
+		name = mlName("graphml")
+	}
+	attrs := doc.Attrs
+	hasNS := false
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == "xmlns" {
+			hasNS = true
+			break
+		}
+	}
+	if !hasNS {
+		attrs = append([]xml.Attr{newAttr("", "xmlns", Namespace)}, attrs...)
+	}
+	d := &docEncoder{enc: enc}
+	return d.encodeRoot(doc, name, attrs)
+}
+
+// xmlNamespace is a namespace of the reserved xml prefix.
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
 func mlName(name string) xml.Name {
 	return xml.Name{Local: name}
 }
@@ -27,36 +57,199 @@ func mlName(name string) xml.Name {
 type docEncoder struct {
 	enc *xml.Encoder
 	err error
+	// prefixes maps namespaces declared on the root element to their prefixes.
+	prefixes map[string]string
+
+	// indent is written before GraphML elements on each level of nesting, if set.
+	indent    string
+	sortAttrs bool
+	depth     int
+	last      lastToken
+
+	// sv validates elements as they are encoded, if set
+	sv    *streamValidator
+	stats CodecStats
 }
 
+// lastToken is a kind of the last written token, used for indentation.
+type lastToken int
+
+const (
+	lastNone = lastToken(iota)
+	lastStart
+	lastEnd
+	lastRaw
+)
+
 func (d *docEncoder) token(t xml.Token) error {
+	if st, ok := t.(xml.StartElement); ok {
+		st.Attr = d.prefixAttrs(st.Attr)
+		t = st
+	}
 	if d.err == nil {
 		d.err = d.enc.EncodeToken(t)
 	}
 	return d.err
 }
+
+// prefixAttrs writes names of namespace declarations and attributes from declared namespaces with a prefix.
+// Otherwise, the XML encoder would generate a new prefix and declaration for each of them.
+func (d *docEncoder) prefixAttrs(attrs []xml.Attr) []xml.Attr {
+	var out []xml.Attr
+	for i, a := range attrs {
+		var prefix string
+		switch a.Name.Space {
+		case "", xmlNamespace:
+			// the encoder handles these correctly
+		case "xmlns":
+			prefix = "xmlns"
+		default:
+			prefix = d.prefixes[a.Name.Space]
+		}
+		if prefix == "" {
+			if out != nil {
+				out = append(out, a)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]xml.Attr, i, len(attrs))
+			copy(out, attrs)
+		}
+		out = append(out, xml.Attr{Name: xml.Name{Local: prefix + ":" + a.Name.Local}, Value: a.Value})
+	}
+	if out == nil {
+		return attrs
+	}
+	return out
+}
+
+// prefixName returns a prefixed name for elements from namespaces declared on the root element.
+func (d *docEncoder) prefixName(name xml.Name) xml.Name {
+	if p := d.prefixes[name.Space]; p != "" {
+		return xml.Name{Local: p + ":" + name.Local}
+	}
+	return name
+}
+
+// rawToken writes a token of the custom XML content. Elements from namespaces declared on the root element
+// are written with the same prefix. Other namespaced elements are declared by the XML encoder itself,
+// thus a matching xmlns attribute of the element is dropped to avoid declaring it twice.
+func (d *docEncoder) rawToken(t xml.Token) error {
+	d.last = lastRaw
+	d.stats.Tokens++
+	switch t := t.(type) {
+	case xml.StartElement:
+		if t.Name.Space == "" {
+			return d.token(t)
+		}
+		attrs := make([]xml.Attr, 0, len(t.Attr))
+		for _, a := range t.Attr {
+			if a.Name.Space == "" && a.Name.Local == "xmlns" && a.Value == t.Name.Space {
+				continue
+			}
+			attrs = append(attrs, a)
+		}
+		t.Attr = attrs
+		t.Name = d.prefixName(t.Name)
+		return d.token(t)
+	case xml.EndElement:
+		t.Name = d.prefixName(t.Name)
+		return d.token(t)
+	}
+	return d.token(t)
+}
+
+// newline writes a line break and indentation for the current level of nesting, if indentation is enabled.
+func (d *docEncoder) newline() error {
+	if d.indent == "" || d.last == lastNone {
+		return nil
+	}
+	return d.token(xml.CharData("\n" + strings.Repeat(d.indent, d.depth)))
+}
+
+// attrsByName sorts attributes by local name. It doesn't use sort.Slice to avoid reflection in TinyGo builds.
+type attrsByName []xml.Attr
+
+func (a attrsByName) Len() int           { return len(a) }
+func (a attrsByName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a attrsByName) Less(i, j int) bool { return a[i].Name.Local < a[j].Name.Local }
+
 func (d *docEncoder) start(name xml.Name, attrs []xml.Attr) error {
+	if err := d.newline(); err != nil {
+		return err
+	}
+	if d.sortAttrs {
+		attrs = d.prefixAttrs(attrs)
+		attrs = append([]xml.Attr{}, attrs...)
+		sort.Stable(attrsByName(attrs))
+	}
+	d.depth++
+	d.last = lastStart
 	return d.token(xml.StartElement{Name: name, Attr: attrs})
 }
 func (d *docEncoder) end(name xml.Name) error {
+	d.depth--
+	if d.last == lastEnd {
+		// only break lines after nested GraphML elements, custom content is written as-is
+		if err := d.newline(); err != nil {
+			return err
+		}
+	}
+	d.last = lastEnd
 	return d.token(xml.EndElement{Name: name})
 }
 func (d *docEncoder) startEnd(name xml.Name, attrs []xml.Attr) error {
-	t := xml.StartElement{Name: name, Attr: attrs}
-	if err := d.token(t); err != nil {
+	if err := d.start(name, attrs); err != nil {
 		return err
 	}
-	return d.token(t.End())
+	return d.end(name)
 }
+func (d *docEncoder) comment(c xml.Comment) error {
+	if err := d.newline(); err != nil {
+		return err
+	}
+	d.last = lastEnd
+	return d.token(c)
+}
+
 func (d *docEncoder) Encode(doc *Document) error {
-	if err := d.token(doc.Instr); err != nil {
+	if doc.Instr.Target != "" {
+		if err := d.token(doc.Instr); err != nil {
+			return err
+		}
+		d.last = lastEnd
+	}
+	if err := d.encodeRoot(doc, mlName("graphml"), doc.Attrs); err != nil {
+		return err
+	}
+	if err := d.sv.finish(); err != nil {
 		return err
 	}
-	if err := d.start(mlName("graphml"), doc.Attrs); err != nil {
+	if d.indent != "" {
+		return d.token(xml.CharData("\n"))
+	}
+	return nil
+}
+func (d *docEncoder) encodeRoot(doc *Document, name xml.Name, attrs []xml.Attr) error {
+	for _, a := range attrs {
+		if a.Name.Space == "xmlns" {
+			if d.prefixes == nil {
+				d.prefixes = make(map[string]string)
+			}
+			d.prefixes[a.Value] = a.Name.Local
+		}
+	}
+	if err := d.start(name, attrs); err != nil {
 		return err
 	}
+	for _, c := range doc.Comments {
+		if err := d.comment(c); err != nil {
+			return err
+		}
+	}
 	for _, k := range doc.Keys {
-		if err := d.startEnd(mlName("key"), k.attrs()); err != nil {
+		if err := d.encodeKey(&k); err != nil {
 			return err
 		}
 	}
@@ -65,18 +258,54 @@ func (d *docEncoder) Encode(doc *Document) error {
 			return err
 		}
 	}
-	if err := d.encodeData(doc.Data); err != nil {
+	if err := d.encodeData(KindGraphML, doc.Data); err != nil {
 		return err
 	}
-	return d.end(mlName("graphml"))
+	return d.end(name)
 }
-func (d *docEncoder) encodeData(data []Data) error {
+func (d *docEncoder) encodeKey(k *Key) error {
+	d.stats.Keys++
+	d.sv.enter("key", k.ID)
+	defer d.sv.leave()
+	if err := d.sv.key(k); err != nil {
+		return err
+	}
+	if err := checkKind(k.For); err != nil {
+		return err
+	}
+	if k.Default == nil {
+		return d.startEnd(mlName("key"), k.attrs())
+	}
+	if err := d.start(mlName("key"), k.attrs()); err != nil {
+		return err
+	}
+	if err := d.start(mlName("default"), nil); err != nil {
+		return err
+	}
+	for _, t := range k.Default {
+		if err := d.rawToken(t); err != nil {
+			return err
+		}
+	}
+	if err := d.end(mlName("default")); err != nil {
+		return err
+	}
+	return d.end(mlName("key"))
+}
+func (d *docEncoder) encodeData(kind Kind, data []Data) error {
 	for _, dt := range data {
+		d.stats.Data++
+		d.sv.enter("data", "")
+		err := d.sv.data(kind, &dt)
+		d.sv.leave()
+		if err != nil {
+			return err
+		}
 		if err := d.start(mlName("data"), dt.attrs()); err != nil {
 			return err
 		}
 		for _, t := range dt.Data {
-			if err := d.token(t); err != nil {
+			if err := d.rawToken(t); err != nil {
 				return err
 			}
 		}
@@ -87,10 +316,19 @@ func (d *docEncoder) encodeData(data []Data) error {
 	return nil
 }
 func (d *docEncoder) encodeGraph(g *Graph) error {
+	d.stats.Graphs++
+	d.sv.enter("graph", g.ID)
+	defer d.sv.leave()
+	if err := d.sv.graph(g); err != nil {
+		return err
+	}
+	if err := checkEdgeDir(g.EdgeDefault); err != nil {
+		return err
+	}
 	if err := d.start(mlName("graph"), g.attrs()); err != nil {
 		return err
 	}
-	if err := d.encodeData(g.Data); err != nil {
+	if err := d.encodeData(KindGraph, g.Data); err != nil {
 		return err
 	}
 	for _, n := range g.Nodes {
@@ -106,10 +344,16 @@ func (d *docEncoder) encodeGraph(g *Graph) error {
 	return d.end(mlName("graph"))
 }
 func (d *docEncoder) encodeNode(n *Node) error {
+	d.stats.Nodes++
+	d.sv.enter("node", n.ID)
+	defer d.sv.leave()
+	if err := d.sv.node(n); err != nil {
+		return err
+	}
 	if err := d.start(mlName("node"), n.attrs()); err != nil {
 		return err
 	}
-	if err := d.encodeData(n.Data); err != nil {
+	if err := d.encodeData(KindNode, n.Data); err != nil {
 		return err
 	}
 	for _, g := range n.Graphs {
@@ -120,10 +364,16 @@ func (d *docEncoder) encodeNode(n *Node) error {
 	return d.end(mlName("node"))
 }
 func (d *docEncoder) encodeEdge(e *Edge) error {
+	d.stats.Edges++
+	d.sv.enter("edge", e.ID)
+	defer d.sv.leave()
+	if err := d.sv.edge(e); err != nil {
+		return err
+	}
 	if err := d.start(mlName("edge"), e.attrs()); err != nil {
 		return err
 	}
-	if err := d.encodeData(e.Data); err != nil {
+	if err := d.encodeData(KindEdge, e.Data); err != nil {
 		return err
 	}
 	return d.end(mlName("edge"))
@@ -0,0 +1,142 @@
+package graphml
+
+import (
+	"fmt"
+	"strings"
+)
+
+// kinds are all element kinds defined by the specification.
+var kinds = []string{
+	string(KindAll), string(KindGraphML), string(KindGraph), string(KindNode),
+	string(KindEdge), string(KindHyperEdge), string(KindPort), string(KindEndpoint),
+}
+
+// edgeDirs are all edge directions defined by the specification.
+var edgeDirs = []string{string(EdgeDirected), string(EdgeUndirected)}
+
+// attrTypes are all attribute types defined by the specification.
+var attrTypes = []string{
+	string(TypeBoolean), string(TypeInt), string(TypeLong), string(TypeFloat), string(TypeDouble), string(TypeString),
+}
+
+// ParseAttrType checks if the string is an attribute type defined by the specification and returns it.
+// An empty string is accepted and means that the attribute has no declared type.
+// For other values, an ErrInvalidValue is returned, with a suggestion if the value looks like a misspelled type.
+func ParseAttrType(s string) (AttrType, error) {
+	if s == "" || hasString(attrTypes, s) {
+		return AttrType(s), nil
+	}
+	return "", ErrInvalidValue{Attr: "attr.type", Value: s, Suggestion: suggest(s, attrTypes)}
+}
+
+// validKind checks if the kind is defined by the specification. An empty kind is valid and means KindAll.
+func validKind(k Kind) bool {
+	return k == "" || hasString(kinds, string(k))
+}
+
+// validEdgeDir checks if the edge direction is defined by the specification. An empty direction is valid.
+func validEdgeDir(d EdgeDir) bool {
+	return d == "" || hasString(edgeDirs, string(d))
+}
+
+// ParseKind checks if the string is an element kind defined by the specification and returns it.
+// An empty string is accepted and returns KindAll, the default of the "for" attribute.
+// For other values, an ErrInvalidValue is returned, with a suggestion if the value looks like a misspelled kind.
+func ParseKind(s string) (Kind, error) {
+	if s == "" {
+		return KindAll, nil
+	} else if hasString(kinds, s) {
+		return Kind(s), nil
+	}
+	return "", ErrInvalidValue{Attr: "for", Value: s, Suggestion: suggest(s, kinds)}
+}
+
+// ParseEdgeDir checks if the string is an edge direction defined by the specification and returns it.
+// For other values, including an empty string, an ErrInvalidValue is returned, with a suggestion
+// if the value looks like a misspelled direction.
+func ParseEdgeDir(s string) (EdgeDir, error) {
+	if hasString(edgeDirs, s) {
+		return EdgeDir(s), nil
+	}
+	return "", ErrInvalidValue{Attr: "edgedefault", Value: s, Suggestion: suggest(s, edgeDirs)}
+}
+
+// checkKind returns an error if the kind is not defined by the specification. An empty kind is valid.
+func checkKind(k Kind) error {
+	_, err := ParseKind(string(k))
+	return err
+}
+
+// checkEdgeDir returns an error if the edge direction is not defined by the specification. An empty direction is valid.
+func checkEdgeDir(d EdgeDir) error {
+	if d == "" {
+		return nil
+	}
+	_, err := ParseEdgeDir(string(d))
+	return err
+}
+
+// suggest returns an option that is most likely meant instead of an invalid value s, or an empty string.
+// It accepts options that differ by case, by a plural suffix, by abbreviation or by a couple of typos.
+func suggest(s string, options []string) string {
+	ls := strings.ToLower(strings.TrimSpace(s))
+	for _, o := range options {
+		if ls == o || strings.TrimSuffix(ls, "s") == o {
+			return o
+		}
+	}
+	if len(ls) >= 3 {
+		for _, o := range options {
+			if strings.HasPrefix(o, ls) || strings.HasPrefix(ls, o) {
+				return o
+			}
+		}
+	}
+	best, dist := "", 3
+	for _, o := range options {
+		if d := editDistance(ls, o); d < dist && d < len(o) {
+			best, dist = o, d
+		}
+	}
+	return best
+}
+
+// didYouMean returns a suffix for error messages with a suggestion for an invalid value, if any.
+func didYouMean(s string, options []string) string {
+	if o := suggest(s, options); o != "" {
+		return fmt.Sprintf(", did you mean %q?", o)
+	}
+	return ""
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions of adjacent
+// characters needed to turn one string into another.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			c := d[i-1][j-1]
+			if a[i-1] != b[j-1] {
+				c++
+			}
+			if v := d[i-1][j] + 1; v < c {
+				c = v
+			}
+			if v := d[i][j-1] + 1; v < c {
+				c = v
+			}
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && d[i-2][j-2]+1 < c {
+				c = d[i-2][j-2] + 1
+			}
+			d[i][j] = c
+		}
+	}
+	return d[len(a)][len(b)]
+}
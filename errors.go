@@ -0,0 +1,146 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	// ErrMissingNamespace is reported for GraphML elements without the GraphML namespace.
+	// Such documents are accepted by DecodeWith with ProfileGephi.
+	ErrMissingNamespace = errors.New("graphml: element without GraphML namespace")
+	// ErrTooDeep is returned when graphs are nested deeper than MaxDepth.
+	ErrTooDeep = errors.New("graphs are nested too deeply (more than " + strconv.Itoa(MaxDepth) + " levels)")
+)
+
+// ErrDuplicateID is returned when a graph, node or edge reuses an ID of another element.
+type ErrDuplicateID struct {
+	ID string
+}
+
+func (e ErrDuplicateID) Error() string {
+	return fmt.Sprintf("redefinition of id %q", e.ID)
+}
+
+// ErrDuplicateKey is returned when a key reuses an ID of another key for the same kind of elements.
+type ErrDuplicateKey struct {
+	ID  string
+	For Kind
+}
+
+func (e ErrDuplicateKey) Error() string {
+	if e.For == KindAll {
+		return fmt.Sprintf("redefinition of key %q", e.ID)
+	}
+	return fmt.Sprintf("redefinition of key %q for %v", e.ID, e.For)
+}
+
+// ErrUnknownKey is returned when data references a key that is not declared for the kind of elements.
+type ErrUnknownKey struct {
+	Key  string
+	Kind Kind
+}
+
+func (e ErrUnknownKey) Error() string {
+	return fmt.Sprintf("unexpected attr for %v: %q", e.Kind, e.Key)
+}
+
+// ErrUnexpectedElement is returned for elements that are not allowed at the current position,
+// including elements from other namespaces. It matches ErrMissingNamespace if the element has no namespace.
+type ErrUnexpectedElement struct {
+	Name xml.Name
+}
+
+func (e ErrUnexpectedElement) Error() string {
+	return fmt.Sprintf("unexpected element: %v", e.Name)
+}
+
+func (e ErrUnexpectedElement) Unwrap() error {
+	if e.Name.Space == "" {
+		return ErrMissingNamespace
+	}
+	return nil
+}
+
+// ErrUnknownElement is returned for elements of the GraphML namespace that are not supported at the current position,
+// for example ports and hyperedges.
+type ErrUnknownElement struct {
+	Name xml.Name
+}
+
+func (e ErrUnknownElement) Error() string {
+	return fmt.Sprintf("unknown element: %v", e.Name)
+}
+
+// ErrUnexpectedToken is returned for XML tokens that are not allowed at the current position, for example text.
+type ErrUnexpectedToken struct {
+	Token xml.Token
+}
+
+func (e ErrUnexpectedToken) Error() string {
+	return fmt.Sprintf("unexpected token: %T: %#v", e.Token, e.Token)
+}
+
+// ErrNoCodec is returned when a typed value is set for a namespace without a registered codec.
+type ErrNoCodec struct {
+	Namespace string
+}
+
+func (e ErrNoCodec) Error() string {
+	return fmt.Sprintf("no codec registered for namespace %q", e.Namespace)
+}
+
+// ErrInvalidValue is returned by the encoder for values of enumerated attributes that are not defined
+// by the specification, such as Key.For and Graph.EdgeDefault.
+type ErrInvalidValue struct {
+	Attr  string
+	Value string
+	// Suggestion is a valid value that was likely meant instead, if any.
+	Suggestion string
+}
+
+func (e ErrInvalidValue) Error() string {
+	s := fmt.Sprintf("invalid %s value %q", e.Attr, e.Value)
+	if e.Suggestion != "" {
+		s += fmt.Sprintf(", did you mean %q?", e.Suggestion)
+	}
+	return s
+}
+
+// ErrValidation is returned by the decoder or the encoder when a validation rule reports an error.
+// See Options.Validator and Options.Strict.
+type ErrValidation struct {
+	Finding Finding
+}
+
+func (e ErrValidation) Error() string {
+	return fmt.Sprintf("validation failed: %v", e.Finding)
+}
+
+// DecodeError is returned by the decoder. It records a location of the element that failed to decode.
+// The underlying error is available via errors.Is and errors.As.
+type DecodeError struct {
+	// Path is a location of the element in the document, for example graph[0]/node[132]/data[2].
+	// Elements are counted from zero, separately for each element name. It is empty for errors
+	// outside of the root element.
+	Path string
+	// Line, Column and Offset is a position in the source document where decoding stopped.
+	Line   int
+	Column int
+	Offset int64
+	Err    error
+}
+
+func (e DecodeError) Error() string {
+	pos := fmt.Sprintf("line %d, column %d, offset %d", e.Line, e.Column, e.Offset)
+	if e.Path == "" {
+		return pos + ": " + e.Err.Error()
+	}
+	return e.Path + " (" + pos + "): " + e.Err.Error()
+}
+
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,284 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Clone returns a deep copy of the document.
+func (d *Document) Clone() *Document {
+	if d == nil {
+		return nil
+	}
+	out := &Document{
+		Instr:  d.Instr.Copy(),
+		Attrs:  cloneAttrs(d.Attrs),
+		Graphs: cloneGraphs(d.Graphs),
+		Data:   cloneData(d.Data),
+
+		Incomplete: d.Incomplete,
+	}
+	if d.Comments != nil {
+		out.Comments = make([]xml.Comment, len(d.Comments))
+		for i, c := range d.Comments {
+			out.Comments[i] = c.Copy()
+		}
+	}
+	if d.Keys != nil {
+		out.Keys = make([]Key, len(d.Keys))
+		for i, k := range d.Keys {
+			k.Object = k.Object.clone()
+			k.Default = cloneTokens(k.Default)
+			out.Keys[i] = k
+		}
+	}
+	return out
+}
+
+func cloneAttrs(attrs []xml.Attr) []xml.Attr {
+	if attrs == nil {
+		return nil
+	}
+	return append([]xml.Attr{}, attrs...)
+}
+
+func (o Object) clone() Object {
+	o.Unrecognized = cloneAttrs(o.Unrecognized)
+	return o
+}
+
+func (o ExtObject) clone() ExtObject {
+	o.Object = o.Object.clone()
+	o.Data = cloneData(o.Data)
+	return o
+}
+
+func cloneData(data []Data) []Data {
+	if data == nil {
+		return nil
+	}
+	out := make([]Data, len(data))
+	for i, d := range data {
+		d.Unrecognized = cloneAttrs(d.Unrecognized)
+		d.Data = cloneTokens(d.Data)
+		out[i] = d
+	}
+	return out
+}
+
+func cloneTokens(toks []xml.Token) []xml.Token {
+	if toks == nil {
+		return nil
+	}
+	out := make([]xml.Token, len(toks))
+	for i, t := range toks {
+		out[i] = xml.CopyToken(t)
+	}
+	return out
+}
+
+func cloneGraphs(graphs []Graph) []Graph {
+	if graphs == nil {
+		return nil
+	}
+	out := make([]Graph, len(graphs))
+	for i, g := range graphs {
+		g.ExtObject = g.ExtObject.clone()
+		if g.Nodes != nil {
+			nodes := make([]Node, len(g.Nodes))
+			for j, n := range g.Nodes {
+				n.ExtObject = n.ExtObject.clone()
+				n.Graphs = cloneGraphs(n.Graphs)
+				nodes[j] = n
+			}
+			g.Nodes = nodes
+		}
+		if g.Edges != nil {
+			edges := make([]Edge, len(g.Edges))
+			for j, e := range g.Edges {
+				e.ExtObject = e.ExtObject.clone()
+				edges[j] = e
+			}
+			g.Edges = edges
+		}
+		out[i] = g
+	}
+	return out
+}
+
+// Freeze returns a read-only snapshot of the document with an index for fast lookups.
+//
+// The view is safe for concurrent use by multiple goroutines. Changes made to the document
+// after the call are not visible in the view. To modify a frozen document, use View.Thaw
+// to get a private copy, change it and freeze it again.
+func (d *Document) Freeze() *View {
+	v := &View{
+		doc:    d.Clone(),
+		graphs: make(map[string]*Graph),
+		nodes:  make(map[string]*Node),
+		edges:  make(map[string]*Edge),
+		nodeG:  make(map[*Node]*Graph),
+		edgeG:  make(map[*Edge]*Graph),
+		graphN: make(map[*Graph]*Node),
+		out:    make(map[string][]*Edge),
+		in:     make(map[string][]*Edge),
+		keys:   make(map[docKey]*Key),
+	}
+	for i := range v.doc.Keys {
+		k := &v.doc.Keys[i]
+		dk := docKey{name: k.ID, kind: k.For}
+		if dk.kind == "" {
+			dk.kind = KindAll
+		}
+		if _, ok := v.keys[dk]; !ok {
+			v.keys[dk] = k
+		}
+	}
+	v.indexGraphs(nil, v.doc.Graphs)
+	return v
+}
+
+// View is an immutable snapshot of a Document, created by Document.Freeze.
+//
+// All the objects returned by the view are shared between readers and must not be modified.
+type View struct {
+	doc *Document
+
+	graphs map[string]*Graph
+	nodes  map[string]*Node
+	edges  map[string]*Edge
+	nodeG  map[*Node]*Graph
+	edgeG  map[*Edge]*Graph
+	graphN map[*Graph]*Node
+	out    map[string][]*Edge
+	in     map[string][]*Edge
+	keys   map[docKey]*Key
+}
+
+func (v *View) indexGraphs(parent *Node, graphs []Graph) {
+	for i := range graphs {
+		g := &graphs[i]
+		if g.ID != "" {
+			v.graphs[g.ID] = g
+		}
+		if parent != nil {
+			v.graphN[g] = parent
+		}
+		for j := range g.Nodes {
+			n := &g.Nodes[j]
+			if n.ID != "" {
+				v.nodes[n.ID] = n
+			}
+			v.nodeG[n] = g
+			v.indexGraphs(n, n.Graphs)
+		}
+		for j := range g.Edges {
+			e := &g.Edges[j]
+			if e.ID != "" {
+				v.edges[e.ID] = e
+			}
+			v.edgeG[e] = g
+			v.out[e.Source] = append(v.out[e.Source], e)
+			v.in[e.Target] = append(v.in[e.Target], e)
+		}
+	}
+}
+
+// Document returns the frozen document. It must not be modified.
+func (v *View) Document() *Document {
+	return v.doc
+}
+
+// Thaw returns a private copy of the document that can be modified freely.
+func (v *View) Thaw() *Document {
+	return v.doc.Clone()
+}
+
+// Encode writes the frozen document to the stream.
+func (v *View) Encode(w io.Writer) error {
+	return Encode(w, v.doc)
+}
+
+// Graph returns a graph with a given ID.
+func (v *View) Graph(id string) (*Graph, bool) {
+	g, ok := v.graphs[id]
+	return g, ok
+}
+
+// Node returns a node with a given ID.
+func (v *View) Node(id string) (*Node, bool) {
+	n, ok := v.nodes[id]
+	return n, ok
+}
+
+// Edge returns an edge with a given ID.
+func (v *View) Edge(id string) (*Edge, bool) {
+	e, ok := v.edges[id]
+	return e, ok
+}
+
+// GraphNode returns a node that contains a given nested graph, or nil for top-level graphs.
+func (v *View) GraphNode(g *Graph) *Node {
+	return v.graphN[g]
+}
+
+// ParentGraph returns a graph that contains the node with a given nested graph, or nil for top-level graphs.
+func (v *View) ParentGraph(g *Graph) *Graph {
+	if n := v.graphN[g]; n != nil {
+		return v.nodeG[n]
+	}
+	return nil
+}
+
+// ParentNode returns a node that contains the graph with a given node, or nil for nodes of top-level graphs.
+func (v *View) ParentNode(n *Node) *Node {
+	return v.graphN[v.nodeG[n]]
+}
+
+// NodeGraph returns a graph that contains a given node.
+func (v *View) NodeGraph(n *Node) *Graph {
+	return v.nodeG[n]
+}
+
+// EdgeGraph returns a graph that contains a given edge.
+func (v *View) EdgeGraph(e *Edge) *Graph {
+	return v.edgeG[e]
+}
+
+// Out returns all edges with a given source node.
+func (v *View) Out(node string) []*Edge {
+	return v.out[node]
+}
+
+// In returns all edges with a given target node.
+func (v *View) In(node string) []*Edge {
+	return v.in[node]
+}
+
+// Key returns a key definition for a given element kind. Keys defined for all elements are used as a fallback.
+func (v *View) Key(kind Kind, id string) (*Key, bool) {
+	if k, ok := v.keys[docKey{name: id, kind: kind}]; ok {
+		return k, true
+	}
+	k, ok := v.keys[docKey{name: id, kind: KindAll}]
+	return k, ok
+}
+
+// Walk calls fn for each node in the document, including nodes of nested graphs.
+// Walk stops if fn returns false.
+func (v *View) Walk(fn func(g *Graph, n *Node) bool) {
+	walkNodes(v.doc.Graphs, fn)
+}
+
+func walkNodes(graphs []Graph, fn func(g *Graph, n *Node) bool) bool {
+	for i := range graphs {
+		g := &graphs[i]
+		for j := range g.Nodes {
+			n := &g.Nodes[j]
+			if !fn(g, n) || !walkNodes(n.Graphs, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package graphml
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// ExtGzip is a file extension for gzip-compressed GraphML files.
+const ExtGzip = Ext + ".gz"
+
+// IsGraphMLFile reports if the file name has a GraphML extension (either Ext or ExtGzip).
+func IsGraphMLFile(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, Ext) || strings.HasSuffix(name, ExtGzip)
+}
+
+// DecodeFS reads a GraphML document from a file in the file system.
+// Files with ExtGzip extension are decompressed automatically.
+func DecodeFS(fsys fs.FS, path string) (*Document, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(strings.ToLower(path), ExtGzip) {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	doc, err := Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return doc, nil
+}
+
+// WalkFunc is a function called by WalkGraphML for each GraphML file.
+//
+// If the file cannot be decoded, the function is called with a nil document and a non-nil error.
+// Returning an error stops the walk, except for fs.SkipDir, which skips the remaining files
+// in the directory containing the file.
+type WalkFunc func(path string, doc *Document, err error) error
+
+// WalkGraphML walks the file tree rooted at root and decodes all GraphML files (see IsGraphMLFile) found there.
+// Files are visited in lexical order, one at a time, thus only one document is kept in memory.
+func WalkGraphML(fsys fs.FS, root string, fn WalkFunc) error {
+	return fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !IsGraphMLFile(d.Name()) {
+			return nil
+		}
+		doc, err := DecodeFS(fsys, path)
+		return fn(path, doc, err)
+	})
+}
@@ -0,0 +1,132 @@
+package graphml
+
+import (
+	"sort"
+	"strconv"
+)
+
+// gephiEdgeLabel is an attribute name used by Gephi for edge labels.
+const gephiEdgeLabel = "Edge Label"
+
+// gephiKindOrder defines an order of keys in documents written for Gephi.
+var gephiKindOrder = map[Kind]int{
+	KindGraphML: 0,
+	KindGraph:   1,
+	KindNode:    2,
+	KindEdge:    3,
+}
+
+// gephiKeys sorts keys by kind. It doesn't use sort.Slice to avoid reflection in TinyGo builds.
+type gephiKeys []Key
+
+func (a gephiKeys) Len() int      { return len(a) }
+func (a gephiKeys) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a gephiKeys) Less(i, j int) bool {
+	return gephiKeyOrder(a[i].For) < gephiKeyOrder(a[j].For)
+}
+
+func gephiKeyOrder(kind Kind) int {
+	if o, ok := gephiKindOrder[kind]; ok {
+		return o
+	}
+	return len(gephiKindOrder)
+}
+
+// uniqueID returns an ID with a given prefix that is not in the set, and adds it to the set.
+func uniqueID(used map[string]struct{}, prefix string, start int) (string, int) {
+	for i := start; ; i++ {
+		id := prefix + strconv.Itoa(i)
+		if _, ok := used[id]; !ok {
+			used[id] = struct{}{}
+			return id, i + 1
+		}
+	}
+}
+
+// gephiDocument returns a copy of the document, rewritten in a way Gephi importer expects.
+func gephiDocument(doc *Document) *Document {
+	doc = doc.Clone()
+	sort.Stable(gephiKeys(doc.Keys))
+	// Gephi uses attribute names as key IDs
+	ids := make(map[docKey]string)
+	used := make(map[string]struct{})
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if k.For == "" {
+			k.For = KindAll
+		}
+		if k.Name == "" {
+			k.Name = k.ID
+		}
+		id := k.Name
+		switch {
+		case k.For == KindEdge && (k.Name == "label" || k.Name == gephiEdgeLabel):
+			k.Name, k.Type = gephiEdgeLabel, "string"
+			id = "edgelabel"
+		case k.Name == "label":
+			k.Type = "string"
+		case k.For == KindEdge && k.Name == "weight":
+			k.Type = "double"
+		}
+		if _, ok := used[id]; ok {
+			id, _ = uniqueID(used, id, 1)
+		} else {
+			used[id] = struct{}{}
+		}
+		ids[docKey{name: k.ID, kind: k.For}] = id
+		k.ID = id
+	}
+	rename := func(kind Kind, data []Data) {
+		for i := range data {
+			d := &data[i]
+			if id, ok := ids[docKey{name: d.Key, kind: kind}]; ok {
+				d.Key = id
+			} else if id, ok = ids[docKey{name: d.Key, kind: KindAll}]; ok {
+				d.Key = id
+			}
+		}
+	}
+	rename(KindGraphML, doc.Data)
+
+	var graphs []*Graph
+	var collect func(list []Graph)
+	collect = func(list []Graph) {
+		for i := range list {
+			g := &list[i]
+			graphs = append(graphs, g)
+			for j := range g.Nodes {
+				collect(g.Nodes[j].Graphs)
+			}
+		}
+	}
+	collect(doc.Graphs)
+
+	elems := make(map[string]struct{})
+	for _, g := range graphs {
+		elems[g.ID] = struct{}{}
+		for _, n := range g.Nodes {
+			elems[n.ID] = struct{}{}
+		}
+		for _, e := range g.Edges {
+			elems[e.ID] = struct{}{}
+		}
+	}
+	next := 0
+	for _, g := range graphs {
+		if g.EdgeDefault == "" {
+			g.EdgeDefault = EdgeDirected
+		}
+		rename(KindGraph, g.Data)
+		for i := range g.Nodes {
+			rename(KindNode, g.Nodes[i].Data)
+		}
+		for i := range g.Edges {
+			e := &g.Edges[i]
+			rename(KindEdge, e.Data)
+			if e.ID == "" {
+				e.ID, next = uniqueID(elems, "e", next)
+			}
+		}
+	}
+	return doc
+}
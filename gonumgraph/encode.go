@@ -0,0 +1,114 @@
+package gonumgraph
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"gonum.org/v1/gonum/graph"
+
+	"github.com/dennwc/graphml"
+)
+
+// AttrProvider provides values of a GraphML attribute for gonum nodes or edges.
+// See NodeAttr and EdgeAttr.
+type AttrProvider struct {
+	kind graphml.Kind
+	name string
+	typ  string
+	node func(n graph.Node) (string, bool)
+	edge func(e graph.Edge) (string, bool)
+}
+
+// NodeAttr declares a node attribute with a given name and GraphML type.
+// Function fn returns a text value of the attribute for the node, or false if the value is not set.
+func NodeAttr(name, typ string, fn func(n graph.Node) (string, bool)) AttrProvider {
+	return AttrProvider{kind: graphml.KindNode, name: name, typ: typ, node: fn}
+}
+
+// EdgeAttr declares an edge attribute with a given name and GraphML type.
+// Function fn returns a text value of the attribute for the edge, or false if the value is not set.
+func EdgeAttr(name, typ string, fn func(e graph.Edge) (string, bool)) AttrProvider {
+	return AttrProvider{kind: graphml.KindEdge, name: name, typ: typ, edge: fn}
+}
+
+// FromGonum builds a GraphML document from a gonum graph.
+//
+// Nodes get "n<id>" IDs and edges get "e<number>" IDs, in the order of gonum node IDs.
+// A key is declared for each attribute provider. If the graph is weighted, edge weights are stored in the
+// WeightAttr attribute, unless a provider with the same name is given. Graphs that implement graph.Undirected
+// are converted to undirected GraphML graphs, and each of their edges is written once.
+func FromGonum(g graph.Graph, attrs ...AttrProvider) *graphml.Document {
+	doc := &graphml.Document{
+		Instr: xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)},
+		Attrs: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: graphml.Namespace}},
+	}
+	_, weighted := g.(graph.Weighted)
+	for _, a := range attrs {
+		if a.kind == graphml.KindEdge && a.name == WeightAttr {
+			weighted = false
+		}
+	}
+	if weighted {
+		attrs = append(attrs, EdgeAttr(WeightAttr, "double", func(e graph.Edge) (string, bool) {
+			we, ok := e.(graph.WeightedEdge)
+			if !ok {
+				return "", false
+			}
+			return strconv.FormatFloat(we.Weight(), 'g', -1, 64), true
+		}))
+	}
+	keys := make([]string, len(attrs))
+	for i, a := range attrs {
+		keys[i] = "d" + strconv.Itoa(i)
+		doc.Keys = append(doc.Keys, graphml.NewKey(a.kind, keys[i], a.name, graphml.AttrType(a.typ)))
+	}
+	data := func(kind graphml.Kind, get func(a AttrProvider) (string, bool)) []graphml.Data {
+		var out []graphml.Data
+		for i, a := range attrs {
+			if a.kind != kind {
+				continue
+			}
+			if v, ok := get(a); ok {
+				out = append(out, graphml.NewStringData(keys[i], v))
+			}
+		}
+		return out
+	}
+	nodeID := func(id int64) string {
+		return "n" + strconv.FormatInt(id, 10)
+	}
+	_, undirected := g.(graph.Undirected)
+	out := graphml.Graph{EdgeDefault: graphml.EdgeDirected}
+	out.ID = "G"
+	if undirected {
+		out.EdgeDefault = graphml.EdgeUndirected
+	}
+	nodes := graph.NodesOf(g.Nodes())
+	ordered(nodes)
+	for _, n := range nodes {
+		var gn graphml.Node
+		gn.ID = nodeID(n.ID())
+		gn.Data = data(graphml.KindNode, func(a AttrProvider) (string, bool) { return a.node(n) })
+		out.Nodes = append(out.Nodes, gn)
+	}
+	for _, u := range nodes {
+		to := graph.NodesOf(g.From(u.ID()))
+		ordered(to)
+		for _, v := range to {
+			if undirected && v.ID() < u.ID() {
+				continue
+			}
+			e := g.Edge(u.ID(), v.ID())
+			if e == nil {
+				continue
+			}
+			var ge graphml.Edge
+			ge.ID = "e" + strconv.Itoa(len(out.Edges))
+			ge.Source, ge.Target = nodeID(u.ID()), nodeID(v.ID())
+			ge.Data = data(graphml.KindEdge, func(a AttrProvider) (string, bool) { return a.edge(e) })
+			out.Edges = append(out.Edges, ge)
+		}
+	}
+	doc.Graphs = []graphml.Graph{out}
+	return doc
+}
@@ -0,0 +1,343 @@
+// Package gonumgraph adapts GraphML documents to gonum graph interfaces.
+package gonumgraph
+
+import (
+	"encoding/xml"
+	"math"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/iterator"
+
+	"github.com/dennwc/graphml"
+)
+
+var (
+	_ graph.Directed           = (*Directed)(nil)
+	_ graph.WeightedDirected   = (*Directed)(nil)
+	_ graph.Undirected         = (*Undirected)(nil)
+	_ graph.WeightedUndirected = (*Undirected)(nil)
+)
+
+// WeightAttr is a name of the attribute used for edge weights.
+const WeightAttr = "weight"
+
+// Node is a gonum node backed by a GraphML node.
+type Node struct {
+	id   int64
+	node *graphml.Node
+	g    *base
+}
+
+// ID returns a gonum ID of the node.
+func (n *Node) ID() int64 { return n.id }
+
+// GraphML returns the GraphML node.
+func (n *Node) GraphML() *graphml.Node { return n.node }
+
+// Attr returns a text value of the node attribute with a given name (or key ID, if the name is not set).
+func (n *Node) Attr(name string) (string, bool) {
+	return n.g.attr(graphml.KindNode, n.node.Data, name)
+}
+
+// Edge is a gonum edge backed by one or more GraphML edges connecting the same nodes.
+type Edge struct {
+	from, to *Node
+	edges    []*graphml.Edge
+	weight   float64
+}
+
+// From returns the source node of the edge.
+func (e *Edge) From() graph.Node { return e.from }
+
+// To returns the target node of the edge.
+func (e *Edge) To() graph.Node { return e.to }
+
+// ReversedEdge returns a new edge with the end points swapped.
+func (e *Edge) ReversedEdge() graph.Edge {
+	return &Edge{from: e.to, to: e.from, edges: e.edges, weight: e.weight}
+}
+
+// Weight returns the weight of the edge.
+func (e *Edge) Weight() float64 { return e.weight }
+
+// GraphML returns all GraphML edges between the nodes. It usually contains a single edge.
+func (e *Edge) GraphML() []*graphml.Edge { return e.edges }
+
+// Attr returns a text value of the attribute of the first GraphML edge.
+func (e *Edge) Attr(name string) (string, bool) {
+	return e.from.g.attr(graphml.KindEdge, e.edges[0].Data, name)
+}
+
+type base struct {
+	view  *graphml.View
+	names map[[2]string]string // (kind, name) -> key ID
+	nodes []*Node
+	ids   map[string]int64
+	from  map[int64]map[int64]*Edge
+	to    map[int64]map[int64]*Edge
+}
+
+func newBase(v *graphml.View, directed bool) *base {
+	g := &base{
+		view:  v,
+		names: make(map[[2]string]string),
+		ids:   make(map[string]int64),
+		from:  make(map[int64]map[int64]*Edge),
+		to:    make(map[int64]map[int64]*Edge),
+	}
+	doc := v.Document()
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		name := k.Name
+		if name == "" {
+			name = k.ID
+		}
+		kind := k.For
+		if kind == "" {
+			kind = graphml.KindAll
+		}
+		nk := [2]string{string(kind), name}
+		if _, ok := g.names[nk]; !ok {
+			g.names[nk] = k.ID
+		}
+	}
+	v.Walk(func(_ *graphml.Graph, n *graphml.Node) bool {
+		if _, ok := g.ids[n.ID]; !ok {
+			g.ids[n.ID] = int64(len(g.nodes))
+			g.nodes = append(g.nodes, &Node{id: int64(len(g.nodes)), node: n, g: g})
+		}
+		return true
+	})
+	v.Walk(func(_ *graphml.Graph, n *graphml.Node) bool {
+		for _, e := range v.Out(n.ID) {
+			src, ok1 := g.ids[e.Source]
+			dst, ok2 := g.ids[e.Target]
+			if !ok1 || !ok2 {
+				continue
+			}
+			g.addEdge(src, dst, e)
+			if (!directed || !edgeDirected(v.EdgeGraph(e), e)) && src != dst {
+				g.addEdge(dst, src, e)
+			}
+		}
+		return true
+	})
+	return g
+}
+
+// edgeDirected reports if the edge is directed, taking the default of the graph into account.
+func edgeDirected(g *graphml.Graph, e *graphml.Edge) bool {
+	return e.EffectiveDirection(g) == graphml.EdgeDirected
+}
+
+func (g *base) addEdge(src, dst int64, e *graphml.Edge) {
+	out := g.from[src]
+	if out == nil {
+		out = make(map[int64]*Edge)
+		g.from[src] = out
+	}
+	if ge := out[dst]; ge != nil {
+		ge.edges = append(ge.edges, e)
+		return
+	}
+	ge := &Edge{from: g.nodes[src], to: g.nodes[dst], edges: []*graphml.Edge{e}, weight: 1}
+	if v, ok := g.attr(graphml.KindEdge, e.Data, WeightAttr); ok {
+		if w, err := strconv.ParseFloat(v, 64); err == nil {
+			ge.weight = w
+		}
+	}
+	out[dst] = ge
+	in := g.to[dst]
+	if in == nil {
+		in = make(map[int64]*Edge)
+		g.to[dst] = in
+	}
+	in[src] = ge
+}
+
+func (g *base) attr(kind graphml.Kind, data []graphml.Data, name string) (string, bool) {
+	id, ok := g.names[[2]string{string(kind), name}]
+	if !ok {
+		id, ok = g.names[[2]string{string(graphml.KindAll), name}]
+	}
+	if !ok {
+		id = name
+	}
+	for _, d := range data {
+		if d.Key != id {
+			continue
+		}
+		var s []byte
+		for _, t := range d.Data {
+			switch t := t.(type) {
+			case xml.CharData:
+				s = append(s, t...)
+			case xml.Comment:
+			default:
+				return "", false
+			}
+		}
+		return string(s), true
+	}
+	return "", false
+}
+
+// ordered sorts nodes by ID.
+func ordered(nodes []graph.Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID() < nodes[j].ID()
+	})
+}
+
+// View returns the frozen GraphML document that backs the graph.
+func (g *base) View() *graphml.View { return g.view }
+
+// NodeID returns a gonum ID for a GraphML node ID.
+func (g *base) NodeID(id string) (int64, bool) {
+	i, ok := g.ids[id]
+	return i, ok
+}
+
+// Node returns the node with the given ID if it exists in the graph, and nil otherwise.
+func (g *base) Node(id int64) graph.Node {
+	if id < 0 || id >= int64(len(g.nodes)) {
+		return nil
+	}
+	return g.nodes[id]
+}
+
+// Nodes returns all the nodes in the graph.
+func (g *base) Nodes() graph.Nodes {
+	if len(g.nodes) == 0 {
+		return graph.Empty
+	}
+	nodes := make([]graph.Node, len(g.nodes))
+	for i, n := range g.nodes {
+		nodes[i] = n
+	}
+	return iterator.NewOrderedNodes(nodes)
+}
+
+func (g *base) adjacent(m map[int64]*Edge) graph.Nodes {
+	if len(m) == 0 {
+		return graph.Empty
+	}
+	nodes := make([]graph.Node, 0, len(m))
+	for id := range m {
+		nodes = append(nodes, g.nodes[id])
+	}
+	ordered(nodes)
+	return iterator.NewOrderedNodes(nodes)
+}
+
+// From returns all nodes that can be reached directly from the node with the given ID.
+func (g *base) From(id int64) graph.Nodes {
+	return g.adjacent(g.from[id])
+}
+
+// HasEdgeBetween returns whether an edge exists between nodes x and y without considering direction.
+func (g *base) HasEdgeBetween(xid, yid int64) bool {
+	return g.from[xid][yid] != nil || g.from[yid][xid] != nil
+}
+
+func (g *base) edge(uid, vid int64) *Edge {
+	return g.from[uid][vid]
+}
+
+func (g *base) weight(xid, yid int64) (float64, bool) {
+	if xid == yid {
+		return 0, true
+	}
+	if e := g.from[xid][yid]; e != nil {
+		return e.weight, true
+	}
+	return math.Inf(1), false
+}
+
+// Directed is a directed gonum graph backed by a GraphML document.
+// Undirected GraphML edges are represented as two edges in opposite directions.
+type Directed struct {
+	*base
+}
+
+// NewDirected creates a directed gonum graph from a frozen GraphML document.
+//
+// All nodes of the document, including nodes of nested graphs, are added to the graph.
+// Parallel edges are merged into a single gonum edge. Edge weights are taken from the WeightAttr attribute
+// and default to 1.
+func NewDirected(v *graphml.View) *Directed {
+	return &Directed{base: newBase(v, true)}
+}
+
+// Edge returns the edge from u to v if such an edge exists and nil otherwise.
+func (g *Directed) Edge(uid, vid int64) graph.Edge {
+	return g.WeightedEdge(uid, vid)
+}
+
+// WeightedEdge returns the weighted edge from u to v if such an edge exists and nil otherwise.
+func (g *Directed) WeightedEdge(uid, vid int64) graph.WeightedEdge {
+	if e := g.edge(uid, vid); e != nil {
+		return e
+	}
+	return nil
+}
+
+// HasEdgeFromTo returns whether an edge exists in the graph from u to v.
+func (g *Directed) HasEdgeFromTo(uid, vid int64) bool {
+	return g.from[uid][vid] != nil
+}
+
+// To returns all nodes that can reach directly to the node with the given ID.
+func (g *Directed) To(id int64) graph.Nodes {
+	return g.adjacent(g.to[id])
+}
+
+// Weight returns the weight for the edge between x and y if it exists.
+// Self-loops have zero weight and absent edges have infinite weight.
+func (g *Directed) Weight(xid, yid int64) (w float64, ok bool) {
+	return g.weight(xid, yid)
+}
+
+// Undirected is an undirected gonum graph backed by a GraphML document.
+// Directions of GraphML edges are ignored.
+type Undirected struct {
+	*base
+}
+
+// NewUndirected creates an undirected gonum graph from a frozen GraphML document.
+//
+// See NewDirected for details.
+func NewUndirected(v *graphml.View) *Undirected {
+	return &Undirected{base: newBase(v, false)}
+}
+
+// Edge returns the edge between u and v if such an edge exists and nil otherwise.
+func (g *Undirected) Edge(uid, vid int64) graph.Edge {
+	return g.WeightedEdgeBetween(uid, vid)
+}
+
+// EdgeBetween returns the edge between x and y.
+func (g *Undirected) EdgeBetween(xid, yid int64) graph.Edge {
+	return g.WeightedEdgeBetween(xid, yid)
+}
+
+// WeightedEdge returns the weighted edge between u and v.
+func (g *Undirected) WeightedEdge(uid, vid int64) graph.WeightedEdge {
+	return g.WeightedEdgeBetween(uid, vid)
+}
+
+// WeightedEdgeBetween returns the weighted edge between x and y.
+func (g *Undirected) WeightedEdgeBetween(xid, yid int64) graph.WeightedEdge {
+	if e := g.edge(xid, yid); e != nil {
+		return e
+	}
+	return nil
+}
+
+// Weight returns the weight for the edge between x and y if it exists.
+// Self-loops have zero weight and absent edges have infinite weight.
+func (g *Undirected) Weight(xid, yid int64) (w float64, ok bool) {
+	return g.weight(xid, yid)
+}
@@ -0,0 +1,107 @@
+package gonumgraph
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/path"
+	"gonum.org/v1/gonum/graph/simple"
+	"gonum.org/v1/gonum/graph/topo"
+
+	"github.com/dennwc/graphml"
+)
+
+const testDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="label" attr.type="string"/>
+  <key id="d1" for="edge" attr.name="weight" attr.type="double"/>
+  <graph id="G" edgedefault="directed">
+    <node id="a"><data key="d0">A</data></node>
+    <node id="b"/>
+    <node id="c">
+      <graph id="c:" edgedefault="directed">
+        <node id="c::a"/>
+      </graph>
+    </node>
+    <edge source="a" target="b"><data key="d1">2</data></edge>
+    <edge source="b" target="c"><data key="d1">3</data></edge>
+    <edge source="a" target="c"><data key="d1">10</data></edge>
+    <edge source="c" target="c::a" directed="false"/>
+  </graph>
+</graphml>`
+
+func testView(t testing.TB) *graphml.View {
+	doc, err := graphml.Decode(strings.NewReader(testDoc))
+	require.NoError(t, err)
+	return doc.Freeze()
+}
+
+func TestDirected(t *testing.T) {
+	g := NewDirected(testView(t))
+	require.Equal(t, 4, g.Nodes().Len())
+
+	a, _ := g.NodeID("a")
+	c, _ := g.NodeID("c")
+	ca, _ := g.NodeID("c::a")
+	require.Equal(t, "A", mustAttr(t, g.Node(a).(*Node)))
+	require.True(t, g.HasEdgeFromTo(ca, c))
+	require.False(t, g.HasEdgeFromTo(c, a))
+
+	sp := path.DijkstraFrom(g.Node(a), g)
+	p, w := sp.To(c)
+	require.Len(t, p, 3)
+	require.Equal(t, 5.0, w)
+
+	_, err := topo.Sort(g)
+	require.Error(t, err) // c <-> c::a forms a cycle
+}
+
+func mustAttr(t testing.TB, n *Node) string {
+	v, ok := n.Attr("label")
+	require.True(t, ok)
+	return v
+}
+
+func TestUndirected(t *testing.T) {
+	g := NewUndirected(testView(t))
+	b, _ := g.NodeID("b")
+	a, _ := g.NodeID("a")
+	e := g.EdgeBetween(b, a).(*Edge)
+	require.Equal(t, 2.0, e.Weight())
+	require.Equal(t, "a", e.GraphML()[0].Source)
+	require.Len(t, topo.ConnectedComponents(g), 1)
+}
+
+func TestFromGonum(t *testing.T) {
+	g := simple.NewWeightedUndirectedGraph(0, math.Inf(1))
+	g.SetWeightedEdge(g.NewWeightedEdge(simple.Node(0), simple.Node(1), 2.5))
+	g.SetWeightedEdge(g.NewWeightedEdge(simple.Node(2), simple.Node(1), 1))
+
+	doc := FromGonum(g, NodeAttr("even", "boolean", func(n graph.Node) (string, bool) {
+		return strconv.FormatBool(n.ID()%2 == 0), true
+	}))
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.Encode(buf, doc))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+
+		`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`+
+		`<key id="d0" for="node" attr.name="even" attr.type="boolean"></key>`+
+		`<key id="d1" for="edge" attr.name="weight" attr.type="double"></key>`+
+		`<graph id="G" edgedefault="undirected">`+
+		`<node id="n0"><data key="d0">true</data></node>`+
+		`<node id="n1"><data key="d0">false</data></node>`+
+		`<node id="n2"><data key="d0">true</data></node>`+
+		`<edge id="e0" source="n0" target="n1"><data key="d1">2.5</data></edge>`+
+		`<edge id="e1" source="n1" target="n2"><data key="d1">1</data></edge>`+
+		`</graph></graphml>`, buf.String())
+
+	// round-trip through the adapter
+	u := NewUndirected(doc.Freeze())
+	w, ok := u.Weight(0, 1)
+	require.True(t, ok)
+	require.Equal(t, 2.5, w)
+}
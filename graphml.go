@@ -3,6 +3,8 @@ package graphml
 import (
 	"encoding/xml"
 	"io"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -23,11 +25,15 @@ func newAttr(ns, local, value string) xml.Attr {
 
 // Document is a self-contained GraphML document.
 type Document struct {
-	Instr  xml.ProcInst
-	Attrs  []xml.Attr
-	Keys   []Key
-	Graphs []Graph `xml:"graph"`
-	Data   []Data  `xml:"data"`
+	Instr xml.ProcInst
+	Attrs []xml.Attr
+	// Comments are comments found at the top level of the root element. They are written before keys.
+	Comments []xml.Comment
+	Keys     []Key
+	Graphs   []Graph `xml:"graph"`
+	Data     []Data  `xml:"data"`
+	// Incomplete is set on documents that failed to decode, see Options.Partial.
+	Incomplete bool `xml:"-"`
 }
 
 // Object is a set of common attributes for nodes edges and graphs.
@@ -59,14 +65,14 @@ type ExtObject struct {
 	Data []Data `xml:"data"`
 }
 
-// NewKey creates a new custom attribute definition.
-func NewKey(kind Kind, id, name, typ string) Key {
+// NewKey creates a new custom attribute definition. The type may be empty for attributes without a declared type.
+func NewKey(kind Kind, id, name string, typ AttrType) Key {
 	return Key{
 		Object: Object{
 			ID: id,
 		},
 		For:  kind,
-		Name: name, Type: typ,
+		Name: name, Type: string(typ),
 	}
 }
 
@@ -76,6 +82,12 @@ type Key struct {
 	For  Kind   `xml:"for,attr"`
 	Name string `xml:"attr.name,attr"`
 	Type string `xml:"attr.type,attr"`
+	// YFilesType is a type of yFiles-specific data, for example "nodegraphics". Keys declared by yEd for its
+	// extensions set this attribute instead of the name and the type.
+	YFilesType string `xml:"yfiles.type,attr"`
+	// Default is a raw XML value of the attribute for elements that have no data for this key.
+	// Nil value means that the key has no default.
+	Default []xml.Token
 }
 
 func (k *Key) addAttr(a xml.Attr) {
@@ -86,6 +98,8 @@ func (k *Key) addAttr(a xml.Attr) {
 		k.Name = a.Value
 	case "attr.type":
 		k.Type = a.Value
+	case "yfiles.type":
+		k.YFilesType = a.Value
 	default:
 		k.Object.addAttr(a)
 	}
@@ -99,9 +113,23 @@ func (k *Key) attrs() []xml.Attr {
 	if k.Type != "" {
 		attrs = append(attrs, newAttr("", "attr.type", k.Type))
 	}
+	if k.YFilesType != "" {
+		attrs = append(attrs, newAttr("", "yfiles.type", k.YFilesType))
+	}
 	return attrs
 }
 
+// KeyByYFilesType finds a key with a given yFiles data type declared for a given kind of elements.
+func (doc *Document) KeyByYFilesType(kind Kind, typ string) (*Key, bool) {
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if k.For == kind && k.YFilesType == typ {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
 // Graph is a set of nodes and edges.
 type Graph struct {
 	ExtObject
@@ -169,11 +197,120 @@ func (e *Edge) attrs() []xml.Attr {
 	return attrs
 }
 
+// AddGraph adds a new nested graph to the node and returns a pointer to it. The graph gets an ID derived
+// from the node ID, the way yEd does it: "n0:" for the first graph of node "n0", "n0:1" for the second one, and so on.
+// The pointer is only valid until the next graph is added to the node.
+func (n *Node) AddGraph(edgeDefault EdgeDir) *Graph {
+	var g Graph
+	g.EdgeDefault = edgeDefault
+	if n.ID != "" {
+		g.ID = n.ID + ":"
+		if len(n.Graphs) != 0 {
+			g.ID += strconv.Itoa(len(n.Graphs))
+		}
+	}
+	n.Graphs = append(n.Graphs, g)
+	return &n.Graphs[len(n.Graphs)-1]
+}
+
+// GraphByID finds a graph with a given ID, including nested graphs. Graphs are searched in the document order,
+// with graphs nested in a node searched before the following nodes.
+func (doc *Document) GraphByID(id string) (*Graph, bool) {
+	var find func(graphs []Graph) *Graph
+	find = func(graphs []Graph) *Graph {
+		for i := range graphs {
+			g := &graphs[i]
+			if g.ID == id {
+				return g
+			}
+			for j := range g.Nodes {
+				if sg := find(g.Nodes[j].Graphs); sg != nil {
+					return sg
+				}
+			}
+		}
+		return nil
+	}
+	g := find(doc.Graphs)
+	return g, g != nil
+}
+
+// GraphByPath finds a graph by a slash-separated path. The first element is an ID of a top-level graph,
+// and each following element addresses a graph nested in a node of the previous graph as "node:graph",
+// a node ID and a graph ID separated by a colon. For example, "G/n5:g2" is the graph "g2" of the node "n5"
+// of the graph "G". Colons in node IDs are allowed, as long as the path is not ambiguous: for yEd-style IDs,
+// "G/n0:n0:" addresses the graph "n0:" of the node "n0".
+func (doc *Document) GraphByPath(path string) (*Graph, bool) {
+	elems := strings.Split(path, "/")
+	var g *Graph
+	for i := range doc.Graphs {
+		if doc.Graphs[i].ID == elems[0] {
+			g = &doc.Graphs[i]
+			break
+		}
+	}
+	for _, e := range elems[1:] {
+		if g == nil {
+			break
+		}
+		g = g.nestedGraph(e)
+	}
+	return g, g != nil
+}
+
+// nestedGraph finds a graph nested in a node of the graph by a path element in "node:graph" form.
+func (g *Graph) nestedGraph(elem string) *Graph {
+	for i := 0; i < len(elem); i++ {
+		if elem[i] != ':' {
+			continue
+		}
+		nid, gid := elem[:i], elem[i+1:]
+		for j := range g.Nodes {
+			n := &g.Nodes[j]
+			if n.ID != nid {
+				continue
+			}
+			for k := range n.Graphs {
+				if n.Graphs[k].ID == gid {
+					return &n.Graphs[k]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// IsDirected reports if edges of the graph are directed by default. The edgedefault attribute is required
+// by the specification, but graphs without it are common; like most tools, they are considered undirected.
+func (g *Graph) IsDirected() bool {
+	return g.EdgeDefault == EdgeDirected
+}
+
+// EffectiveDirection returns a direction of the edge in the graph g: the value of the "directed" attribute
+// of the edge, if it is set, or the default direction of the graph (see Graph.IsDirected).
+func (e *Edge) EffectiveDirection(g *Graph) EdgeDir {
+	if v, ok := findAttr(e.Unrecognized, "", "directed"); ok {
+		if dir, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			if dir {
+				return EdgeDirected
+			}
+			return EdgeUndirected
+		}
+	}
+	if g != nil && g.IsDirected() {
+		return EdgeDirected
+	}
+	return EdgeUndirected
+}
+
 // Data is a raw XML value for a custom attribute.
+// It may also hold a typed value, if the content is handled by a registered Codec.
 type Data struct {
 	Key          string     `xml:"key,attr"`
 	Unrecognized []xml.Attr `xml:",any,attr"`
 	Data         []xml.Token
+
+	value interface{}
 }
 
 // Reader returns a XML token reader for this custom attribute. See xml.NewTokenDecoder().
@@ -195,6 +332,32 @@ func (d *Data) attrs() []xml.Attr {
 	return attrs
 }
 
+// NewStringData creates a data element for a given key with a text value.
+func NewStringData(key, value string) Data {
+	return Data{Key: key, Data: []xml.Token{xml.CharData(value)}}
+}
+
+// NewIntData creates a data element for a given key with an integer value.
+// It is suitable for keys of both "int" and "long" types.
+func NewIntData(key string, value int64) Data {
+	return NewStringData(key, strconv.FormatInt(value, 10))
+}
+
+// NewFloatData creates a data element for a given key with a floating point value.
+// The value is written in the shortest form that parses back to the same number.
+func NewFloatData(key string, value float64) Data {
+	return NewStringData(key, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// NewXMLData creates a data element for a given key with a raw XML content. Tokens are copied.
+func NewXMLData(key string, tokens []xml.Token) Data {
+	d := Data{Key: key, Data: make([]xml.Token, 0, len(tokens))}
+	for _, t := range tokens {
+		d.Data = append(d.Data, xml.CopyToken(t))
+	}
+	return d
+}
+
 type tokenReader struct {
 	tokens []xml.Token
 }
@@ -216,6 +379,18 @@ const (
 	EdgeUndirected = EdgeDir("undirected")
 )
 
+// AttrType is a type of custom attribute values, see Key.Type.
+type AttrType string
+
+const (
+	TypeBoolean = AttrType("boolean")
+	TypeInt     = AttrType("int")
+	TypeLong    = AttrType("long")
+	TypeFloat   = AttrType("float")
+	TypeDouble  = AttrType("double")
+	TypeString  = AttrType("string")
+)
+
 // Kind is an element kind used for extensions.
 type Kind string
 
@@ -1,13 +1,19 @@
 package graphml
 
 import (
+	"bytes"
 	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/require"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 const testdata = "data"
@@ -43,8 +49,10 @@ func TestRoundtrip(t *testing.T) {
 				require.NoError(t, err)
 				defer zr.Close()
 
-				doc, err := Decode(zr)
+				rep, err := VerifyRoundTrip(zr)
 				require.NoError(t, err)
+				require.True(t, rep.OK(), "%v", rep)
+				doc := rep.Doc
 
 				out, err := os.Create(strings.TrimSuffix(name, ".gz"))
 				require.NoError(t, err)
@@ -56,3 +64,1547 @@ func TestRoundtrip(t *testing.T) {
 		}
 	}
 }
+
+func decodeTestFile(t testing.TB, name string) *Document {
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	doc, err := Decode(zr)
+	require.NoError(t, err)
+	return doc
+}
+
+func TestFreeze(t *testing.T) {
+	doc := decodeTestFile(t, filepath.Join(testdata, "yed_tree"+Ext+".gz"))
+	v := doc.Freeze()
+
+	g := &doc.Graphs[0]
+	n0 := g.Nodes[0]
+	e0 := g.Edges[0]
+
+	n, ok := v.Node(n0.ID)
+	require.True(t, ok)
+	require.Equal(t, n0.ID, n.ID)
+	require.Equal(t, g.ID, v.NodeGraph(n).ID)
+
+	e, ok := v.Edge(e0.ID)
+	require.True(t, ok)
+	require.Contains(t, v.Out(e0.Source), e)
+	require.Contains(t, v.In(e0.Target), e)
+
+	// changes to the original document must not affect the view
+	g.Nodes[0].ID = "changed"
+	_, ok = v.Node("changed")
+	require.False(t, ok)
+
+	doc2 := v.Thaw()
+	doc2.Graphs[0].Nodes[0].ID = "changed"
+	n, _ = v.Node(n0.ID)
+	require.Equal(t, n0.ID, n.ID)
+}
+
+func TestKeyDefault(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><key id="d0" for="node" attr.name="color" attr.type="string"><default>yellow</default></key><graph edgedefault="directed"><node id="n0"></node></graph></graphml>`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Len(t, doc.Keys, 1)
+	require.Equal(t, []xml.Token{xml.CharData("yellow")}, doc.Keys[0].Default)
+
+	buf := new(bytes.Buffer)
+	err = Encode(buf, doc)
+	require.NoError(t, err)
+	require.Equal(t, src, buf.String())
+}
+
+func TestMarshalXML(t *testing.T) {
+	doc := decodeTestFile(t, filepath.Join(testdata, "yed_tree"+Ext+".gz"))
+
+	type wrapper struct {
+		XMLName xml.Name  `xml:"wrapper"`
+		Name    string    `xml:"name"`
+		Graph   *Document `xml:"graphml"`
+	}
+	data, err := xml.Marshal(wrapper{Name: "tree", Graph: doc})
+	require.NoError(t, err)
+
+	var w wrapper
+	err = xml.Unmarshal(data, &w)
+	require.NoError(t, err)
+	require.Equal(t, "tree", w.Name)
+	require.NotNil(t, w.Graph)
+
+	// compare with a document that was encoded and decoded the same number of times
+	buf := new(bytes.Buffer)
+	require.NoError(t, Encode(buf, doc))
+	doc2, err := Decode(buf)
+	require.NoError(t, err)
+	doc2.Instr = xml.ProcInst{}
+
+	exp, got := new(bytes.Buffer), new(bytes.Buffer)
+	require.NoError(t, Encode(exp, doc2))
+	require.NoError(t, Encode(got, w.Graph))
+	require.Equal(t, exp.String(), got.String())
+}
+
+func TestWalkGraphML(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/one.graphml":   {Data: []byte(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><graph id="G1"></graph></graphml>`)},
+		"a/b/bad.graphml": {Data: []byte(`<graphml>`)},
+		"a/notes.txt":     {Data: []byte(`not a graph`)},
+	}
+	data, err := os.ReadFile(filepath.Join(testdata, "yed_tree"+ExtGzip))
+	require.NoError(t, err)
+	fsys["a/tree"+ExtGzip] = &fstest.MapFile{Data: data}
+
+	doc, err := DecodeFS(fsys, "a/one.graphml")
+	require.NoError(t, err)
+	require.Equal(t, "G1", doc.Graphs[0].ID)
+
+	var (
+		found  []string
+		failed []string
+	)
+	err = WalkGraphML(fsys, "a", func(path string, doc *Document, err error) error {
+		if err != nil {
+			failed = append(failed, path)
+			return nil
+		}
+		require.NotNil(t, doc)
+		found = append(found, path)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a/one.graphml", "a/tree" + ExtGzip}, found)
+	require.Equal(t, []string{"a/b/bad.graphml"}, failed)
+}
+
+const testPointNS = "urn:test:point"
+
+type testPoint struct {
+	XMLName xml.Name `xml:"urn:test:point point"`
+	X       int      `xml:"x,attr"`
+	Y       int      `xml:"y,attr"`
+}
+
+type testPointCodec struct{}
+
+func (testPointCodec) DecodeData(dec *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	p := new(testPoint)
+	if err := dec.DecodeElement(p, &start); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (testPointCodec) EncodeData(enc *xml.Encoder, v interface{}) error {
+	return enc.Encode(v)
+}
+
+func TestCodec(t *testing.T) {
+	RegisterCodec(testPointNS, testPointCodec{})
+	defer RegisterCodec(testPointNS, nil)
+
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><key id="d0" for="node"></key><graph edgedefault="directed"><node id="n0"><data key="d0"><point xmlns="urn:test:point" x="1" y="2"></point></data></node><node id="n1"><data key="d0">text</data></node></graph></graphml>`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+
+	g := &doc.Graphs[0]
+	p, ok := g.Nodes[0].Data[0].Value().(*testPoint)
+	require.True(t, ok)
+	require.Equal(t, 1, p.X)
+	require.Equal(t, 2, p.Y)
+	require.Nil(t, g.Nodes[1].Data[0].Value())
+
+	p.X = 3
+	err = g.Nodes[0].Data[0].SetValue(testPointNS, p)
+	require.NoError(t, err)
+	err = g.Nodes[1].Data[0].SetValue(testPointNS, &testPoint{X: 4, Y: 5})
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	err = Encode(buf, doc)
+	require.NoError(t, err)
+	require.Equal(t, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><key id="d0" for="node"></key><graph edgedefault="directed"><node id="n0"><data key="d0"><point xmlns="urn:test:point" x="3" y="2"></point></data></node><node id="n1"><data key="d0"><point xmlns="urn:test:point" x="4" y="5"></point></data></node></graph></graphml>`, buf.String())
+
+	err = g.Nodes[1].Data[0].SetValue("urn:unknown", &testPoint{})
+	require.Error(t, err)
+}
+
+func TestProfileGephi(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><key id="d1" for="edge" attr.name="weight" attr.type="int"></key><key id="d0" for="node" attr.name="label" attr.type="string"></key><key id="d2" for="edge" attr.name="label" attr.type="string"></key><graph><node id="n0"><data key="d0">first</data></node><node id="n1"></node><edge source="n0" target="n1"><data key="d1">2</data><data key="d2">link</data></edge></graph></graphml>`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	err = EncodeWith(buf, doc, &Options{Profile: ProfileGephi})
+	require.NoError(t, err)
+	const exp = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><key id="label" for="node" attr.name="label" attr.type="string"></key><key id="weight" for="edge" attr.name="weight" attr.type="double"></key><key id="edgelabel" for="edge" attr.name="Edge Label" attr.type="string"></key><graph edgedefault="directed"><node id="n0"><data key="label">first</data></node><node id="n1"></node><edge id="e0" source="n0" target="n1"><data key="weight">2</data><data key="edgelabel">link</data></edge></graph></graphml>`
+	require.Equal(t, exp, buf.String())
+	// the original document must not change
+	require.Equal(t, "d1", doc.Keys[0].ID)
+
+	doc, err = DecodeWith(strings.NewReader(exp), &Options{Profile: ProfileGephi})
+	require.NoError(t, err)
+	require.Equal(t, "label", doc.Keys[2].Name)
+
+	// quirks: no namespace and undeclared keys
+	const quirks = `<graphml><graph edgedefault="undirected"><node id="n0"><data key="size">10.0</data></node></graph></graphml>`
+	_, err = Decode(strings.NewReader(quirks))
+	require.Error(t, err)
+	doc, err = DecodeWith(strings.NewReader(quirks), &Options{Profile: ProfileGephi})
+	require.NoError(t, err)
+	require.Equal(t, "size", doc.Graphs[0].Nodes[0].Data[0].Key)
+
+	// documents written by Gephi keep their key IDs
+	doc = decodeTestFile(t, filepath.Join(testdata, "gephi_graph"+ExtGzip))
+	buf.Reset()
+	require.NoError(t, EncodeWith(buf, doc, &Options{Profile: ProfileGephi}))
+	doc2, err := DecodeWith(buf, &Options{Profile: ProfileGephi})
+	require.NoError(t, err)
+	require.Equal(t, len(doc.Keys), len(doc2.Keys))
+	for _, k := range doc2.Keys {
+		found := false
+		for _, k2 := range doc.Keys {
+			found = found || k2.ID == k.ID
+		}
+		require.True(t, found, k.ID)
+	}
+	require.Equal(t, doc.Graphs[0].Edges[0].ID, doc2.Graphs[0].Edges[0].ID)
+}
+
+func TestMaxDepth(t *testing.T) {
+	nested := func(n int) string {
+		var sb strings.Builder
+		sb.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+		for i := 0; i < n; i++ {
+			sb.WriteString(`<graph><node id="n` + strconv.Itoa(i) + `">`)
+		}
+		for i := 0; i < n; i++ {
+			sb.WriteString(`</node></graph>`)
+		}
+		sb.WriteString(`</graphml>`)
+		return sb.String()
+	}
+	_, err := Decode(strings.NewReader(nested(MaxDepth)))
+	require.NoError(t, err)
+	_, err = Decode(strings.NewReader(nested(MaxDepth + 1)))
+	require.Error(t, err)
+}
+
+type panicCodec struct{}
+
+func (panicCodec) DecodeData(dec *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	panic("bad codec")
+}
+
+func (panicCodec) EncodeData(enc *xml.Encoder, v interface{}) error {
+	return nil
+}
+
+func TestCodecPanic(t *testing.T) {
+	RegisterCodec("urn:test:panic", panicCodec{})
+	defer RegisterCodec("urn:test:panic", nil)
+
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><key id="d0" for="node"></key><graph><node id="n0"><data key="d0"><p xmlns="urn:test:panic"/></data></node></graph></graphml>`
+	var warns []Warning
+	doc, err := DecodeWith(strings.NewReader(src), &Options{Warn: func(w Warning) { warns = append(warns, w) }})
+	require.NoError(t, err)
+	d := doc.Graphs[0].Nodes[0].Data[0]
+	require.Nil(t, d.Value())
+	require.Len(t, d.Data, 2)
+	require.Len(t, warns, 1)
+	require.Equal(t, WarnCodecFailed, warns[0].Code)
+	require.Equal(t, "graph[0]/node[0]/data[0]", warns[0].Path)
+	require.Contains(t, warns[0].Message, "codec panic: bad codec")
+}
+
+func TestKeyByYFilesType(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><key id="d0" for="node" attr.name="url" attr.type="string"></key><key id="d1" for="node" yfiles.type="nodegraphics"></key><key id="d2" for="edge" yfiles.type="edgegraphics"></key></graphml>`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Empty(t, doc.Keys[1].Unrecognized)
+
+	k, ok := doc.KeyByYFilesType(KindNode, "nodegraphics")
+	require.True(t, ok)
+	require.Equal(t, "d1", k.ID)
+	_, ok = doc.KeyByYFilesType(KindNode, "edgegraphics")
+	require.False(t, ok)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, Encode(buf, doc))
+	require.Equal(t, src, buf.String())
+}
+
+func TestEncodePrefixes(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:y="urn:test:y" xsi:schemaLocation="http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd"><graph edgedefault="directed"><node id="n0" y:kind="a"></node></graph></graphml>`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	require.NoError(t, Encode(buf, doc))
+	require.Equal(t, src, buf.String())
+}
+
+func TestEncodeComments(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:y="urn:test:y"><!--Created by test--><graph edgedefault="directed"><node id="n0"><data key="d0"><y:Shape type="ellipse"></y:Shape></data></node></graph></graphml>`
+	doc, err := DecodeWith(strings.NewReader(src), &Options{Profile: ProfileGephi})
+	require.NoError(t, err)
+	require.Equal(t, []xml.Comment{xml.Comment("Created by test")}, doc.Comments)
+	buf := new(bytes.Buffer)
+	require.NoError(t, Encode(buf, doc))
+	require.Equal(t, src, buf.String())
+}
+
+func TestProfileYEd(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:y="urn:test:y">
+  <!--Created by test-->
+  <!-- see x> <p></p> here -->
+  <key for="node" id="d0" yfiles.type="nodegraphics"/>
+  <graph edgedefault="directed" id="G">
+    <node id="n0">
+      <data key="d0">
+        <y:Shape type="ellipse"/>
+        <!-- see x> <p></p> here --><?pi <a></a>?>
+      </data>
+    </node>
+    <node id="n1"/>
+  </graph>
+</graphml>
+`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	require.NoError(t, EncodeWith(buf, doc, &Options{Profile: ProfileYEd}))
+	require.Equal(t, src, buf.String())
+}
+
+func TestValidate(t *testing.T) {
+	doc := &Document{
+		Keys: []Key{
+			NewKey(KindNode, "d0", "label", "string"),
+			NewKey(Kind("vertex"), "d1", "weight", "double"),
+		},
+	}
+	var g Graph
+	g.ID = "G"
+	g.EdgeDefault = "both"
+	var n Node
+	n.ID = "n0"
+	n.Data = []Data{{Key: "d0"}, {Key: "d2"}}
+	g.Nodes = []Node{n, n}
+	var e Edge
+	e.Source, e.Target = "n0", "n0"
+	e.Data = []Data{{Key: "d0"}}
+	g.Edges = []Edge{e}
+	doc.Graphs = []Graph{g}
+
+	require.Equal(t, []Finding{
+		{Rule: "unique-ids", Path: "/graphml/graph[@id='G']/node[@id='n0']", Message: `id "n0" is already used by /graphml/graph[@id='G']/node[@id='n0']`},
+		{Rule: "data-keys", Path: "/graphml/graph[@id='G']/node[@id='n0']/data[2]", Message: `undeclared key "d2"`},
+		{Rule: "data-keys", Path: "/graphml/graph[@id='G']/node[@id='n0']/data[2]", Message: `undeclared key "d2"`},
+		{Rule: "data-keys", Severity: SeverityWarning, Path: "/graphml/graph[@id='G']/edge[1]/data[1]", Message: `key "d0" is not declared for edge`},
+		{Rule: "attr-values", Path: "/graphml/key[@id='d1']", Message: `unknown element kind "vertex"`},
+		{Rule: "attr-values", Path: "/graphml/graph[@id='G']", Message: `unknown edge direction "both"`},
+		{Rule: "unused-keys", Severity: SeverityInfo, Path: "/graphml/key[@id='d1']", Message: `key "d1" is never used`},
+	}, doc.Validate())
+
+	minSeverity := SeverityError
+	p := &Policy{
+		Severity:    map[string]Severity{"attr-values": SeverityWarning, "data-keys": SeverityError},
+		Ignore:      map[string]bool{"unique-ids": true},
+		MinSeverity: &minSeverity,
+	}
+	require.Equal(t, []Finding{
+		{Rule: "data-keys", Path: "/graphml/graph[@id='G']/node[@id='n0']/data[2]", Message: `undeclared key "d2"`},
+		{Rule: "data-keys", Path: "/graphml/graph[@id='G']/node[@id='n0']/data[2]", Message: `undeclared key "d2"`},
+		{Rule: "data-keys", Path: "/graphml/graph[@id='G']/edge[1]/data[1]", Message: `key "d0" is not declared for edge`},
+	}, p.Validate(doc))
+	require.True(t, HasErrors(p.Validate(doc)))
+	minSeverity = SeverityInfo
+	require.Len(t, p.Validate(doc), 6)
+	p.MinSeverity = nil
+	require.Len(t, p.Validate(doc), 6)
+
+	// a policy without MinSeverity keeps warnings and infos
+	p = &Policy{Ignore: map[string]bool{"unique-ids": true}}
+	require.Equal(t, []Finding{
+		{Rule: "data-keys", Severity: SeverityWarning, Path: "/graphml/graph[@id='G']/edge[1]/data[1]", Message: `key "d0" is not declared for edge`},
+		{Rule: "unused-keys", Severity: SeverityInfo, Path: "/graphml/key[@id='d1']", Message: `key "d1" is never used`},
+	}, p.Apply([]Finding{
+		{Rule: "unique-ids", Message: "x"},
+		{Rule: "data-keys", Severity: SeverityWarning, Path: "/graphml/graph[@id='G']/edge[1]/data[1]", Message: `key "d0" is not declared for edge`},
+		{Rule: "unused-keys", Severity: SeverityInfo, Path: "/graphml/key[@id='d1']", Message: `key "d1" is never used`},
+	}))
+
+	rule := RuleFunc("custom", func(doc *Document) []Finding {
+		return []Finding{{Severity: SeverityWarning, Path: "/graphml", Message: "test"}}
+	})
+	require.Equal(t, []Finding{
+		{Rule: "custom", Severity: SeverityWarning, Path: "/graphml", Message: "test"},
+	}, doc.Validate(rule))
+}
+
+func TestValidateEdgeEndpoints(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<graph id="G" edgedefault="directed">
+	<node id="n0"><graph id="n0:"><node id="n1"/><edge source="n1" target="n0"/><edge source="n1" target="n3"/></graph></node>
+	<node id="n2"/>
+	<edge source="n1" target="n2"/>
+	<edge id="e1" source="n2" target="n4"/>
+	<edge target="n2"/>
+</graph>
+</graphml>`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Equal(t, []Finding{
+		{Rule: "edge-endpoints", Path: "/graphml/graph[@id='G']/node[@id='n0']/graph[@id='n0:']/edge[2]", Message: `target references unknown node "n3"`},
+		{Rule: "edge-endpoints", Path: "/graphml/graph[@id='G']/edge[@id='e1']", Message: `target references unknown node "n4"`},
+		{Rule: "edge-endpoints", Path: "/graphml/graph[@id='G']/edge[3]", Message: "missing source"},
+		{Rule: "edge-scope", Path: "/graphml/graph[@id='G']/node[@id='n0']/graph[@id='n0:']/edge[1]", Message: `node "n0" is outside of the graph, the edge must be declared in an enclosing graph`},
+	}, doc.Validate())
+	require.Len(t, doc.Validate(edgeEndpointsRule), 3)
+
+	_, err = DecodeWith(strings.NewReader(src), &Options{Strict: true})
+	require.EqualError(t, err, `validation failed: /graphml/graph[@id='G']/node[@id='n0']/graph[@id='n0:']/edge[2]: error: target references unknown node "n3" (edge-endpoints)`)
+
+	const outer = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<graph id="G" edgedefault="directed">
+	<node id="n0"><graph id="n0:"><node id="n1"/><edge source="n1" target="n0"/></graph></node>
+</graph>
+</graphml>`
+	_, err = DecodeWith(strings.NewReader(outer), &Options{Strict: true})
+	require.EqualError(t, err, `validation failed: /graphml/graph[@id='G']/node[@id='n0']/graph[@id='n0:']/edge[1]: error: node "n0" is outside of the graph, the edge must be declared in an enclosing graph (edge-scope)`)
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	doc := &Document{
+		Keys: []Key{
+			NewKey(KindNode, "d0", "label", "string"),
+			NewKey(KindEdge, "d0", "weight", "double"),
+			NewKey(KindNode, "d1", "label", "string"),
+			NewKey(KindNode, "d2", "label", "int"),
+		},
+	}
+	var g Graph
+	var n Node
+	n.ID = "n0"
+	n.Data = []Data{{Key: "d0"}, {Key: "d1"}, {Key: "d2"}}
+	var e Edge
+	e.Source, e.Target = "n0", "n0"
+	e.Data = []Data{{Key: "d0"}}
+	g.Nodes = []Node{n}
+	g.Edges = []Edge{e}
+	doc.Graphs = []Graph{g}
+
+	rule := RuleFunc("duplicate-keys", checkDuplicateKeys)
+	require.Equal(t, []Finding{
+		{Rule: "duplicate-keys", Path: "/graphml/key[@id='d0']", Message: `key id "d0" is declared more than once`},
+		{Rule: "duplicate-keys", Severity: SeverityWarning, Path: "/graphml/key[@id='d1']", Message: `attribute "label" for node is already declared by /graphml/key[@id='d0']`},
+		{Rule: "duplicate-keys", Severity: SeverityWarning, Path: "/graphml/key[@id='d2']", Message: `attribute "label" for node is already declared by /graphml/key[@id='d0']`},
+	}, doc.Validate(rule))
+
+	require.Equal(t, 3, doc.FixDuplicateKeys())
+	require.Empty(t, doc.Validate(rule))
+	require.Equal(t, []Key{
+		NewKey(KindNode, "d0", "label", "string"),
+		NewKey(KindEdge, "d3", "weight", "double"),
+		NewKey(KindNode, "d2", "label_2", "int"),
+	}, doc.Keys)
+	require.Equal(t, []Data{{Key: "d0"}, {Key: "d2"}}, doc.Graphs[0].Nodes[0].Data)
+	require.Equal(t, []Data{{Key: "d3"}}, doc.Graphs[0].Edges[0].Data)
+}
+
+func TestValidateSchema(t *testing.T) {
+	const valid = `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd">
+	<desc>test</desc>
+	<key id="d0" for="node" attr.name="color" attr.type="string"><default>yellow</default></key>
+	<graph id="G" edgedefault="undirected">
+		<node id="n0"><data key="d0">green<b>!</b></data><port name="p0"/></node>
+		<node id="n1"><graph id="n1:" edgedefault="directed"><node id="n1::n0"/></graph></node>
+		<edge source="n0" target="n1::n0" sourceport="p0" directed="true"/>
+		<hyperedge><endpoint node="n0"/><endpoint node="n1" type="in"/></hyperedge>
+	</graph>
+</graphml>`
+	findings, err := ValidateSchema(strings.NewReader(valid))
+	require.NoError(t, err)
+	require.Empty(t, findings)
+
+	const invalid = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+	<graph id="G">
+		<node id="n0">text<data key="d1"/></node>
+		<node id="n0"/>
+		<edge source="n0" target="n2" directed="yes"/>
+	</graph>
+	<key id="d1" for="node" attr.type="color"/>
+	<foo/>
+</graphml>`
+	findings, err = ValidateSchema(strings.NewReader(invalid))
+	require.NoError(t, err)
+	msgs := make([]string, 0, len(findings))
+	for _, f := range findings {
+		require.Equal(t, "schema", f.Rule)
+		msgs = append(msgs, f.Path+": "+f.Message)
+	}
+	require.Equal(t, []string{
+		`/graphml/graph[@id='G']: missing attribute "edgedefault"`,
+		`/graphml/graph[@id='G']/node[@id='n0']: unexpected text content`,
+		`/graphml/graph[@id='G']/node[@id='n0']: node id "n0" is already used by /graphml/graph[@id='G']/node[@id='n0']`,
+		`/graphml/graph[@id='G']/edge[1]: invalid value of "directed": "yes"`,
+		`/graphml/key[@id='d1']: element key is not allowed here`,
+		`/graphml/key[@id='d1']: invalid value of "attr.type": "color"`,
+		`/graphml/foo[1]: unknown element {http://graphml.graphdrawing.org/xmlns}foo`,
+		`/graphml/graph[@id='G']/edge[1]: reference to unknown node "n2"`,
+	}, msgs)
+
+	doc, err := Decode(strings.NewReader(valid[:strings.Index(valid, "<desc>")] + `<graph id="G" edgedefault="directed"/></graphml>`))
+	require.NoError(t, err)
+	findings, err = doc.ValidateSchema()
+	require.NoError(t, err)
+	require.Empty(t, findings)
+}
+
+func TestPruneUnusedKeys(t *testing.T) {
+	def := NewKey(KindNode, "d2", "color", "string")
+	def.Default = []xml.Token{xml.CharData("red")}
+	doc := &Document{
+		Keys: []Key{
+			NewKey(KindNode, "d0", "label", "string"),
+			NewKey(KindEdge, "d1", "weight", "double"),
+			def,
+		},
+	}
+	var g Graph
+	var n Node
+	n.ID = "n0"
+	n.Data = []Data{{Key: "d0"}}
+	g.Nodes = []Node{n}
+	doc.Graphs = []Graph{g}
+
+	require.Equal(t, []Finding{
+		{Rule: "unused-keys", Severity: SeverityInfo, Path: "/graphml/key[@id='d1']", Message: `key "d1" is never used`},
+	}, doc.Validate(RuleFunc("unused-keys", checkUnusedKeys)))
+	require.Equal(t, 1, doc.PruneUnusedKeys())
+	require.Equal(t, []Key{NewKey(KindNode, "d0", "label", "string"), def}, doc.Keys)
+}
+
+func TestDataTypes(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="visible" attr.type="boolean"><default>True</default></key>
+<key id="d1" for="node" attr.name="weight" attr.type="int"/>
+<key id="d2" for="all" attr.name="size" attr.type="double"/>
+<graph id="G" edgedefault="directed">
+	<node id="n0"><data key="d0">false</data><data key="d1">2.0</data><data key="d2"> 1.5 </data></node>
+	<node id="n1"><data key="d1">2.5</data><data key="d2"><x/></data></node>
+</graph>
+</graphml>`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+	rule := dataTypesRule
+	require.Equal(t, []Finding{
+		{Rule: "data-types", Path: "/graphml/key[@id='d0']/default", Message: `value "True" is not a valid boolean`},
+		{Rule: "data-types", Path: "/graphml/graph[@id='G']/node[@id='n0']/data[2]", Message: `value "2.0" is not a valid int`},
+		{Rule: "data-types", Path: "/graphml/graph[@id='G']/node[@id='n1']/data[1]", Message: `value "2.5" is not a valid int`},
+		{Rule: "data-types", Path: "/graphml/graph[@id='G']/node[@id='n1']/data[2]", Message: `expected a double value, got XML elements`},
+	}, doc.Validate(rule))
+
+	require.Equal(t, 2, doc.CoerceDataTypes())
+	require.Len(t, doc.Validate(rule), 2)
+	require.Equal(t, []xml.Token{xml.CharData("true")}, doc.Keys[0].Default)
+	require.Equal(t, []xml.Token{xml.CharData("2")}, doc.Graphs[0].Nodes[0].Data[1].Data)
+}
+
+func TestValidator(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="weight" attr.type="int"/>
+<graph id="G" edgedefault="directed">
+	<node id="n0">
+		<data key="d0">x</data>
+	</node>
+	<edge source="n0" target="n1"/>
+</graph>
+</graphml>`
+	var findings []Finding
+	v := &Validator{
+		Report: func(f Finding) error {
+			findings = append(findings, f)
+			return nil
+		},
+	}
+	doc, err := DecodeWith(strings.NewReader(src), &Options{Validator: v})
+	require.NoError(t, err)
+	require.Equal(t, []Finding{
+		{Rule: "data-types", Path: "/graphml/graph[@id='G']/node[@id='n0']/data[1]", Message: `value "x" is not a valid int`, Line: 5, Column: 3},
+		{Rule: "edge-endpoints", Path: "/graphml/graph[@id='G']/edge[1]", Message: `target references unknown node "n1"`},
+	}, findings)
+
+	findings = nil
+	require.NoError(t, EncodeWith(io.Discard, doc, &Options{Validator: v}))
+	require.Equal(t, []Finding{
+		{Rule: "data-types", Path: "/graphml/graph[@id='G']/node[@id='n0']/data[1]", Message: `value "x" is not a valid int`},
+		{Rule: "edge-endpoints", Path: "/graphml/graph[@id='G']/edge[1]", Message: `target references unknown node "n1"`},
+	}, findings)
+
+	_, err = DecodeWith(strings.NewReader(src), &Options{Validator: &Validator{}})
+	require.EqualError(t, err, `graph[0]/node[0]/data[0] (line 5, column 26, offset 196): validation failed: 5:3: /graphml/graph[@id='G']/node[@id='n0']/data[1]: error: value "x" is not a valid int (data-types)`)
+
+	v = &Validator{Policy: &Policy{Ignore: map[string]bool{"data-types": true, "edge-endpoints": true}}}
+	_, err = DecodeWith(strings.NewReader(src), &Options{Validator: v})
+	require.NoError(t, err)
+}
+
+func TestRegisterRule(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="type" attr.type="string"/>
+<graph id="G" edgedefault="directed">
+	<node id="n0"><data key="d0">server</data></node>
+	<node id="n1"/>
+</graph>
+</graphml>`
+	rule := NewElementRule("node-type", ElementChecks{
+		Node: func(path string, n *Node) []Finding {
+			for _, d := range n.Data {
+				if d.Key == "d0" {
+					return nil
+				}
+			}
+			return []Finding{{Path: path, Message: "node has no type"}}
+		},
+	})
+	RegisterRule(rule)
+	defer func() {
+		rules.Lock()
+		rules.list = nil
+		rules.Unlock()
+	}()
+	r, ok := LookupRule("node-type")
+	require.True(t, ok)
+	require.Equal(t, rule, r)
+
+	var findings []Finding
+	v := &Validator{
+		Report: func(f Finding) error {
+			findings = append(findings, f)
+			return nil
+		},
+	}
+	doc, err := DecodeWith(strings.NewReader(src), &Options{Validator: v})
+	require.NoError(t, err)
+	want := Finding{Rule: "node-type", Path: "/graphml/graph[@id='G']/node[@id='n1']", Message: "node has no type"}
+	require.Equal(t, []Finding{{Rule: want.Rule, Path: want.Path, Message: want.Message, Line: 5, Column: 2}}, findings)
+	require.Equal(t, []Finding{want}, doc.Validate())
+}
+
+func TestStats(t *testing.T) {
+	doc := &Document{
+		Keys: []Key{
+			NewKey(KindNode, "d0", "label", "string"),
+			NewKey(KindEdge, "d1", "weight", "double"),
+		},
+	}
+	var g Graph
+	g.EdgeDefault = EdgeDirected
+	for _, id := range []string{"a", "b", "c"} {
+		var n Node
+		n.ID = id
+		n.Data = []Data{{Key: "d0"}}
+		g.Nodes = append(g.Nodes, n)
+	}
+	var sub Graph
+	sub.EdgeDefault = EdgeUndirected
+	var n Node
+	n.ID = "a1"
+	sub.Nodes = []Node{n}
+	sub.Edges = []Edge{{Source: "a1", Target: "a1"}}
+	g.Nodes[0].Graphs = []Graph{sub}
+	g.Edges = []Edge{{Source: "a", Target: "b"}, {Source: "b", Target: "a1"}}
+	doc.Graphs = []Graph{g}
+
+	require.Equal(t, &Stats{
+		Graphs: 2, MaxDepth: 2,
+		Nodes: 4, Edges: 3,
+		Directed: 2, Undirected: 1, SelfLoops: 1,
+		Isolated: 1,
+		Keys:     2, KeyUsage: map[string]int{"d0": 3, "d1": 0},
+		Degrees:   []int{1, 1, 1, 1},
+		MaxDegree: 3, AvgDegree: 1.5,
+	}, doc.Stats())
+}
+
+func TestMerge(t *testing.T) {
+	newDoc := func(key Key, edgeDefault EdgeDir, nodes ...string) *Document {
+		var g Graph
+		g.ID = "G"
+		g.EdgeDefault = edgeDefault
+		for _, id := range nodes {
+			var n Node
+			n.ID = id
+			n.Data = []Data{{Key: key.ID, Data: []xml.Token{xml.CharData(id)}}}
+			g.Nodes = append(g.Nodes, n)
+		}
+		g.Edges = []Edge{{Source: nodes[0], Target: nodes[1]}}
+		return &Document{Keys: []Key{key}, Graphs: []Graph{g}}
+	}
+	a := newDoc(NewKey(KindNode, "d0", "label", "string"), EdgeDirected, "a", "b")
+	b := newDoc(NewKey(KindNode, "d1", "label", "string"), EdgeDirected, "b", "c")
+	c := newDoc(NewKey(KindNode, "d0", "name", "string"), EdgeUndirected, "c", "d")
+
+	_, err := Merge([]*Document{a, b}, &MergeOptions{OnConflict: MergeError})
+	require.Error(t, err)
+
+	doc, err := Merge([]*Document{a, b, c}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []Key{
+		NewKey(KindNode, "d0", "label", "string"),
+		NewKey(KindNode, "d1", "name", "string"),
+	}, doc.Keys)
+	require.Len(t, doc.Graphs, 1)
+	g := doc.Graphs[0]
+	var ids []string
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID+"="+n.Data[0].Key)
+	}
+	require.Equal(t, []string{"a=d0", "b=d0", "b_2=d0", "c=d0", "c_2=d1", "d=d1"}, ids)
+	require.Equal(t, "b_2", g.Edges[1].Source)
+	require.Equal(t, "c_2", g.Edges[2].Source)
+	require.Equal(t, []xml.Attr{{Name: xml.Name{Local: "directed"}, Value: "false"}}, g.Edges[2].Unrecognized)
+	// the original documents are not modified
+	require.Equal(t, "d0", c.Keys[0].ID)
+	require.Equal(t, "b", b.Graphs[0].Nodes[0].ID)
+
+	doc, err = Merge([]*Document{a, b, c}, &MergeOptions{OnConflict: MergeCombine})
+	require.NoError(t, err)
+	g = doc.Graphs[0]
+	ids = nil
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	require.Equal(t, []string{"a", "b", "c", "d"}, ids)
+	require.Equal(t, []Data{
+		{Key: "d0", Data: []xml.Token{xml.CharData("c")}},
+		{Key: "d1", Data: []xml.Token{xml.CharData("c")}},
+	}, g.Nodes[2].Data)
+	require.Len(t, g.Edges, 3)
+
+	doc, err = Merge([]*Document{a, b}, &MergeOptions{SeparateGraphs: true})
+	require.NoError(t, err)
+	require.Len(t, doc.Graphs, 2)
+	require.Equal(t, "G", doc.Graphs[0].ID)
+	require.Equal(t, "G_2", doc.Graphs[1].ID)
+	require.Equal(t, "b_2", doc.Graphs[1].Nodes[0].ID)
+}
+
+func TestDiff(t *testing.T) {
+	a, err := Decode(strings.NewReader(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="label" attr.type="string"/>
+<key id="d1" for="edge" attr.name="weight" attr.type="int"/>
+<graph id="G" edgedefault="directed">
+<node id="a"><data key="d0">A</data></node>
+<node id="b"><data key="d0">B</data></node>
+<node id="c"/>
+<edge source="a" target="b"><data key="d1">1</data></edge>
+<edge source="a" target="b"/>
+</graph>
+</graphml>`))
+	require.NoError(t, err)
+	b, err := Decode(strings.NewReader(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="k1" for="edge" attr.name="weight" attr.type="double"/>
+<key id="k0" for="node" attr.name="label" attr.type="string"/>
+<graph id="G" edgedefault="directed">
+<node id="a"><data key="k0">A</data></node>
+<node id="b"><data key="k0">B2</data></node>
+<node id="d"><data key="k0">D</data></node>
+<edge source="a" target="b"><data key="k1">1</data></edge>
+</graph>
+</graphml>`))
+	require.NoError(t, err)
+	require.Empty(t, Diff(a, a))
+	changes := Diff(a, b)
+	require.Equal(t, []Change{
+		{Op: ChangeModify, Element: "key", ID: "edge:weight", Attr: "attr.type", Old: "int", New: "double"},
+		{Op: ChangeModify, Element: "node", ID: "b", Attr: "label", Old: "B", New: "B2"},
+		{Op: ChangeRemove, Element: "node", ID: "c"},
+		{Op: ChangeRemove, Element: "edge", ID: "a->b#2"},
+		{Op: ChangeAdd, Element: "node", ID: "d", Attrs: map[string]string{"graph": "G", "label": "D"}},
+	}, changes)
+	require.Equal(t, `~ node b label: "B" -> "B2"`, changes[1].String())
+	require.Equal(t, `- node c`, changes[2].String())
+
+	// anonymous elements, unrecognized attributes and nesting
+	a, err = Decode(strings.NewReader(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<graph id="G" edgedefault="directed">
+<node/><node/>
+<node id="a"><graph id="S"><node id="s"/></graph></node>
+<edge source="a" target="s" directed="false" sourceport="p"/>
+</graph>
+</graphml>`))
+	require.NoError(t, err)
+	b, err = Decode(strings.NewReader(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<graph id="G" edgedefault="directed">
+<node/>
+<node id="a"/>
+<edge source="a" target="s"/>
+</graph>
+<graph id="S"><node id="s"/></graph>
+</graphml>`))
+	require.NoError(t, err)
+	changes = Diff(a, b)
+	require.Equal(t, []Change{
+		{Op: ChangeRemove, Element: "node", ID: "G/node[2]"},
+		{Op: ChangeRemove, Element: "graph", ID: "S", Attr: "parent", Old: "a"},
+		{Op: ChangeRemove, Element: "edge", ID: "a->s", Attr: "@directed", Old: "false"},
+		{Op: ChangeRemove, Element: "edge", ID: "a->s", Attr: "@sourceport", Old: "p"},
+	}, changes)
+	require.False(t, Equal(a, b))
+}
+
+func TestSelector(t *testing.T) {
+	doc, err := Decode(strings.NewReader(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="label" attr.type="string"/>
+<key id="d1" for="edge" attr.name="weight" attr.type="double"><default>1</default></key>
+<graph id="G" edgedefault="directed">
+<node id="a"><data key="d0">auth-api</data></node>
+<node id="b"><data key="d0">auth-db</data></node>
+<node id="c"><data key="d0">billing</data></node>
+<node id="d"/>
+<edge source="a" target="b"><data key="d1">0.5</data></edge>
+<edge source="a" target="c"/>
+<edge source="b" target="d"/>
+</graph>
+</graphml>`))
+	require.NoError(t, err)
+	nodes := func(expr string) []string {
+		s, err := ParseSelector(expr)
+		require.NoError(t, err)
+		var ids []string
+		for _, n := range doc.Graphs[0].Nodes {
+			if s.MatchNode(doc, &n) {
+				ids = append(ids, n.ID)
+			}
+		}
+		return ids
+	}
+	require.Equal(t, []string{"a", "b"}, nodes(`label~="auth.*"`))
+	require.Equal(t, []string{"c", "d"}, nodes(`label!~="auth.*"`))
+	require.Equal(t, []string{"d"}, nodes(`!label`))
+	require.Equal(t, []string{"a", "c"}, nodes(`label=auth-api || d0 = "billing"`))
+	require.Equal(t, []string{"b"}, nodes(`label~="auth.*" && id!=a`))
+	for _, expr := range []string{``, `label=`, `label<x`, `label~="("`, `a b`, `label="x`} {
+		_, err = ParseSelector(expr)
+		require.Error(t, err, expr)
+	}
+
+	ns, err := ParseSelector(`label~="auth.*" || id=d`)
+	require.NoError(t, err)
+	es, err := ParseSelector(`weight>=1`)
+	require.NoError(t, err)
+	out := doc.Filter(ns, es)
+	g := out.Graphs[0]
+	require.Len(t, g.Nodes, 3)
+	require.Equal(t, []Edge{doc.Graphs[0].Edges[2]}, g.Edges)
+	require.Len(t, doc.Graphs[0].Nodes, 4)
+}
+
+func TestAnonymize(t *testing.T) {
+	doc, err := Decode(strings.NewReader(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<!-- internal -->
+<key id="d0" for="node" attr.name="label" attr.type="string"/>
+<key id="d1" for="node" attr.name="size" attr.type="int"/>
+<key id="d2" for="edge" attr.name="weight" attr.type="double"/>
+<graph id="G" edgedefault="directed">
+<node id="alice"><data key="d0">Alice</data><data key="d1">3</data></node>
+<node id="bob"><data key="d0">Bob</data><data key="d1">5</data></node>
+<edge source="alice" target="bob" label="friends"><data key="d2">1</data></edge>
+</graph>
+</graphml>`))
+	require.NoError(t, err)
+	doc.Anonymize(AnonymizeOptions{DropKeys: []string{"weight"}, HashIDs: true, Salt: "x", StripLabels: true})
+	require.Empty(t, doc.Comments)
+	require.Equal(t, []Key{NewKey(KindNode, "d1", "size", "int")}, doc.Keys)
+	g := doc.Graphs[0]
+	a, b := g.Nodes[0], g.Nodes[1]
+	require.Len(t, a.ID, 17)
+	require.NotEqual(t, a.ID, b.ID)
+	require.Equal(t, []Data{{Key: "d1", Data: []xml.Token{xml.CharData("3")}}}, a.Data)
+	e := g.Edges[0]
+	require.Equal(t, [2]string{a.ID, b.ID}, [2]string{e.Source, e.Target})
+	require.Empty(t, e.Data)
+	require.Empty(t, e.Unrecognized)
+}
+
+func TestSplit(t *testing.T) {
+	doc, err := Decode(strings.NewReader(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="label" attr.type="string"/>
+<graph id="G1" edgedefault="directed">
+<node id="a"/>
+<node id="b"><graph id="b:" edgedefault="directed"><node id="b1"/><node id="b2"/><edge source="b1" target="c"/></graph></node>
+<node id="c"/>
+<node id="d"/>
+<node id="e"/>
+<edge source="a" target="b"/>
+<edge source="d" target="e"/>
+</graph>
+<graph id="G2" edgedefault="undirected">
+<node id="x"/>
+</graph>
+</graphml>`))
+	require.NoError(t, err)
+
+	graphs := doc.SplitGraphs()
+	require.Len(t, graphs, 2)
+	require.Equal(t, doc.Keys, graphs[1].Keys)
+	require.Equal(t, doc.Graphs[1], graphs[1].Graphs[0])
+
+	comps := doc.SplitComponents()
+	var ids [][]string
+	for _, d := range comps {
+		require.Len(t, d.Graphs, 1)
+		var nodes []string
+		for _, n := range d.Graphs[0].Nodes {
+			nodes = append(nodes, n.ID)
+		}
+		ids = append(ids, nodes)
+	}
+	require.Equal(t, [][]string{{"a", "b", "c"}, {"d", "e"}, {"x"}}, ids)
+	require.Len(t, comps[0].Graphs[0].Edges, 1)
+	require.Equal(t, EdgeUndirected, comps[2].Graphs[0].EdgeDefault)
+}
+
+func TestKeyMigration(t *testing.T) {
+	doc, err := Decode(strings.NewReader(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="size" attr.type="double"><default>1.0</default></key>
+<key id="d1" for="node" attr.name="weight" attr.type="string"/>
+<key id="d2" for="edge" attr.name="weight" attr.type="string"/>
+<graph id="G" edgedefault="directed">
+<node id="a"><data key="d0">2.0</data><data key="d1">heavy</data></node>
+<node id="b"><data key="d0">3</data></node>
+<edge source="a" target="b"><data key="d2">1.5</data></edge>
+</graph>
+</graphml>`))
+	require.NoError(t, err)
+
+	_, err = doc.RenameAttr(KindNode, "size", "weight")
+	require.Error(t, err)
+	n, err := doc.RenameAttr("", "weight", "w")
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, "w", doc.Keys[2].Name)
+
+	_, err = doc.RetypeAttr(KindNode, "w", "int")
+	require.Error(t, err)
+	require.Equal(t, "string", doc.Keys[1].Type)
+
+	n, err = doc.RetypeAttr(KindNode, "size", "int")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, "int", doc.Keys[0].Type)
+	require.Equal(t, []xml.Token{xml.CharData("1")}, doc.Keys[0].Default)
+	require.Equal(t, []xml.Token{xml.CharData("2")}, doc.Graphs[0].Nodes[0].Data[0].Data)
+
+	n, err = doc.RetypeAttr(KindEdge, "w", "double")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Empty(t, doc.Validate(dataTypesRule))
+}
+
+func TestErrors(t *testing.T) {
+	decode := func(s string) error {
+		_, err := Decode(strings.NewReader(s))
+		require.Error(t, err)
+		return err
+	}
+	const ns = `xmlns="http://graphml.graphdrawing.org/xmlns"`
+
+	err := decode(`<graphml><graph/></graphml>`)
+	require.True(t, errors.Is(err, ErrMissingNamespace))
+	var ue ErrUnexpectedElement
+	require.True(t, errors.As(err, &ue))
+	require.Equal(t, "graphml", ue.Name.Local)
+
+	err = decode(`<graphml ` + ns + `><graph><node id="a"/><node id="a"/></graph></graphml>`)
+	var de DecodeError
+	require.True(t, errors.As(err, &de))
+	require.Equal(t, ErrDuplicateID{ID: "a"}, de.Err)
+	require.Equal(t, "graph[0]/node[1]", de.Path)
+
+	err = decode(`<graphml ` + ns + `><key id="d0" for="node"/><key id="d0" for="node"/></graphml>`)
+	require.True(t, errors.As(err, &de))
+	require.Equal(t, ErrDuplicateKey{ID: "d0", For: KindNode}, de.Err)
+	require.Equal(t, "key[1]", de.Path)
+
+	err = decode(`<graphml ` + ns + `><graph><node id="a"><data key="d0"/></node></graph></graphml>`)
+	require.True(t, errors.As(err, new(ErrUnknownKey)))
+	require.EqualError(t, err, `graph[0]/node[0]/data[0] (line 1, column 92, offset 91): unexpected attr for node: "d0"`)
+
+	err = decode(`<graphml ` + ns + `><graph><hyperedge/></graph></graphml>`)
+	require.True(t, errors.As(err, new(ErrUnknownElement)))
+	require.False(t, errors.Is(err, ErrMissingNamespace))
+
+	err = decode(`<graphml ` + ns + `><graph>text</graph></graphml>`)
+	require.True(t, errors.As(err, new(ErrUnexpectedToken)))
+
+	err = new(Data).SetValue("urn:none", 1)
+	require.Equal(t, ErrNoCodec{Namespace: "urn:none"}, err)
+}
+
+func TestDecodePartial(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="label"/>
+<graph id="G">
+	<node id="a"><data key="d0">A</data></node>
+	<node id="b"><data key="d0">B</data><data key="d0">`
+
+	doc, err := DecodeWith(strings.NewReader(src), nil)
+	require.Error(t, err)
+	require.Nil(t, doc)
+
+	doc, err = DecodeWith(strings.NewReader(src), &Options{Partial: true})
+	var de DecodeError
+	require.True(t, errors.As(err, &de))
+	require.Equal(t, "graph[0]/node[1]/data[1]", de.Path)
+	require.NotNil(t, doc)
+	require.True(t, doc.Incomplete)
+	require.Len(t, doc.Keys, 1)
+	require.Len(t, doc.Graphs, 1)
+	g := doc.Graphs[0]
+	require.Equal(t, "G", g.ID)
+	require.Len(t, g.Nodes, 2)
+	require.Equal(t, "b", g.Nodes[1].ID)
+	require.Len(t, g.Nodes[1].Data, 1)
+	require.True(t, doc.Clone().Incomplete)
+
+	doc, err = DecodeWith(strings.NewReader(src+`</data></node></graph></graphml>`), &Options{Partial: true})
+	require.NoError(t, err)
+	require.False(t, doc.Incomplete)
+}
+
+func TestDecodeTrace(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><!--c--><graph><node id="a"/><edge source="a" target="a"/><port/></graph></graphml>`
+	var events []string
+	_, err := DecodeWith(strings.NewReader(src), &Options{Trace: func(ev TraceEvent) {
+		events = append(events, ev.String())
+	}})
+	require.Error(t, err)
+	require.Equal(t, []string{
+		"0: start graphml",
+		"55: comment",
+		"63: start graph",
+		"70: start node",
+		"84: end node",
+		"84: start edge",
+		"113: end edge",
+		"113: start port",
+		"120: error graph",
+	}, events)
+}
+
+type testMetrics struct {
+	decoded, encoded []CodecStats
+}
+
+func (m *testMetrics) Decoded(s CodecStats, err error) {
+	s.Duration = 0
+	m.decoded = append(m.decoded, s)
+}
+
+func (m *testMetrics) Encoded(s CodecStats, err error) {
+	s.Duration = 0
+	m.encoded = append(m.encoded, s)
+}
+
+func TestMetrics(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="label"><default>x</default></key>
+<graph id="G">
+	<node id="a"><data key="d0">A</data></node>
+	<node id="b"><data key="d0"><b>B</b></data></node>
+	<edge source="a" target="b"/>
+</graph>
+</graphml>`
+	m := new(testMetrics)
+	opt := &Options{Metrics: m}
+	doc, err := DecodeWith(strings.NewReader(src), opt)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, EncodeWith(&buf, doc, opt))
+
+	exp := CodecStats{Keys: 1, Graphs: 1, Nodes: 2, Edges: 1, Data: 2, Tokens: 5}
+	got := m.decoded[0]
+	require.Equal(t, int64(len(src)), got.Bytes)
+	got.Bytes = 0
+	require.Equal(t, exp, got)
+
+	got = m.encoded[0]
+	require.Equal(t, int64(buf.Len()), got.Bytes)
+	got.Bytes = 0
+	require.Equal(t, exp, got)
+}
+
+func TestDecodeWarnings(t *testing.T) {
+	const src = `<graphml>
+<key id="d0" for="edge" attr.name="Edge Label"/>
+<graph id="G">
+	<node id="a"><data key="d1">A</data></node>
+	<node id="b"><data key="d1">B</data></node>
+	<edge source="a" target="b"><data key="d0">e</data></edge>
+</graph>
+</graphml>`
+	var warns []string
+	_, err := DecodeWith(strings.NewReader(src), &Options{Profile: ProfileGephi, Warn: func(w Warning) {
+		warns = append(warns, w.String())
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`1:10: element "graphml" has no GraphML namespace (missing-namespace)`,
+		`4:30: graph[0]/node[0]/data[0]: key "d1" is not declared for node (undeclared-key)`,
+		`key[0]: attribute "Edge Label" of key "d0" is renamed to "label" (renamed-attr)`,
+	}, warns)
+}
+
+func TestPositions(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node"/>
+<graph id="G">
+  <node id="a"><data key="d0">A</data></node>
+  <node id="b">
+    <graph id="b:">
+      <node id="c"/>
+    </graph>
+  </node>
+  <edge id="e" source="a" target="c"/>
+</graph>
+</graphml>`
+	pos := make(PositionMap)
+	doc, err := DecodeWith(strings.NewReader(src), &Options{Positions: pos})
+	require.NoError(t, err)
+	require.Equal(t, PositionMap{
+		"key[0]":                            {Line: 2, Column: 1, Offset: 56},
+		"graph[0]":                          {Line: 3, Column: 1, Offset: 82},
+		"graph[0]/node[0]":                  {Line: 4, Column: 3, Offset: 99},
+		"graph[0]/node[0]/data[0]":          {Line: 4, Column: 16, Offset: 112},
+		"graph[0]/node[1]":                  {Line: 5, Column: 3, Offset: 145},
+		"graph[0]/node[1]/graph[0]":         {Line: 6, Column: 5, Offset: 163},
+		"graph[0]/node[1]/graph[0]/node[0]": {Line: 7, Column: 7, Offset: 185},
+		"graph[0]/edge[0]":                  {Line: 10, Column: 3, Offset: 225},
+	}, pos)
+	require.Equal(t, "graph[0]/node[1]/graph[0]/node[0]", doc.ElementPath("c"))
+	require.Equal(t, "graph[0]/edge[0]", doc.ElementPath("e"))
+	require.Equal(t, "graph[0]/node[1]/graph[0]", doc.ElementPath("b:"))
+	require.Equal(t, "", doc.ElementPath("x"))
+	require.Equal(t, "4:16", pos["graph[0]/node[0]/data[0]"].String())
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="label"/>
+<graph id="G"><node id="a"><data key="d0">A</data></node></graph>
+</graphml>`
+	rep, err := VerifyRoundTrip(strings.NewReader(src))
+	require.NoError(t, err)
+	require.True(t, rep.OK())
+	require.Equal(t, "round trip: ok", rep.String())
+
+	b := rep.Doc.Clone()
+	b.Graphs[0].Nodes[0].Data[0].Data = []xml.Token{xml.CharData("B")}
+	require.True(t, Equal(rep.Doc, rep.Doc.Clone()))
+	require.False(t, Equal(rep.Doc, b))
+
+	_, err = VerifyRoundTrip(strings.NewReader(`<graphml/>`))
+	require.Error(t, err)
+
+	const lossy = `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<!-- comment -->
+<key id="d0" for="node" attr.name="label" attr.type="string"/>
+<graph id="G" edgedefault="directed">
+<node id="a"><data key="d0">A</data><graph id="S"><node id="s"/></graph></node>
+<edge source="a" target="s" directed="false" sourceport="p"/>
+</graph>
+</graphml>`
+	rep, err = VerifyRoundTrip(strings.NewReader(lossy))
+	require.NoError(t, err)
+	require.True(t, rep.OK(), "%v", rep)
+
+	rep, err = VerifyRoundTripWith(strings.NewReader(lossy), &Options{Profile: ProfileGephi})
+	require.NoError(t, err)
+	require.False(t, rep.OK())
+	require.Equal(t, "round trip: 3 changes\n+ graph S edgedefault: \"directed\"\n- edge a->s\n+ edge e0", rep.String())
+
+	// each of these changes must be detected
+	for i, fn := range []func(doc *Document){
+		func(doc *Document) { doc.Instr = xml.ProcInst{} },
+		func(doc *Document) { doc.Comments = nil },
+		func(doc *Document) { doc.Attrs = append(doc.Attrs, newAttr("", "version", "1")) },
+		func(doc *Document) { doc.Keys[0].YFilesType = "nodegraphics" },
+		func(doc *Document) { doc.Graphs[0].Edges[0].Unrecognized = nil },
+		func(doc *Document) { doc.Graphs[0].Nodes[0].Data[0].Unrecognized = []xml.Attr{newAttr("", "x", "1")} },
+		func(doc *Document) {
+			// move the nested graph to the top level
+			doc.Graphs = append(doc.Graphs, doc.Graphs[0].Nodes[0].Graphs...)
+			doc.Graphs[0].Nodes[0].Graphs = nil
+		},
+	} {
+		b := rep.Doc.Clone()
+		fn(b)
+		require.False(t, Equal(rep.Doc, b), "case %d", i)
+	}
+}
+
+func TestEncodeEnums(t *testing.T) {
+	encode := func(doc *Document) error {
+		return Encode(io.Discard, doc)
+	}
+	err := encode(&Document{Graphs: []Graph{{EdgeDefault: "Directed"}}})
+	require.Equal(t, ErrInvalidValue{Attr: "edgedefault", Value: "Directed", Suggestion: "directed"}, err)
+	require.EqualError(t, err, `invalid edgedefault value "Directed", did you mean "directed"?`)
+
+	err = encode(&Document{Keys: []Key{{Object: Object{ID: "d0"}, For: "nodes"}}})
+	require.Equal(t, ErrInvalidValue{Attr: "for", Value: "nodes", Suggestion: "node"}, err)
+
+	err = encode(&Document{Keys: []Key{{Object: Object{ID: "d0"}, For: "egde"}}})
+	require.Equal(t, ErrInvalidValue{Attr: "for", Value: "egde", Suggestion: "edge"}, err)
+
+	err = encode(&Document{Keys: []Key{{Object: Object{ID: "d0"}, For: "vertex"}}})
+	require.EqualError(t, err, `invalid for value "vertex"`)
+
+	require.NoError(t, encode(&Document{
+		Keys:   []Key{{Object: Object{ID: "d0"}}, {Object: Object{ID: "d1"}, For: KindHyperEdge}},
+		Graphs: []Graph{{}, {EdgeDefault: EdgeUndirected}},
+	}))
+}
+
+func TestEdgeDirection(t *testing.T) {
+	g := &Graph{Edges: []Edge{
+		{Source: "a", Target: "b"},
+		{ExtObject: ExtObject{Object: Object{Unrecognized: []xml.Attr{newAttr("", "directed", "true")}}}},
+		{ExtObject: ExtObject{Object: Object{Unrecognized: []xml.Attr{newAttr("", "directed", "false")}}}},
+		{ExtObject: ExtObject{Object: Object{Unrecognized: []xml.Attr{newAttr("", "directed", "maybe")}}}},
+	}}
+	dirs := func() []EdgeDir {
+		var out []EdgeDir
+		for i := range g.Edges {
+			out = append(out, g.Edges[i].EffectiveDirection(g))
+		}
+		return out
+	}
+	require.False(t, g.IsDirected())
+	require.Equal(t, []EdgeDir{EdgeUndirected, EdgeDirected, EdgeUndirected, EdgeUndirected}, dirs())
+
+	g.EdgeDefault = EdgeDirected
+	require.True(t, g.IsDirected())
+	require.Equal(t, []EdgeDir{EdgeDirected, EdgeDirected, EdgeUndirected, EdgeDirected}, dirs())
+
+	require.Equal(t, EdgeUndirected, g.Edges[0].EffectiveDirection(nil))
+}
+
+func TestDump(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="label" attr.type="string"><default>?</default></key>
+<key id="d1" for="edge" attr.name="weight" attr.type="double"/>
+<graph id="G" edgedefault="directed">
+  <node id="a"><data key="d0">A long label</data></node>
+  <node id="b">
+    <graph id="b:" edgedefault="undirected">
+      <node id="c"/>
+      <node id="d"/>
+      <edge source="c" target="d"/>
+    </graph>
+  </node>
+  <node id="e"/>
+  <edge id="e0" source="a" target="b" directed="false"><data key="d1">1.5</data></edge>
+</graph>
+</graphml>`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, doc.Dump(&buf, nil))
+	require.Equal(t, `graphml: 2 keys, 1 graph
+  key d0: for=node name="label" type=string default="?"
+  key d1: for=edge name="weight" type=double
+  graph G: directed, 3 nodes, 1 edge
+    node a label="A long label"
+    node b
+      graph b:: undirected, 2 nodes, 1 edge
+        node c
+        node d
+        edge c -- d
+    node e
+    edge e0 a -- b weight="1.5"
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, doc.Dump(&buf, &DumpOptions{MaxElements: 1, MaxValueLen: 6}))
+	require.Equal(t, `graphml: 2 keys, 1 graph
+  key d0: for=node name="label" type=string default="?"
+  key d1: for=edge name="weight" type=double
+  graph G: directed, 3 nodes, 1 edge
+    node a label="A long..."
+    ... 2 more nodes
+    edge e0 a -- b weight="1.5"
+`, buf.String())
+	require.True(t, strings.HasPrefix(doc.String(), "graphml: 2 keys, 1 graph\n"))
+}
+
+func TestDocumentAttrs(t *testing.T) {
+	doc := &Document{Graphs: []Graph{{EdgeDefault: EdgeDirected}}}
+	doc.Graphs[0].ID = "G"
+	doc.SetSchemaLocation("")
+	_, ok := doc.Attr("", "xmlns")
+	require.True(t, ok)
+	prefix, ok := doc.NamespacePrefix(XSINamespace)
+	require.True(t, ok)
+	require.Equal(t, "xsi", prefix)
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, doc))
+	require.Equal(t, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"`+
+		` xsi:schemaLocation="http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd">`+
+		`<graph id="G" edgedefault="directed"></graph></graphml>`, buf.String())
+
+	doc2, err := Decode(&buf)
+	require.NoError(t, err)
+	loc, ok := doc2.Attr(XSINamespace, "schemaLocation")
+	require.True(t, ok)
+	require.Equal(t, Namespace+" "+SchemaLocation, loc)
+	doc2.SetSchemaLocation("graphml.xsd")
+	require.Len(t, doc2.Attrs, 3)
+	loc, _ = doc2.Attr(XSINamespace, "schemaLocation")
+	require.Equal(t, Namespace+" graphml.xsd", loc)
+
+	require.True(t, doc2.RemoveAttr(XSINamespace, "schemaLocation"))
+	require.False(t, doc2.RemoveAttr(XSINamespace, "schemaLocation"))
+	require.Len(t, doc2.Attrs, 2)
+}
+
+func TestParseAttrType(t *testing.T) {
+	typ, err := ParseAttrType("double")
+	require.NoError(t, err)
+	require.Equal(t, TypeDouble, typ)
+
+	typ, err = ParseAttrType("")
+	require.NoError(t, err)
+	require.Equal(t, AttrType(""), typ)
+
+	_, err = ParseAttrType("Integer")
+	require.EqualError(t, err, `invalid attr.type value "Integer", did you mean "int"?`)
+	_, err = ParseAttrType("bool")
+	require.Equal(t, ErrInvalidValue{Attr: "attr.type", Value: "bool", Suggestion: "boolean"}, err)
+	_, err = ParseAttrType("text")
+	require.EqualError(t, err, `invalid attr.type value "text"`)
+	_, err = ParseAttrType("Strings")
+	require.Equal(t, ErrInvalidValue{Attr: "attr.type", Value: "Strings", Suggestion: "string"}, err)
+
+	k := NewKey(KindNode, "d0", "weight", TypeFloat)
+	require.Equal(t, "float", k.Type)
+}
+
+func TestParseKind(t *testing.T) {
+	k, err := ParseKind("node")
+	require.NoError(t, err)
+	require.Equal(t, KindNode, k)
+	k, err = ParseKind("")
+	require.NoError(t, err)
+	require.Equal(t, KindAll, k)
+	_, err = ParseKind("nodes")
+	require.EqualError(t, err, `invalid for value "nodes", did you mean "node"?`)
+
+	dir, err := ParseEdgeDir("undirected")
+	require.NoError(t, err)
+	require.Equal(t, EdgeUndirected, dir)
+	_, err = ParseEdgeDir("Directed")
+	require.Equal(t, ErrInvalidValue{Attr: "edgedefault", Value: "Directed", Suggestion: "directed"}, err)
+	_, err = ParseEdgeDir("")
+	require.Error(t, err)
+
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><key id="d0" for="%s"/><graph edgedefault="%s"/></graphml>`
+	_, err = DecodeWith(strings.NewReader(fmt.Sprintf(src, "nodes", "directed")), nil)
+	require.NoError(t, err)
+	_, err = DecodeWith(strings.NewReader(fmt.Sprintf(src, "nodes", "directed")), &Options{Strict: true})
+	require.True(t, errors.As(err, new(ErrInvalidValue)))
+	require.EqualError(t, err, `key[0] (line 1, column 82, offset 81): invalid for value "nodes", did you mean "node"?`)
+	_, err = DecodeWith(strings.NewReader(fmt.Sprintf(src, "node", "Directed")), &Options{Strict: true})
+	require.Equal(t, ErrInvalidValue{Attr: "edgedefault", Value: "Directed", Suggestion: "directed"}, errors.Unwrap(err))
+	_, err = DecodeWith(strings.NewReader(fmt.Sprintf(src, "", "undirected")), &Options{Strict: true})
+	require.NoError(t, err)
+}
+
+func TestNodeAddGraph(t *testing.T) {
+	doc := &Document{Graphs: []Graph{{EdgeDefault: EdgeDirected}}}
+	root := &doc.Graphs[0]
+	root.ID = "G"
+	root.Nodes = []Node{{}}
+	n := &root.Nodes[0]
+	n.ID = "n0"
+	g := n.AddGraph(EdgeUndirected)
+	require.Equal(t, "n0:", g.ID)
+	require.Equal(t, EdgeUndirected, g.EdgeDefault)
+	g.Nodes = []Node{{}}
+	g.Nodes[0].ID = "n0::n0"
+	g2 := n.AddGraph("")
+	require.Equal(t, "n0:1", g2.ID)
+	require.Len(t, n.Graphs, 2)
+
+	v := doc.Freeze()
+	inner, ok := v.Node("n0::n0")
+	require.True(t, ok)
+	outer, _ := v.Node("n0")
+	require.Same(t, outer, v.ParentNode(inner))
+	require.Nil(t, v.ParentNode(outer))
+	ng, _ := v.Graph("n0:")
+	top, _ := v.Graph("G")
+	require.Same(t, top, v.ParentGraph(ng))
+	require.Nil(t, v.ParentGraph(top))
+}
+
+func TestNewData(t *testing.T) {
+	require.Equal(t, Data{Key: "d0", Data: []xml.Token{xml.CharData("a b")}}, NewStringData("d0", "a b"))
+	require.Equal(t, Data{Key: "d1", Data: []xml.Token{xml.CharData("-42")}}, NewIntData("d1", -42))
+	require.Equal(t, Data{Key: "d2", Data: []xml.Token{xml.CharData("0.1")}}, NewFloatData("d2", 0.1))
+
+	toks := []xml.Token{xml.StartElement{Name: xml.Name{Local: "b"}, Attr: []xml.Attr{}}, xml.CharData("x"), xml.EndElement{Name: xml.Name{Local: "b"}}}
+	d := NewXMLData("d3", toks)
+	require.Equal(t, toks, d.Data)
+	toks[1].(xml.CharData)[0] = 'y'
+	require.Equal(t, xml.CharData("x"), d.Data[1])
+
+	doc := &Document{
+		Keys:   []Key{NewKey(KindNode, "d1", "size", TypeInt)},
+		Graphs: []Graph{{Nodes: []Node{{ExtObject: ExtObject{Data: []Data{NewIntData("d1", 3)}}}}}},
+	}
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, Encode(buf, doc))
+	require.Contains(t, buf.String(), `<data key="d1">3</data>`)
+}
+
+func TestCompact(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="label" attr.type="string"/>
+<key id="d1" for="node" attr.name="note" attr.type="string"/>
+<key id="d2" for="node" attr.name="color" attr.type="string"><default>red</default></key>
+<key id="d3" for="edge" attr.name="weight" attr.type="double"/>
+<graph id="G" edgedefault="directed">
+<node id="n0" x="1" x="2"><data key="d0">a</data><data key="d1"></data><data key="d2"></data>
+<graph edgedefault="directed"></graph>
+</node>
+<node id="n1"><graph id="n1:" edgedefault="directed"></graph></node>
+</graph>
+</graphml>`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+	st := doc.Compact()
+	require.Equal(t, CompactStats{Keys: 2, Data: 1, Graphs: 1, Attrs: 1}, st)
+	require.Equal(t, 5, st.Total())
+	require.Equal(t, "removed 2 keys, 1 data element, 1 nested graph, 1 duplicate attribute", st.String())
+
+	require.Len(t, doc.Keys, 2)
+	n0 := doc.Graphs[0].Nodes[0]
+	require.Equal(t, []xml.Attr{{Name: xml.Name{Local: "x"}, Value: "1"}}, n0.Unrecognized)
+	require.Len(t, n0.Data, 2)
+	require.Nil(t, n0.Graphs)
+	require.Len(t, doc.Graphs[0].Nodes[1].Graphs, 1)
+	require.Equal(t, CompactStats{}, doc.Compact())
+}
+
+func TestKeyUsage(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="d0" for="node" attr.name="label" attr.type="string"/>
+<key id="d0" for="edge" attr.name="label" attr.type="string"/>
+<key id="d1" for="all" attr.name="weight" attr.type="double"/>
+<key id="d2" for="node" attr.name="note" attr.type="string"/>
+<graph id="G" edgedefault="directed">
+<data key="d1">0</data>
+<node id="n0"><data key="d0">a</data><data key="d1">1</data></node>
+<node id="n1"><data key="d0">b</data><data key="d1">1</data></node>
+<edge source="n0" target="n1"><data key="d0">a-b</data></edge>
+</graph>
+</graphml>`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+	n1 := &doc.Graphs[0].Nodes[1]
+	n1.Data = append(n1.Data, NewXMLData("d9", []xml.Token{xml.StartElement{Name: xml.Name{Local: "x"}}, xml.EndElement{Name: xml.Name{Local: "x"}}}))
+	usage := doc.KeyUsage()
+	require.Equal(t, []KeyUsage{
+		{Key: &doc.Keys[0], ID: "d0", Nodes: 2, Samples: []string{"a", "b"}},
+		{Key: &doc.Keys[1], ID: "d0", Edges: 1, Samples: []string{"a-b"}},
+		{Key: &doc.Keys[2], ID: "d1", Graphs: 1, Nodes: 2, Samples: []string{"0", "1"}},
+		{Key: &doc.Keys[3], ID: "d2"},
+		{ID: "d9", Nodes: 1},
+	}, usage)
+	require.Equal(t, 3, usage[2].Total())
+}
+
+func TestKeysFor(t *testing.T) {
+	doc := &Document{Keys: []Key{
+		NewKey(KindAll, "d0", "label", TypeString),
+		NewKey(KindNode, "d0", "name", TypeString),
+		NewKey("", "d1", "weight", TypeDouble),
+		NewKey(KindEdge, "d2", "color", TypeString),
+		NewKey(KindNode, "d0", "dup", TypeString),
+	}}
+	k, ok := doc.KeyFor(KindNode, "d0")
+	require.True(t, ok)
+	require.Same(t, &doc.Keys[1], k)
+	k, ok = doc.KeyFor(KindEdge, "d0")
+	require.True(t, ok)
+	require.Same(t, &doc.Keys[0], k)
+	k, ok = doc.KeyFor(KindGraph, "d1")
+	require.True(t, ok)
+	require.Same(t, &doc.Keys[2], k)
+	_, ok = doc.KeyFor(KindNode, "d2")
+	require.False(t, ok)
+
+	require.Equal(t, []Key{doc.Keys[1], doc.Keys[2]}, doc.KeysFor(KindNode))
+	require.Equal(t, []Key{doc.Keys[0], doc.Keys[2], doc.Keys[3]}, doc.KeysFor(KindEdge))
+	require.Equal(t, []Key{doc.Keys[0], doc.Keys[2]}, doc.KeysFor(KindGraph))
+}
+
+func TestGraphByPath(t *testing.T) {
+	doc := &Document{Graphs: []Graph{{}, {}}}
+	doc.Graphs[0].ID = "G"
+	doc.Graphs[1].ID = "H"
+	g := &doc.Graphs[0]
+	g.Nodes = make([]Node, 2)
+	g.Nodes[0].ID = "n0"
+	g.Nodes[1].ID = "n5"
+	g1 := g.Nodes[0].AddGraph(EdgeDirected)
+	g1.Nodes = make([]Node, 1)
+	g1.Nodes[0].ID = "n0::n0"
+	g3 := g1.Nodes[0].AddGraph(EdgeDirected)
+	g2 := g.Nodes[1].AddGraph(EdgeDirected)
+	g2.ID = "g2"
+
+	for _, c := range []struct {
+		path string
+		exp  *Graph
+	}{
+		{"G", g},
+		{"H", &doc.Graphs[1]},
+		{"G/n5:g2", g2},
+		{"G/n0:n0:", g1},
+		{"G/n0:n0:/n0::n0:n0::n0:", g3},
+		{"G/n5:n5:", nil},
+		{"H/n5:g2", nil},
+		{"X", nil},
+		{"", nil},
+	} {
+		t.Run(c.path, func(t *testing.T) {
+			got, ok := doc.GraphByPath(c.path)
+			require.Equal(t, c.exp != nil, ok)
+			require.Same(t, c.exp, got)
+		})
+	}
+
+	got, ok := doc.GraphByID("n0::n0:")
+	require.True(t, ok)
+	require.Same(t, g3, got)
+	got, ok = doc.GraphByID("g2")
+	require.True(t, ok)
+	require.Same(t, g2, got)
+	_, ok = doc.GraphByID("n5:")
+	require.False(t, ok)
+}
@@ -0,0 +1,78 @@
+// Package graphmlfuzz provides fuzz targets for GraphML decoder and encoder.
+//
+// Downstream projects can run the targets against their own inputs with go test -fuzz:
+//
+//	func FuzzGraphML(f *testing.F) {
+//		if err := graphmlfuzz.Seed(f, os.DirFS("testdata"), "."); err != nil {
+//			f.Fatal(err)
+//		}
+//		f.Fuzz(graphmlfuzz.Decode)
+//	}
+package graphmlfuzz
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/dennwc/graphml"
+)
+
+// Decode is a fuzz target for the decoder. Decoding must never panic, and every successfully decoded document
+// must be encoded without errors and decoded again to an equivalent document.
+func Decode(t *testing.T, data []byte) {
+	doc, err := graphml.Decode(bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	buf := new(bytes.Buffer)
+	if err = graphml.Encode(buf, doc); err != nil {
+		t.Fatalf("cannot encode decoded document: %v", err)
+	}
+	doc2, err := graphml.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("cannot decode encoded document: %v\n%s", err, buf.Bytes())
+	}
+	buf2 := new(bytes.Buffer)
+	if err = graphml.Encode(buf2, doc2); err != nil {
+		t.Fatalf("cannot encode decoded document: %v", err)
+	}
+	if len(doc.Graphs) != len(doc2.Graphs) || len(doc.Keys) != len(doc2.Keys) {
+		t.Fatalf("documents differ after round trip:\n%s\n%s", buf.Bytes(), buf2.Bytes())
+	}
+}
+
+// Seed adds all GraphML files (see graphml.IsGraphMLFile) found in the file tree to the fuzzing corpus.
+// Compressed files are added in the decompressed form.
+func Seed(f *testing.F, fsys fs.FS, root string) error {
+	return fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if d.IsDir() || !graphml.IsGraphMLFile(d.Name()) {
+			return nil
+		}
+		file, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		var r io.Reader = file
+		if strings.HasSuffix(strings.ToLower(path), graphml.ExtGzip) {
+			zr, err := gzip.NewReader(file)
+			if err != nil {
+				return err
+			}
+			defer zr.Close()
+			r = zr
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		f.Add(data)
+		return nil
+	})
+}
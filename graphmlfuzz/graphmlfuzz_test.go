@@ -0,0 +1,14 @@
+package graphmlfuzz
+
+import (
+	"os"
+	"testing"
+)
+
+func FuzzDecode(f *testing.F) {
+	if err := Seed(f, os.DirFS("../data"), "."); err != nil {
+		f.Fatal(err)
+	}
+	f.Add([]byte(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><key id="d0" for="node"><default>x</default></key><graph edgedefault="directed"><node id="n0"><data key="d0"><a xmlns="urn:a"><b/></a></data></node></graph></graphml>`))
+	f.Fuzz(Decode)
+}
@@ -0,0 +1,419 @@
+// Package graphmlgen generates Go code for typed access to GraphML attributes declared by document keys.
+//
+// For each element kind (document, graph, node and edge) that has keys, the generated code contains:
+// constants with key IDs, a struct with one field per attribute (tagged with `graphml:"<name>"`),
+// functions to read and write the struct from and to element data, and typed getters and setters
+// for each attribute. Keys declared for all kinds are added to each of them.
+//
+// Attribute types are mapped to Go types as follows: boolean to bool, int to int32, long to int64,
+// float to float32, double to float64 and string to string. Keys with XML content (for example,
+// yFiles graphics) are skipped.
+package graphmlgen
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dennwc/graphml"
+)
+
+// Options controls code generation.
+type Options struct {
+	// Package is a name of the generated Go package. Defaults to "main".
+	Package string
+}
+
+// kindInfo describes an element kind supported by the generator.
+type kindInfo struct {
+	kind   graphml.Kind
+	prefix string // prefix of generated names
+	typ    string // Go type of the element
+}
+
+var kinds = []kindInfo{
+	{graphml.KindGraphML, "Document", "*graphml.Document"},
+	{graphml.KindGraph, "Graph", "*graphml.Graph"},
+	{graphml.KindNode, "Node", "*graphml.Node"},
+	{graphml.KindEdge, "Edge", "*graphml.Edge"},
+}
+
+var kindConsts = map[graphml.Kind]string{
+	graphml.KindAll:       "KindAll",
+	graphml.KindGraphML:   "KindGraphML",
+	graphml.KindGraph:     "KindGraph",
+	graphml.KindNode:      "KindNode",
+	graphml.KindEdge:      "KindEdge",
+	graphml.KindHyperEdge: "KindHyperEdge",
+	graphml.KindPort:      "KindPort",
+	graphml.KindEndpoint:  "KindEndpoint",
+}
+
+// goType describes how values of a GraphML attribute type are represented in Go.
+type goType struct {
+	name   string
+	parse  string // function that parses a string
+	format string // format expression with %s for the value
+}
+
+var goTypes = map[string]goType{
+	"boolean": {"bool", "gmlParseBool", "strconv.FormatBool(%s)"},
+	"int":     {"int32", "gmlParseInt32", "strconv.FormatInt(int64(%s), 10)"},
+	"long":    {"int64", "gmlParseInt64", "strconv.FormatInt(%s, 10)"},
+	"float":   {"float32", "gmlParseFloat32", "strconv.FormatFloat(float64(%s), 'g', -1, 32)"},
+	"double":  {"float64", "gmlParseFloat64", "strconv.FormatFloat(%s, 'g', -1, 64)"},
+	"string":  {"string", "", "%s"},
+}
+
+// field is an attribute of an element kind.
+type field struct {
+	key  *graphml.Key
+	name string // Go name of the attribute
+	attr string // GraphML name of the attribute
+	typ  goType
+	def  string // Go literal for the default value, if any
+}
+
+// Generate writes Go source code with typed accessors for the keys. See package description for details.
+func Generate(w io.Writer, keys []graphml.Key, opt *Options) error {
+	pkg := "main"
+	if opt != nil && opt.Package != "" {
+		pkg = opt.Package
+	}
+	var (
+		fields = make(map[graphml.Kind][]field)
+		used   []*graphml.Key
+	)
+	for i := range keys {
+		k := &keys[i]
+		typ := k.Type
+		if typ == "" {
+			if isXMLKey(k) {
+				continue
+			}
+			typ = "string"
+		}
+		gt, ok := goTypes[typ]
+		if !ok {
+			continue
+		}
+		attr := k.Name
+		if attr == "" {
+			attr = k.ID
+		}
+		f := field{key: k, attr: attr, typ: gt}
+		if k.Default != nil {
+			f.def = defaultLiteral(k, gt)
+		}
+		found := false
+		for _, ki := range kinds {
+			if k.For == ki.kind || k.For == graphml.KindAll || k.For == "" {
+				fields[ki.kind] = append(fields[ki.kind], f)
+				found = true
+			}
+		}
+		if found {
+			used = append(used, k)
+		}
+	}
+	if len(used) == 0 {
+		return errors.New("graphmlgen: no keys with simple types")
+	}
+	g := &generator{}
+	g.genKeys(used)
+	for _, ki := range kinds {
+		list := fields[ki.kind]
+		if len(list) == 0 {
+			continue
+		}
+		names := make(map[string]bool)
+		for i := range list {
+			list[i].name = uniqueName(names, goName(list[i].attr))
+		}
+		g.genKind(ki, list)
+	}
+	g.genHelpers()
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by graphmlgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\nimport (\n", pkg)
+	src.WriteString("\"encoding/xml\"\n")
+	if g.needFmt {
+		src.WriteString("\"fmt\"\n")
+	}
+	src.WriteString("\"strconv\"\n\"strings\"\n\n\"github.com/dennwc/graphml\"\n)\n")
+	src.Write(g.buf.Bytes())
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("graphmlgen: cannot format generated code: %v", err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// isXMLKey checks if the key holds XML content instead of simple values.
+func isXMLKey(k *graphml.Key) bool {
+	return k.YFilesType != ""
+}
+
+// defaultLiteral returns a Go literal for a default value of the key, or an empty string if it's not valid.
+func defaultLiteral(k *graphml.Key, gt goType) string {
+	var sb strings.Builder
+	for _, t := range k.Default {
+		cd, ok := t.(xml.CharData)
+		if !ok {
+			return ""
+		}
+		sb.Write(cd)
+	}
+	s := sb.String()
+	if gt.name == "string" {
+		return strconv.Quote(s)
+	}
+	s = strings.TrimSpace(s)
+	switch gt.name {
+	case "bool":
+		if v, err := strconv.ParseBool(s); err == nil {
+			return strconv.FormatBool(v)
+		}
+	case "int32", "int64":
+		bits := 64
+		if gt.name == "int32" {
+			bits = 32
+		}
+		if v, err := strconv.ParseInt(s, 10, bits); err == nil {
+			return strconv.FormatInt(v, 10)
+		}
+	case "float32", "float64":
+		bits := 64
+		if gt.name == "float32" {
+			bits = 32
+		}
+		if v, err := strconv.ParseFloat(s, bits); err == nil && !math.IsNaN(v) && !math.IsInf(v, 0) {
+			return strconv.FormatFloat(v, 'g', -1, bits)
+		}
+	}
+	return ""
+}
+
+// goName converts an attribute name to an exported Go identifier.
+func goName(s string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if sb.Len() == 0 && unicode.IsDigit(r) {
+			sb.WriteString("X")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 {
+		return "X"
+	}
+	return sb.String()
+}
+
+func uniqueName(used map[string]bool, name string) string {
+	out := name
+	for i := 2; used[out]; i++ {
+		out = name + strconv.Itoa(i)
+	}
+	used[out] = true
+	return out
+}
+
+type generator struct {
+	buf     bytes.Buffer
+	needFmt bool
+}
+
+func (g *generator) line(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+func (g *generator) genKeys(keys []*graphml.Key) {
+	g.line("")
+	g.line("// Keys returns declarations of all keys used by the generated code.")
+	g.line("func Keys() []graphml.Key {")
+	g.line("keys := []graphml.Key{")
+	for _, k := range keys {
+		kind := k.For
+		if kind == "" {
+			kind = graphml.KindAll
+		}
+		g.line("graphml.NewKey(graphml.%s, %q, %q, %q),", kindConsts[kind], k.ID, k.Name, k.Type)
+	}
+	g.line("}")
+	for i, k := range keys {
+		if k.Default == nil {
+			continue
+		}
+		var sb strings.Builder
+		for _, t := range k.Default {
+			if cd, ok := t.(xml.CharData); ok {
+				sb.Write(cd)
+			}
+		}
+		g.line("keys[%d].Default = []xml.Token{xml.CharData(%q)}", i, sb.String())
+	}
+	g.line("return keys")
+	g.line("}")
+}
+
+func (g *generator) genKind(ki kindInfo, fields []field) {
+	p := ki.prefix
+	g.line("")
+	g.line("// Key IDs of %s attributes.", strings.ToLower(p))
+	g.line("const (")
+	for _, f := range fields {
+		g.line("%s%sKey = %q", p, f.name, f.key.ID)
+	}
+	g.line(")")
+
+	g.line("")
+	g.line("// %sAttrs is a set of %s attributes.", p, strings.ToLower(p))
+	g.line("type %sAttrs struct {", p)
+	for _, f := range fields {
+		g.line("%s %s `graphml:%q`", f.name, f.typ.name, f.attr)
+	}
+	g.line("}")
+
+	g.line("")
+	g.line("// Read%[1]sAttrs reads all attributes of the %[2]s. Missing attributes are set to their default values.", p, strings.ToLower(p))
+	g.line("func Read%sAttrs(e %s) (%sAttrs, error) {", p, ki.typ, p)
+	g.line("var a %sAttrs", p)
+	for _, f := range fields {
+		if f.def != "" {
+			g.line("a.%s = %s", f.name, f.def)
+		}
+		g.line("if s, ok := gmlText(e.Data, %s%sKey); ok {", p, f.name)
+		if f.typ.parse == "" {
+			g.line("a.%s = s", f.name)
+		} else {
+			g.needFmt = true
+			g.line("v, err := %s(s)", f.typ.parse)
+			g.line("if err != nil {")
+			g.line("return a, fmt.Errorf(\"invalid value of %%q attribute: %%v\", %q, err)", f.attr)
+			g.line("}")
+			g.line("a.%s = v", f.name)
+		}
+		g.line("}")
+	}
+	g.line("return a, nil")
+	g.line("}")
+
+	g.line("")
+	g.line("// Write%[1]sAttrs sets all attributes of the %[2]s.", p, strings.ToLower(p))
+	g.line("func Write%sAttrs(e %s, a %sAttrs) {", p, ki.typ, p)
+	for _, f := range fields {
+		g.line("Set%s%s(e, a.%s)", p, f.name, f.name)
+	}
+	g.line("}")
+
+	for _, f := range fields {
+		g.line("")
+		if f.def != "" {
+			g.line("// %s%s returns a value of %q attribute of the %s, or its default value if it is missing.", p, f.name, f.attr, strings.ToLower(p))
+		} else {
+			g.line("// %s%s returns a value of %q attribute of the %s.", p, f.name, f.attr, strings.ToLower(p))
+		}
+		g.line("// It returns false if the value is missing or invalid.")
+		g.line("func %s%s(e %s) (%s, bool) {", p, f.name, ki.typ, f.typ.name)
+		g.line("s, ok := gmlText(e.Data, %s%sKey)", p, f.name)
+		g.line("if !ok {")
+		if f.def != "" {
+			g.line("return %s, true", f.def)
+		} else {
+			g.line("var zero %s", f.typ.name)
+			g.line("return zero, false")
+		}
+		g.line("}")
+		if f.typ.parse == "" {
+			g.line("return s, true")
+		} else {
+			g.line("v, err := %s(s)", f.typ.parse)
+			g.line("return v, err == nil")
+		}
+		g.line("}")
+
+		g.line("")
+		g.line("// Set%s%s sets a value of %q attribute of the %s.", p, f.name, f.attr, strings.ToLower(p))
+		g.line("func Set%s%s(e %s, v %s) {", p, f.name, ki.typ, f.typ.name)
+		g.line("e.Data = gmlSetText(e.Data, %s%sKey, %s)", p, f.name, fmt.Sprintf(f.typ.format, "v"))
+		g.line("}")
+	}
+}
+
+func (g *generator) genHelpers() {
+	g.buf.WriteString(helpers)
+}
+
+const helpers = `
+func gmlText(data []graphml.Data, key string) (string, bool) {
+	for _, d := range data {
+		if d.Key != key {
+			continue
+		}
+		var sb strings.Builder
+		for _, t := range d.Data {
+			switch t := t.(type) {
+			case xml.CharData:
+				sb.Write(t)
+			case xml.Comment:
+			default:
+				return "", false
+			}
+		}
+		return sb.String(), true
+	}
+	return "", false
+}
+
+func gmlSetText(data []graphml.Data, key, v string) []graphml.Data {
+	d := graphml.NewStringData(key, v)
+	for i := range data {
+		if data[i].Key == key {
+			data[i] = d
+			return data
+		}
+	}
+	return append(data, d)
+}
+
+func gmlParseBool(s string) (bool, error) {
+	return strconv.ParseBool(strings.TrimSpace(s))
+}
+
+func gmlParseInt32(s string) (int32, error) {
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32)
+	return int32(v), err
+}
+
+func gmlParseInt64(s string) (int64, error) {
+	return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+}
+
+func gmlParseFloat32(s string) (float32, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 32)
+	return float32(v), err
+}
+
+func gmlParseFloat64(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
+`
@@ -0,0 +1,62 @@
+package graphmlgen
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dennwc/graphml"
+)
+
+const testDoc = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="label" attr.type="string"/>
+  <key id="d1" for="node" attr.name="size" attr.type="float"><default>10</default></key>
+  <key id="d2" for="edge" attr.name="weight" attr.type="double"/>
+  <key id="d3" for="all" attr.name="is visible" attr.type="boolean"/>
+  <key id="d4" for="node" yfiles.type="nodegraphics"/>
+  <graph edgedefault="directed"/>
+</graphml>`
+
+func TestGenerate(t *testing.T) {
+	doc, err := graphml.Decode(strings.NewReader(testDoc))
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	err = Generate(buf, doc.Keys, &Options{Package: "model"})
+	require.NoError(t, err)
+	src := buf.String()
+
+	_, err = parser.ParseFile(token.NewFileSet(), "gen.go", src, 0)
+	require.NoError(t, err)
+
+	for _, s := range []string{
+		"package model",
+		`NodeLabelKey     = "d0"`,
+		"type NodeAttrs struct {",
+		"Label     string  `graphml:\"label\"`",
+		"Size      float32 `graphml:\"size\"`",
+		"IsVisible bool    `graphml:\"is visible\"`",
+		"func ReadEdgeAttrs(e *graphml.Edge) (EdgeAttrs, error) {",
+		"func NodeSize(e *graphml.Node) (float32, bool) {",
+		"return 10, true",
+		"func SetEdgeWeight(e *graphml.Edge, v float64) {",
+		"func GraphIsVisible(e *graphml.Graph) (bool, bool) {",
+		`keys[1].Default = []xml.Token{xml.CharData("10")}`,
+	} {
+		require.Contains(t, src, s)
+	}
+	require.NotContains(t, src, `"d4"`)
+
+	require.Error(t, Generate(buf, nil, nil))
+}
+
+func TestGoName(t *testing.T) {
+	require.Equal(t, "Label", goName("label"))
+	require.Equal(t, "EdgeLabel", goName("Edge Label"))
+	require.Equal(t, "AttrName", goName("attr.name"))
+	require.Equal(t, "X3d", goName("3d"))
+}
@@ -0,0 +1,1016 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: graphml.proto
+
+package graphmlpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Name is an XML name with a namespace.
+type Name struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Space         string                 `protobuf:"bytes,1,opt,name=space,proto3" json:"space,omitempty"`
+	Local         string                 `protobuf:"bytes,2,opt,name=local,proto3" json:"local,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Name) Reset() {
+	*x = Name{}
+	mi := &file_graphml_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Name) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Name) ProtoMessage() {}
+
+func (x *Name) ProtoReflect() protoreflect.Message {
+	mi := &file_graphml_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Name.ProtoReflect.Descriptor instead.
+func (*Name) Descriptor() ([]byte, []int) {
+	return file_graphml_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Name) GetSpace() string {
+	if x != nil {
+		return x.Space
+	}
+	return ""
+}
+
+func (x *Name) GetLocal() string {
+	if x != nil {
+		return x.Local
+	}
+	return ""
+}
+
+// Attr is an XML attribute.
+type Attr struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *Name                  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attr) Reset() {
+	*x = Attr{}
+	mi := &file_graphml_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attr) ProtoMessage() {}
+
+func (x *Attr) ProtoReflect() protoreflect.Message {
+	mi := &file_graphml_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attr.ProtoReflect.Descriptor instead.
+func (*Attr) Descriptor() ([]byte, []int) {
+	return file_graphml_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Attr) GetName() *Name {
+	if x != nil {
+		return x.Name
+	}
+	return nil
+}
+
+func (x *Attr) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// ProcInst is an XML processing instruction.
+type ProcInst struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        string                 `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Inst          []byte                 `protobuf:"bytes,2,opt,name=inst,proto3" json:"inst,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcInst) Reset() {
+	*x = ProcInst{}
+	mi := &file_graphml_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcInst) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcInst) ProtoMessage() {}
+
+func (x *ProcInst) ProtoReflect() protoreflect.Message {
+	mi := &file_graphml_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcInst.ProtoReflect.Descriptor instead.
+func (*ProcInst) Descriptor() ([]byte, []int) {
+	return file_graphml_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProcInst) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *ProcInst) GetInst() []byte {
+	if x != nil {
+		return x.Inst
+	}
+	return nil
+}
+
+// StartElement is an XML start element token.
+type StartElement struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *Name                  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Attrs         []*Attr                `protobuf:"bytes,2,rep,name=attrs,proto3" json:"attrs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartElement) Reset() {
+	*x = StartElement{}
+	mi := &file_graphml_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartElement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartElement) ProtoMessage() {}
+
+func (x *StartElement) ProtoReflect() protoreflect.Message {
+	mi := &file_graphml_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartElement.ProtoReflect.Descriptor instead.
+func (*StartElement) Descriptor() ([]byte, []int) {
+	return file_graphml_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StartElement) GetName() *Name {
+	if x != nil {
+		return x.Name
+	}
+	return nil
+}
+
+func (x *StartElement) GetAttrs() []*Attr {
+	if x != nil {
+		return x.Attrs
+	}
+	return nil
+}
+
+// Token is a raw XML token.
+type Token struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Token:
+	//
+	//	*Token_Start
+	//	*Token_End
+	//	*Token_CharData
+	//	*Token_Comment
+	//	*Token_ProcInst
+	//	*Token_Directive
+	Token         isToken_Token `protobuf_oneof:"token"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	mi := &file_graphml_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_graphml_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_graphml_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Token) GetToken() isToken_Token {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+func (x *Token) GetStart() *StartElement {
+	if x != nil {
+		if x, ok := x.Token.(*Token_Start); ok {
+			return x.Start
+		}
+	}
+	return nil
+}
+
+func (x *Token) GetEnd() *Name {
+	if x != nil {
+		if x, ok := x.Token.(*Token_End); ok {
+			return x.End
+		}
+	}
+	return nil
+}
+
+func (x *Token) GetCharData() []byte {
+	if x != nil {
+		if x, ok := x.Token.(*Token_CharData); ok {
+			return x.CharData
+		}
+	}
+	return nil
+}
+
+func (x *Token) GetComment() []byte {
+	if x != nil {
+		if x, ok := x.Token.(*Token_Comment); ok {
+			return x.Comment
+		}
+	}
+	return nil
+}
+
+func (x *Token) GetProcInst() *ProcInst {
+	if x != nil {
+		if x, ok := x.Token.(*Token_ProcInst); ok {
+			return x.ProcInst
+		}
+	}
+	return nil
+}
+
+func (x *Token) GetDirective() []byte {
+	if x != nil {
+		if x, ok := x.Token.(*Token_Directive); ok {
+			return x.Directive
+		}
+	}
+	return nil
+}
+
+type isToken_Token interface {
+	isToken_Token()
+}
+
+type Token_Start struct {
+	Start *StartElement `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
+}
+
+type Token_End struct {
+	End *Name `protobuf:"bytes,2,opt,name=end,proto3,oneof"`
+}
+
+type Token_CharData struct {
+	CharData []byte `protobuf:"bytes,3,opt,name=char_data,json=charData,proto3,oneof"`
+}
+
+type Token_Comment struct {
+	Comment []byte `protobuf:"bytes,4,opt,name=comment,proto3,oneof"`
+}
+
+type Token_ProcInst struct {
+	ProcInst *ProcInst `protobuf:"bytes,5,opt,name=proc_inst,json=procInst,proto3,oneof"`
+}
+
+type Token_Directive struct {
+	Directive []byte `protobuf:"bytes,6,opt,name=directive,proto3,oneof"`
+}
+
+func (*Token_Start) isToken_Token() {}
+
+func (*Token_End) isToken_Token() {}
+
+func (*Token_CharData) isToken_Token() {}
+
+func (*Token_Comment) isToken_Token() {}
+
+func (*Token_ProcInst) isToken_Token() {}
+
+func (*Token_Directive) isToken_Token() {}
+
+// Document is a self-contained GraphML document.
+type Document struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Instr         *ProcInst              `protobuf:"bytes,1,opt,name=instr,proto3" json:"instr,omitempty"`
+	Attrs         []*Attr                `protobuf:"bytes,2,rep,name=attrs,proto3" json:"attrs,omitempty"`
+	Keys          []*Key                 `protobuf:"bytes,3,rep,name=keys,proto3" json:"keys,omitempty"`
+	Graphs        []*Graph               `protobuf:"bytes,4,rep,name=graphs,proto3" json:"graphs,omitempty"`
+	Data          []*Data                `protobuf:"bytes,5,rep,name=data,proto3" json:"data,omitempty"`
+	Comments      []string               `protobuf:"bytes,6,rep,name=comments,proto3" json:"comments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Document) Reset() {
+	*x = Document{}
+	mi := &file_graphml_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Document) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Document) ProtoMessage() {}
+
+func (x *Document) ProtoReflect() protoreflect.Message {
+	mi := &file_graphml_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Document.ProtoReflect.Descriptor instead.
+func (*Document) Descriptor() ([]byte, []int) {
+	return file_graphml_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Document) GetInstr() *ProcInst {
+	if x != nil {
+		return x.Instr
+	}
+	return nil
+}
+
+func (x *Document) GetAttrs() []*Attr {
+	if x != nil {
+		return x.Attrs
+	}
+	return nil
+}
+
+func (x *Document) GetKeys() []*Key {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *Document) GetGraphs() []*Graph {
+	if x != nil {
+		return x.Graphs
+	}
+	return nil
+}
+
+func (x *Document) GetData() []*Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Document) GetComments() []string {
+	if x != nil {
+		return x.Comments
+	}
+	return nil
+}
+
+// Key is a definition of a custom attribute.
+type Key struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Id           string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Unrecognized []*Attr                `protobuf:"bytes,2,rep,name=unrecognized,proto3" json:"unrecognized,omitempty"`
+	For          string                 `protobuf:"bytes,3,opt,name=for,proto3" json:"for,omitempty"`
+	Name         string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Type         string                 `protobuf:"bytes,5,opt,name=type,proto3" json:"type,omitempty"`
+	// has_default distinguishes a key without a default from a key with an empty default.
+	HasDefault    bool     `protobuf:"varint,6,opt,name=has_default,json=hasDefault,proto3" json:"has_default,omitempty"`
+	Default       []*Token `protobuf:"bytes,7,rep,name=default,proto3" json:"default,omitempty"`
+	YfilesType    string   `protobuf:"bytes,8,opt,name=yfiles_type,json=yfilesType,proto3" json:"yfiles_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Key) Reset() {
+	*x = Key{}
+	mi := &file_graphml_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Key) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Key) ProtoMessage() {}
+
+func (x *Key) ProtoReflect() protoreflect.Message {
+	mi := &file_graphml_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Key.ProtoReflect.Descriptor instead.
+func (*Key) Descriptor() ([]byte, []int) {
+	return file_graphml_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Key) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Key) GetUnrecognized() []*Attr {
+	if x != nil {
+		return x.Unrecognized
+	}
+	return nil
+}
+
+func (x *Key) GetFor() string {
+	if x != nil {
+		return x.For
+	}
+	return ""
+}
+
+func (x *Key) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Key) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Key) GetHasDefault() bool {
+	if x != nil {
+		return x.HasDefault
+	}
+	return false
+}
+
+func (x *Key) GetDefault() []*Token {
+	if x != nil {
+		return x.Default
+	}
+	return nil
+}
+
+func (x *Key) GetYfilesType() string {
+	if x != nil {
+		return x.YfilesType
+	}
+	return ""
+}
+
+// Graph is a set of nodes and edges.
+type Graph struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Unrecognized  []*Attr                `protobuf:"bytes,2,rep,name=unrecognized,proto3" json:"unrecognized,omitempty"`
+	Data          []*Data                `protobuf:"bytes,3,rep,name=data,proto3" json:"data,omitempty"`
+	EdgeDefault   string                 `protobuf:"bytes,4,opt,name=edge_default,json=edgeDefault,proto3" json:"edge_default,omitempty"`
+	Nodes         []*Node                `protobuf:"bytes,5,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Edges         []*Edge                `protobuf:"bytes,6,rep,name=edges,proto3" json:"edges,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Graph) Reset() {
+	*x = Graph{}
+	mi := &file_graphml_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Graph) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Graph) ProtoMessage() {}
+
+func (x *Graph) ProtoReflect() protoreflect.Message {
+	mi := &file_graphml_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Graph.ProtoReflect.Descriptor instead.
+func (*Graph) Descriptor() ([]byte, []int) {
+	return file_graphml_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Graph) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Graph) GetUnrecognized() []*Attr {
+	if x != nil {
+		return x.Unrecognized
+	}
+	return nil
+}
+
+func (x *Graph) GetData() []*Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Graph) GetEdgeDefault() string {
+	if x != nil {
+		return x.EdgeDefault
+	}
+	return ""
+}
+
+func (x *Graph) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *Graph) GetEdges() []*Edge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+// Node is a node in a graph.
+type Node struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Unrecognized  []*Attr                `protobuf:"bytes,2,rep,name=unrecognized,proto3" json:"unrecognized,omitempty"`
+	Data          []*Data                `protobuf:"bytes,3,rep,name=data,proto3" json:"data,omitempty"`
+	Graphs        []*Graph               `protobuf:"bytes,4,rep,name=graphs,proto3" json:"graphs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_graphml_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_graphml_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_graphml_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Node) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Node) GetUnrecognized() []*Attr {
+	if x != nil {
+		return x.Unrecognized
+	}
+	return nil
+}
+
+func (x *Node) GetData() []*Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Node) GetGraphs() []*Graph {
+	if x != nil {
+		return x.Graphs
+	}
+	return nil
+}
+
+// Edge is a connection between two nodes in a graph.
+type Edge struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Unrecognized  []*Attr                `protobuf:"bytes,2,rep,name=unrecognized,proto3" json:"unrecognized,omitempty"`
+	Data          []*Data                `protobuf:"bytes,3,rep,name=data,proto3" json:"data,omitempty"`
+	Source        string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	Target        string                 `protobuf:"bytes,5,opt,name=target,proto3" json:"target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Edge) Reset() {
+	*x = Edge{}
+	mi := &file_graphml_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Edge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Edge) ProtoMessage() {}
+
+func (x *Edge) ProtoReflect() protoreflect.Message {
+	mi := &file_graphml_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Edge.ProtoReflect.Descriptor instead.
+func (*Edge) Descriptor() ([]byte, []int) {
+	return file_graphml_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Edge) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Edge) GetUnrecognized() []*Attr {
+	if x != nil {
+		return x.Unrecognized
+	}
+	return nil
+}
+
+func (x *Edge) GetData() []*Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Edge) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Edge) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+// Data is a raw XML value for a custom attribute.
+type Data struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Unrecognized  []*Attr                `protobuf:"bytes,2,rep,name=unrecognized,proto3" json:"unrecognized,omitempty"`
+	Tokens        []*Token               `protobuf:"bytes,3,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data) Reset() {
+	*x = Data{}
+	mi := &file_graphml_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data) ProtoMessage() {}
+
+func (x *Data) ProtoReflect() protoreflect.Message {
+	mi := &file_graphml_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data.ProtoReflect.Descriptor instead.
+func (*Data) Descriptor() ([]byte, []int) {
+	return file_graphml_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Data) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Data) GetUnrecognized() []*Attr {
+	if x != nil {
+		return x.Unrecognized
+	}
+	return nil
+}
+
+func (x *Data) GetTokens() []*Token {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+var File_graphml_proto protoreflect.FileDescriptor
+
+const file_graphml_proto_rawDesc = "" +
+	"\n" +
+	"\rgraphml.proto\x12\agraphml\"2\n" +
+	"\x04Name\x12\x14\n" +
+	"\x05space\x18\x01 \x01(\tR\x05space\x12\x14\n" +
+	"\x05local\x18\x02 \x01(\tR\x05local\"?\n" +
+	"\x04Attr\x12!\n" +
+	"\x04name\x18\x01 \x01(\v2\r.graphml.NameR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"6\n" +
+	"\bProcInst\x12\x16\n" +
+	"\x06target\x18\x01 \x01(\tR\x06target\x12\x12\n" +
+	"\x04inst\x18\x02 \x01(\fR\x04inst\"V\n" +
+	"\fStartElement\x12!\n" +
+	"\x04name\x18\x01 \x01(\v2\r.graphml.NameR\x04name\x12#\n" +
+	"\x05attrs\x18\x02 \x03(\v2\r.graphml.AttrR\x05attrs\"\xef\x01\n" +
+	"\x05Token\x12-\n" +
+	"\x05start\x18\x01 \x01(\v2\x15.graphml.StartElementH\x00R\x05start\x12!\n" +
+	"\x03end\x18\x02 \x01(\v2\r.graphml.NameH\x00R\x03end\x12\x1d\n" +
+	"\tchar_data\x18\x03 \x01(\fH\x00R\bcharData\x12\x1a\n" +
+	"\acomment\x18\x04 \x01(\fH\x00R\acomment\x120\n" +
+	"\tproc_inst\x18\x05 \x01(\v2\x11.graphml.ProcInstH\x00R\bprocInst\x12\x1e\n" +
+	"\tdirective\x18\x06 \x01(\fH\x00R\tdirectiveB\a\n" +
+	"\x05token\"\xe1\x01\n" +
+	"\bDocument\x12'\n" +
+	"\x05instr\x18\x01 \x01(\v2\x11.graphml.ProcInstR\x05instr\x12#\n" +
+	"\x05attrs\x18\x02 \x03(\v2\r.graphml.AttrR\x05attrs\x12 \n" +
+	"\x04keys\x18\x03 \x03(\v2\f.graphml.KeyR\x04keys\x12&\n" +
+	"\x06graphs\x18\x04 \x03(\v2\x0e.graphml.GraphR\x06graphs\x12!\n" +
+	"\x04data\x18\x05 \x03(\v2\r.graphml.DataR\x04data\x12\x1a\n" +
+	"\bcomments\x18\x06 \x03(\tR\bcomments\"\xee\x01\n" +
+	"\x03Key\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x121\n" +
+	"\funrecognized\x18\x02 \x03(\v2\r.graphml.AttrR\funrecognized\x12\x10\n" +
+	"\x03for\x18\x03 \x01(\tR\x03for\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x05 \x01(\tR\x04type\x12\x1f\n" +
+	"\vhas_default\x18\x06 \x01(\bR\n" +
+	"hasDefault\x12(\n" +
+	"\adefault\x18\a \x03(\v2\x0e.graphml.TokenR\adefault\x12\x1f\n" +
+	"\vyfiles_type\x18\b \x01(\tR\n" +
+	"yfilesType\"\xda\x01\n" +
+	"\x05Graph\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x121\n" +
+	"\funrecognized\x18\x02 \x03(\v2\r.graphml.AttrR\funrecognized\x12!\n" +
+	"\x04data\x18\x03 \x03(\v2\r.graphml.DataR\x04data\x12!\n" +
+	"\fedge_default\x18\x04 \x01(\tR\vedgeDefault\x12#\n" +
+	"\x05nodes\x18\x05 \x03(\v2\r.graphml.NodeR\x05nodes\x12#\n" +
+	"\x05edges\x18\x06 \x03(\v2\r.graphml.EdgeR\x05edges\"\x94\x01\n" +
+	"\x04Node\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x121\n" +
+	"\funrecognized\x18\x02 \x03(\v2\r.graphml.AttrR\funrecognized\x12!\n" +
+	"\x04data\x18\x03 \x03(\v2\r.graphml.DataR\x04data\x12&\n" +
+	"\x06graphs\x18\x04 \x03(\v2\x0e.graphml.GraphR\x06graphs\"\x9c\x01\n" +
+	"\x04Edge\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x121\n" +
+	"\funrecognized\x18\x02 \x03(\v2\r.graphml.AttrR\funrecognized\x12!\n" +
+	"\x04data\x18\x03 \x03(\v2\r.graphml.DataR\x04data\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x16\n" +
+	"\x06target\x18\x05 \x01(\tR\x06target\"s\n" +
+	"\x04Data\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x121\n" +
+	"\funrecognized\x18\x02 \x03(\v2\r.graphml.AttrR\funrecognized\x12&\n" +
+	"\x06tokens\x18\x03 \x03(\v2\x0e.graphml.TokenR\x06tokensB%Z#github.com/dennwc/graphml/graphmlpbb\x06proto3"
+
+var (
+	file_graphml_proto_rawDescOnce sync.Once
+	file_graphml_proto_rawDescData []byte
+)
+
+func file_graphml_proto_rawDescGZIP() []byte {
+	file_graphml_proto_rawDescOnce.Do(func() {
+		file_graphml_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_graphml_proto_rawDesc), len(file_graphml_proto_rawDesc)))
+	})
+	return file_graphml_proto_rawDescData
+}
+
+var file_graphml_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_graphml_proto_goTypes = []any{
+	(*Name)(nil),         // 0: graphml.Name
+	(*Attr)(nil),         // 1: graphml.Attr
+	(*ProcInst)(nil),     // 2: graphml.ProcInst
+	(*StartElement)(nil), // 3: graphml.StartElement
+	(*Token)(nil),        // 4: graphml.Token
+	(*Document)(nil),     // 5: graphml.Document
+	(*Key)(nil),          // 6: graphml.Key
+	(*Graph)(nil),        // 7: graphml.Graph
+	(*Node)(nil),         // 8: graphml.Node
+	(*Edge)(nil),         // 9: graphml.Edge
+	(*Data)(nil),         // 10: graphml.Data
+}
+var file_graphml_proto_depIdxs = []int32{
+	0,  // 0: graphml.Attr.name:type_name -> graphml.Name
+	0,  // 1: graphml.StartElement.name:type_name -> graphml.Name
+	1,  // 2: graphml.StartElement.attrs:type_name -> graphml.Attr
+	3,  // 3: graphml.Token.start:type_name -> graphml.StartElement
+	0,  // 4: graphml.Token.end:type_name -> graphml.Name
+	2,  // 5: graphml.Token.proc_inst:type_name -> graphml.ProcInst
+	2,  // 6: graphml.Document.instr:type_name -> graphml.ProcInst
+	1,  // 7: graphml.Document.attrs:type_name -> graphml.Attr
+	6,  // 8: graphml.Document.keys:type_name -> graphml.Key
+	7,  // 9: graphml.Document.graphs:type_name -> graphml.Graph
+	10, // 10: graphml.Document.data:type_name -> graphml.Data
+	1,  // 11: graphml.Key.unrecognized:type_name -> graphml.Attr
+	4,  // 12: graphml.Key.default:type_name -> graphml.Token
+	1,  // 13: graphml.Graph.unrecognized:type_name -> graphml.Attr
+	10, // 14: graphml.Graph.data:type_name -> graphml.Data
+	8,  // 15: graphml.Graph.nodes:type_name -> graphml.Node
+	9,  // 16: graphml.Graph.edges:type_name -> graphml.Edge
+	1,  // 17: graphml.Node.unrecognized:type_name -> graphml.Attr
+	10, // 18: graphml.Node.data:type_name -> graphml.Data
+	7,  // 19: graphml.Node.graphs:type_name -> graphml.Graph
+	1,  // 20: graphml.Edge.unrecognized:type_name -> graphml.Attr
+	10, // 21: graphml.Edge.data:type_name -> graphml.Data
+	1,  // 22: graphml.Data.unrecognized:type_name -> graphml.Attr
+	4,  // 23: graphml.Data.tokens:type_name -> graphml.Token
+	24, // [24:24] is the sub-list for method output_type
+	24, // [24:24] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
+}
+
+func init() { file_graphml_proto_init() }
+func file_graphml_proto_init() {
+	if File_graphml_proto != nil {
+		return
+	}
+	file_graphml_proto_msgTypes[4].OneofWrappers = []any{
+		(*Token_Start)(nil),
+		(*Token_End)(nil),
+		(*Token_CharData)(nil),
+		(*Token_Comment)(nil),
+		(*Token_ProcInst)(nil),
+		(*Token_Directive)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_graphml_proto_rawDesc), len(file_graphml_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_graphml_proto_goTypes,
+		DependencyIndexes: file_graphml_proto_depIdxs,
+		MessageInfos:      file_graphml_proto_msgTypes,
+	}.Build()
+	File_graphml_proto = out.File
+	file_graphml_proto_goTypes = nil
+	file_graphml_proto_depIdxs = nil
+}
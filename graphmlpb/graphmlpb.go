@@ -0,0 +1,265 @@
+// Package graphmlpb implements a Protocol Buffers representation of GraphML documents.
+//
+// Messages mirror the structure of graphml.Document, including raw XML tokens of data elements,
+// thus the conversion with ToProto and FromProto is lossless.
+package graphmlpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative graphml.proto
+
+import (
+	"encoding/xml"
+	"errors"
+
+	"github.com/dennwc/graphml"
+)
+
+// ToProto converts a GraphML document to a protobuf message.
+func ToProto(doc *graphml.Document) *Document {
+	out := &Document{
+		Attrs:  toAttrs(doc.Attrs),
+		Graphs: toGraphs(doc.Graphs),
+		Data:   toData(doc.Data),
+	}
+	if doc.Instr.Target != "" {
+		out.Instr = &ProcInst{Target: doc.Instr.Target, Inst: doc.Instr.Inst}
+	}
+	for _, c := range doc.Comments {
+		out.Comments = append(out.Comments, string(c))
+	}
+	for _, k := range doc.Keys {
+		out.Keys = append(out.Keys, &Key{
+			Id:           k.ID,
+			Unrecognized: toAttrs(k.Unrecognized),
+			For:          string(k.For),
+			Name:         k.Name,
+			Type:         k.Type,
+			YfilesType:   k.YFilesType,
+			HasDefault:   k.Default != nil,
+			Default:      toTokens(k.Default),
+		})
+	}
+	return out
+}
+
+func toName(n xml.Name) *Name {
+	return &Name{Space: n.Space, Local: n.Local}
+}
+
+func toAttrs(attrs []xml.Attr) []*Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]*Attr, 0, len(attrs))
+	for _, a := range attrs {
+		out = append(out, &Attr{Name: toName(a.Name), Value: a.Value})
+	}
+	return out
+}
+
+func toTokens(toks []xml.Token) []*Token {
+	if len(toks) == 0 {
+		return nil
+	}
+	out := make([]*Token, 0, len(toks))
+	for _, t := range toks {
+		var pt Token
+		switch t := t.(type) {
+		case xml.StartElement:
+			pt.Token = &Token_Start{Start: &StartElement{Name: toName(t.Name), Attrs: toAttrs(t.Attr)}}
+		case xml.EndElement:
+			pt.Token = &Token_End{End: toName(t.Name)}
+		case xml.CharData:
+			pt.Token = &Token_CharData{CharData: t}
+		case xml.Comment:
+			pt.Token = &Token_Comment{Comment: t}
+		case xml.ProcInst:
+			pt.Token = &Token_ProcInst{ProcInst: &ProcInst{Target: t.Target, Inst: t.Inst}}
+		case xml.Directive:
+			pt.Token = &Token_Directive{Directive: t}
+		default:
+			continue
+		}
+		out = append(out, &pt)
+	}
+	return out
+}
+
+func toData(data []graphml.Data) []*Data {
+	if len(data) == 0 {
+		return nil
+	}
+	out := make([]*Data, 0, len(data))
+	for _, d := range data {
+		out = append(out, &Data{Key: d.Key, Unrecognized: toAttrs(d.Unrecognized), Tokens: toTokens(d.Data)})
+	}
+	return out
+}
+
+func toGraphs(graphs []graphml.Graph) []*Graph {
+	if len(graphs) == 0 {
+		return nil
+	}
+	out := make([]*Graph, 0, len(graphs))
+	for i := range graphs {
+		g := &graphs[i]
+		pg := &Graph{
+			Id:           g.ID,
+			Unrecognized: toAttrs(g.Unrecognized),
+			Data:         toData(g.Data),
+			EdgeDefault:  string(g.EdgeDefault),
+		}
+		for j := range g.Nodes {
+			n := &g.Nodes[j]
+			pg.Nodes = append(pg.Nodes, &Node{
+				Id:           n.ID,
+				Unrecognized: toAttrs(n.Unrecognized),
+				Data:         toData(n.Data),
+				Graphs:       toGraphs(n.Graphs),
+			})
+		}
+		for j := range g.Edges {
+			e := &g.Edges[j]
+			pg.Edges = append(pg.Edges, &Edge{
+				Id:           e.ID,
+				Unrecognized: toAttrs(e.Unrecognized),
+				Data:         toData(e.Data),
+				Source:       e.Source,
+				Target:       e.Target,
+			})
+		}
+		out = append(out, pg)
+	}
+	return out
+}
+
+// FromProto converts a protobuf message back to a GraphML document.
+func FromProto(d *Document) (*graphml.Document, error) {
+	doc := &graphml.Document{Attrs: fromAttrs(d.GetAttrs())}
+	if in := d.GetInstr(); in != nil {
+		doc.Instr = xml.ProcInst{Target: in.Target, Inst: in.Inst}
+	}
+	for _, c := range d.GetComments() {
+		doc.Comments = append(doc.Comments, xml.Comment(c))
+	}
+	for _, k := range d.GetKeys() {
+		def, err := fromTokens(k.GetDefault())
+		if err != nil {
+			return nil, err
+		}
+		if k.GetHasDefault() && def == nil {
+			def = []xml.Token{}
+		}
+		key := graphml.NewKey(graphml.Kind(k.GetFor()), k.GetId(), k.GetName(), graphml.AttrType(k.GetType()))
+		key.Unrecognized = fromAttrs(k.GetUnrecognized())
+		key.YFilesType = k.GetYfilesType()
+		key.Default = def
+		doc.Keys = append(doc.Keys, key)
+	}
+	var err error
+	if doc.Graphs, err = fromGraphs(d.GetGraphs()); err != nil {
+		return nil, err
+	}
+	if doc.Data, err = fromData(d.GetData()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func fromName(n *Name) xml.Name {
+	return xml.Name{Space: n.GetSpace(), Local: n.GetLocal()}
+}
+
+func fromAttrs(attrs []*Attr) []xml.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]xml.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		out = append(out, xml.Attr{Name: fromName(a.GetName()), Value: a.GetValue()})
+	}
+	return out
+}
+
+func fromTokens(toks []*Token) ([]xml.Token, error) {
+	if len(toks) == 0 {
+		return nil, nil
+	}
+	out := make([]xml.Token, 0, len(toks))
+	for _, t := range toks {
+		switch t := t.GetToken().(type) {
+		case *Token_Start:
+			out = append(out, xml.StartElement{Name: fromName(t.Start.GetName()), Attr: fromAttrs(t.Start.GetAttrs())})
+		case *Token_End:
+			out = append(out, xml.EndElement{Name: fromName(t.End)})
+		case *Token_CharData:
+			out = append(out, xml.CharData(t.CharData))
+		case *Token_Comment:
+			out = append(out, xml.Comment(t.Comment))
+		case *Token_ProcInst:
+			out = append(out, xml.ProcInst{Target: t.ProcInst.GetTarget(), Inst: t.ProcInst.GetInst()})
+		case *Token_Directive:
+			out = append(out, xml.Directive(t.Directive))
+		default:
+			return nil, errors.New("graphmlpb: empty token")
+		}
+	}
+	return out, nil
+}
+
+func fromData(data []*Data) ([]graphml.Data, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	out := make([]graphml.Data, 0, len(data))
+	for _, d := range data {
+		toks, err := fromTokens(d.GetTokens())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, graphml.Data{Key: d.GetKey(), Unrecognized: fromAttrs(d.GetUnrecognized()), Data: toks})
+	}
+	return out, nil
+}
+
+func fromGraphs(graphs []*Graph) ([]graphml.Graph, error) {
+	if len(graphs) == 0 {
+		return nil, nil
+	}
+	out := make([]graphml.Graph, 0, len(graphs))
+	for _, pg := range graphs {
+		var (
+			g   graphml.Graph
+			err error
+		)
+		g.ID = pg.GetId()
+		g.Unrecognized = fromAttrs(pg.GetUnrecognized())
+		g.EdgeDefault = graphml.EdgeDir(pg.GetEdgeDefault())
+		if g.Data, err = fromData(pg.GetData()); err != nil {
+			return nil, err
+		}
+		for _, pn := range pg.GetNodes() {
+			var n graphml.Node
+			n.ID = pn.GetId()
+			n.Unrecognized = fromAttrs(pn.GetUnrecognized())
+			if n.Data, err = fromData(pn.GetData()); err != nil {
+				return nil, err
+			}
+			if n.Graphs, err = fromGraphs(pn.GetGraphs()); err != nil {
+				return nil, err
+			}
+			g.Nodes = append(g.Nodes, n)
+		}
+		for _, pe := range pg.GetEdges() {
+			var e graphml.Edge
+			e.ID = pe.GetId()
+			e.Unrecognized = fromAttrs(pe.GetUnrecognized())
+			e.Source, e.Target = pe.GetSource(), pe.GetTarget()
+			if e.Data, err = fromData(pe.GetData()); err != nil {
+				return nil, err
+			}
+			g.Edges = append(g.Edges, e)
+		}
+		out = append(out, g)
+	}
+	return out, nil
+}
@@ -0,0 +1,44 @@
+package graphmlpb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dennwc/graphml"
+)
+
+func TestProto(t *testing.T) {
+	names, err := filepath.Glob(filepath.Join("..", "data", "*"+graphml.Ext+".gz"))
+	require.NoError(t, err)
+	require.NotEmpty(t, names)
+	for _, name := range names {
+		name := name
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			f, err := os.Open(name)
+			require.NoError(t, err)
+			defer f.Close()
+			zr, err := gzip.NewReader(f)
+			require.NoError(t, err)
+			doc, err := graphml.Decode(zr)
+			require.NoError(t, err)
+
+			data, err := proto.Marshal(ToProto(doc))
+			require.NoError(t, err)
+			var msg Document
+			require.NoError(t, proto.Unmarshal(data, &msg))
+			doc2, err := FromProto(&msg)
+			require.NoError(t, err)
+
+			exp, got := new(bytes.Buffer), new(bytes.Buffer)
+			require.NoError(t, graphml.Encode(exp, doc))
+			require.NoError(t, graphml.Encode(got, doc2))
+			require.Equal(t, exp.String(), got.String())
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Package httpgraphml implements helpers for serving and receiving GraphML documents over HTTP.
+package httpgraphml
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// MediaType is a registered media type for GraphML documents.
+const MediaType = "application/graphml+xml"
+
+// DefaultMaxSize is a default limit for the size of decoded requests, used by DecodeRequest.
+const DefaultMaxSize = 32 << 20
+
+var (
+	// ErrUnsupportedMediaType is returned by DecodeRequest if the request body is not a GraphML or XML document.
+	ErrUnsupportedMediaType = errors.New("httpgraphml: unsupported media type")
+	// ErrTooLarge is returned by DecodeRequest if the request body exceeds the size limit.
+	ErrTooLarge = errors.New("httpgraphml: request body is too large")
+)
+
+// ServeDocument writes the document as a response with a GraphML media type.
+//
+// The response is compressed with gzip if the client accepts it. Since the headers are already sent when the
+// document is being written, encoding errors cannot be reported to the client and are only returned to the caller.
+func ServeDocument(w http.ResponseWriter, r *http.Request, doc *graphml.Document) error {
+	h := w.Header()
+	h.Set("Content-Type", MediaType+"; charset=utf-8")
+	h.Add("Vary", "Accept-Encoding")
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	if !acceptsGzip(r) {
+		w.WriteHeader(http.StatusOK)
+		return graphml.Encode(w, doc)
+	}
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	w.WriteHeader(http.StatusOK)
+	zw := gzip.NewWriter(w)
+	if err := graphml.Encode(zw, doc); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// acceptsGzip checks if the client accepts gzip content encoding.
+func acceptsGzip(r *http.Request) bool {
+	for _, v := range r.Header.Values("Accept-Encoding") {
+		for _, enc := range strings.Split(v, ",") {
+			enc = strings.TrimSpace(enc)
+			q := 1.0
+			if i := strings.Index(enc, ";"); i >= 0 {
+				param := strings.TrimSpace(enc[i+1:])
+				enc = strings.TrimSpace(enc[:i])
+				if strings.HasPrefix(param, "q=") {
+					var err error
+					q, err = strconv.ParseFloat(param[2:], 64)
+					if err != nil {
+						q = 0
+					}
+				}
+			}
+			if strings.EqualFold(enc, "gzip") || enc == "*" {
+				return q > 0
+			}
+		}
+	}
+	return false
+}
+
+// DecodeRequest reads a GraphML document from the request body, limiting its size to DefaultMaxSize.
+// See DecodeRequestLimit for details.
+func DecodeRequest(r *http.Request) (*graphml.Document, error) {
+	return DecodeRequestLimit(r, DefaultMaxSize)
+}
+
+// DecodeRequestLimit reads a GraphML document from the request body.
+//
+// The request must either have no content type, or have a GraphML or XML media type. Otherwise,
+// ErrUnsupportedMediaType is returned. Bodies compressed with gzip are decompressed automatically.
+// If the body (decompressed, if necessary) exceeds a given number of bytes, ErrTooLarge is returned.
+func DecodeRequestLimit(r *http.Request, limit int64) (*graphml.Document, error) {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		typ, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return nil, ErrUnsupportedMediaType
+		}
+		switch typ {
+		case MediaType, "application/xml", "text/xml":
+		default:
+			return nil, ErrUnsupportedMediaType
+		}
+	}
+	var body io.Reader = r.Body
+	switch enc := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); enc {
+	case "", "identity":
+	case "gzip":
+		zr, err := gzip.NewReader(&limitReader{r: r.Body, n: limit})
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		body = zr
+	default:
+		return nil, ErrUnsupportedMediaType
+	}
+	doc, err := graphml.Decode(&limitReader{r: body, n: limit})
+	if errors.Is(err, ErrTooLarge) {
+		return nil, ErrTooLarge
+	}
+	return doc, err
+}
+
+// limitReader is similar to io.LimitedReader, but returns ErrTooLarge when the limit is exceeded.
+type limitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *limitReader) Read(p []byte) (int, error) {
+	if r.n < 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > r.n+1 {
+		p = p[:r.n+1]
+	}
+	n, err := r.r.Read(p)
+	r.n -= int64(n)
+	if r.n < 0 {
+		return 0, ErrTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,70 @@
+package httpgraphml
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dennwc/graphml"
+)
+
+const testDoc = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><graph id="G" edgedefault="directed"><node id="n0"></node><node id="n1"></node><edge source="n0" target="n1"></edge></graph></graphml>`
+
+func TestServeDocument(t *testing.T) {
+	doc, err := graphml.Decode(strings.NewReader(testDoc))
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	require.NoError(t, ServeDocument(w, r, doc))
+	require.Equal(t, MediaType+"; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+	require.Equal(t, testDoc, w.Body.String())
+
+	r.Header.Set("Accept-Encoding", "deflate, gzip;q=0.5")
+	w = httptest.NewRecorder()
+	require.NoError(t, ServeDocument(w, r, doc))
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	zr, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	doc2, err := graphml.Decode(zr)
+	require.NoError(t, err)
+	require.Len(t, doc2.Graphs[0].Nodes, 2)
+
+	r.Header.Set("Accept-Encoding", "gzip;q=0")
+	w = httptest.NewRecorder()
+	require.NoError(t, ServeDocument(w, r, doc))
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+}
+
+func TestDecodeRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testDoc))
+	r.Header.Set("Content-Type", MediaType)
+	doc, err := DecodeRequest(r)
+	require.NoError(t, err)
+	require.Len(t, doc.Graphs[0].Edges, 1)
+
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+	zw.Write([]byte(testDoc))
+	zw.Close()
+	r = httptest.NewRequest(http.MethodPost, "/", buf)
+	r.Header.Set("Content-Encoding", "gzip")
+	doc, err = DecodeRequest(r)
+	require.NoError(t, err)
+	require.Len(t, doc.Graphs[0].Nodes, 2)
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testDoc))
+	_, err = DecodeRequestLimit(r, 10)
+	require.Equal(t, ErrTooLarge, err)
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testDoc))
+	r.Header.Set("Content-Type", "application/json")
+	_, err = DecodeRequest(r)
+	require.Equal(t, ErrUnsupportedMediaType, err)
+}
@@ -0,0 +1,337 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+// checkDuplicateKeys reports keys that reuse an ID of another key, and keys that declare an attribute
+// with the same name for the same kind of elements as another key.
+func checkDuplicateKeys(doc *Document) []Finding {
+	var out []Finding
+	ids := make(map[string]struct{})
+	names := make(map[docKey]string)
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		path := pathStep("/graphml", "key", k.ID, i)
+		if _, ok := ids[k.ID]; ok {
+			out = append(out, Finding{Path: path, Message: fmt.Sprintf("key id %q is declared more than once", k.ID)})
+		}
+		ids[k.ID] = struct{}{}
+		if k.Name == "" {
+			continue
+		}
+		dk := docKey{name: k.Name, kind: k.For}
+		if prev, ok := names[dk]; ok {
+			out = append(out, Finding{Severity: SeverityWarning, Path: path,
+				Message: fmt.Sprintf("attribute %q for %v is already declared by %s", k.Name, k.For, prev)})
+			continue
+		}
+		names[dk] = path
+	}
+	return out
+}
+
+// KeyFor finds a key referenced by data with a given key ID on elements of a given kind. It resolves keys
+// the same way the decoder does: a key declared for the kind takes precedence over a key declared for all
+// elements (with "for" attribute set to "all" or not set).
+func (doc *Document) KeyFor(kind Kind, id string) (*Key, bool) {
+	k := doc.dataKey(kind, id)
+	return k, k != nil
+}
+
+// KeysFor returns keys that data of elements of a given kind may reference, in the order of declaration.
+// It includes keys declared for the kind and keys declared for all elements, except the ones shadowed
+// by a key with the same ID declared for the kind, see KeyFor.
+func (doc *Document) KeysFor(kind Kind) []Key {
+	var out []Key
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if k.For != kind && k.For != KindAll && k.For != "" {
+			continue
+		}
+		if doc.dataKey(kind, k.ID) == k {
+			out = append(out, *k)
+		}
+	}
+	return out
+}
+
+// newKeyID returns an unused key ID in "d<n>" form.
+func newKeyID(used map[string]struct{}) string {
+	for i := len(used); ; i++ {
+		id := "d" + strconv.Itoa(i)
+		if _, ok := used[id]; !ok {
+			used[id] = struct{}{}
+			return id
+		}
+	}
+}
+
+// eachData calls fn for data lists of all elements of the document, including nested graphs.
+func eachData(doc *Document, fn func(kind Kind, data *[]Data)) {
+	fn(KindGraphML, &doc.Data)
+	var visit func(graphs []Graph)
+	visit = func(graphs []Graph) {
+		for i := range graphs {
+			g := &graphs[i]
+			fn(KindGraph, &g.Data)
+			for j := range g.Nodes {
+				fn(KindNode, &g.Nodes[j].Data)
+				visit(g.Nodes[j].Graphs)
+			}
+			for j := range g.Edges {
+				fn(KindEdge, &g.Edges[j].Data)
+			}
+		}
+	}
+	visit(doc.Graphs)
+}
+
+// FixDuplicateKeys resolves problems reported by the "duplicate-keys" validation rule and returns
+// the number of keys that were changed or removed.
+//
+// A key that reuses an ID of a key for a different kind of elements gets a new ID. A key that reuses an ID
+// of a key for the same kind is removed, since its data cannot be told apart. Keys that declare an attribute
+// with the same name and type for the same kind are merged into the first one, and the name is made unique
+// if types are different. Data is updated to reference the new keys; if an element ends up with multiple
+// values for the same key, only the first one is kept.
+func (doc *Document) FixDuplicateKeys() int {
+	type ref struct {
+		kind Kind
+		id   string
+	}
+	used := make(map[string]struct{}, len(doc.Keys))
+	for _, k := range doc.Keys {
+		used[k.ID] = struct{}{}
+	}
+	var (
+		fixed   int
+		keys    = make([]Key, 0, len(doc.Keys))
+		ids     = make(map[string]Kind)
+		names   = make(map[docKey]int)
+		renames = make(map[ref]string) // maps original key IDs to new ones
+		kept    = make(map[ref]struct{})
+	)
+	for _, k := range doc.Keys {
+		orig := k.ID
+		if kind, ok := ids[k.ID]; ok {
+			fixed++
+			if kind == k.For {
+				continue
+			}
+			k.ID = newKeyID(used)
+		}
+		if k.Name != "" {
+			dk := docKey{name: k.Name, kind: k.For}
+			if i, ok := names[dk]; ok {
+				if k.ID == orig {
+					fixed++
+				}
+				if prev := keys[i]; prev.Type == k.Type {
+					renames[ref{kind: k.For, id: orig}] = prev.ID
+					continue
+				}
+				for n := 2; ; n++ {
+					dk.name = k.Name + "_" + strconv.Itoa(n)
+					if _, ok := names[dk]; !ok {
+						break
+					}
+				}
+				k.Name = dk.name
+			}
+			names[dk] = len(keys)
+		}
+		if k.ID != orig {
+			renames[ref{kind: k.For, id: orig}] = k.ID
+		} else {
+			kept[ref{kind: k.For, id: orig}] = struct{}{}
+		}
+		ids[k.ID] = k.For
+		keys = append(keys, k)
+	}
+	doc.Keys = keys
+	if len(renames) == 0 {
+		return fixed
+	}
+	eachData(doc, func(kind Kind, data *[]Data) {
+		changed := false
+		for i := range *data {
+			d := &(*data)[i]
+			id, ok := renames[ref{kind: kind, id: d.Key}]
+			if !ok {
+				if _, ok2 := kept[ref{kind: kind, id: d.Key}]; !ok2 {
+					id, ok = renames[ref{kind: KindAll, id: d.Key}]
+				}
+			}
+			if ok {
+				d.Key = id
+				changed = true
+			}
+		}
+		if !changed {
+			return
+		}
+		seen := make(map[string]struct{}, len(*data))
+		out := (*data)[:0]
+		for _, d := range *data {
+			if _, ok := seen[d.Key]; ok {
+				continue
+			}
+			seen[d.Key] = struct{}{}
+			out = append(out, d)
+		}
+		*data = out
+	})
+	return fixed
+}
+
+// usedKeys returns IDs of keys referenced by data of any element.
+func usedKeys(doc *Document) map[string]struct{} {
+	used := make(map[string]struct{})
+	eachData(doc, func(_ Kind, data *[]Data) {
+		for _, d := range *data {
+			used[d.Key] = struct{}{}
+		}
+	})
+	return used
+}
+
+// isUnused checks if the key is not referenced by any data. Keys with a default value are always used,
+// since the default applies to all elements of the kind.
+func (k *Key) isUnused(used map[string]struct{}) bool {
+	if k.Default != nil {
+		return false
+	}
+	_, ok := used[k.ID]
+	return !ok
+}
+
+// checkUnusedKeys reports keys that have no default and are not referenced by any data.
+func checkUnusedKeys(doc *Document) []Finding {
+	var out []Finding
+	used := usedKeys(doc)
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if k.isUnused(used) {
+			out = append(out, Finding{Severity: SeverityInfo, Path: pathStep("/graphml", "key", k.ID, i), Message: fmt.Sprintf("key %q is never used", k.ID)})
+		}
+	}
+	return out
+}
+
+// PruneUnusedKeys removes keys that have no default and are not referenced by any data.
+// It returns the number of removed keys.
+func (doc *Document) PruneUnusedKeys() int {
+	used := usedKeys(doc)
+	keys := doc.Keys[:0]
+	for _, k := range doc.Keys {
+		if !k.isUnused(used) {
+			keys = append(keys, k)
+		}
+	}
+	n := len(doc.Keys) - len(keys)
+	doc.Keys = keys
+	return n
+}
+
+// matchKey checks if the key declares an attribute with a given name for a given kind of elements.
+// Empty kind matches keys for any kind.
+func (k *Key) matchKey(kind Kind, name string) bool {
+	return k.Name == name && (kind == "" || k.For == kind)
+}
+
+// RenameAttr changes the name of attributes declared by keys for a given kind of elements (or for any kind,
+// if kind is empty). Key IDs and data are not changed. It returns the number of renamed keys, or an error
+// if an attribute with the new name is already declared for the same kind.
+func (doc *Document) RenameAttr(kind Kind, from, to string) (int, error) {
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if !k.matchKey(kind, from) {
+			continue
+		}
+		for j := range doc.Keys {
+			if j != i && doc.Keys[j].matchKey(k.For, to) {
+				return 0, fmt.Errorf("attribute %q is already declared for %v", to, k.For)
+			}
+		}
+	}
+	n := 0
+	for i := range doc.Keys {
+		if k := &doc.Keys[i]; k.matchKey(kind, from) {
+			k.Name = to
+			n++
+		}
+	}
+	return n, nil
+}
+
+// RetypeAttr changes the type of attributes declared by keys for a given kind of elements (or for any kind,
+// if kind is empty), and converts their data and default values to the new type. It returns the number of
+// changed keys, or an error if any of the values cannot be converted, in which case the document is not changed.
+func (doc *Document) RetypeAttr(kind Kind, name string, typ AttrType) (int, error) {
+	if _, err := ParseAttrType(string(typ)); err != nil || typ == "" {
+		return 0, fmt.Errorf("unknown attribute type %q", typ)
+	}
+	keys := make(map[string]*Key)
+	for i := range doc.Keys {
+		if k := &doc.Keys[i]; k.matchKey(kind, name) {
+			keys[k.ID] = k
+		}
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	convert := func(toks []xml.Token, apply bool) ([]xml.Token, error) {
+		s, ok := TokensText(toks)
+		if !ok {
+			return nil, fmt.Errorf("cannot convert XML content of %q to %s", name, typ)
+		}
+		v, ok := coerceValue(string(typ), s)
+		if typ == TypeString {
+			v, ok = s, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("cannot convert value %q of %q to %s", s, name, typ)
+		}
+		if !apply {
+			return toks, nil
+		}
+		return []xml.Token{xml.CharData(v)}, nil
+	}
+	// check all values first, then convert them
+	for _, apply := range []bool{false, true} {
+		for _, k := range keys {
+			if k.Default == nil {
+				continue
+			}
+			toks, err := convert(k.Default, apply)
+			if err != nil {
+				return 0, err
+			}
+			k.Default = toks
+		}
+		var err error
+		eachData(doc, func(kind Kind, data *[]Data) {
+			for i := range *data {
+				d := &(*data)[i]
+				k := doc.dataKey(kind, d.Key)
+				if err != nil || k == nil || keys[k.ID] != k {
+					continue
+				}
+				var toks []xml.Token
+				if toks, err = convert(d.Data, apply); err == nil {
+					d.Data = toks
+				}
+			}
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	for _, k := range keys {
+		k.Type = string(typ)
+	}
+	return len(keys), nil
+}
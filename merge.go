@@ -0,0 +1,329 @@
+package graphml
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// MergeConflict is a strategy for elements with the same ID found in multiple documents, see Merge.
+type MergeConflict int
+
+const (
+	// MergeRename gives conflicting elements of later documents new IDs.
+	MergeRename = MergeConflict(iota)
+	// MergeCombine combines conflicting elements into one: data for keys missing on the first element
+	// is copied from later ones, and nested graphs of nodes are appended.
+	MergeCombine
+	// MergeError fails on any conflicting element.
+	MergeError
+)
+
+// MergeOptions controls how documents are merged.
+type MergeOptions struct {
+	// OnConflict is a strategy for nodes, edges and nested graphs with the same ID in multiple documents.
+	OnConflict MergeConflict
+	// SeparateGraphs keeps top-level graphs of all documents separate, instead of combining graphs with the same ID.
+	// Conflicting graph IDs are resolved according to OnConflict, as for nested graphs.
+	SeparateGraphs bool
+}
+
+// Merge combines multiple documents into one. Documents are not modified.
+//
+// Keys that declare the same attribute (or the same yFiles data type) for the same kind of elements are shared,
+// other keys get new IDs if their IDs are already used. Top-level graphs with the same ID (including graphs
+// without an ID) are combined into one graph, unless SeparateGraphs is set; if their edge directions differ, edges of later graphs get
+// an explicit direction. Conflicting IDs of other elements are resolved according to the OnConflict option.
+// The processing instruction and attributes of the root element are taken from the first document.
+func Merge(docs []*Document, opt *MergeOptions) (*Document, error) {
+	m := &merger{
+		out:    &Document{},
+		keys:   make(map[string]struct{}),
+		graphs: make(map[string]struct{}),
+		nodes:  make(map[string]struct{}),
+		edges:  make(map[string]struct{}),
+		top:    make(map[string]int),
+		pnodes: make(map[string]*Node),
+		pedges: make(map[string]*Edge),
+	}
+	if opt != nil {
+		m.mode = opt.OnConflict
+		m.separate = opt.SeparateGraphs
+	}
+	for i, doc := range docs {
+		doc = doc.Clone()
+		if i == 0 {
+			m.out.Instr = doc.Instr
+			m.out.Attrs = doc.Attrs
+		}
+		if err := m.add(doc); err != nil {
+			return nil, err
+		}
+	}
+	// nested graphs of combined nodes may have elements to combine as well
+	for n := -1; n != len(m.pnodes)+len(m.pedges); {
+		n = len(m.pnodes) + len(m.pedges)
+		m.combine(m.out.Graphs)
+	}
+	return m.out, nil
+}
+
+// merger accumulates merged documents.
+type merger struct {
+	mode     MergeConflict
+	separate bool
+	out      *Document
+	// used IDs of elements in the output document
+	keys, graphs, nodes, edges map[string]struct{}
+	// top maps IDs of top-level graphs to their indexes in the output document
+	top map[string]int
+	// pnodes and pedges are elements that must be combined with elements of the same ID in the output
+	pnodes map[string]*Node
+	pedges map[string]*Edge
+}
+
+// keyRef identifies a key referenced by data.
+type keyRef struct {
+	kind Kind
+	id   string
+}
+
+func (m *merger) add(doc *Document) error {
+	m.addKeys(doc)
+	nodes := make(map[string]string)
+	combine := make(map[interface{}]bool)
+	for i := range doc.Graphs {
+		g := &doc.Graphs[i]
+		if _, ok := m.top[g.ID]; !ok || m.separate {
+			if err := m.resolve(m.graphs, "graph", &g.ID); err != nil {
+				return err
+			}
+		}
+		if err := m.resolveGraph(g, nodes, combine); err != nil {
+			return err
+		}
+	}
+	if len(nodes) != 0 {
+		renameEdgeNodes(doc.Graphs, nodes)
+	}
+	if len(combine) != 0 {
+		for i := range doc.Graphs {
+			m.extract(&doc.Graphs[i], combine)
+		}
+	}
+	for _, g := range doc.Graphs {
+		i, ok := m.top[g.ID]
+		if !ok || m.separate {
+			m.top[g.ID] = len(m.out.Graphs)
+			m.out.Graphs = append(m.out.Graphs, g)
+			continue
+		}
+		dst := &m.out.Graphs[i]
+		if dst.EdgeDefault != g.EdgeDefault {
+			directed := strconv.FormatBool(g.IsDirected())
+			for j := range g.Edges {
+				e := &g.Edges[j]
+				if _, ok := findAttr(e.Unrecognized, "", "directed"); !ok {
+					e.Unrecognized = append(e.Unrecognized, newAttr("", "directed", directed))
+				}
+			}
+		}
+		dst.Nodes = append(dst.Nodes, g.Nodes...)
+		dst.Edges = append(dst.Edges, g.Edges...)
+		mergeData(&dst.Data, g.Data)
+	}
+	mergeData(&m.out.Data, doc.Data)
+	m.out.Comments = append(m.out.Comments, doc.Comments...)
+	return nil
+}
+
+// addKeys adds keys of the document to the output and updates data of the document to reference them.
+func (m *merger) addKeys(doc *Document) {
+	ids := make(map[keyRef]string, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.For == "" {
+			k.For = KindAll
+		}
+		ids[keyRef{kind: k.For, id: k.ID}] = m.addKey(k)
+	}
+	eachData(doc, func(kind Kind, data *[]Data) {
+		for i := range *data {
+			d := &(*data)[i]
+			if id, ok := ids[keyRef{kind: kind, id: d.Key}]; ok {
+				d.Key = id
+			} else if id, ok = ids[keyRef{kind: KindAll, id: d.Key}]; ok {
+				d.Key = id
+			}
+		}
+	})
+}
+
+// addKey adds a key to the output, unless an equivalent key already exists. It returns an ID of the key in the output.
+func (m *merger) addKey(k Key) string {
+	for i := range m.out.Keys {
+		e := &m.out.Keys[i]
+		if e.For != k.For || e.Name != k.Name || e.Type != k.Type || e.YFilesType != k.YFilesType {
+			continue
+		}
+		if e.ID == k.ID || e.Name != "" || e.YFilesType != "" {
+			return e.ID
+		}
+	}
+	if _, ok := m.keys[k.ID]; ok {
+		k.ID = newKeyID(m.keys)
+	} else {
+		m.keys[k.ID] = struct{}{}
+	}
+	m.out.Keys = append(m.out.Keys, k)
+	return k.ID
+}
+
+// resolve checks if an element ID is already used and resolves the conflict by renaming the element or returning an error.
+// Empty IDs never conflict.
+func (m *merger) resolve(used map[string]struct{}, kind string, id *string) error {
+	if *id == "" {
+		return nil
+	}
+	if _, ok := used[*id]; !ok {
+		used[*id] = struct{}{}
+		return nil
+	}
+	if m.mode == MergeError {
+		return fmt.Errorf("merge: %s %q is defined in multiple documents", kind, *id)
+	}
+	for n := 2; ; n++ {
+		nid := *id + "_" + strconv.Itoa(n)
+		if _, ok := used[nid]; !ok {
+			used[nid] = struct{}{}
+			*id = nid
+			return nil
+		}
+	}
+}
+
+// resolveGraph resolves conflicting IDs of elements in the graph. Renamed nodes are recorded in the nodes map.
+// Nodes and edges that must be combined with existing elements are recorded in the combine map.
+func (m *merger) resolveGraph(g *Graph, nodes map[string]string, combine map[interface{}]bool) error {
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if _, ok := m.nodes[n.ID]; ok && m.mode == MergeCombine {
+			combine[n] = true
+		} else {
+			orig := n.ID
+			if err := m.resolve(m.nodes, "node", &n.ID); err != nil {
+				return err
+			}
+			if n.ID != orig {
+				nodes[orig] = n.ID
+			}
+		}
+		for j := range n.Graphs {
+			if err := m.resolve(m.graphs, "graph", &n.Graphs[j].ID); err != nil {
+				return err
+			}
+			if err := m.resolveGraph(&n.Graphs[j], nodes, combine); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		if _, ok := m.edges[e.ID]; ok && e.ID != "" && m.mode == MergeCombine {
+			combine[e] = true
+		} else if err := m.resolve(m.edges, "edge", &e.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extract removes nodes and edges recorded by resolveGraph from the graph and adds them to pending elements.
+func (m *merger) extract(g *Graph, combine map[interface{}]bool) {
+	nodes := g.Nodes[:0]
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		for j := range n.Graphs {
+			m.extract(&n.Graphs[j], combine)
+		}
+		if !combine[n] {
+			nodes = append(nodes, *n)
+		} else if p := m.pnodes[n.ID]; p != nil {
+			mergeData(&p.Data, n.Data)
+			p.Graphs = append(p.Graphs, n.Graphs...)
+		} else {
+			p := *n
+			m.pnodes[n.ID] = &p
+		}
+	}
+	g.Nodes = nodes
+	edges := g.Edges[:0]
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		if !combine[e] {
+			edges = append(edges, *e)
+		} else if p := m.pedges[e.ID]; p != nil {
+			mergeData(&p.Data, e.Data)
+		} else {
+			p := *e
+			m.pedges[e.ID] = &p
+		}
+	}
+	g.Edges = edges
+}
+
+// combine copies data of pending elements into elements of the output with the same IDs.
+func (m *merger) combine(graphs []Graph) {
+	for i := range graphs {
+		g := &graphs[i]
+		for j := range g.Nodes {
+			n := &g.Nodes[j]
+			if p := m.pnodes[n.ID]; p != nil {
+				delete(m.pnodes, n.ID)
+				mergeData(&n.Data, p.Data)
+				n.Graphs = append(n.Graphs, p.Graphs...)
+			}
+			m.combine(n.Graphs)
+		}
+		for j := range g.Edges {
+			e := &g.Edges[j]
+			if p := m.pedges[e.ID]; p != nil {
+				delete(m.pedges, e.ID)
+				mergeData(&e.Data, p.Data)
+			}
+		}
+	}
+}
+
+// renameEdgeNodes updates edges in the graphs to reference renamed nodes.
+func renameEdgeNodes(graphs []Graph, nodes map[string]string) {
+	for i := range graphs {
+		g := &graphs[i]
+		for j := range g.Edges {
+			e := &g.Edges[j]
+			if id, ok := nodes[e.Source]; ok {
+				e.Source = id
+			}
+			if id, ok := nodes[e.Target]; ok {
+				e.Target = id
+			}
+		}
+		for j := range g.Nodes {
+			renameEdgeNodes(g.Nodes[j].Graphs, nodes)
+		}
+	}
+}
+
+// mergeData appends data for keys that are not yet set in dst.
+func mergeData(dst *[]Data, src []Data) {
+	for _, d := range src {
+		found := false
+		for _, e := range *dst {
+			if e.Key == d.Key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*dst = append(*dst, d)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package graphml
+
+import (
+	"io"
+	"time"
+)
+
+// Metrics receives statistics of decoded and encoded documents, see Options.Metrics.
+// It allows to export parsing telemetry without wrapping readers and writers.
+type Metrics interface {
+	// Decoded is called once DecodeWith finishes. Err is the error returned by DecodeWith, if any.
+	Decoded(s CodecStats, err error)
+	// Encoded is called once EncodeWith finishes. Err is the error returned by EncodeWith, if any.
+	Encoded(s CodecStats, err error)
+}
+
+// CodecStats are counters of a single decoding or encoding operation, see Metrics.
+type CodecStats struct {
+	// Keys, Graphs, Nodes, Edges and Data are numbers of elements decoded or encoded.
+	Keys   int
+	Graphs int
+	Nodes  int
+	Edges  int
+	Data   int
+	// Bytes is a number of bytes read or written.
+	Bytes int64
+	// Tokens is a number of XML tokens copied as content of data elements and key defaults.
+	Tokens int
+	// Duration is a time spent on the operation.
+	Duration time.Duration
+}
+
+// count increments a counter of elements with a given name.
+func (s *CodecStats) count(name string) {
+	switch name {
+	case "key":
+		s.Keys++
+	case "graph":
+		s.Graphs++
+	case "node":
+		s.Nodes++
+	case "edge":
+		s.Edges++
+	case "data":
+		s.Data++
+	}
+}
+
+// countingWriter counts bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,38 @@
+//go:build tinygo || graphml_noreflect
+
+package graphml
+
+import (
+	"errors"
+	"io"
+)
+
+// Builds with TinyGo (or with graphml_noreflect tag) exclude features that depend on reflection,
+// keeping Decode and Encode usable in small WASM bundles. Excluded functions are still declared,
+// but always return ErrNoReflect.
+
+// ErrNoReflect is returned by functions that require reflection in reflect-free builds.
+var ErrNoReflect = errors.New("graphml: not supported in reflect-free builds")
+
+// ErrCacheVersion is returned by LoadCache when the stream was not written by SaveCache or was written by an incompatible version.
+var ErrCacheVersion = errors.New("graphml: unsupported cache format")
+
+// SaveCache is not supported in reflect-free builds.
+func SaveCache(w io.Writer, doc *Document) error {
+	return ErrNoReflect
+}
+
+// LoadCache is not supported in reflect-free builds.
+func LoadCache(r io.Reader) (*Document, error) {
+	return nil, ErrNoReflect
+}
+
+// WriteJSON is not supported in reflect-free builds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	return ErrNoReflect
+}
+
+// SchemaFromType is not supported in reflect-free builds.
+func SchemaFromType[T any](kind Kind) ([]Key, error) {
+	return nil, ErrNoReflect
+}
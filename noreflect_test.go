@@ -0,0 +1,25 @@
+//go:build tinygo || graphml_noreflect
+
+package graphml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoReflect(t *testing.T) {
+	doc, err := Decode(strings.NewReader(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><graph id="G"><node id="n0"/></graph></graphml>`))
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.Equal(t, ErrNoReflect, SaveCache(buf, doc))
+	_, err = LoadCache(buf)
+	require.Equal(t, ErrNoReflect, err)
+	require.Equal(t, ErrNoReflect, NewReport(doc.Validate()).WriteJSON(buf))
+	_, err = SchemaFromType[struct{ Label string }](KindNode)
+	require.Equal(t, ErrNoReflect, err)
+	require.Zero(t, buf.Len())
+}
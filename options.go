@@ -0,0 +1,252 @@
+package graphml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Profile is a target application profile that adjusts encoding and decoding of documents.
+type Profile string
+
+const (
+	// ProfileDefault follows the GraphML specification strictly.
+	ProfileDefault = Profile("")
+	// ProfileGephi writes documents in a way Gephi importer expects and tolerates quirks of documents written by Gephi.
+	// See EncodeWith and DecodeWith for details.
+	ProfileGephi = Profile("gephi")
+	// ProfileYEd writes documents formatted the same way as yEd does it. See EncodeWith for details.
+	ProfileYEd = Profile("yed")
+)
+
+// Options controls encoding and decoding of GraphML documents.
+type Options struct {
+	// Profile is a target application profile.
+	Profile Profile
+	// Strict makes the decoder check that edges reference existing nodes and that key kinds and edge directions
+	// are valid. See DecodeWith for details.
+	Strict bool
+	// Validator runs validation rules while the document is decoded or encoded.
+	Validator *Validator
+	// Partial makes DecodeWith return the document decoded so far together with an error, instead of nil.
+	// Such documents have Incomplete flag set.
+	Partial bool
+	// Trace is called by the decoder for each XML token it reads, and once more if decoding fails.
+	// It allows to log the token stream when diagnosing decoding issues.
+	Trace func(ev TraceEvent)
+	// Metrics receives statistics of each DecodeWith and EncodeWith call, if set.
+	Metrics Metrics
+	// Warn is called by DecodeWith for problems that the decoder accepted, see Warning.
+	Warn func(w Warning)
+	// Positions, if not nil, is filled by DecodeWith with positions of keys, graphs, nodes, edges and data elements.
+	Positions PositionMap
+}
+
+func (opt *Options) validator() *Validator {
+	if opt == nil {
+		return nil
+	}
+	return opt.Validator
+}
+
+func (opt *Options) profile() Profile {
+	if opt == nil {
+		return ProfileDefault
+	}
+	return opt.Profile
+}
+
+// EncodeWith is similar to Encode, but allows to set encoding options.
+//
+// With ProfileGephi, the document is rewritten before encoding (the original document is not modified):
+// keys are ordered by kind and their IDs are replaced with attribute names, node and edge labels and weights
+// are declared with types Gephi recognizes, edges without IDs get generated ones and graphs without an edge
+// direction default to directed.
+//
+// With ProfileYEd, GraphML elements are indented with two spaces, their attributes are sorted by name
+// and empty elements are self-closing. Custom data content is written as-is, except that empty elements in it
+// are self-closing as well, the same way yEd writes them; comments are never modified. Together with top-level
+// comments and namespace prefixes preserved by the decoder, this allows to write yEd files back byte-for-byte.
+//
+// If a Validator is set, rules that check single elements run as elements are encoded,
+// and other rules run after the document is encoded. See Validator for details.
+func EncodeWith(w io.Writer, doc *Document, opt *Options) error {
+	if opt == nil || opt.Metrics == nil {
+		_, err := encodeWith(w, doc, opt)
+		return err
+	}
+	start := time.Now()
+	cw := &countingWriter{w: w}
+	st, err := encodeWith(cw, doc, opt)
+	st.Bytes = cw.n
+	st.Duration = time.Since(start)
+	opt.Metrics.Encoded(st, err)
+	return err
+}
+
+func encodeWith(w io.Writer, doc *Document, opt *Options) (CodecStats, error) {
+	switch opt.profile() {
+	case ProfileGephi:
+		doc = gephiDocument(doc)
+	case ProfileYEd:
+		return encodeYEd(w, doc, opt)
+	}
+	enc := xml.NewEncoder(w)
+	d := &docEncoder{enc: enc, sv: newStreamValidator(opt.validator(), doc)}
+	if err := d.Encode(doc); err != nil {
+		return d.stats, err
+	}
+	return d.stats, enc.Flush()
+}
+
+// encodeYEd writes the document formatted the same way as yEd does it.
+func encodeYEd(w io.Writer, doc *Document, opt *Options) (CodecStats, error) {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	d := &docEncoder{enc: enc, indent: "  ", sortAttrs: true, sv: newStreamValidator(opt.validator(), doc)}
+	if err := d.Encode(doc); err != nil {
+		return d.stats, err
+	}
+	if err := enc.Flush(); err != nil {
+		return d.stats, err
+	}
+	_, err := w.Write(collapseEmpty(buf.Bytes()))
+	return d.stats, err
+}
+
+// collapseEmpty replaces empty elements in the XML output with self-closing ones: <a x="1"></a> becomes <a x="1"/>.
+// Comments, processing instructions and CDATA sections are copied unchanged.
+func collapseEmpty(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for len(b) != 0 {
+		i := bytes.IndexByte(b, '<')
+		if i < 0 {
+			break
+		}
+		out = append(out, b[:i]...)
+		b = b[i:]
+		if n := rawSpan(b); n != 0 {
+			out = append(out, b[:n]...)
+			b = b[n:]
+			continue
+		}
+		j := bytes.IndexByte(b, '>')
+		if j < 0 {
+			break
+		}
+		tag := b[:j+1]
+		out = append(out, tag...)
+		b = b[j+1:]
+		if len(tag) < 3 || tag[1] == '/' || tag[1] == '!' || tag[1] == '?' || tag[len(tag)-2] == '/' {
+			continue
+		}
+		name := tag[1 : len(tag)-1]
+		if k := bytes.IndexAny(name, " \t\r\n"); k >= 0 {
+			name = name[:k]
+		}
+		end := len(name) + 3
+		if len(b) >= end && b[0] == '<' && b[1] == '/' && bytes.Equal(b[2:end-1], name) && b[end-1] == '>' {
+			out = append(out[:len(out)-1], "/>"...)
+			b = b[end:]
+		}
+	}
+	return append(out, b...)
+}
+
+// rawSpans are XML constructs that may contain markup characters which must not be interpreted as tags.
+var rawSpans = [...]struct{ start, end string }{
+	{"<!--", "-->"},
+	{"<?", "?>"},
+	{"<![CDATA[", "]]>"},
+}
+
+// rawSpan returns the length of a comment, a processing instruction or a CDATA section at the beginning of b,
+// or 0 if b starts with something else. Unterminated spans extend to the end of b.
+func rawSpan(b []byte) int {
+	for _, s := range rawSpans {
+		if !bytes.HasPrefix(b, []byte(s.start)) {
+			continue
+		}
+		if i := bytes.Index(b[len(s.start):], []byte(s.end)); i >= 0 {
+			return len(s.start) + i + len(s.end)
+		}
+		return len(b)
+	}
+	return 0
+}
+
+// DecodeWith is similar to Decode, but allows to set decoding options.
+//
+// With ProfileGephi, the decoder accepts GraphML elements without a namespace and data for undeclared keys.
+// Edge labels stored by Gephi in "Edge Label" attribute are renamed to "label". These workarounds are reported
+// as warnings, if the Warn option is set.
+//
+// If a Validator is set, rules that check single elements run as elements are decoded,
+// and other rules run after the document is decoded. See Validator for details.
+//
+// With Strict option, the decoder returns an error if source or target of any edge is not a node of the same graph
+// or of one of its nested graphs (see the "edge-endpoints" and "edge-scope" validation rules), and if "for"
+// attribute of keys or "edgedefault" attribute of graphs has a value not defined by the specification
+// (see ParseKind and ParseEdgeDir).
+//
+// With Partial option, the document is returned even if decoding fails. It contains all keys, graphs, nodes and edges
+// decoded before the error, including graphs, nodes and edges that were not closed; data elements are only included
+// if they were fully decoded.
+func DecodeWith(r io.Reader, opt *Options) (*Document, error) {
+	b := newDocDecoder()
+	if opt == nil || opt.Metrics == nil {
+		return b.decodeWith(r, opt)
+	}
+	start := time.Now()
+	doc, err := b.decodeWith(r, opt)
+	st := b.stats
+	st.Bytes = b.dec.InputOffset()
+	st.Duration = time.Since(start)
+	opt.Metrics.Decoded(st, err)
+	return doc, err
+}
+
+func (b *docDecoder) decodeWith(r io.Reader, opt *Options) (*Document, error) {
+	b.lax = opt.profile() == ProfileGephi
+	b.strict = opt != nil && opt.Strict
+	b.sv = newStreamValidator(opt.validator(), b.doc)
+	if opt != nil {
+		b.trace = opt.Trace
+		b.warn = opt.Warn
+		b.positions = opt.Positions
+	}
+	if err := b.DecodeFrom(xml.NewDecoder(r)); err != nil {
+		if opt != nil && opt.Partial {
+			b.doc.Incomplete = true
+			return b.doc, err
+		}
+		return nil, err
+	}
+	if opt.profile() == ProfileGephi {
+		for i := range b.doc.Keys {
+			k := &b.doc.Keys[i]
+			if k.For == KindEdge && k.Name == gephiEdgeLabel {
+				k.Name = "label"
+				if b.warn != nil {
+					b.warn(Warning{Code: WarnRenamedAttr, Path: "key[" + strconv.Itoa(i) + "]",
+						Message: fmt.Sprintf("attribute %q of key %q is renamed to %q", gephiEdgeLabel, k.ID, k.Name)})
+				}
+			}
+		}
+	}
+	if err := b.sv.finish(); err != nil {
+		return nil, err
+	}
+	if opt != nil && opt.Strict {
+		for _, r := range [...]Rule{edgeEndpointsRule, edgeScopeRule} {
+			if f := r.Check(b.doc); len(f) != 0 {
+				f[0].Rule = r.Name()
+				return nil, ErrValidation{Finding: f[0]}
+			}
+		}
+	}
+	return b.doc, nil
+}
@@ -0,0 +1,59 @@
+package graphml
+
+import (
+	"strconv"
+)
+
+// Position is a location of an element in the source document.
+type Position struct {
+	Line   int
+	Column int
+	// Offset is a byte offset of the start tag of the element.
+	Offset int64
+}
+
+func (p Position) String() string {
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// PositionMap maps paths of elements to their positions in the source document, see Options.Positions.
+//
+// Paths have the same form as DecodeError.Path: elements are counted from zero, separately for each element name,
+// thus "graph[0]/node[3]/data[1]" refers to doc.Graphs[0].Nodes[3].Data[1] and "key[2]" refers to doc.Keys[2].
+// Data of the root element has paths like "data[0]". See Document.ElementPath for finding paths by element IDs.
+type PositionMap map[string]Position
+
+// ElementPath returns a path of the graph, node or edge with a given ID, in the form used by PositionMap
+// and DecodeError. It returns an empty string if there is no such element.
+func (doc *Document) ElementPath(id string) string {
+	if id == "" {
+		return ""
+	}
+	var find func(prefix string, graphs []Graph) string
+	find = func(prefix string, graphs []Graph) string {
+		for i := range graphs {
+			g := &graphs[i]
+			gp := prefix + "graph[" + strconv.Itoa(i) + "]"
+			if g.ID == id {
+				return gp
+			}
+			for j := range g.Edges {
+				if g.Edges[j].ID == id {
+					return gp + "/edge[" + strconv.Itoa(j) + "]"
+				}
+			}
+			for j := range g.Nodes {
+				n := &g.Nodes[j]
+				np := gp + "/node[" + strconv.Itoa(j) + "]"
+				if n.ID == id {
+					return np
+				}
+				if p := find(np+"/", n.Graphs); p != "" {
+					return p
+				}
+			}
+		}
+		return ""
+	}
+	return find("", doc.Graphs)
+}
@@ -0,0 +1,145 @@
+//go:build !tinygo && !graphml_noreflect
+
+package graphml
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	names, err := filepath.Glob(filepath.Join(testdata, "*"+Ext+".gz"))
+	require.NoError(t, err)
+	for _, name := range names {
+		name := name
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			doc := decodeTestFile(t, name)
+
+			buf := new(bytes.Buffer)
+			err := SaveCache(buf, doc)
+			require.NoError(t, err)
+
+			doc2, err := LoadCache(buf)
+			require.NoError(t, err)
+
+			// gob does not distinguish nil and empty slices, thus compare the encoded documents
+			exp, got := new(bytes.Buffer), new(bytes.Buffer)
+			require.NoError(t, Encode(exp, doc))
+			require.NoError(t, Encode(got, doc2))
+			require.Equal(t, exp.String(), got.String())
+		})
+	}
+	_, err = LoadCache(strings.NewReader("<graphml/>"))
+	require.Equal(t, ErrCacheVersion, err)
+
+	doc, err := Decode(strings.NewReader(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><key id="d0" for="node" attr.name="a"><default></default></key><key id="d1" for="node" attr.name="b"></key></graphml>`))
+	require.NoError(t, err)
+	require.Equal(t, []xml.Token{}, doc.Keys[0].Default)
+	buf := new(bytes.Buffer)
+	require.NoError(t, SaveCache(buf, doc))
+	doc2, err := LoadCache(buf)
+	require.NoError(t, err)
+	require.Equal(t, []xml.Token{}, doc2.Keys[0].Default)
+	require.Nil(t, doc2.Keys[1].Default)
+}
+
+func TestCacheCodec(t *testing.T) {
+	RegisterCodec(testPointNS, testPointCodec{})
+	defer RegisterCodec(testPointNS, nil)
+
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns"><key id="d0" for="all"></key><data key="d0"><point xmlns="urn:test:point" x="5" y="6"></point></data><graph edgedefault="directed"><node id="n0"><data key="d0"><point xmlns="urn:test:point" x="1" y="2"></point></data><graph><node id="n0::n0"><data key="d0"><point xmlns="urn:test:point" x="3" y="4"></point></data></node></graph></node><node id="n1"><data key="d0">text</data></node></graph></graphml>`
+	doc, err := Decode(strings.NewReader(src))
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, SaveCache(buf, doc))
+	doc2, err := LoadCache(buf)
+	require.NoError(t, err)
+
+	point := func(d Data) testPoint {
+		p, ok := d.Value().(*testPoint)
+		require.True(t, ok)
+		return testPoint{X: p.X, Y: p.Y}
+	}
+	g := &doc2.Graphs[0]
+	require.Equal(t, testPoint{X: 5, Y: 6}, point(doc2.Data[0]))
+	require.Equal(t, testPoint{X: 1, Y: 2}, point(g.Nodes[0].Data[0]))
+	require.Equal(t, testPoint{X: 3, Y: 4}, point(g.Nodes[0].Graphs[0].Nodes[0].Data[0]))
+	require.Nil(t, g.Nodes[1].Data[0].Value())
+}
+
+func TestSchemaFromType(t *testing.T) {
+	type Base struct {
+		Label string `graphml:"label"`
+	}
+	type NodeAttrs struct {
+		Base
+		Size    float32  `graphml:"size,default=10"`
+		Weight  *float64 `graphml:"weight"`
+		Count   int
+		Visible bool   `graphml:"is visible,id=vis"`
+		Ignored string `graphml:"-"`
+		private int
+	}
+	keys, err := SchemaFromType[NodeAttrs](KindNode)
+	require.NoError(t, err)
+	size := NewKey(KindNode, "node_size", "size", "float")
+	size.Default = []xml.Token{xml.CharData("10")}
+	require.Equal(t, []Key{
+		NewKey(KindNode, "node_label", "label", "string"),
+		size,
+		NewKey(KindNode, "node_weight", "weight", "double"),
+		NewKey(KindNode, "node_Count", "Count", "long"),
+		NewKey(KindNode, "vis", "is visible", "boolean"),
+	}, keys)
+
+	type Bad struct {
+		Tags []string
+	}
+	_, err = SchemaFromType[Bad](KindNode)
+	require.Error(t, err)
+	_, err = SchemaFromType[int](KindNode)
+	require.Error(t, err)
+}
+
+func TestReport(t *testing.T) {
+	r := NewReport([]Finding{
+		{Rule: "data-types", Path: "/graphml/graph[1]/node[@id='n0']/data[1]", Message: `value "x" is not a valid int`, Line: 5, Column: 3},
+		{Rule: "unused-keys", Severity: SeverityInfo, Path: "/graphml/key[@id='d1']", Message: `key "d1" is never used`},
+	})
+	buf := new(bytes.Buffer)
+	require.NoError(t, r.WriteJSON(buf))
+	require.Equal(t, `{
+  "valid": false,
+  "errors": 1,
+  "warnings": 0,
+  "infos": 1,
+  "findings": [
+    {
+      "rule": "data-types",
+      "severity": "error",
+      "path": "/graphml/graph[1]/node[@id='n0']/data[1]",
+      "message": "value \"x\" is not a valid int",
+      "line": 5,
+      "column": 3
+    },
+    {
+      "rule": "unused-keys",
+      "severity": "info",
+      "path": "/graphml/key[@id='d1']",
+      "message": "key \"d1\" is never used"
+    }
+  ]
+}
+`, buf.String())
+
+	var r2 Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &r2))
+	require.Equal(t, r, &r2)
+}
@@ -0,0 +1,15 @@
+//go:build !tinygo && !graphml_noreflect
+
+package graphml
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WriteJSON writes the report as an indented JSON object.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
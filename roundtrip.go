@@ -0,0 +1,64 @@
+package graphml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RoundTripReport is a result of VerifyRoundTrip.
+type RoundTripReport struct {
+	// Doc is the decoded source document.
+	Doc *Document
+	// Size is a size of the encoded document in bytes.
+	Size int
+	// Changes are differences between the source document and the document decoded from its encoded form.
+	// They are reported as changes needed to turn the former into the latter; see Diff for details.
+	Changes []Change
+}
+
+// OK reports whether the document survived the round trip without changes.
+func (r *RoundTripReport) OK() bool {
+	return len(r.Changes) == 0
+}
+
+func (r *RoundTripReport) String() string {
+	if r.OK() {
+		return "round trip: ok"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "round trip: %d changes", len(r.Changes))
+	for _, c := range r.Changes {
+		sb.WriteString("\n")
+		sb.WriteString(c.String())
+	}
+	return sb.String()
+}
+
+// VerifyRoundTrip decodes a document, encodes it, decodes the result again and compares the two documents
+// with Diff. It returns an error if any of these steps fails. The report lists everything that was lost or changed
+// in the process; see Equal for what is considered a change.
+func VerifyRoundTrip(r io.Reader) (*RoundTripReport, error) {
+	return VerifyRoundTripWith(r, nil)
+}
+
+// VerifyRoundTripWith is similar to VerifyRoundTrip, but encodes and decodes documents with given options.
+// It allows to check what is lost when a document is written for a specific Profile.
+func VerifyRoundTripWith(r io.Reader, opt *Options) (*RoundTripReport, error) {
+	doc, err := DecodeWith(r, opt)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = EncodeWith(&buf, doc, opt); err != nil {
+		return nil, fmt.Errorf("round trip: encode: %w", err)
+	}
+	rep := &RoundTripReport{Doc: doc, Size: buf.Len()}
+	doc2, err := DecodeWith(&buf, opt)
+	if err != nil {
+		return rep, fmt.Errorf("round trip: decode encoded document: %w", err)
+	}
+	rep.Changes = Diff(doc, doc2)
+	return rep, nil
+}
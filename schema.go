@@ -0,0 +1,136 @@
+//go:build !tinygo && !graphml_noreflect
+
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SchemaFromType derives key declarations from fields of a struct type T, which can be added to Document.Keys.
+//
+// Each exported field becomes a key for a given kind. Field options are set with a struct tag:
+//
+//	Field int `graphml:"name,id=d0,type=long,default=1"`
+//
+// The name defaults to the field name, the type is derived from the Go type of the field and the ID is derived
+// from the kind and the name. Fields with a "-" tag are skipped, and embedded structs are flattened.
+// Pointers are allowed for optional values. The tag format matches the one used by code generated by graphmlgen.
+func SchemaFromType[T any](kind Kind) ([]Key, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("graphml: expected a struct type, got %v", typ)
+	}
+	var keys []Key
+	if err := schemaFields(&keys, kind, typ); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]string)
+	for _, k := range keys {
+		if prev, ok := seen[k.ID]; ok {
+			return nil, fmt.Errorf("graphml: attributes %q and %q have the same key id %q", prev, k.Name, k.ID)
+		}
+		seen[k.ID] = k.Name
+	}
+	return keys, nil
+}
+
+func schemaFields(keys *[]Key, kind Kind, typ reflect.Type) error {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag, hasTag := f.Tag.Lookup("graphml")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && !hasTag {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := schemaFields(keys, kind, ft); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		k := NewKey(kind, "", f.Name, "")
+		opts := strings.Split(tag, ",")
+		if opts[0] != "" {
+			k.Name = opts[0]
+		}
+		for _, opt := range opts[1:] {
+			i := strings.Index(opt, "=")
+			if i < 0 {
+				return fmt.Errorf("graphml: field %s: invalid tag option: %q", f.Name, opt)
+			}
+			switch name, val := opt[:i], opt[i+1:]; name {
+			case "id":
+				k.ID = val
+			case "type":
+				k.Type = val
+			case "default":
+				k.Default = []xml.Token{xml.CharData(val)}
+			default:
+				return fmt.Errorf("graphml: field %s: unknown tag option: %q", f.Name, name)
+			}
+		}
+		if k.Type == "" {
+			k.Type = schemaType(f.Type)
+			if k.Type == "" {
+				return fmt.Errorf("graphml: field %s: unsupported type: %v", f.Name, f.Type)
+			}
+		}
+		if k.ID == "" {
+			k.ID = schemaID(kind, k.Name)
+		}
+		*keys = append(*keys, k)
+	}
+	return nil
+}
+
+// schemaType returns GraphML attribute type for a Go type.
+func schemaType(typ reflect.Type) string {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return "int"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return "long"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.String:
+		return "string"
+	}
+	return ""
+}
+
+// schemaID derives a key ID from the kind and the attribute name. Characters that are not allowed in IDs are replaced.
+func schemaID(kind Kind, name string) string {
+	var sb strings.Builder
+	sb.WriteString(string(kind))
+	sb.WriteByte('_')
+	for _, r := range name {
+		if r == '_' || r == '-' || r == '.' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteString("_" + strconv.FormatInt(int64(r), 16))
+		}
+	}
+	return sb.String()
+}
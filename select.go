@@ -0,0 +1,321 @@
+package graphml
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Selector matches nodes and edges by their attributes. See ParseSelector for the syntax.
+type Selector struct {
+	src string
+	// any is a disjunction of conjunctions of conditions
+	any [][]selectCond
+}
+
+// selectCond is a single condition of a selector.
+type selectCond struct {
+	name  string
+	op    string // empty for existence checks
+	value string
+	num   float64
+	isNum bool
+	re    *regexp.Regexp
+}
+
+// ParseSelector parses a selector expression. An expression is a list of conditions joined with "&&" (and)
+// and "||" (or); "&&" binds tighter than "||". Each condition compares an attribute with a value:
+//
+//	label="Auth service"   equal to the value
+//	label!=main            not equal to the value
+//	label~="auth.*"        matches the regular expression (anchored to the whole value)
+//	label!~="auth.*"       does not match the regular expression
+//	weight>=0.5            numeric comparison with <, <=, > or >=
+//	label                  has the attribute
+//	!label                 does not have the attribute
+//
+// Attributes are referenced by the attribute name of their key, or by the key ID. Key defaults apply
+// to elements without data for the key. Names "id", "source" and "target" refer to XML attributes of elements.
+// Values may be double-quoted, using Go string syntax.
+func ParseSelector(s string) (*Selector, error) {
+	p := &selectParser{s: s}
+	sel := &Selector{src: s}
+	var and []selectCond
+	for {
+		c, err := p.cond()
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %v", s, err)
+		}
+		and = append(and, c)
+		p.space()
+		switch {
+		case p.eof():
+			sel.any = append(sel.any, and)
+			return sel, nil
+		case p.consume("&&"):
+		case p.consume("||"):
+			sel.any = append(sel.any, and)
+			and = nil
+		default:
+			return nil, fmt.Errorf("invalid selector %q: unexpected %q at %d", s, p.s[p.i:], p.i)
+		}
+	}
+}
+
+func (s *Selector) String() string {
+	return s.src
+}
+
+type selectParser struct {
+	s string
+	i int
+}
+
+func (p *selectParser) eof() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *selectParser) space() {
+	for p.i < len(p.s) && unicode.IsSpace(rune(p.s[p.i])) {
+		p.i++
+	}
+}
+
+func (p *selectParser) consume(tok string) bool {
+	if strings.HasPrefix(p.s[p.i:], tok) {
+		p.i += len(tok)
+		return true
+	}
+	return false
+}
+
+// word reads a quoted string or a sequence of characters up to an operator or a space.
+func (p *selectParser) word() (string, error) {
+	p.space()
+	if p.eof() {
+		return "", fmt.Errorf("unexpected end of expression")
+	}
+	if p.s[p.i] == '"' {
+		j := p.i + 1
+		for ; j < len(p.s); j++ {
+			if p.s[j] == '\\' {
+				j++
+			} else if p.s[j] == '"' {
+				break
+			}
+		}
+		if j >= len(p.s) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		v, err := strconv.Unquote(p.s[p.i : j+1])
+		if err != nil {
+			return "", err
+		}
+		p.i = j + 1
+		return v, nil
+	}
+	j := p.i
+	for ; j < len(p.s); j++ {
+		if c := p.s[j]; unicode.IsSpace(rune(c)) || strings.IndexByte("=!~<>&|\"", c) >= 0 {
+			break
+		}
+	}
+	if j == p.i {
+		return "", fmt.Errorf("unexpected %q at %d", p.s[p.i:], p.i)
+	}
+	v := p.s[p.i:j]
+	p.i = j
+	return v, nil
+}
+
+var selectOps = []string{"~=", "!~=", "!=", "<=", ">=", "=", "<", ">"}
+
+func (p *selectParser) cond() (selectCond, error) {
+	p.space()
+	if p.consume("!") {
+		name, err := p.word()
+		if err != nil {
+			return selectCond{}, err
+		}
+		return selectCond{name: name, op: "!"}, nil
+	}
+	name, err := p.word()
+	if err != nil {
+		return selectCond{}, err
+	}
+	c := selectCond{name: name}
+	p.space()
+	for _, op := range selectOps {
+		if p.consume(op) {
+			c.op = op
+			break
+		}
+	}
+	if c.op == "" {
+		return c, nil
+	}
+	if c.value, err = p.word(); err != nil {
+		return c, err
+	}
+	switch c.op {
+	case "~=", "!~=":
+		if c.re, err = regexp.Compile("^(?:" + c.value + ")$"); err != nil {
+			return c, err
+		}
+	default:
+		if f, err := strconv.ParseFloat(c.value, 64); err == nil {
+			c.num, c.isNum = f, true
+		} else if c.op != "=" && c.op != "!=" {
+			return c, fmt.Errorf("expected a number for %q, got %q", c.op, c.value)
+		}
+	}
+	return c, nil
+}
+
+func (c *selectCond) match(v string, ok bool) bool {
+	switch c.op {
+	case "":
+		return ok
+	case "!":
+		return !ok
+	}
+	if !ok {
+		return c.op == "!=" || c.op == "!~="
+	}
+	switch c.op {
+	case "=", "!=":
+		eq := v == c.value
+		if !eq && c.isNum {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			eq = err == nil && f == c.num
+		}
+		return eq == (c.op == "=")
+	case "~=":
+		return c.re.MatchString(v)
+	case "!~=":
+		return !c.re.MatchString(v)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return false
+	}
+	switch c.op {
+	case "<":
+		return f < c.num
+	case "<=":
+		return f <= c.num
+	case ">":
+		return f > c.num
+	case ">=":
+		return f >= c.num
+	}
+	return false
+}
+
+func (s *Selector) match(attr func(name string) (string, bool)) bool {
+	for _, and := range s.any {
+		ok := true
+		for i := range and {
+			c := &and[i]
+			if !c.match(attr(c.name)) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// attrValue returns a value of an attribute of an element with given data, applying key defaults.
+func (doc *Document) attrValue(kind Kind, data []Data, name string) (string, bool) {
+	for _, d := range data {
+		if k := doc.dataKey(kind, d.Key); k != nil && (k.Name == name || k.ID == name) {
+			return tokensString(d.Data), true
+		} else if k == nil && d.Key == name {
+			return tokensString(d.Data), true
+		}
+	}
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if k.Default != nil && (k.Name == name || k.ID == name) && (k.For == kind || k.For == KindAll || k.For == "") {
+			return tokensString(k.Default), true
+		}
+	}
+	return "", false
+}
+
+// MatchNode checks if the node of the document matches the selector.
+func (s *Selector) MatchNode(doc *Document, n *Node) bool {
+	return s.match(func(name string) (string, bool) {
+		if name == "id" {
+			return n.ID, n.ID != ""
+		}
+		return doc.attrValue(KindNode, n.Data, name)
+	})
+}
+
+// MatchEdge checks if the edge of the document matches the selector.
+func (s *Selector) MatchEdge(doc *Document, e *Edge) bool {
+	return s.match(func(name string) (string, bool) {
+		switch name {
+		case "id":
+			return e.ID, e.ID != ""
+		case "source":
+			return e.Source, true
+		case "target":
+			return e.Target, true
+		}
+		return doc.attrValue(KindEdge, e.Data, name)
+	})
+}
+
+// Filter returns a copy of the document with nodes and edges that match given selectors. Nil selectors match
+// all elements. Nodes that do not match are removed together with their nested graphs, and edges are removed
+// if they do not match or if any of their endpoints is removed. Keys are not changed.
+func (doc *Document) Filter(nodes, edges *Selector) *Document {
+	out := doc.Clone()
+	kept := make(map[string]struct{})
+	var filterNodes func(graphs []Graph)
+	filterNodes = func(graphs []Graph) {
+		for i := range graphs {
+			g := &graphs[i]
+			list := g.Nodes[:0]
+			for _, n := range g.Nodes {
+				if nodes != nil && !nodes.MatchNode(doc, &n) {
+					continue
+				}
+				kept[n.ID] = struct{}{}
+				filterNodes(n.Graphs)
+				list = append(list, n)
+			}
+			g.Nodes = list
+		}
+	}
+	filterNodes(out.Graphs)
+	var filterEdges func(graphs []Graph)
+	filterEdges = func(graphs []Graph) {
+		for i := range graphs {
+			g := &graphs[i]
+			list := g.Edges[:0]
+			for _, e := range g.Edges {
+				_, src := kept[e.Source]
+				_, dst := kept[e.Target]
+				if !src || !dst || (edges != nil && !edges.MatchEdge(doc, &e)) {
+					continue
+				}
+				list = append(list, e)
+			}
+			g.Edges = list
+			for j := range g.Nodes {
+				filterEdges(g.Nodes[j].Graphs)
+			}
+		}
+	}
+	filterEdges(out.Graphs)
+	return out
+}
@@ -0,0 +1,100 @@
+package graphml
+
+// splitDoc returns a copy of the document without graphs.
+func (doc *Document) splitDoc() *Document {
+	out := *doc
+	out.Graphs = nil
+	return out.Clone()
+}
+
+// SplitGraphs returns a separate document for each top-level graph of the document.
+// All documents share a copy of keys and document data.
+func (doc *Document) SplitGraphs() []*Document {
+	out := make([]*Document, 0, len(doc.Graphs))
+	for _, g := range doc.Graphs {
+		d := doc.splitDoc()
+		d.Graphs = cloneGraphs([]Graph{g})
+		out = append(out, d)
+	}
+	return out
+}
+
+// SplitComponents returns a separate document for each weakly connected component of each top-level graph.
+// Nodes with nested graphs are kept together with their content, and edges of nested graphs connect
+// the top-level nodes that contain their endpoints. Edges of the top-level graph go to the component
+// of their source node. Components are ordered by their first node.
+func (doc *Document) SplitComponents() []*Document {
+	var out []*Document
+	for _, g := range cloneGraphs(doc.Graphs) {
+		// top maps IDs of all nodes to indexes of top-level nodes that contain them
+		top := make(map[string]int)
+		var index func(i int, graphs []Graph)
+		index = func(i int, graphs []Graph) {
+			for _, sub := range graphs {
+				for _, n := range sub.Nodes {
+					top[n.ID] = i
+					index(i, n.Graphs)
+				}
+			}
+		}
+		for i, n := range g.Nodes {
+			top[n.ID] = i
+			index(i, n.Graphs)
+		}
+		parent := make([]int, len(g.Nodes))
+		for i := range parent {
+			parent[i] = i
+		}
+		var find func(i int) int
+		find = func(i int) int {
+			for parent[i] != i {
+				parent[i] = parent[parent[i]]
+				i = parent[i]
+			}
+			return i
+		}
+		var union func(graphs []Graph)
+		union = func(graphs []Graph) {
+			for _, sub := range graphs {
+				for _, e := range sub.Edges {
+					s, ok1 := top[e.Source]
+					t, ok2 := top[e.Target]
+					if ok1 && ok2 {
+						parent[find(s)] = find(t)
+					}
+				}
+				for _, n := range sub.Nodes {
+					union(n.Graphs)
+				}
+			}
+		}
+		union([]Graph{g})
+
+		comps := make(map[int]*Graph)
+		var order []int
+		for i, n := range g.Nodes {
+			c := find(i)
+			cg := comps[c]
+			if cg == nil {
+				cg = &Graph{ExtObject: g.ExtObject.clone(), EdgeDefault: g.EdgeDefault}
+				comps[c] = cg
+				order = append(order, c)
+			}
+			cg.Nodes = append(cg.Nodes, n)
+		}
+		for _, e := range g.Edges {
+			s, ok := top[e.Source]
+			if !ok {
+				continue
+			}
+			cg := comps[find(s)]
+			cg.Edges = append(cg.Edges, e)
+		}
+		for _, c := range order {
+			d := doc.splitDoc()
+			d.Graphs = []Graph{*comps[c]}
+			out = append(out, d)
+		}
+	}
+	return out
+}
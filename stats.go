@@ -0,0 +1,159 @@
+package graphml
+
+// Stats is a summary of the document structure, see Document.Stats.
+type Stats struct {
+	// Graphs is a number of graphs, including nested ones.
+	Graphs int `json:"graphs"`
+	// MaxDepth is the maximal nesting level of graphs. It is 1 for documents without nested graphs.
+	MaxDepth int `json:"max_depth"`
+	Nodes    int `json:"nodes"`
+	Edges    int `json:"edges"`
+	// Directed and Undirected are numbers of directed and undirected edges, see Edge.EffectiveDirection.
+	Directed   int `json:"directed"`
+	Undirected int `json:"undirected"`
+	SelfLoops  int `json:"self_loops"`
+	// Isolated is a number of nodes without any edges.
+	Isolated int `json:"isolated"`
+	Keys     int `json:"keys"`
+	// KeyUsage maps key IDs to the number of data elements that reference them.
+	KeyUsage map[string]int `json:"key_usage"`
+	// Degrees is a degree distribution: Degrees[d] is a number of nodes with d incident edges.
+	// Self-loops are counted twice.
+	Degrees   []int   `json:"degrees"`
+	MaxDegree int     `json:"max_degree"`
+	AvgDegree float64 `json:"avg_degree"`
+}
+
+// Stats counts elements of the document and collects the degree distribution of its nodes.
+// Edges are matched to nodes by ID across all graphs of the document.
+func (doc *Document) Stats() *Stats {
+	st := &Stats{Keys: len(doc.Keys), KeyUsage: make(map[string]int, len(doc.Keys))}
+	for _, k := range doc.Keys {
+		st.KeyUsage[k.ID] = 0
+	}
+	eachData(doc, func(_ Kind, data *[]Data) {
+		for _, d := range *data {
+			st.KeyUsage[d.Key]++
+		}
+	})
+	degree := make(map[string]int)
+	var visit func(graphs []Graph, depth int)
+	visit = func(graphs []Graph, depth int) {
+		for i := range graphs {
+			g := &graphs[i]
+			st.Graphs++
+			if depth > st.MaxDepth {
+				st.MaxDepth = depth
+			}
+			for j := range g.Nodes {
+				n := &g.Nodes[j]
+				st.Nodes++
+				if _, ok := degree[n.ID]; !ok {
+					degree[n.ID] = 0
+				}
+				visit(n.Graphs, depth+1)
+			}
+			for _, e := range g.Edges {
+				st.Edges++
+				if e.EffectiveDirection(g) == EdgeUndirected {
+					st.Undirected++
+				} else {
+					st.Directed++
+				}
+				if e.Source == e.Target {
+					st.SelfLoops++
+				}
+				degree[e.Source]++
+				degree[e.Target]++
+			}
+		}
+	}
+	visit(doc.Graphs, 1)
+	if len(degree) == 0 {
+		return st
+	}
+	sum := 0
+	for _, d := range degree {
+		sum += d
+		if d > st.MaxDegree {
+			st.MaxDegree = d
+		}
+	}
+	st.Degrees = make([]int, st.MaxDegree+1)
+	for _, d := range degree {
+		st.Degrees[d]++
+	}
+	st.Isolated = st.Degrees[0]
+	st.AvgDegree = float64(sum) / float64(len(degree))
+	return st
+}
+
+// maxKeySamples is a maximal number of sample values collected by Document.KeyUsage for each key.
+const maxKeySamples = 5
+
+// KeyUsage describes how the data of the document references a key, see Document.KeyUsage.
+type KeyUsage struct {
+	// Key is the key declaration, or nil if data references a key that is not declared.
+	Key *Key
+	// ID is a key ID referenced by data.
+	ID string
+	// Document, Graphs, Nodes and Edges are numbers of elements of each kind that have data for the key.
+	Document int
+	Graphs   int
+	Nodes    int
+	Edges    int
+	// Samples are up to 5 distinct text values of the data, in the document order.
+	// Data with XML content is not sampled.
+	Samples []string
+}
+
+// Total returns the number of elements that have data for the key.
+func (u *KeyUsage) Total() int {
+	return u.Document + u.Graphs + u.Nodes + u.Edges
+}
+
+// KeyUsage returns usage statistics for each key of the document, in the order of declaration.
+// Data is matched to keys the same way the decoder does it, so keys that share an ID but are declared
+// for different kinds are counted separately. Keys referenced by data but not declared are listed last,
+// in the order they are first referenced.
+func (doc *Document) KeyUsage() []KeyUsage {
+	out := make([]KeyUsage, len(doc.Keys))
+	byKey := make(map[*Key]int, len(doc.Keys))
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		out[i] = KeyUsage{Key: k, ID: k.ID}
+		byKey[k] = i
+	}
+	undeclared := make(map[string]int)
+	eachData(doc, func(kind Kind, data *[]Data) {
+		for _, d := range *data {
+			var i int
+			if k := doc.dataKey(kind, d.Key); k != nil {
+				i = byKey[k]
+			} else if j, ok := undeclared[d.Key]; ok {
+				i = j
+			} else {
+				i = len(out)
+				undeclared[d.Key] = i
+				out = append(out, KeyUsage{ID: d.Key})
+			}
+			u := &out[i]
+			switch kind {
+			case KindGraphML:
+				u.Document++
+			case KindGraph:
+				u.Graphs++
+			case KindNode:
+				u.Nodes++
+			case KindEdge:
+				u.Edges++
+			}
+			if len(u.Samples) < maxKeySamples {
+				if s, ok := TokensText(d.Data); ok && !hasString(u.Samples, s) {
+					u.Samples = append(u.Samples, s)
+				}
+			}
+		}
+	})
+	return out
+}
@@ -0,0 +1,75 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// TraceType is a type of a decoder event, see Options.Trace.
+type TraceType int
+
+const (
+	// TraceStart is reported for start elements.
+	TraceStart = TraceType(iota)
+	// TraceEnd is reported for end elements.
+	TraceEnd
+	// TraceText is reported for character data, including whitespace.
+	TraceText
+	// TraceComment is reported for comments.
+	TraceComment
+	// TraceOther is reported for processing instructions and directives.
+	TraceOther
+	// TraceError is reported once when decoding fails. The event has the name of the innermost element being decoded.
+	TraceError
+)
+
+func (t TraceType) String() string {
+	switch t {
+	case TraceStart:
+		return "start"
+	case TraceEnd:
+		return "end"
+	case TraceText:
+		return "text"
+	case TraceComment:
+		return "comment"
+	case TraceOther:
+		return "other"
+	case TraceError:
+		return "error"
+	}
+	return "TraceType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// TraceEvent is an event reported by the decoder, see Options.Trace.
+type TraceEvent struct {
+	Type TraceType
+	// Name is a name of the element for TraceStart, TraceEnd and TraceError events.
+	Name xml.Name
+	// Offset is a byte offset of the token in the source document. For TraceError, it is an offset where decoding stopped.
+	Offset int64
+}
+
+func (e TraceEvent) String() string {
+	s := strconv.FormatInt(e.Offset, 10) + ": " + e.Type.String()
+	if e.Name.Local != "" {
+		s += " " + e.Name.Local
+	}
+	return s
+}
+
+// traceToken reports a token read by the decoder at a given offset.
+func (d *docDecoder) traceToken(t xml.Token, off int64) {
+	ev := TraceEvent{Type: TraceOther, Offset: off}
+	switch t := t.(type) {
+	case xml.StartElement:
+		ev.Type, ev.Name = TraceStart, t.Name
+	case xml.EndElement:
+		ev.Type, ev.Name = TraceEnd, t.Name
+	case xml.CharData:
+		ev.Type = TraceText
+	case xml.Comment:
+		ev.Type = TraceComment
+	}
+	d.trace(ev)
+}
@@ -0,0 +1,171 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// dataKey finds a key referenced by data of a given kind of elements.
+// Keys declared for the kind take precedence over keys declared for all elements.
+func (doc *Document) dataKey(kind Kind, id string) *Key {
+	var all *Key
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if k.ID != id {
+			continue
+		}
+		if k.For == kind {
+			return k
+		} else if all == nil && (k.For == KindAll || k.For == "") {
+			all = k
+		}
+	}
+	return all
+}
+
+// TokensText returns text of the XML content, such as data or a key default, ignoring comments and processing
+// instructions. It returns false if the content has any elements.
+func TokensText(toks []xml.Token) (string, bool) {
+	var sb strings.Builder
+	for _, t := range toks {
+		switch t := t.(type) {
+		case xml.CharData:
+			sb.Write(t)
+		case xml.Comment, xml.ProcInst:
+		default:
+			return "", false
+		}
+	}
+	return sb.String(), true
+}
+
+// validValue checks if the text is a valid value of a given attribute type. Unknown types accept any value.
+func validValue(typ, s string) bool {
+	s = strings.TrimSpace(s)
+	var err error
+	switch typ {
+	case "boolean":
+		switch s {
+		case "true", "false", "1", "0":
+			return true
+		}
+		return false
+	case "int":
+		_, err = strconv.ParseInt(s, 10, 32)
+	case "long":
+		_, err = strconv.ParseInt(s, 10, 64)
+	case "float":
+		_, err = strconv.ParseFloat(s, 32)
+	case "double":
+		_, err = strconv.ParseFloat(s, 64)
+	}
+	return err == nil
+}
+
+// coerceValue converts the text to a canonical value of a given attribute type, if possible.
+// Booleans are accepted in any case, integers may be written as floating point numbers without a fraction,
+// and surrounding whitespace is removed.
+func coerceValue(typ, s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	switch typ {
+	case "boolean":
+		v, err := strconv.ParseBool(strings.ToLower(s))
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatBool(v), true
+	case "int", "long":
+		bits := 64
+		if typ == "int" {
+			bits = 32
+		}
+		if _, err := strconv.ParseInt(s, 10, bits); err == nil {
+			return s, true
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || f != math.Trunc(f) {
+			return "", false
+		}
+		if bits == 32 && (f < math.MinInt32 || f > math.MaxInt32) {
+			return "", false
+		}
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return "", false
+		}
+		return strconv.FormatInt(int64(f), 10), true
+	}
+	if !validValue(typ, s) {
+		return "", false
+	}
+	return s, true
+}
+
+// dataTypesRule reports data and key defaults with values that are not valid for the type of the key.
+var dataTypesRule = NewElementRule("data-types", ElementChecks{
+	Key: func(path string, k *Key) []Finding {
+		if k.Default == nil {
+			return nil
+		}
+		return checkValueType(path+"/default", k.Type, k.Default)
+	},
+	Data: func(doc *Document, path string, kind Kind, d *Data) []Finding {
+		if k := doc.dataKey(kind, d.Key); k != nil {
+			return checkValueType(path, k.Type, d.Data)
+		}
+		return nil
+	},
+})
+
+func checkValueType(path, typ string, toks []xml.Token) []Finding {
+	if typ == "" || typ == "string" {
+		return nil
+	}
+	s, ok := TokensText(toks)
+	if !ok {
+		return []Finding{{Path: path, Message: fmt.Sprintf("expected a %s value, got XML elements", typ)}}
+	} else if !validValue(typ, s) {
+		return []Finding{{Path: path, Message: fmt.Sprintf("value %q is not a valid %s", s, typ)}}
+	}
+	return nil
+}
+
+// CoerceDataTypes fixes values of data and key defaults that are not valid for the type of the key,
+// if they can be converted without losing information: for example, "True" becomes "true" for booleans
+// and "2.0" becomes "2" for integers. It returns the number of changed values.
+// Values that cannot be converted are left as-is and are still reported by the "data-types" validation rule.
+func (doc *Document) CoerceDataTypes() int {
+	n := 0
+	fix := func(typ string, toks []xml.Token) []xml.Token {
+		if typ == "" || typ == "string" {
+			return toks
+		}
+		s, ok := TokensText(toks)
+		if !ok || validValue(typ, s) {
+			return toks
+		}
+		v, ok := coerceValue(typ, s)
+		if !ok {
+			return toks
+		}
+		n++
+		return []xml.Token{xml.CharData(v)}
+	}
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if k.Default != nil {
+			k.Default = fix(k.Type, k.Default)
+		}
+	}
+	eachData(doc, func(kind Kind, data *[]Data) {
+		for i := range *data {
+			d := &(*data)[i]
+			if k := doc.dataKey(kind, d.Key); k != nil {
+				d.Data = fix(k.Type, d.Data)
+			}
+		}
+	})
+	return n
+}
@@ -0,0 +1,518 @@
+package graphml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Severity is a severity of a validation finding.
+type Severity int
+
+const (
+	// SeverityError marks violations of the GraphML specification.
+	SeverityError = Severity(iota)
+	// SeverityWarning marks documents that are valid, but likely to be misinterpreted by other tools.
+	SeverityWarning
+	// SeverityInfo marks harmless issues, for example declarations that are never used.
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	}
+	return "severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(b []byte) error {
+	for _, v := range []Severity{SeverityError, SeverityWarning, SeverityInfo} {
+		if v.String() == string(b) {
+			*s = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity: %q", b)
+}
+
+// Finding is a single problem found by a validation rule.
+type Finding struct {
+	// Rule is a name of the rule that reported the finding.
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Path is a location of the element in XPath form, for example /graphml/graph[@id='G']/node[@id='n0'].
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// Line and Column is a position of the element in the decoded document, if known.
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+}
+
+func (f Finding) String() string {
+	if f.Line != 0 {
+		return fmt.Sprintf("%d:%d: %s: %s: %s (%s)", f.Line, f.Column, f.Path, f.Severity, f.Message, f.Rule)
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Path, f.Severity, f.Message, f.Rule)
+}
+
+// Rule is a validation rule for GraphML documents.
+type Rule interface {
+	// Name is a unique name of the rule, for example "unique-ids".
+	Name() string
+	// Check validates the document and returns all problems found.
+	Check(doc *Document) []Finding
+}
+
+// RuleFunc creates a validation rule from a function.
+func RuleFunc(name string, check func(doc *Document) []Finding) Rule {
+	return &funcRule{name: name, check: check}
+}
+
+type funcRule struct {
+	name  string
+	check func(doc *Document) []Finding
+}
+
+func (r *funcRule) Name() string {
+	return r.name
+}
+func (r *funcRule) Check(doc *Document) []Finding {
+	return r.check(doc)
+}
+
+var rules struct {
+	sync.RWMutex
+	list []Rule
+}
+
+// RegisterRule registers a custom validation rule, so that it's returned by DefaultRules and LookupRule.
+// It replaces any rule registered previously with the same name, including standard rules.
+func RegisterRule(r Rule) {
+	rules.Lock()
+	defer rules.Unlock()
+	for i, r2 := range rules.list {
+		if r2.Name() == r.Name() {
+			rules.list[i] = r
+			return
+		}
+	}
+	rules.list = append(rules.list, r)
+}
+
+// LookupRule finds a standard or a registered rule with a given name.
+func LookupRule(name string) (Rule, bool) {
+	for _, r := range DefaultRules() {
+		if r.Name() == name {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// DefaultRules returns standard validation rules used by Validate, followed by rules registered with RegisterRule.
+func DefaultRules() []Rule {
+	out := standardRules()
+	rules.RLock()
+	defer rules.RUnlock()
+	for _, r := range rules.list {
+		replaced := false
+		for i := range out {
+			if out[i].Name() == r.Name() {
+				out[i] = r
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func standardRules() []Rule {
+	return []Rule{
+		RuleFunc("unique-ids", checkUniqueIDs),
+		dataKeysRule,
+		attrValuesRule,
+		edgeEndpointsRule,
+		RuleFunc("duplicate-keys", checkDuplicateKeys),
+		RuleFunc("unused-keys", checkUnusedKeys),
+		dataTypesRule,
+		edgeScopeRule,
+	}
+}
+
+// Validate checks the document with given rules and returns all problems found.
+// If no rules are given, DefaultRules are used. Findings without a rule name are attributed to the rule
+// that reported them.
+func (doc *Document) Validate(rules ...Rule) []Finding {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	var out []Finding
+	for _, r := range rules {
+		for _, f := range r.Check(doc) {
+			if f.Rule == "" {
+				f.Rule = r.Name()
+			}
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Report is a machine-readable summary of validation findings. See WriteJSON.
+type Report struct {
+	// Valid is set if there are no error-level findings.
+	Valid    bool      `json:"valid"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+	Infos    int       `json:"infos"`
+	Findings []Finding `json:"findings"`
+}
+
+// NewReport creates a report for given findings.
+func NewReport(findings []Finding) *Report {
+	r := &Report{Findings: findings}
+	if r.Findings == nil {
+		r.Findings = []Finding{}
+	}
+	for _, f := range findings {
+		switch f.Severity {
+		case SeverityError:
+			r.Errors++
+		case SeverityWarning:
+			r.Warnings++
+		case SeverityInfo:
+			r.Infos++
+		}
+	}
+	r.Valid = r.Errors == 0
+	return r
+}
+
+// Policy adjusts findings reported by validation rules, allowing to enforce a custom level of strictness.
+type Policy struct {
+	// Severity overrides severity of all findings of rules with given names.
+	Severity map[string]Severity
+	// Ignore disables rules with given names.
+	Ignore map[string]bool
+	// MinSeverity drops findings that are less severe than the given level. Nil value keeps all findings.
+	MinSeverity *Severity
+}
+
+// Apply adjusts findings according to the policy. The slice is modified in place.
+func (p *Policy) Apply(findings []Finding) []Finding {
+	if p == nil {
+		return findings
+	}
+	out := findings[:0]
+	for _, f := range findings {
+		if p.Ignore[f.Rule] {
+			continue
+		}
+		if s, ok := p.Severity[f.Rule]; ok {
+			f.Severity = s
+		}
+		if p.MinSeverity != nil && f.Severity > *p.MinSeverity {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// Validate checks the document with given rules (or DefaultRules) and adjusts findings according to the policy.
+func (p *Policy) Validate(doc *Document, rules ...Rule) []Finding {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	if p != nil && len(p.Ignore) != 0 {
+		enabled := make([]Rule, 0, len(rules))
+		for _, r := range rules {
+			if !p.Ignore[r.Name()] {
+				enabled = append(enabled, r)
+			}
+		}
+		rules = enabled
+		if len(rules) == 0 {
+			return nil
+		}
+	}
+	return p.Apply(doc.Validate(rules...))
+}
+
+// HasErrors checks if any of findings is an error.
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// pathStep returns a step of an element path. Elements are addressed by ID, if they have one,
+// or by a 1-based position among siblings otherwise.
+func pathStep(parent, name, id string, i int) string {
+	if id != "" {
+		return parent + "/" + name + "[@id=" + quotePath(id) + "]"
+	}
+	return parent + "/" + name + "[" + strconv.Itoa(i+1) + "]"
+}
+
+// quotePath quotes a string for an element path.
+func quotePath(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	return strconv.Quote(s)
+}
+
+// docVisitor is a set of callbacks for walkDoc. Each callback receives a path of the element.
+type docVisitor struct {
+	Key   func(path string, k *Key)
+	Graph func(path string, g *Graph)
+	Node  func(path string, n *Node)
+	Edge  func(path string, e *Edge)
+	Data  func(path string, kind Kind, d *Data)
+}
+
+// walkDoc calls visitor callbacks for all elements of the document, including nested graphs.
+func walkDoc(doc *Document, v *docVisitor) {
+	const root = "/graphml"
+	for i := range doc.Keys {
+		if v.Key != nil {
+			v.Key(pathStep(root, "key", doc.Keys[i].ID, i), &doc.Keys[i])
+		}
+	}
+	v.data(root, KindGraphML, doc.Data)
+	v.graphs(root, doc.Graphs)
+}
+func (v *docVisitor) data(parent string, kind Kind, data []Data) {
+	if v.Data == nil {
+		return
+	}
+	for i := range data {
+		v.Data(parent+"/data["+strconv.Itoa(i+1)+"]", kind, &data[i])
+	}
+}
+func (v *docVisitor) graphs(parent string, graphs []Graph) {
+	for i := range graphs {
+		g := &graphs[i]
+		gp := pathStep(parent, "graph", g.ID, i)
+		if v.Graph != nil {
+			v.Graph(gp, g)
+		}
+		v.data(gp, KindGraph, g.Data)
+		for j := range g.Nodes {
+			n := &g.Nodes[j]
+			np := pathStep(gp, "node", n.ID, j)
+			if v.Node != nil {
+				v.Node(np, n)
+			}
+			v.data(np, KindNode, n.Data)
+			v.graphs(np, n.Graphs)
+		}
+		for j := range g.Edges {
+			e := &g.Edges[j]
+			ep := pathStep(gp, "edge", e.ID, j)
+			if v.Edge != nil {
+				v.Edge(ep, e)
+			}
+			v.data(ep, KindEdge, e.Data)
+		}
+	}
+}
+
+// checkUniqueIDs reports graphs, nodes and edges that reuse an ID of another element.
+func checkUniqueIDs(doc *Document) []Finding {
+	var out []Finding
+	seen := make(map[string]string)
+	check := func(path, id string) {
+		if id == "" {
+			return
+		}
+		if prev, ok := seen[id]; ok {
+			out = append(out, Finding{Path: path, Message: fmt.Sprintf("id %q is already used by %s", id, prev)})
+			return
+		}
+		seen[id] = path
+	}
+	walkDoc(doc, &docVisitor{
+		Graph: func(path string, g *Graph) { check(path, g.ID) },
+		Node:  func(path string, n *Node) { check(path, n.ID) },
+		Edge:  func(path string, e *Edge) { check(path, e.ID) },
+	})
+	return out
+}
+
+// ElementChecks are callbacks of a rule that checks elements one at a time, see NewElementRule.
+// Each callback receives a path of the element and is called once the element is complete,
+// for example after all data and nested graphs of a node are decoded.
+type ElementChecks struct {
+	Key   func(path string, k *Key) []Finding
+	Graph func(path string, g *Graph) []Finding
+	Node  func(path string, n *Node) []Finding
+	Edge  func(path string, e *Edge) []Finding
+	// Data is called for data of all elements. Kind is a kind of the element that owns the data.
+	// The document contains all keys, but may not have all graphs yet, if it is being decoded.
+	Data func(doc *Document, path string, kind Kind, d *Data) []Finding
+}
+
+// NewElementRule creates a validation rule that checks elements one at a time.
+// Unlike rules created with RuleFunc, Validator runs such rules inline, as elements are decoded or encoded.
+func NewElementRule(name string, checks ElementChecks) Rule {
+	return &elementRule{name: name, ElementChecks: checks}
+}
+
+type elementRule struct {
+	name string
+	ElementChecks
+}
+
+func (r *elementRule) Name() string {
+	return r.name
+}
+func (r *elementRule) Check(doc *Document) []Finding {
+	var out []Finding
+	v := &docVisitor{}
+	if r.Key != nil {
+		v.Key = func(path string, k *Key) { out = append(out, r.Key(path, k)...) }
+	}
+	if r.Graph != nil {
+		v.Graph = func(path string, g *Graph) { out = append(out, r.Graph(path, g)...) }
+	}
+	if r.Node != nil {
+		v.Node = func(path string, n *Node) { out = append(out, r.Node(path, n)...) }
+	}
+	if r.Edge != nil {
+		v.Edge = func(path string, e *Edge) { out = append(out, r.Edge(path, e)...) }
+	}
+	if r.Data != nil {
+		v.Data = func(path string, kind Kind, d *Data) { out = append(out, r.Data(doc, path, kind, d)...) }
+	}
+	walkDoc(doc, v)
+	return out
+}
+
+var dataKeysRule = NewElementRule("data-keys", ElementChecks{Data: checkDataKey})
+
+// checkDataKey reports data that references a key not declared for the kind of its element.
+func checkDataKey(doc *Document, path string, kind Kind, d *Data) []Finding {
+	declared := false
+	for _, k := range doc.Keys {
+		if k.ID != d.Key {
+			continue
+		}
+		if k.For == kind || k.For == KindAll || k.For == "" {
+			return nil
+		}
+		declared = true
+	}
+	if declared {
+		// other tools usually resolve keys by ID only
+		return []Finding{{Severity: SeverityWarning, Path: path, Message: fmt.Sprintf("key %q is not declared for %v", d.Key, kind)}}
+	}
+	return []Finding{{Path: path, Message: fmt.Sprintf("undeclared key %q", d.Key)}}
+}
+
+// attrValuesRule reports values of enumerated attributes that are not allowed by the specification.
+var attrValuesRule = NewElementRule("attr-values", ElementChecks{
+	Key: func(path string, k *Key) []Finding {
+		if validKind(k.For) {
+			return nil
+		}
+		return []Finding{{Path: path, Message: fmt.Sprintf("unknown element kind %q", k.For) + didYouMean(string(k.For), kinds)}}
+	},
+	Graph: func(path string, g *Graph) []Finding {
+		if validEdgeDir(g.EdgeDefault) {
+			return nil
+		}
+		return []Finding{{Path: path, Message: fmt.Sprintf("unknown edge direction %q", g.EdgeDefault) + didYouMean(string(g.EdgeDefault), edgeDirs)}}
+	},
+})
+
+var edgeEndpointsRule = RuleFunc("edge-endpoints", checkEdgeEndpoints)
+
+// collectNodeIDs adds IDs of all nodes of the graph, including nodes of nested graphs, to the set.
+func collectNodeIDs(ids map[string]struct{}, g *Graph) {
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		ids[n.ID] = struct{}{}
+		for j := range n.Graphs {
+			collectNodeIDs(ids, &n.Graphs[j])
+		}
+	}
+}
+
+// checkEdgeEndpoints reports edges with a missing source or target, or with one that references a node
+// that does not exist in the document. References to nodes outside of the edge's graph are reported
+// by the "edge-scope" rule instead.
+func checkEdgeEndpoints(doc *Document) []Finding {
+	all := make(map[string]struct{})
+	for i := range doc.Graphs {
+		collectNodeIDs(all, &doc.Graphs[i])
+	}
+	var out []Finding
+	walkDoc(doc, &docVisitor{
+		Edge: func(path string, e *Edge) {
+			for _, end := range [...]struct{ attr, id string }{{"source", e.Source}, {"target", e.Target}} {
+				if end.id == "" {
+					out = append(out, Finding{Path: path, Message: "missing " + end.attr})
+				} else if _, ok := all[end.id]; !ok {
+					out = append(out, Finding{Path: path, Message: fmt.Sprintf("%s references unknown node %q", end.attr, end.id)})
+				}
+			}
+		},
+	})
+	return out
+}
+
+var edgeScopeRule = RuleFunc("edge-scope", checkEdgeScope)
+
+// checkEdgeScope reports edges that are not declared in a common ancestor graph of their endpoints,
+// as required by the specification: an edge may only reference nodes of its own graph or of graphs nested in it.
+// References to nodes that do not exist are reported by the "edge-endpoints" rule instead.
+func checkEdgeScope(doc *Document) []Finding {
+	all := make(map[string]struct{})
+	for i := range doc.Graphs {
+		collectNodeIDs(all, &doc.Graphs[i])
+	}
+	var out []Finding
+	walkDoc(doc, &docVisitor{
+		Graph: func(path string, g *Graph) {
+			if len(g.Edges) == 0 {
+				return
+			}
+			scope := make(map[string]struct{})
+			collectNodeIDs(scope, g)
+			for i := range g.Edges {
+				e := &g.Edges[i]
+				for _, id := range [...]string{e.Source, e.Target} {
+					if _, ok := scope[id]; ok {
+						continue
+					}
+					if _, ok := all[id]; ok {
+						out = append(out, Finding{Path: pathStep(path, "edge", e.ID, i),
+							Message: fmt.Sprintf("node %q is outside of the graph, the edge must be declared in an enclosing graph", id)})
+					}
+				}
+			}
+		},
+	})
+	return out
+}
@@ -0,0 +1,201 @@
+package graphml
+
+// Validator runs validation rules while a document is decoded or encoded, see Options.
+//
+// Rules that check elements one at a time (see NewElementRule) run inline,
+// as elements are streamed, and findings of the decoder include positions in the source document.
+// Other rules need the whole document, thus they run once the root element is decoded or encoded.
+type Validator struct {
+	// Rules is a list of rules to run. Defaults to DefaultRules.
+	Rules []Rule
+	// Policy adjusts findings before they are reported.
+	Policy *Policy
+	// Report is called for each finding. Decoding or encoding stops if it returns an error.
+	// If it is not set, the first error-level finding stops decoding or encoding.
+	Report func(f Finding) error
+}
+
+func (v *Validator) report(f Finding) error {
+	if v.Policy != nil {
+		fs := v.Policy.Apply([]Finding{f})
+		if len(fs) == 0 {
+			return nil
+		}
+		f = fs[0]
+	}
+	if v.Report != nil {
+		return v.Report(f)
+	}
+	if f.Severity == SeverityError {
+		return ErrValidation{Finding: f}
+	}
+	return nil
+}
+
+// streamLevel is a state of a single element on the streamValidator stack.
+type streamLevel struct {
+	path      string
+	line, col int
+	counts    map[string]int
+}
+
+// streamValidator runs validation rules for elements as they are decoded or encoded.
+// All methods are safe to call on a nil value, which disables validation.
+type streamValidator struct {
+	v      *Validator
+	inline []*elementRule
+	whole  []Rule
+	doc    *Document
+	stack  []streamLevel
+	// line and col is a position of the last token read by the decoder, if any
+	line, col int
+}
+
+func newStreamValidator(v *Validator, doc *Document) *streamValidator {
+	if v == nil {
+		return nil
+	}
+	s := &streamValidator{v: v, doc: doc}
+	rules := v.Rules
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	for _, r := range rules {
+		if er, ok := r.(*elementRule); ok {
+			s.inline = append(s.inline, er)
+		} else {
+			s.whole = append(s.whole, r)
+		}
+	}
+	s.stack = []streamLevel{{path: "/graphml", counts: make(map[string]int)}}
+	return s
+}
+
+// setPos records a position of the next token in the source document.
+func (s *streamValidator) setPos(line, col int) {
+	if s == nil {
+		return
+	}
+	s.line, s.col = line, col
+}
+
+// enter must be called when an element starts. The element must be closed with leave.
+func (s *streamValidator) enter(name, id string) {
+	if s == nil {
+		return
+	}
+	top := &s.stack[len(s.stack)-1]
+	i := top.counts[name]
+	top.counts[name]++
+	s.stack = append(s.stack, streamLevel{
+		path: pathStep(top.path, name, id, i), line: s.line, col: s.col,
+		counts: make(map[string]int),
+	})
+}
+func (s *streamValidator) leave() {
+	if s == nil {
+		return
+	}
+	s.stack = s.stack[:len(s.stack)-1]
+}
+
+// report sends findings of the current element to the validator.
+func (s *streamValidator) report(rule string, findings []Finding) error {
+	top := &s.stack[len(s.stack)-1]
+	for _, f := range findings {
+		if f.Rule == "" {
+			f.Rule = rule
+		}
+		f.Line, f.Column = top.line, top.col
+		if err := s.v.report(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (s *streamValidator) path() string {
+	return s.stack[len(s.stack)-1].path
+}
+func (s *streamValidator) key(k *Key) error {
+	if s == nil {
+		return nil
+	}
+	for _, r := range s.inline {
+		if r.Key != nil {
+			if err := s.report(r.name, r.Key(s.path(), k)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+func (s *streamValidator) graph(g *Graph) error {
+	if s == nil {
+		return nil
+	}
+	for _, r := range s.inline {
+		if r.Graph != nil {
+			if err := s.report(r.name, r.Graph(s.path(), g)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+func (s *streamValidator) node(n *Node) error {
+	if s == nil {
+		return nil
+	}
+	for _, r := range s.inline {
+		if r.Node != nil {
+			if err := s.report(r.name, r.Node(s.path(), n)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+func (s *streamValidator) edge(e *Edge) error {
+	if s == nil {
+		return nil
+	}
+	for _, r := range s.inline {
+		if r.Edge != nil {
+			if err := s.report(r.name, r.Edge(s.path(), e)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+func (s *streamValidator) data(kind Kind, d *Data) error {
+	if s == nil {
+		return nil
+	}
+	for _, r := range s.inline {
+		if r.Data != nil {
+			if err := s.report(r.name, r.Data(s.doc, s.path(), kind, d)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// finish runs rules that need the whole document.
+func (s *streamValidator) finish() error {
+	if s == nil {
+		return nil
+	}
+	for _, r := range s.whole {
+		for _, f := range r.Check(s.doc) {
+			if f.Rule == "" {
+				f.Rule = r.Name()
+			}
+			if err := s.v.report(f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
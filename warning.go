@@ -0,0 +1,52 @@
+package graphml
+
+import "fmt"
+
+// WarningCode identifies a type of a Warning.
+type WarningCode string
+
+const (
+	// WarnMissingNamespace is reported once per document if it has GraphML elements without a namespace.
+	WarnMissingNamespace = WarningCode("missing-namespace")
+	// WarnUndeclaredKey is reported once per key for data that references a key not declared for the kind of elements.
+	WarnUndeclaredKey = WarningCode("undeclared-key")
+	// WarnRenamedAttr is reported for attributes renamed by the decoder, for example Gephi edge labels.
+	WarnRenamedAttr = WarningCode("renamed-attr")
+	// WarnCodecFailed is reported for data that a registered Codec failed to decode. The data keeps raw XML tokens.
+	WarnCodecFailed = WarningCode("codec-failed")
+)
+
+// Warning is a problem in the document that the decoder accepted, either in lenient mode (see ProfileGephi)
+// or because the content is kept as-is (see WarnCodecFailed).
+// Such documents decode without errors, but may be interpreted differently by other tools.
+type Warning struct {
+	Code WarningCode
+	// Path is a location of the element, in the same form as DecodeError.Path.
+	Path string
+	// Line, Column and Offset is a position in the source document, if known.
+	Line    int
+	Column  int
+	Offset  int64
+	Message string
+}
+
+func (w Warning) String() string {
+	s := w.Message + " (" + string(w.Code) + ")"
+	if w.Path != "" {
+		s = w.Path + ": " + s
+	}
+	if w.Line != 0 {
+		s = fmt.Sprintf("%d:%d: %s", w.Line, w.Column, s)
+	}
+	return s
+}
+
+// warnf reports a warning for the current element, if warnings are enabled.
+func (d *docDecoder) warnf(code WarningCode, format string, args ...interface{}) {
+	if d.warn == nil {
+		return
+	}
+	w := Warning{Code: code, Path: d.pathString(), Offset: d.dec.InputOffset(), Message: fmt.Sprintf(format, args...)}
+	w.Line, w.Column = d.dec.InputPos()
+	d.warn(w)
+}
@@ -0,0 +1,359 @@
+package graphml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// xlinkNamespace is a namespace of XLink attributes used by locator elements.
+const xlinkNamespace = "http://www.w3.org/1999/xlink"
+
+// schemaStage is a group of child elements that may appear at a given position in the content of an element.
+type schemaStage struct {
+	names []string
+	// many allows more than one element from the group.
+	many bool
+	// exclusive disallows any elements after an element from this group.
+	exclusive bool
+}
+
+func (s *schemaStage) has(name string) bool {
+	for _, n := range s.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaElement describes constraints on a GraphML element imposed by graphml.xsd.
+type schemaElement struct {
+	content []schemaStage
+	// mixed allows arbitrary content, including text and elements from other namespaces.
+	mixed bool
+	// text allows text content.
+	text     bool
+	attrs    map[string][]string // allowed attributes and their allowed values (any value, if nil)
+	required []string
+}
+
+var (
+	enumBool     = []string{"true", "false"}
+	enumKind     = []string{"all", "graphml", "graph", "node", "edge", "hyperedge", "port", "endpoint"}
+	enumAttrType = attrTypes
+)
+
+// schemaElements are content models and attributes of GraphML elements, as declared by graphml.xsd
+// (including the attributes extension).
+var schemaElements = map[string]*schemaElement{
+	"graphml": {
+		content: []schemaStage{{names: []string{"desc"}}, {names: []string{"key"}, many: true}, {names: []string{"graph", "data"}, many: true}},
+		attrs:   map[string][]string{},
+	},
+	"desc": {text: true, attrs: map[string][]string{}},
+	"key": {
+		content:  []schemaStage{{names: []string{"desc"}}, {names: []string{"default"}}},
+		attrs:    map[string][]string{"id": nil, "for": enumKind, "attr.name": nil, "attr.type": enumAttrType},
+		required: []string{"id"},
+	},
+	"default": {mixed: true, attrs: map[string][]string{}},
+	"data": {
+		mixed:    true,
+		attrs:    map[string][]string{"key": nil, "id": nil},
+		required: []string{"key"},
+	},
+	"graph": {
+		content: []schemaStage{
+			{names: []string{"desc"}},
+			{names: []string{"locator"}, exclusive: true},
+			{names: []string{"data", "node", "edge", "hyperedge"}, many: true},
+		},
+		attrs: map[string][]string{
+			"id": nil, "edgedefault": {"directed", "undirected"},
+			"parse.nodeids": {"canonical", "free"}, "parse.edgeids": {"canonical", "free"},
+			"parse.order": {"nodesfirst", "adjacencylist", "free"},
+			"parse.nodes": nil, "parse.edges": nil, "parse.maxindegree": nil, "parse.maxoutdegree": nil,
+		},
+		required: []string{"edgedefault"},
+	},
+	"node": {
+		content: []schemaStage{
+			{names: []string{"desc"}},
+			{names: []string{"locator"}, exclusive: true},
+			{names: []string{"data", "port"}, many: true},
+			{names: []string{"graph"}},
+		},
+		attrs:    map[string][]string{"id": nil, "parse.indegree": nil, "parse.outdegree": nil},
+		required: []string{"id"},
+	},
+	"port": {
+		content:  []schemaStage{{names: []string{"desc"}}, {names: []string{"data", "port"}, many: true}},
+		attrs:    map[string][]string{"name": nil},
+		required: []string{"name"},
+	},
+	"edge": {
+		content: []schemaStage{{names: []string{"desc"}}, {names: []string{"data"}, many: true}, {names: []string{"graph"}}},
+		attrs: map[string][]string{
+			"id": nil, "directed": enumBool, "source": nil, "target": nil, "sourceport": nil, "targetport": nil,
+		},
+		required: []string{"source", "target"},
+	},
+	"hyperedge": {
+		content: []schemaStage{{names: []string{"desc"}}, {names: []string{"data", "endpoint"}, many: true}, {names: []string{"graph"}}},
+		attrs:   map[string][]string{"id": nil},
+	},
+	"endpoint": {
+		content:  []schemaStage{{names: []string{"desc"}}},
+		attrs:    map[string][]string{"id": nil, "node": nil, "port": nil, "type": {"in", "out", "undir"}},
+		required: []string{"node"},
+	},
+	"locator": {attrs: map[string][]string{}},
+}
+
+// schemaFrame is a state of an element that is being validated.
+type schemaFrame struct {
+	elem   *schemaElement
+	path   string
+	stage  int
+	n      int            // number of elements in the current stage
+	counts map[string]int // number of children with a given name
+	done   bool           // no more children are allowed
+	skip   int            // nesting level inside mixed content
+}
+
+// ValidateSchema checks a GraphML document against constraints of the official graphml.xsd schema:
+// content models of elements, required attributes, enumerated attribute values, uniqueness of IDs
+// and references from data to keys and from edges and endpoints to nodes.
+//
+// Unlike Decode, it accepts all elements of the specification, including ports, hyperedges and locators.
+// Content of data and default elements is not checked. Attributes from other namespaces are allowed,
+// while unknown attributes without a namespace (for example, yFiles extensions) are reported as errors,
+// as graphml.xsd does not declare them. An error is returned only if the document is not well-formed XML.
+func ValidateSchema(r io.Reader) ([]Finding, error) {
+	dec := xml.NewDecoder(r)
+	var (
+		out   []Finding
+		stack []*schemaFrame
+		root  bool
+	)
+	report := func(path, format string, args ...interface{}) {
+		out = append(out, Finding{Rule: "schema", Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	type ref struct {
+		path, id string
+	}
+	var (
+		keys    = make(map[string]string)
+		nodes   = make(map[string]string)
+		edges   = make(map[string]string)
+		graphs  = make(map[string]string)
+		hyper   = make(map[string]string)
+		keyRefs []ref
+		nodeRef []ref
+	)
+	unique := func(ids map[string]string, path, kind, id string) {
+		if id == "" {
+			return
+		}
+		if prev, ok := ids[id]; ok {
+			report(path, "%s id %q is already used by %s", kind, id, prev)
+			return
+		}
+		ids[id] = path
+	}
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return out, err
+		}
+		var top *schemaFrame
+		if len(stack) != 0 {
+			top = stack[len(stack)-1]
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			if top != nil && top.skip != 0 {
+				top.skip++
+				continue
+			}
+			if top != nil && top.elem.mixed {
+				top.skip++
+				continue
+			}
+			parent := ""
+			if top != nil {
+				parent = top.path
+			}
+			id := ""
+			for _, a := range t.Attr {
+				if a.Name.Space == "" && a.Name.Local == "id" {
+					id = a.Value
+				}
+			}
+			i := 0
+			if top != nil {
+				i = top.counts[t.Name.Local]
+				top.counts[t.Name.Local]++
+			}
+			path := pathStep(parent, t.Name.Local, id, i)
+			if top == nil {
+				path = "/" + t.Name.Local
+				if root {
+					report(path, "multiple root elements")
+				}
+				root = true
+			}
+			elem := schemaElements[t.Name.Local]
+			if t.Name.Space != Namespace || elem == nil {
+				report(path, "unknown element %s", formatName(t.Name))
+				// skip the content, but keep track of the nesting
+				stack = append(stack, &schemaFrame{elem: &schemaElement{mixed: true}, path: path})
+				continue
+			} else if top == nil && t.Name.Local != "graphml" {
+				report(path, "root element must be graphml")
+			} else if top != nil {
+				top.child(t.Name.Local, path, report)
+			}
+			for _, a := range t.Attr {
+				if a.Name.Space != "" || a.Name.Local == "xmlns" {
+					continue
+				}
+				values, ok := elem.attrs[a.Name.Local]
+				if !ok && elem.attrs != nil {
+					report(path, "unknown attribute %q", a.Name.Local)
+				} else if values != nil && !hasString(values, a.Value) {
+					report(path, "invalid value of %q: %q", a.Name.Local, a.Value)
+				}
+			}
+			for _, name := range elem.required {
+				if _, ok := schemaAttr(t, name); !ok {
+					report(path, "missing attribute %q", name)
+				}
+			}
+			switch t.Name.Local {
+			case "locator":
+				if _, ok := findAttr(t.Attr, xlinkNamespace, "href"); !ok {
+					report(path, "missing attribute %q", "xlink:href")
+				}
+			case "key":
+				unique(keys, path, "key", id)
+			case "node":
+				unique(nodes, path, "node", id)
+			case "edge":
+				unique(edges, path, "edge", id)
+				for _, name := range []string{"source", "target"} {
+					if v, ok := schemaAttr(t, name); ok {
+						nodeRef = append(nodeRef, ref{path: path, id: v})
+					}
+				}
+			case "graph":
+				unique(graphs, path, "graph", id)
+			case "hyperedge":
+				unique(hyper, path, "hyperedge", id)
+			case "endpoint":
+				if v, ok := schemaAttr(t, "node"); ok {
+					nodeRef = append(nodeRef, ref{path: path, id: v})
+				}
+			case "data":
+				if v, ok := schemaAttr(t, "key"); ok {
+					keyRefs = append(keyRefs, ref{path: path, id: v})
+				}
+			}
+			stack = append(stack, &schemaFrame{elem: elem, path: path, counts: make(map[string]int)})
+		case xml.EndElement:
+			if top.skip != 0 {
+				top.skip--
+				continue
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if top == nil || top.skip != 0 || top.elem.mixed || top.elem.text {
+				continue
+			}
+			if len(bytes.TrimSpace(t)) != 0 {
+				report(top.path, "unexpected text content")
+			}
+		}
+	}
+	if !root {
+		report("/", "missing root element")
+	}
+	for _, r := range keyRefs {
+		if _, ok := keys[r.id]; !ok {
+			report(r.path, "undeclared key %q", r.id)
+		}
+	}
+	for _, r := range nodeRef {
+		if _, ok := nodes[r.id]; !ok {
+			report(r.path, "reference to unknown node %q", r.id)
+		}
+	}
+	return out, nil
+}
+
+// child checks if an element with a given name is allowed at the current position in the content of the frame.
+func (f *schemaFrame) child(name, path string, report func(path, format string, args ...interface{})) {
+	content := f.elem.content
+	if !f.done {
+		for st := f.stage; st < len(content); st++ {
+			s := &content[st]
+			if !s.has(name) {
+				continue
+			}
+			if st == f.stage && f.n != 0 && !s.many {
+				break
+			}
+			if st != f.stage {
+				f.stage, f.n = st, 0
+			}
+			f.n++
+			f.done = s.exclusive
+			return
+		}
+	}
+	report(path, "element %s is not allowed here", name)
+}
+
+// schemaAttr returns a value of an attribute without a namespace.
+func schemaAttr(t xml.StartElement, name string) (string, bool) {
+	return findAttr(t.Attr, "", name)
+}
+
+func findAttr(attrs []xml.Attr, ns, name string) (string, bool) {
+	for _, a := range attrs {
+		if a.Name.Space == ns && a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func hasString(arr []string, s string) bool {
+	for _, v := range arr {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func formatName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
+
+// ValidateSchema encodes the document and checks it against constraints of the official graphml.xsd schema.
+// See ValidateSchema function for details.
+func (doc *Document) ValidateSchema() ([]Finding, error) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, doc); err != nil {
+		return nil, err
+	}
+	return ValidateSchema(&buf)
+}
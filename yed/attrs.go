@@ -0,0 +1,109 @@
+package yed
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// Names of attributes that yEd shows in the properties of nodes and edges.
+const (
+	AttrURL         = "url"
+	AttrDescription = "description"
+)
+
+// textKey finds a key for a string attribute with a given name.
+func textKey(doc *graphml.Document, kind graphml.Kind, name string) *graphml.Key {
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if k.Name == name && (k.For == kind || k.For == graphml.KindAll) {
+			return k
+		}
+	}
+	return nil
+}
+
+// DeclareTextKey returns an ID of a key for a string attribute with a given name, declaring the key if necessary.
+func DeclareTextKey(doc *graphml.Document, kind graphml.Kind, name string) string {
+	if k := textKey(doc, kind, name); k != nil {
+		return k.ID
+	}
+	id := uniqueKeyID(doc)
+	doc.Keys = append(doc.Keys, graphml.NewKey(kind, id, name, "string"))
+	return id
+}
+
+// getText returns a value of a string attribute from element data.
+func getText(doc *graphml.Document, kind graphml.Kind, data []graphml.Data, name string) (string, bool) {
+	k := textKey(doc, kind, name)
+	if k == nil {
+		return "", false
+	}
+	d := findData(data, k.ID)
+	if d == nil {
+		return "", false
+	}
+	var sb strings.Builder
+	for _, t := range d.Data {
+		switch t := t.(type) {
+		case xml.CharData:
+			sb.Write(t)
+		case xml.Comment:
+		default:
+			return "", false
+		}
+	}
+	return sb.String(), true
+}
+
+// setText sets a value of a string attribute in element data, declaring the key if necessary.
+func setText(doc *graphml.Document, kind graphml.Kind, data *[]graphml.Data, name, value string) {
+	key := DeclareTextKey(doc, kind, name)
+	toks := []xml.Token{xml.CharData(value)}
+	if d := findData(*data, key); d != nil {
+		d.Data = toks
+		return
+	}
+	*data = append(*data, graphml.Data{Key: key, Data: toks})
+}
+
+// NodeURL returns a URL of the node.
+func NodeURL(doc *graphml.Document, n *graphml.Node) (string, bool) {
+	return getText(doc, graphml.KindNode, n.Data, AttrURL)
+}
+
+// SetNodeURL sets a URL of the node. yEd opens it when the node is clicked in a browser export.
+func SetNodeURL(doc *graphml.Document, n *graphml.Node, url string) {
+	setText(doc, graphml.KindNode, &n.Data, AttrURL, url)
+}
+
+// NodeDescription returns a description of the node.
+func NodeDescription(doc *graphml.Document, n *graphml.Node) (string, bool) {
+	return getText(doc, graphml.KindNode, n.Data, AttrDescription)
+}
+
+// SetNodeDescription sets a description of the node. yEd shows it as a tooltip.
+func SetNodeDescription(doc *graphml.Document, n *graphml.Node, desc string) {
+	setText(doc, graphml.KindNode, &n.Data, AttrDescription, desc)
+}
+
+// EdgeURL returns a URL of the edge.
+func EdgeURL(doc *graphml.Document, e *graphml.Edge) (string, bool) {
+	return getText(doc, graphml.KindEdge, e.Data, AttrURL)
+}
+
+// SetEdgeURL sets a URL of the edge.
+func SetEdgeURL(doc *graphml.Document, e *graphml.Edge, url string) {
+	setText(doc, graphml.KindEdge, &e.Data, AttrURL, url)
+}
+
+// EdgeDescription returns a description of the edge.
+func EdgeDescription(doc *graphml.Document, e *graphml.Edge) (string, bool) {
+	return getText(doc, graphml.KindEdge, e.Data, AttrDescription)
+}
+
+// SetEdgeDescription sets a description of the edge. yEd shows it as a tooltip.
+func SetEdgeDescription(doc *graphml.Document, e *graphml.Edge, desc string) {
+	setText(doc, graphml.KindEdge, &e.Data, AttrDescription, desc)
+}
@@ -0,0 +1,79 @@
+package yed
+
+import (
+	"encoding/xml"
+
+	"github.com/dennwc/graphml"
+)
+
+// SchemaLocation is a location of GraphML schema with yFiles extensions.
+const SchemaLocation = "http://www.yworks.com/xml/schema/graphml/1.1/ygraphml.xsd"
+
+// IDs of keys declared by NewDocument. They match IDs of keys written by yEd.
+const (
+	KeyGraphDescription = "d0"
+	KeyPortGraphics     = "d1"
+	KeyPortGeometry     = "d2"
+	KeyPortUserData     = "d3"
+	KeyNodeURL          = "d4"
+	KeyNodeDescription  = "d5"
+	KeyNodeGraphics     = "d6"
+	KeyResources        = "d7"
+	KeyEdgeURL          = "d8"
+	KeyEdgeDescription  = "d9"
+	KeyEdgeGraphics     = "d10"
+)
+
+func nsAttr(prefix, ns string) xml.Attr {
+	return xml.Attr{Name: xml.Name{Space: "xmlns", Local: prefix}, Value: ns}
+}
+
+func yfilesKey(kind graphml.Kind, id, typ string) graphml.Key {
+	k := graphml.NewKey(kind, id, "", "")
+	k.YFilesType = typ
+	return k
+}
+
+// NewDocument creates an empty document with namespaces and keys declared the same way as yEd does it.
+// The document contains a single directed graph with ID "G".
+func NewDocument() *graphml.Document {
+	doc := &graphml.Document{
+		Instr: xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8" standalone="no"`)},
+		Attrs: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns"}, Value: graphml.Namespace},
+			nsAttr("java", "http://www.yworks.com/xml/yfiles-common/1.0/java"),
+			nsAttr("sys", "http://www.yworks.com/xml/yfiles-common/markup/primitives/2.0"),
+			nsAttr("x", "http://www.yworks.com/xml/yfiles-common/markup/2.0"),
+			nsAttr("xsi", graphml.XSINamespace),
+			nsAttr("y", Namespace),
+			nsAttr("yed", "http://www.yworks.com/xml/yed/3"),
+			{
+				Name:  xml.Name{Space: graphml.XSINamespace, Local: "schemaLocation"},
+				Value: graphml.Namespace + " " + SchemaLocation,
+			},
+		},
+		Keys: []graphml.Key{
+			graphml.NewKey(graphml.KindGraph, KeyGraphDescription, "Description", "string"),
+			yfilesKey(graphml.KindPort, KeyPortGraphics, "portgraphics"),
+			yfilesKey(graphml.KindPort, KeyPortGeometry, "portgeometry"),
+			yfilesKey(graphml.KindPort, KeyPortUserData, "portuserdata"),
+			graphml.NewKey(graphml.KindNode, KeyNodeURL, "url", "string"),
+			graphml.NewKey(graphml.KindNode, KeyNodeDescription, "description", "string"),
+			yfilesKey(graphml.KindNode, KeyNodeGraphics, TypeNodeGraphics),
+			yfilesKey(graphml.KindGraphML, KeyResources, TypeResources),
+			graphml.NewKey(graphml.KindEdge, KeyEdgeURL, "url", "string"),
+			graphml.NewKey(graphml.KindEdge, KeyEdgeDescription, "description", "string"),
+			yfilesKey(graphml.KindEdge, KeyEdgeGraphics, TypeEdgeGraphics),
+		},
+	}
+	var g graphml.Graph
+	g.ID = "G"
+	g.EdgeDefault = graphml.EdgeDirected
+	doc.Graphs = []graphml.Graph{g}
+	// yEd always writes the resources section, even if it's empty
+	doc.Data = []graphml.Data{{Key: KeyResources}}
+	if err := doc.Data[0].SetValue(Namespace, &Resources{}); err != nil {
+		panic(err)
+	}
+	return doc
+}
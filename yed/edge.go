@@ -0,0 +1,100 @@
+package yed
+
+import (
+	"encoding/xml"
+
+	"github.com/dennwc/graphml"
+)
+
+// ArrowType is a style of an arrowhead at the end of an edge.
+type ArrowType string
+
+// Arrowheads supported by yEd.
+const (
+	ArrowNone         = ArrowType("none")
+	ArrowStandard     = ArrowType("standard")
+	ArrowDelta        = ArrowType("delta")
+	ArrowWhiteDelta   = ArrowType("white_delta")
+	ArrowDiamond      = ArrowType("diamond")
+	ArrowWhiteDiamond = ArrowType("white_diamond")
+	ArrowShort        = ArrowType("short")
+	ArrowPlain        = ArrowType("plain")
+	ArrowConcave      = ArrowType("concave")
+	ArrowConvex       = ArrowType("convex")
+	ArrowCircle       = ArrowType("circle")
+	ArrowCircleOpen   = ArrowType("transparent_circle")
+	ArrowDash         = ArrowType("dash")
+	ArrowTShape       = ArrowType("t_shape")
+)
+
+// PolyLineEdge is a yEd edge drawn as a sequence of straight line segments (y:PolyLineEdge).
+type PolyLineEdge struct {
+	XMLName   xml.Name    `xml:"http://www.yworks.com/xml/graphml PolyLineEdge"`
+	Path      *Path       `xml:"http://www.yworks.com/xml/graphml Path"`
+	LineStyle *LineStyle  `xml:"http://www.yworks.com/xml/graphml LineStyle"`
+	Arrows    *Arrows     `xml:"http://www.yworks.com/xml/graphml Arrows"`
+	Labels    []EdgeLabel `xml:"http://www.yworks.com/xml/graphml EdgeLabel"`
+	BendStyle *BendStyle  `xml:"http://www.yworks.com/xml/graphml BendStyle"`
+	// Extra contains all other elements of the edge.
+	Extra []Element `xml:",any"`
+}
+
+// Path is a route of the edge.
+type Path struct {
+	// SX, SY, TX and TY are offsets of edge ports from the centers of source and target nodes.
+	SX float64 `xml:"sx,attr"`
+	SY float64 `xml:"sy,attr"`
+	TX float64 `xml:"tx,attr"`
+	TY float64 `xml:"ty,attr"`
+	// Points are bends of the edge in absolute coordinates.
+	Points []Point `xml:"http://www.yworks.com/xml/graphml Point"`
+}
+
+// Point is a bend of the edge.
+type Point struct {
+	X float64 `xml:"x,attr"`
+	Y float64 `xml:"y,attr"`
+}
+
+// LineStyle is a style of an edge line.
+type LineStyle struct {
+	Color string   `xml:"color,attr,omitempty"`
+	Type  LineType `xml:"type,attr,omitempty"`
+	Width float64  `xml:"width,attr"`
+}
+
+// Arrows are arrowheads at the source and the target ends of the edge.
+type Arrows struct {
+	Source ArrowType `xml:"source,attr"`
+	Target ArrowType `xml:"target,attr"`
+}
+
+// BendStyle controls if the bends of the edge are rounded.
+type BendStyle struct {
+	Smoothed bool `xml:"smoothed,attr"`
+}
+
+// NewPolyLineEdge creates graphics of a straight edge with yEd default style.
+func NewPolyLineEdge() *PolyLineEdge {
+	return &PolyLineEdge{
+		Path:      &Path{},
+		LineStyle: &LineStyle{Color: "#000000", Type: LineSolid, Width: 1},
+		Arrows:    &Arrows{Source: ArrowNone, Target: ArrowStandard},
+		BendStyle: &BendStyle{},
+	}
+}
+
+// GetPolyLineEdge returns yEd graphics of the edge, if it is drawn as a PolyLineEdge.
+func GetPolyLineEdge(doc *graphml.Document, e *graphml.Edge) (*PolyLineEdge, bool) {
+	d := EdgeGraphics(doc, e)
+	if d == nil {
+		return nil, false
+	}
+	p, ok := d.Value().(*PolyLineEdge)
+	return p, ok
+}
+
+// SetPolyLineEdge sets yEd graphics of the edge to a PolyLineEdge. It declares the edge graphics key, if necessary.
+func SetPolyLineEdge(doc *graphml.Document, e *graphml.Edge, p *PolyLineEdge) error {
+	return setEdgeGraphics(doc, e, p)
+}
@@ -0,0 +1,92 @@
+package yed
+
+import (
+	"github.com/dennwc/graphml"
+)
+
+// ExtractOptions controls ExtractLabels.
+type ExtractOptions struct {
+	// Name is a name of the attribute for label text. Defaults to "label".
+	Name string
+	// StripGraphics removes all yFiles data and keys from the document after extracting labels.
+	StripGraphics bool
+}
+
+func (opt *ExtractOptions) name() string {
+	if opt == nil || opt.Name == "" {
+		return "label"
+	}
+	return opt.Name
+}
+
+// walkGraphs calls fn for all graphs, including nested ones.
+func walkGraphs(graphs []graphml.Graph, fn func(g *graphml.Graph)) {
+	for i := range graphs {
+		g := &graphs[i]
+		fn(g)
+		for j := range g.Nodes {
+			walkGraphs(g.Nodes[j].Graphs, fn)
+		}
+	}
+}
+
+// ExtractLabels copies text of the first yEd label of each node and edge into a plain string attribute,
+// so that the document can be used by tools that do not understand yFiles graphics.
+//
+// Existing values of the attribute are overwritten for elements that have labels.
+func ExtractLabels(doc *graphml.Document, opt *ExtractOptions) {
+	name := opt.name()
+	walkGraphs(doc.Graphs, func(g *graphml.Graph) {
+		for i := range g.Nodes {
+			n := &g.Nodes[i]
+			if labels := NodeLabels(doc, n); len(labels) != 0 {
+				setText(doc, graphml.KindNode, &n.Data, name, labels[0].Text)
+			}
+		}
+		for i := range g.Edges {
+			e := &g.Edges[i]
+			if labels := EdgeLabels(doc, e); len(labels) != 0 {
+				setText(doc, graphml.KindEdge, &e.Data, name, labels[0].Text)
+			}
+		}
+	})
+	if opt != nil && opt.StripGraphics {
+		StripGraphics(doc)
+	}
+}
+
+// StripGraphics removes all yFiles data and keys from the document.
+func StripGraphics(doc *graphml.Document) {
+	yfiles := make(map[string]struct{})
+	keys := doc.Keys[:0]
+	for _, k := range doc.Keys {
+		if k.YFilesType != "" {
+			yfiles[k.ID] = struct{}{}
+		} else {
+			keys = append(keys, k)
+		}
+	}
+	doc.Keys = keys
+	if len(yfiles) == 0 {
+		return
+	}
+	strip := func(data []graphml.Data) []graphml.Data {
+		out := data[:0]
+		for _, d := range data {
+			if _, ok := yfiles[d.Key]; !ok {
+				out = append(out, d)
+			}
+		}
+		return out
+	}
+	doc.Data = strip(doc.Data)
+	walkGraphs(doc.Graphs, func(g *graphml.Graph) {
+		g.Data = strip(g.Data)
+		for i := range g.Nodes {
+			g.Nodes[i].Data = strip(g.Nodes[i].Data)
+		}
+		for i := range g.Edges {
+			g.Edges[i].Data = strip(g.Edges[i].Data)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package yed
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+// Geometry is a position and a size of a node.
+type Geometry struct {
+	X      float64 `xml:"x,attr"`
+	Y      float64 `xml:"y,attr"`
+	Width  float64 `xml:"width,attr"`
+	Height float64 `xml:"height,attr"`
+}
+
+func (g *Geometry) attrs() []xml.Attr {
+	return []xml.Attr{
+		floatAttr("height", g.Height),
+		floatAttr("width", g.Width),
+		floatAttr("x", g.X),
+		floatAttr("y", g.Y),
+	}
+}
+
+// geometryElement is a standalone y:Geometry element.
+type geometryElement struct {
+	XMLName xml.Name `xml:"http://www.yworks.com/xml/graphml Geometry"`
+	Geometry
+}
+
+func floatAttr(name string, v float64) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: name}, Value: strconv.FormatFloat(v, 'f', -1, 64)}
+}
+
+// GetGeometry returns the position and the size of the node from its yFiles graphics.
+//
+// It works for all kinds of yEd nodes. For group nodes the geometry of the active state is returned.
+func GetGeometry(doc *graphml.Document, n *graphml.Node) (Geometry, bool) {
+	d := NodeGraphics(doc, n)
+	if d == nil {
+		return Geometry{}, false
+	}
+	r, ok := activeRealizer(d.Data)
+	if !ok {
+		return Geometry{}, false
+	}
+	c, ok := child(d.Data, r, "Geometry")
+	if !ok {
+		return Geometry{}, false
+	}
+	var g Geometry
+	if err := decodeSpan(d.Data, c, &g); err != nil {
+		return Geometry{}, false
+	}
+	return g, true
+}
+
+// SetGeometry sets the position and the size of the node in its yFiles graphics.
+//
+// Only the y:Geometry element is changed, all other graphics of the node are kept as-is. If the node has no graphics,
+// it is drawn as a rectangular ShapeNode. For group nodes the geometry of the active state is changed.
+func SetGeometry(doc *graphml.Document, n *graphml.Node, g Geometry) error {
+	d := NodeGraphics(doc, n)
+	if d == nil {
+		return SetShapeNode(doc, n, &ShapeNode{Geometry: &g, Shape: &Shape{Type: ShapeRectangle}})
+	}
+	return updateChild(d, "Geometry", func(st *xml.StartElement) {
+		for _, a := range g.attrs() {
+			setAttr(st, a.Name.Local, a.Value)
+		}
+	}, &geometryElement{Geometry: g})
+}
@@ -0,0 +1,196 @@
+package yed
+
+import (
+	"encoding/xml"
+
+	"github.com/dennwc/graphml"
+)
+
+// Folder types of yEd group nodes, as set in the yfiles.foldertype attribute of a node.
+const (
+	FolderGroup  = "group"  // an open group
+	FolderFolder = "folder" // a closed group
+)
+
+// ProxyAutoBoundsNode is a yEd group node with open and closed states (y:ProxyAutoBoundsNode).
+// Bounds of an open group are adjusted automatically to fit its nested graph.
+type ProxyAutoBoundsNode struct {
+	XMLName   xml.Name  `xml:"http://www.yworks.com/xml/graphml ProxyAutoBoundsNode"`
+	Realizers Realizers `xml:"http://www.yworks.com/xml/graphml Realizers"`
+	// Extra contains all other elements of the node.
+	Extra []Element `xml:",any"`
+}
+
+// Realizers is a list of states of a group node.
+type Realizers struct {
+	// Active is an index of the state that is currently displayed.
+	Active int         `xml:"active,attr"`
+	Nodes  []GroupNode `xml:"http://www.yworks.com/xml/graphml GroupNode"`
+	// Extra contains all other states of the node.
+	Extra []Element `xml:",any"`
+}
+
+// GroupNode is a state of a group node (y:GroupNode).
+type GroupNode struct {
+	XMLName     xml.Name     `xml:"http://www.yworks.com/xml/graphml GroupNode"`
+	Geometry    *Geometry    `xml:"http://www.yworks.com/xml/graphml Geometry"`
+	Fill        *Fill        `xml:"http://www.yworks.com/xml/graphml Fill"`
+	BorderStyle *BorderStyle `xml:"http://www.yworks.com/xml/graphml BorderStyle"`
+	Labels      []NodeLabel  `xml:"http://www.yworks.com/xml/graphml NodeLabel"`
+	Shape       *Shape       `xml:"http://www.yworks.com/xml/graphml Shape"`
+	State       *GroupState  `xml:"http://www.yworks.com/xml/graphml State"`
+	// Extra contains all other elements of the node, for example insets.
+	Extra []Element `xml:",any"`
+}
+
+// GroupState describes if the group is open or closed.
+type GroupState struct {
+	Closed                   bool    `xml:"closed,attr"`
+	ClosedWidth              float64 `xml:"closedWidth,attr,omitempty"`
+	ClosedHeight             float64 `xml:"closedHeight,attr,omitempty"`
+	InnerGraphDisplayEnabled bool    `xml:"innerGraphDisplayEnabled,attr"`
+}
+
+// newGroupNode creates a state of a group node with yEd default style.
+func newGroupNode(label string, closed bool) GroupNode {
+	l := NewNodeLabel(label)
+	l.Alignment = "right"
+	l.BackgroundColor = "#EBEBEB"
+	l.FontSize = 15
+	l.ModelPosition = "t"
+	l.Attrs = append(l.Attrs, xml.Attr{Name: xml.Name{Local: "autoSizePolicy"}, Value: "node_width"})
+	g := GroupNode{
+		XMLName:     xml.Name{Space: Namespace, Local: "GroupNode"},
+		Fill:        &Fill{Color: "#F5F5F5"},
+		BorderStyle: &BorderStyle{Color: "#000000", Type: LineDashed, Width: 1},
+		Labels:      []NodeLabel{l},
+		Shape:       &Shape{Type: ShapeRoundRectangle},
+		State:       &GroupState{Closed: closed, ClosedWidth: 50, ClosedHeight: 50},
+	}
+	if closed {
+		g.Fill.Color = "#F2F0D8"
+		g.Geometry = &Geometry{Width: 50, Height: 50}
+	}
+	return g
+}
+
+// NewProxyAutoBoundsNode creates graphics of a group node with yEd default style for open and closed states.
+func NewProxyAutoBoundsNode(label string) *ProxyAutoBoundsNode {
+	return &ProxyAutoBoundsNode{
+		Realizers: Realizers{
+			Nodes: []GroupNode{
+				newGroupNode(label, false),
+				newGroupNode(label, true),
+			},
+		},
+	}
+}
+
+// Group is a yEd group node together with its nested graph.
+type Group struct {
+	Node  *graphml.Node
+	Graph *graphml.Graph
+	// Label is a text of the first label of the group.
+	Label string
+	// Closed is set for groups that are displayed as closed folders.
+	Closed bool
+}
+
+// folderType returns the value of the yfiles.foldertype attribute of the node.
+func folderType(n *graphml.Node) string {
+	for _, a := range n.Unrecognized {
+		if a.Name.Local == "yfiles.foldertype" {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// IsGroup reports if the node is a group, either marked by yEd or having a nested graph.
+func IsGroup(n *graphml.Node) bool {
+	switch folderType(n) {
+	case FolderGroup, FolderFolder:
+		return true
+	}
+	return len(n.Graphs) != 0
+}
+
+// Groups returns all group nodes of the graph. Groups nested into other groups are not included.
+func Groups(doc *graphml.Document, g *graphml.Graph) []Group {
+	var out []Group
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if !IsGroup(n) {
+			continue
+		}
+		gr := Group{Node: n, Closed: folderType(n) == FolderFolder}
+		if len(n.Graphs) != 0 {
+			gr.Graph = &n.Graphs[0]
+		}
+		if labels := NodeLabels(doc, n); len(labels) != 0 {
+			gr.Label = labels[0].Text
+		}
+		out = append(out, gr)
+	}
+	return out
+}
+
+// isGroupGraphics reports if the data contains graphics of a group node.
+func isGroupGraphics(d *graphml.Data) bool {
+	list := children(d.Data, span{})
+	return len(list) != 0 && nameOf(d.Data, list[0]).Local == "ProxyAutoBoundsNode"
+}
+
+// MakeGroup turns the node into a yEd group with a given label.
+//
+// It marks the node as a group, sets group graphics of the node and adds an empty nested graph,
+// if the node has none. Graphics of the node that is already a group are kept as-is.
+func MakeGroup(doc *graphml.Document, n *graphml.Node, label string) error {
+	if len(n.Graphs) == 0 {
+		var g graphml.Graph
+		g.ID = n.ID + ":"
+		g.EdgeDefault = graphml.EdgeDirected
+		n.Graphs = append(n.Graphs, g)
+	}
+	if folderType(n) == "" {
+		n.Unrecognized = append(n.Unrecognized, xml.Attr{Name: xml.Name{Local: "yfiles.foldertype"}, Value: FolderGroup})
+	}
+	if d := NodeGraphics(doc, n); d != nil && isGroupGraphics(d) {
+		return nil
+	}
+	return setNodeGraphics(doc, n, NewProxyAutoBoundsNode(label))
+}
+
+// NormalizeGroups makes sure that all nodes with nested graphs are valid yEd groups, so that yEd can display them.
+//
+// Nodes that have no group graphics yet are converted with MakeGroup, keeping the text of their labels.
+func NormalizeGroups(doc *graphml.Document) error {
+	var walk func(g *graphml.Graph) error
+	walk = func(g *graphml.Graph) error {
+		for i := range g.Nodes {
+			n := &g.Nodes[i]
+			if len(n.Graphs) == 0 {
+				continue
+			}
+			label := n.ID
+			if labels := NodeLabels(doc, n); len(labels) != 0 {
+				label = labels[0].Text
+			}
+			if err := MakeGroup(doc, n, label); err != nil {
+				return err
+			}
+			for j := range n.Graphs {
+				if err := walk(&n.Graphs[j]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for i := range doc.Graphs {
+		if err := walk(&doc.Graphs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package yed
+
+import (
+	"encoding/xml"
+
+	"github.com/dennwc/graphml"
+)
+
+// FontStyle is a style of a label font.
+type FontStyle string
+
+// Font styles supported by yEd.
+const (
+	FontPlain      = FontStyle("plain")
+	FontBold       = FontStyle("bold")
+	FontItalic     = FontStyle("italic")
+	FontBoldItalic = FontStyle("bolditalic")
+)
+
+// Label is a common part of node and edge labels.
+type Label struct {
+	Text       string    `xml:",chardata"`
+	FontFamily string    `xml:"fontFamily,attr,omitempty"`
+	FontSize   int       `xml:"fontSize,attr,omitempty"`
+	FontStyle  FontStyle `xml:"fontStyle,attr,omitempty"`
+	TextColor  string    `xml:"textColor,attr,omitempty"`
+	// Alignment of multi-line text: left, center or right.
+	Alignment string `xml:"alignment,attr,omitempty"`
+
+	BackgroundColor string `xml:"backgroundColor,attr,omitempty"`
+	LineColor       string `xml:"lineColor,attr,omitempty"`
+	// HasBackgroundColor and HasLineColor are set to false by yEd for labels without a background or a border.
+	// They must be nil or true for the colors to be visible.
+	HasBackgroundColor *bool `xml:"hasBackgroundColor,attr,omitempty"`
+	HasLineColor       *bool `xml:"hasLineColor,attr,omitempty"`
+
+	// ModelName and ModelPosition define a placement of the label, for example "internal" and "c" for the center
+	// of the node, or "centered" and "center" for the middle of the edge.
+	ModelName     string `xml:"modelName,attr,omitempty"`
+	ModelPosition string `xml:"modelPosition,attr,omitempty"`
+
+	Space string `xml:"http://www.w3.org/XML/1998/namespace space,attr,omitempty"`
+	// Attrs contains all other attributes of the label.
+	Attrs []xml.Attr `xml:",any,attr"`
+	// Extra contains child elements of the label, for example a custom label model.
+	Extra []Element `xml:",any"`
+}
+
+// NodeLabel is a label of a yEd node (y:NodeLabel).
+type NodeLabel struct {
+	XMLName xml.Name `xml:"http://www.yworks.com/xml/graphml NodeLabel"`
+	Label
+}
+
+// EdgeLabel is a label of a yEd edge (y:EdgeLabel).
+type EdgeLabel struct {
+	XMLName xml.Name `xml:"http://www.yworks.com/xml/graphml EdgeLabel"`
+	Label
+}
+
+// newLabel creates a label with yEd default style.
+func newLabel(text, model, pos string) Label {
+	return Label{
+		Text:          text,
+		FontFamily:    "Dialog",
+		FontSize:      12,
+		FontStyle:     FontPlain,
+		TextColor:     "#000000",
+		Alignment:     "center",
+		ModelName:     model,
+		ModelPosition: pos,
+		Space:         "preserve",
+	}
+}
+
+// NewNodeLabel creates a label with a given text, placed in the center of the node.
+func NewNodeLabel(text string) NodeLabel {
+	return NodeLabel{
+		XMLName: xml.Name{Space: Namespace, Local: "NodeLabel"},
+		Label:   newLabel(text, "internal", "c"),
+	}
+}
+
+// NewEdgeLabel creates a label with a given text, placed in the middle of the edge.
+func NewEdgeLabel(text string) EdgeLabel {
+	return EdgeLabel{
+		XMLName: xml.Name{Space: Namespace, Local: "EdgeLabel"},
+		Label:   newLabel(text, "centered", "center"),
+	}
+}
+
+// labelSpans returns all labels with a given element name from the active realizer.
+func labelSpans(d *graphml.Data, local string) []span {
+	if d == nil {
+		return nil
+	}
+	r, ok := activeRealizer(d.Data)
+	if !ok {
+		return nil
+	}
+	var out []span
+	for _, c := range children(d.Data, r) {
+		if name := nameOf(d.Data, c); name.Space == Namespace && name.Local == local {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// NodeLabels returns all labels of the node from its yFiles graphics.
+func NodeLabels(doc *graphml.Document, n *graphml.Node) []NodeLabel {
+	d := NodeGraphics(doc, n)
+	var out []NodeLabel
+	for _, s := range labelSpans(d, "NodeLabel") {
+		var l NodeLabel
+		if err := decodeSpan(d.Data, s, &l); err == nil {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// EdgeLabels returns all labels of the edge from its yFiles graphics.
+func EdgeLabels(doc *graphml.Document, e *graphml.Edge) []EdgeLabel {
+	d := EdgeGraphics(doc, e)
+	var out []EdgeLabel
+	for _, s := range labelSpans(d, "EdgeLabel") {
+		var l EdgeLabel
+		if err := decodeSpan(d.Data, s, &l); err == nil {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// SetNodeLabel sets the first label of the node, keeping all other yFiles graphics as-is.
+// If the node has no graphics, it is drawn as a rectangular ShapeNode.
+func SetNodeLabel(doc *graphml.Document, n *graphml.Node, l NodeLabel) error {
+	d := NodeGraphics(doc, n)
+	if d == nil {
+		return SetShapeNode(doc, n, &ShapeNode{Labels: []NodeLabel{l}, Shape: &Shape{Type: ShapeRectangle}})
+	}
+	return setChild(d, "NodeLabel", l, "Geometry", "Fill", "BorderStyle")
+}
+
+// SetEdgeLabel sets the first label of the edge, keeping all other yFiles graphics as-is.
+// If the edge has no graphics, it is drawn as a PolyLineEdge.
+func SetEdgeLabel(doc *graphml.Document, e *graphml.Edge, l EdgeLabel) error {
+	d := EdgeGraphics(doc, e)
+	if d == nil {
+		p := NewPolyLineEdge()
+		p.Labels = []EdgeLabel{l}
+		return SetPolyLineEdge(doc, e, p)
+	}
+	return setChild(d, "EdgeLabel", l, "Path", "LineStyle", "Arrows")
+}
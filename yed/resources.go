@@ -0,0 +1,194 @@
+package yed
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/graphml"
+)
+
+// ResourceImage is a type of resources that contain a Base64-encoded raster image.
+// Resources without a type usually contain SVG images.
+const ResourceImage = "java.awt.image.BufferedImage"
+
+// Resources is a list of resources shared by nodes of the document (y:Resources).
+type Resources struct {
+	XMLName xml.Name   `xml:"http://www.yworks.com/xml/graphml Resources"`
+	Items   []Resource `xml:"http://www.yworks.com/xml/graphml Resource"`
+}
+
+// Resource is a shared resource, for example an SVG icon or an image (y:Resource).
+type Resource struct {
+	ID      string `xml:"id,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Content string `xml:",chardata"`
+}
+
+// IsImage reports if the resource is a raster image.
+func (r *Resource) IsImage() bool {
+	return r.Type == ResourceImage
+}
+
+// Image decodes a raster image stored in the resource.
+func (r *Resource) Image() ([]byte, error) {
+	if !r.IsImage() {
+		return nil, fmt.Errorf("resource %q is not an image", r.ID)
+	}
+	s := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		}
+		return r
+	}, r.Content)
+	return base64.StdEncoding.DecodeString(s)
+}
+
+// Resource finds a resource with a given ID.
+func (rs *Resources) Resource(id string) (*Resource, bool) {
+	if rs == nil {
+		return nil, false
+	}
+	for i := range rs.Items {
+		if rs.Items[i].ID == id {
+			return &rs.Items[i], true
+		}
+	}
+	return nil, false
+}
+
+// resourcesData returns the data with yFiles resources of the document, or nil if there is none.
+func resourcesData(doc *graphml.Document) *graphml.Data {
+	k := findKey(doc, graphml.KindGraphML, TypeResources)
+	if k == nil {
+		return nil
+	}
+	return findData(doc.Data, k.ID)
+}
+
+// GetResources returns shared resources of the document, or nil if there are none.
+// The resources must be written back with SetResources after modifying them.
+func GetResources(doc *graphml.Document) *Resources {
+	d := resourcesData(doc)
+	if d == nil {
+		return nil
+	}
+	rs, _ := d.Value().(*Resources)
+	return rs
+}
+
+// SetResources sets shared resources of the document. It declares the resources key, if necessary.
+func SetResources(doc *graphml.Document, rs *Resources) error {
+	key := declareKey(doc, graphml.KindGraphML, TypeResources)
+	d := findData(doc.Data, key)
+	if d == nil {
+		doc.Data = append(doc.Data, graphml.Data{Key: key})
+		d = &doc.Data[len(doc.Data)-1]
+	}
+	return d.SetValue(Namespace, rs)
+}
+
+// AddResource adds a shared resource to the document and returns its ID.
+// A new numeric ID is assigned to the resource, if it has none.
+func AddResource(doc *graphml.Document, r Resource) (string, error) {
+	rs := GetResources(doc)
+	if rs == nil {
+		rs = &Resources{}
+	}
+	if r.ID == "" {
+		for i := len(rs.Items) + 1; ; i++ {
+			id := strconv.Itoa(i)
+			if _, ok := rs.Resource(id); !ok {
+				r.ID = id
+				break
+			}
+		}
+	} else if _, ok := rs.Resource(r.ID); ok {
+		return "", fmt.Errorf("duplicate resource: %q", r.ID)
+	}
+	rs.Items = append(rs.Items, r)
+	if err := SetResources(doc, rs); err != nil {
+		return "", err
+	}
+	return r.ID, nil
+}
+
+// AddImage adds a raster image (for example, PNG) as a shared resource and returns its ID.
+func AddImage(doc *graphml.Document, data []byte) (string, error) {
+	return AddResource(doc, Resource{Type: ResourceImage, Content: base64.StdEncoding.EncodeToString(data)})
+}
+
+// AddSVG adds an SVG image as a shared resource and returns its ID.
+func AddSVG(doc *graphml.Document, svg string) (string, error) {
+	return AddResource(doc, Resource{Content: svg})
+}
+
+// NodeResource returns a resource referenced by graphics of the node, for example an icon of SVGNode or ImageNode.
+func NodeResource(doc *graphml.Document, n *graphml.Node) (*Resource, bool) {
+	d := NodeGraphics(doc, n)
+	if d == nil {
+		return nil, false
+	}
+	for _, t := range d.Data {
+		st, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		for _, a := range st.Attr {
+			if a.Name.Local == "refid" {
+				return GetResources(doc).Resource(a.Value)
+			}
+		}
+	}
+	return nil, false
+}
+
+// imageNode is a yEd node drawn as a raster image (y:ImageNode).
+type imageNode struct {
+	XMLName  xml.Name    `xml:"http://www.yworks.com/xml/graphml ImageNode"`
+	Geometry *Geometry   `xml:"http://www.yworks.com/xml/graphml Geometry"`
+	Labels   []NodeLabel `xml:"http://www.yworks.com/xml/graphml NodeLabel"`
+	Image    struct {
+		Alpha bool   `xml:"alphaImage,attr"`
+		RefID string `xml:"refid,attr"`
+	} `xml:"http://www.yworks.com/xml/graphml Image"`
+}
+
+// svgNode is a yEd node drawn as an SVG image (y:SVGNode).
+type svgNode struct {
+	XMLName  xml.Name    `xml:"http://www.yworks.com/xml/graphml SVGNode"`
+	Geometry *Geometry   `xml:"http://www.yworks.com/xml/graphml Geometry"`
+	Labels   []NodeLabel `xml:"http://www.yworks.com/xml/graphml NodeLabel"`
+	Model    struct {
+		BoundsPolicy int `xml:"svgBoundsPolicy,attr"`
+		Content      struct {
+			RefID string `xml:"refid,attr"`
+		} `xml:"http://www.yworks.com/xml/graphml SVGContent"`
+	} `xml:"http://www.yworks.com/xml/graphml SVGModel"`
+}
+
+// SetNodeResource draws the node as an image from a shared resource with a given ID.
+// Geometry and labels of the node are kept, other graphics are replaced.
+func SetNodeResource(doc *graphml.Document, n *graphml.Node, id string) error {
+	r, ok := GetResources(doc).Resource(id)
+	if !ok {
+		return fmt.Errorf("resource not found: %q", id)
+	}
+	var geom *Geometry
+	if g, ok := GetGeometry(doc, n); ok {
+		geom = &g
+	}
+	labels := NodeLabels(doc, n)
+	if r.IsImage() {
+		v := &imageNode{Geometry: geom, Labels: labels}
+		v.Image.Alpha = true
+		v.Image.RefID = id
+		return setNodeGraphics(doc, n, v)
+	}
+	v := &svgNode{Geometry: geom, Labels: labels}
+	v.Model.Content.RefID = id
+	return setNodeGraphics(doc, n, v)
+}
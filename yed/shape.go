@@ -0,0 +1,86 @@
+package yed
+
+import (
+	"encoding/xml"
+
+	"github.com/dennwc/graphml"
+)
+
+// ShapeType is a shape of the ShapeNode.
+type ShapeType string
+
+// Shapes supported by yEd.
+const (
+	ShapeRectangle      = ShapeType("rectangle")
+	ShapeRoundRectangle = ShapeType("roundrectangle")
+	ShapeEllipse        = ShapeType("ellipse")
+	ShapeParallelogram  = ShapeType("parallelogram")
+	ShapeHexagon        = ShapeType("hexagon")
+	ShapeOctagon        = ShapeType("octagon")
+	ShapeTriangle       = ShapeType("triangle")
+	ShapeDiamond        = ShapeType("diamond")
+	ShapeTrapezoid      = ShapeType("trapezoid")
+	ShapeTrapezoid2     = ShapeType("trapezoid2")
+	ShapeRectangle3D    = ShapeType("rectangle3d")
+)
+
+// LineType is a style of lines and borders.
+type LineType string
+
+// Line styles supported by yEd.
+const (
+	LineSolid      = LineType("line")
+	LineDashed     = LineType("dashed")
+	LineDotted     = LineType("dotted")
+	LineDashDotted = LineType("dashed_dotted")
+)
+
+// ShapeNode is a yEd node drawn as a simple geometric shape (y:ShapeNode).
+type ShapeNode struct {
+	XMLName     xml.Name     `xml:"http://www.yworks.com/xml/graphml ShapeNode"`
+	Geometry    *Geometry    `xml:"http://www.yworks.com/xml/graphml Geometry"`
+	Fill        *Fill        `xml:"http://www.yworks.com/xml/graphml Fill"`
+	BorderStyle *BorderStyle `xml:"http://www.yworks.com/xml/graphml BorderStyle"`
+	Labels      []NodeLabel  `xml:"http://www.yworks.com/xml/graphml NodeLabel"`
+	Shape       *Shape       `xml:"http://www.yworks.com/xml/graphml Shape"`
+	// Extra contains all other elements of the node.
+	Extra []Element `xml:",any"`
+}
+
+// Fill is a background of a node. Colors are in #RRGGBB or #RRGGBBAA form.
+type Fill struct {
+	Color string `xml:"color,attr,omitempty"`
+	// Color2 is a second color of a gradient.
+	Color2      string `xml:"color2,attr,omitempty"`
+	Transparent bool   `xml:"transparent,attr"`
+}
+
+// BorderStyle is a style of a node border.
+type BorderStyle struct {
+	Color string   `xml:"color,attr,omitempty"`
+	Type  LineType `xml:"type,attr,omitempty"`
+	Width float64  `xml:"width,attr"`
+	// HasColor is set to false for nodes without a border.
+	HasColor *bool `xml:"hasColor,attr,omitempty"`
+	Raised   bool  `xml:"raised,attr"`
+}
+
+// Shape sets a shape of the ShapeNode.
+type Shape struct {
+	Type ShapeType `xml:"type,attr"`
+}
+
+// GetShapeNode returns yEd graphics of the node, if it is drawn as a ShapeNode.
+func GetShapeNode(doc *graphml.Document, n *graphml.Node) (*ShapeNode, bool) {
+	d := NodeGraphics(doc, n)
+	if d == nil {
+		return nil, false
+	}
+	s, ok := d.Value().(*ShapeNode)
+	return s, ok
+}
+
+// SetShapeNode sets yEd graphics of the node to a ShapeNode. It declares the node graphics key, if necessary.
+func SetShapeNode(doc *graphml.Document, n *graphml.Node, s *ShapeNode) error {
+	return setNodeGraphics(doc, n, s)
+}
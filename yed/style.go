@@ -0,0 +1,139 @@
+package yed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+// RGB formats a color in #RRGGBB form used by yEd.
+func RGB(r, g, b uint8) string {
+	return fmt.Sprintf("#%02X%02X%02X", r, g, b)
+}
+
+// nodeGraphics returns graphics of the node, drawing it as a rectangular ShapeNode if it has none.
+func nodeGraphics(doc *graphml.Document, n *graphml.Node) (*graphml.Data, error) {
+	if d := NodeGraphics(doc, n); d != nil {
+		return d, nil
+	}
+	if err := SetShapeNode(doc, n, &ShapeNode{Shape: &Shape{Type: ShapeRectangle}}); err != nil {
+		return nil, err
+	}
+	return NodeGraphics(doc, n), nil
+}
+
+// edgeGraphics returns graphics of the edge, drawing it as a PolyLineEdge if it has none.
+func edgeGraphics(doc *graphml.Document, e *graphml.Edge) (*graphml.Data, error) {
+	if d := EdgeGraphics(doc, e); d != nil {
+		return d, nil
+	}
+	if err := SetPolyLineEdge(doc, e, NewPolyLineEdge()); err != nil {
+		return nil, err
+	}
+	return EdgeGraphics(doc, e), nil
+}
+
+// fillElement is a standalone y:Fill element.
+type fillElement struct {
+	XMLName xml.Name `xml:"http://www.yworks.com/xml/graphml Fill"`
+	Fill
+}
+
+// borderElement is a standalone y:BorderStyle element.
+type borderElement struct {
+	XMLName xml.Name `xml:"http://www.yworks.com/xml/graphml BorderStyle"`
+	BorderStyle
+}
+
+// shapeElement is a standalone y:Shape element.
+type shapeElement struct {
+	XMLName xml.Name `xml:"http://www.yworks.com/xml/graphml Shape"`
+	Shape
+}
+
+// lineStyleElement is a standalone y:LineStyle element.
+type lineStyleElement struct {
+	XMLName xml.Name `xml:"http://www.yworks.com/xml/graphml LineStyle"`
+	LineStyle
+}
+
+// arrowsElement is a standalone y:Arrows element.
+type arrowsElement struct {
+	XMLName xml.Name `xml:"http://www.yworks.com/xml/graphml Arrows"`
+	Arrows
+}
+
+// SetFillColor sets a background color of the node. Other graphics of the node are kept as-is.
+func SetFillColor(doc *graphml.Document, n *graphml.Node, color string) error {
+	d, err := nodeGraphics(doc, n)
+	if err != nil {
+		return err
+	}
+	return updateChild(d, "Fill", func(st *xml.StartElement) {
+		setAttr(st, "color", color)
+		setAttr(st, "transparent", "false")
+	}, &fillElement{Fill: Fill{Color: color}}, "Geometry")
+}
+
+// SetBorderStyle sets a style of the node border. Other graphics of the node are kept as-is.
+func SetBorderStyle(doc *graphml.Document, n *graphml.Node, b BorderStyle) error {
+	d, err := nodeGraphics(doc, n)
+	if err != nil {
+		return err
+	}
+	return setChild(d, "BorderStyle", &borderElement{BorderStyle: b}, "Geometry", "Fill")
+}
+
+// SetShape sets a shape of the node. It returns an error if the node is drawn as something other than ShapeNode.
+func SetShape(doc *graphml.Document, n *graphml.Node, shape ShapeType) error {
+	d, err := nodeGraphics(doc, n)
+	if err != nil {
+		return err
+	}
+	if _, ok := d.Value().(*ShapeNode); !ok {
+		return fmt.Errorf("node %q is not a ShapeNode", n.ID)
+	}
+	return setChild(d, "Shape", &shapeElement{Shape: Shape{Type: shape}}, "Geometry", "Fill", "BorderStyle", "NodeLabel")
+}
+
+// SetEdgeColor sets a color of the edge line. Other graphics of the edge are kept as-is.
+func SetEdgeColor(doc *graphml.Document, e *graphml.Edge, color string) error {
+	d, err := edgeGraphics(doc, e)
+	if err != nil {
+		return err
+	}
+	return updateChild(d, "LineStyle", func(st *xml.StartElement) {
+		setAttr(st, "color", color)
+	}, &lineStyleElement{LineStyle: LineStyle{Color: color, Type: LineSolid, Width: 1}}, "Path")
+}
+
+// SetEdgeWidth sets a width of the edge line. Other graphics of the edge are kept as-is.
+func SetEdgeWidth(doc *graphml.Document, e *graphml.Edge, width float64) error {
+	d, err := edgeGraphics(doc, e)
+	if err != nil {
+		return err
+	}
+	return updateChild(d, "LineStyle", func(st *xml.StartElement) {
+		setAttr(st, "width", strconv.FormatFloat(width, 'f', -1, 64))
+	}, &lineStyleElement{LineStyle: LineStyle{Color: "#000000", Type: LineSolid, Width: width}}, "Path")
+}
+
+// SetLineStyle sets a style of the edge line. Other graphics of the edge are kept as-is.
+func SetLineStyle(doc *graphml.Document, e *graphml.Edge, s LineStyle) error {
+	d, err := edgeGraphics(doc, e)
+	if err != nil {
+		return err
+	}
+	return setChild(d, "LineStyle", &lineStyleElement{LineStyle: s}, "Path")
+}
+
+// SetArrows sets arrowheads of the edge. Other graphics of the edge are kept as-is.
+func SetArrows(doc *graphml.Document, e *graphml.Edge, source, target ArrowType) error {
+	d, err := edgeGraphics(doc, e)
+	if err != nil {
+		return err
+	}
+	return setChild(d, "Arrows", &arrowsElement{Arrows: Arrows{Source: source, Target: target}}, "Path", "LineStyle")
+}
@@ -0,0 +1,225 @@
+package yed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+// span is a range of tokens of an XML element, including its start and end tokens.
+type span struct {
+	start, end int
+}
+
+// elementEnd returns an index of the end token of the element that starts at a given index.
+func elementEnd(toks []xml.Token, start int) int {
+	depth := 0
+	for i := start; i < len(toks); i++ {
+		switch toks[i].(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(toks) - 1
+}
+
+// children returns spans of all direct child elements of the element. A zero span means the top level.
+func children(toks []xml.Token, parent span) []span {
+	from, to := 0, len(toks)
+	if parent != (span{}) {
+		from, to = parent.start+1, parent.end
+	}
+	var out []span
+	for i := from; i < to; i++ {
+		if _, ok := toks[i].(xml.StartElement); ok {
+			end := elementEnd(toks, i)
+			out = append(out, span{start: i, end: end})
+			i = end
+		}
+	}
+	return out
+}
+
+// nameOf returns a name of the element starting at the span.
+func nameOf(toks []xml.Token, s span) xml.Name {
+	return toks[s.start].(xml.StartElement).Name
+}
+
+// child returns the first direct child of the element with a given local name in yFiles namespace.
+func child(toks []xml.Token, parent span, local string) (span, bool) {
+	for _, c := range children(toks, parent) {
+		if name := nameOf(toks, c); name.Space == Namespace && name.Local == local {
+			return c, true
+		}
+	}
+	return span{}, false
+}
+
+// activeRealizer returns the element that defines the graphics of a node or an edge.
+// For group nodes it's the active state of the group.
+func activeRealizer(toks []xml.Token) (span, bool) {
+	list := children(toks, span{})
+	if len(list) == 0 {
+		return span{}, false
+	}
+	r := list[0]
+	if nameOf(toks, r).Local != "ProxyAutoBoundsNode" {
+		return r, true
+	}
+	states, ok := child(toks, r, "Realizers")
+	if !ok {
+		return r, true
+	}
+	list = children(toks, states)
+	if len(list) == 0 {
+		return r, true
+	}
+	active := 0
+	for _, a := range toks[states.start].(xml.StartElement).Attr {
+		if a.Name.Local == "active" {
+			active, _ = strconv.Atoi(a.Value)
+		}
+	}
+	if active < 0 || active >= len(list) {
+		active = 0
+	}
+	return list[active], true
+}
+
+// dropNS removes namespace declarations from attributes.
+func dropNS(attrs []xml.Attr) []xml.Attr {
+	var out []xml.Attr
+	for _, a := range attrs {
+		if a.Name.Space != "xmlns" && !(a.Name.Space == "" && a.Name.Local == "xmlns") {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// nsFilter removes namespace declarations from elements, since the encoder adds them automatically.
+// Otherwise, they would be decoded into attributes of typed values and declared twice when encoding.
+type nsFilter struct {
+	r xml.TokenReader
+}
+
+func (f nsFilter) Token() (xml.Token, error) {
+	t, err := f.r.Token()
+	if st, ok := t.(xml.StartElement); ok {
+		st.Attr = dropNS(st.Attr)
+		t = st
+	}
+	return t, err
+}
+
+// decodeSpan decodes the element from tokens into a typed value.
+func decodeSpan(toks []xml.Token, s span, v interface{}) error {
+	d := &graphml.Data{Data: toks[s.start : s.end+1]}
+	return xml.NewTokenDecoder(nsFilter{d.Reader()}).Decode(v)
+}
+
+// encodeTokens serializes a typed value to XML tokens.
+func encodeTokens(v interface{}) ([]xml.Token, error) {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	var toks []xml.Token
+	dec := xml.NewDecoder(&buf)
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			return toks, nil
+		} else if err != nil {
+			return nil, err
+		}
+		toks = append(toks, xml.CopyToken(t))
+	}
+}
+
+// splice replaces tokens in range [from, to) with a typed value serialized to XML.
+func splice(d *graphml.Data, from, to int, v interface{}) error {
+	sub, err := encodeTokens(v)
+	if err != nil {
+		return err
+	}
+	toks := make([]xml.Token, 0, len(d.Data)-(to-from)+len(sub))
+	toks = append(toks, d.Data[:from]...)
+	toks = append(toks, sub...)
+	toks = append(toks, d.Data[to:]...)
+	d.Data = toks
+	return d.DecodeValue()
+}
+
+// findChild finds the first child of the active realizer with a given local name. If there is no such child,
+// it returns a position for inserting a new one after the last of preceding elements.
+func findChild(d *graphml.Data, local string, after []string) (span, int, bool) {
+	r, ok := activeRealizer(d.Data)
+	if !ok {
+		return span{}, len(d.Data), false
+	}
+	pos := r.start + 1
+	for _, c := range children(d.Data, r) {
+		name := nameOf(d.Data, c)
+		if name.Space != Namespace {
+			continue
+		}
+		if name.Local == local {
+			return c, 0, true
+		}
+		for _, a := range after {
+			if name.Local == a {
+				pos = c.end + 1
+				break
+			}
+		}
+	}
+	return span{}, pos, false
+}
+
+// setChild replaces the first child of the active realizer with a given local name,
+// or inserts a new one after the last of preceding elements.
+func setChild(d *graphml.Data, local string, v interface{}, after ...string) error {
+	c, pos, ok := findChild(d, local, after)
+	if ok {
+		return splice(d, c.start, c.end+1, v)
+	}
+	return splice(d, pos, pos, v)
+}
+
+// updateChild modifies the start token of the first child of the active realizer with a given local name.
+// If there is no such child, a new one is inserted after the last of preceding elements.
+func updateChild(d *graphml.Data, local string, update func(st *xml.StartElement), v interface{}, after ...string) error {
+	c, pos, ok := findChild(d, local, after)
+	if !ok {
+		return splice(d, pos, pos, v)
+	}
+	st := d.Data[c.start].(xml.StartElement)
+	st.Attr = append([]xml.Attr{}, st.Attr...)
+	update(&st)
+	d.Data[c.start] = st
+	return d.DecodeValue()
+}
+
+// setAttr sets a value of the attribute, adding it if necessary.
+func setAttr(st *xml.StartElement, name, value string) {
+	for i, a := range st.Attr {
+		if a.Name.Space == "" && a.Name.Local == name {
+			st.Attr[i].Value = value
+			return
+		}
+	}
+	st.Attr = append(st.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
@@ -0,0 +1,159 @@
+// Package yed implements typed access to yEd (yFiles) extensions of GraphML.
+//
+// Importing the package registers a graphml.Codec for the yFiles namespace, thus node and edge graphics
+// of decoded documents are available as typed values via graphml.Data.Value. The codec never fails:
+// elements it doesn't know and elements with invalid content (for example, malformed numbers) have no typed
+// value and are only available as raw XML tokens.
+package yed
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"github.com/dennwc/graphml"
+)
+
+// Namespace is an XML namespace of yFiles GraphML extensions.
+const Namespace = "http://www.yworks.com/xml/graphml"
+
+// Types of yFiles data, as set in Key.YFilesType.
+const (
+	TypeNodeGraphics = "nodegraphics"
+	TypeEdgeGraphics = "edgegraphics"
+	TypeResources    = "resources"
+)
+
+func init() {
+	graphml.RegisterCodec(Namespace, codec{})
+}
+
+// codec decodes yFiles elements to typed values.
+type codec struct{}
+
+func (codec) DecodeData(dec *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	var v interface{}
+	switch start.Name.Local {
+	case "ShapeNode":
+		v = new(ShapeNode)
+	case "ProxyAutoBoundsNode":
+		v = new(ProxyAutoBoundsNode)
+	case "PolyLineEdge":
+		v = new(PolyLineEdge)
+	case "Resources":
+		v = new(Resources)
+	default:
+		return nil, dec.Skip()
+	}
+	toks := []xml.Token{start}
+	for depth := 1; depth > 0; {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		toks = append(toks, xml.CopyToken(t))
+	}
+	if err := decodeSpan(toks, span{end: len(toks) - 1}, v); err != nil {
+		// invalid content is kept as raw XML tokens, the same as unknown elements
+		return nil, nil
+	}
+	return v, nil
+}
+
+func (codec) EncodeData(enc *xml.Encoder, v interface{}) error {
+	return enc.Encode(v)
+}
+
+// Element is a yFiles XML element that has no typed representation in this package.
+// It is kept as-is, so that decoding and encoding a typed value does not lose information.
+type Element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []Element  `xml:",any"`
+}
+
+// findKey finds a key with a given yFiles data type declared for a given kind of elements.
+func findKey(doc *graphml.Document, kind graphml.Kind, typ string) *graphml.Key {
+	k, _ := doc.KeyByYFilesType(kind, typ)
+	return k
+}
+
+// uniqueKeyID generates an ID for a new key in the same form as yEd does.
+func uniqueKeyID(doc *graphml.Document) string {
+	used := make(map[string]struct{}, len(doc.Keys))
+	for _, k := range doc.Keys {
+		used[k.ID] = struct{}{}
+	}
+	for i := len(doc.Keys); ; i++ {
+		id := "d" + strconv.Itoa(i)
+		if _, ok := used[id]; !ok {
+			return id
+		}
+	}
+}
+
+// declareKey returns an ID of a key with a given yFiles data type, declaring the key if necessary.
+func declareKey(doc *graphml.Document, kind graphml.Kind, typ string) string {
+	if k := findKey(doc, kind, typ); k != nil {
+		return k.ID
+	}
+	id := uniqueKeyID(doc)
+	doc.Keys = append(doc.Keys, yfilesKey(kind, id, typ))
+	return id
+}
+
+// findData returns the data of an element for a given key, or nil if there is none.
+func findData(data []graphml.Data, key string) *graphml.Data {
+	for i := range data {
+		if data[i].Key == key {
+			return &data[i]
+		}
+	}
+	return nil
+}
+
+// NodeGraphics returns the data with yFiles graphics of the node, or nil if the node has none.
+func NodeGraphics(doc *graphml.Document, n *graphml.Node) *graphml.Data {
+	k := findKey(doc, graphml.KindNode, TypeNodeGraphics)
+	if k == nil {
+		return nil
+	}
+	return findData(n.Data, k.ID)
+}
+
+// EdgeGraphics returns the data with yFiles graphics of the edge, or nil if the edge has none.
+func EdgeGraphics(doc *graphml.Document, e *graphml.Edge) *graphml.Data {
+	k := findKey(doc, graphml.KindEdge, TypeEdgeGraphics)
+	if k == nil {
+		return nil
+	}
+	return findData(e.Data, k.ID)
+}
+
+// setNodeGraphics sets a typed value of the node graphics, declaring the key and adding the data if necessary.
+func setNodeGraphics(doc *graphml.Document, n *graphml.Node, v interface{}) error {
+	key := declareKey(doc, graphml.KindNode, TypeNodeGraphics)
+	d := findData(n.Data, key)
+	if d == nil {
+		n.Data = append(n.Data, graphml.Data{Key: key})
+		d = &n.Data[len(n.Data)-1]
+	}
+	return d.SetValue(Namespace, v)
+}
+
+// setEdgeGraphics sets a typed value of the edge graphics, declaring the key and adding the data if necessary.
+func setEdgeGraphics(doc *graphml.Document, e *graphml.Edge, v interface{}) error {
+	key := declareKey(doc, graphml.KindEdge, TypeEdgeGraphics)
+	d := findData(e.Data, key)
+	if d == nil {
+		e.Data = append(e.Data, graphml.Data{Key: key})
+		d = &e.Data[len(e.Data)-1]
+	}
+	return d.SetValue(Namespace, v)
+}
@@ -0,0 +1,387 @@
+package yed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dennwc/graphml"
+)
+
+func decodeTree(t testing.TB) *graphml.Document {
+	doc, err := graphml.DecodeFS(os.DirFS("../data"), "yed_tree"+graphml.ExtGzip)
+	require.NoError(t, err)
+	return doc
+}
+
+func TestShapeNode(t *testing.T) {
+	doc := decodeTree(t)
+	n := &doc.Graphs[0].Nodes[0]
+	s, ok := GetShapeNode(doc, n)
+	require.True(t, ok)
+	require.Equal(t, &Geometry{X: 417.71683673469386, Y: 0, Width: 30, Height: 30}, s.Geometry)
+	require.Equal(t, &Fill{Color: "#FFCC00"}, s.Fill)
+	require.Equal(t, &BorderStyle{Color: "#000000", Type: LineSolid, Width: 1}, s.BorderStyle)
+	require.Equal(t, ShapeRectangle, s.Shape.Type)
+	require.Len(t, s.Labels, 1)
+	require.Equal(t, "ROOT", s.Labels[0].Text)
+	require.Empty(t, s.Extra)
+
+	s.Shape.Type = ShapeEllipse
+	s.Fill.Color = "#FF0000"
+	require.NoError(t, SetShapeNode(doc, n, s))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.Encode(buf, doc))
+	doc, err := graphml.Decode(buf)
+	require.NoError(t, err)
+	s2, ok := GetShapeNode(doc, &doc.Graphs[0].Nodes[0])
+	require.True(t, ok)
+	require.Equal(t, s, s2)
+
+	doc = &graphml.Document{Graphs: []graphml.Graph{{Nodes: []graphml.Node{{}}}}}
+	n = &doc.Graphs[0].Nodes[0]
+	_, ok = GetShapeNode(doc, n)
+	require.False(t, ok)
+	require.NoError(t, SetShapeNode(doc, n, &ShapeNode{Shape: &Shape{Type: ShapeDiamond}}))
+	require.Len(t, doc.Keys, 1)
+	s, ok = GetShapeNode(doc, n)
+	require.True(t, ok)
+	require.Equal(t, ShapeDiamond, s.Shape.Type)
+}
+
+func TestInvalidShapeNode(t *testing.T) {
+	const src = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:y="http://www.yworks.com/xml/graphml">
+<key id="d0" for="node" yfiles.type="nodegraphics"/>
+<graph id="G" edgedefault="directed"><node id="n0"><data key="d0"><y:ShapeNode><y:BorderStyle width="1,5"/></y:ShapeNode></data></node></graph>
+</graphml>`
+	var warns []graphml.Warning
+	doc, err := graphml.DecodeWith(strings.NewReader(src), &graphml.Options{Warn: func(w graphml.Warning) { warns = append(warns, w) }})
+	require.NoError(t, err)
+	require.Empty(t, warns)
+	n := &doc.Graphs[0].Nodes[0]
+	require.Nil(t, NodeGraphics(doc, n).Value())
+	_, ok := GetShapeNode(doc, n)
+	require.False(t, ok)
+}
+
+func TestGeometry(t *testing.T) {
+	doc := decodeTree(t)
+	n := &doc.Graphs[0].Nodes[1]
+	g, ok := GetGeometry(doc, n)
+	require.True(t, ok)
+	require.Equal(t, Geometry{X: 541.5051020408164, Y: 70, Width: 30, Height: 30}, g)
+
+	g = Geometry{X: 10, Y: -20.5, Width: 40, Height: 25}
+	require.NoError(t, SetGeometry(doc, n, g))
+	s, ok := GetShapeNode(doc, n)
+	require.True(t, ok)
+	require.Equal(t, &g, s.Geometry)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.Encode(buf, doc))
+	doc, err := graphml.Decode(buf)
+	require.NoError(t, err)
+	got, ok := GetGeometry(doc, &doc.Graphs[0].Nodes[1])
+	require.True(t, ok)
+	require.Equal(t, g, got)
+
+	n = &graphml.Node{}
+	_, ok = GetGeometry(doc, n)
+	require.False(t, ok)
+	require.NoError(t, SetGeometry(doc, n, g))
+	got, ok = GetGeometry(doc, n)
+	require.True(t, ok)
+	require.Equal(t, g, got)
+}
+
+func TestLabels(t *testing.T) {
+	doc := decodeTree(t)
+	n := &doc.Graphs[0].Nodes[0]
+	labels := NodeLabels(doc, n)
+	require.Len(t, labels, 1)
+	l := labels[0]
+	require.Equal(t, "ROOT", l.Text)
+	require.Equal(t, "Dialog", l.FontFamily)
+	require.Equal(t, 12, l.FontSize)
+	require.Equal(t, FontPlain, l.FontStyle)
+	require.Equal(t, "custom", l.ModelName)
+	require.Len(t, l.Extra, 2)
+
+	l.Text = "root node"
+	l.FontStyle = FontBold
+	require.NoError(t, SetNodeLabel(doc, n, l))
+	s, ok := GetShapeNode(doc, n)
+	require.True(t, ok)
+	require.Equal(t, "root node", s.Labels[0].Text)
+	require.Equal(t, ShapeRectangle, s.Shape.Type)
+
+	e := &doc.Graphs[0].Edges[0]
+	require.Empty(t, EdgeLabels(doc, e))
+	require.NoError(t, SetEdgeLabel(doc, e, NewEdgeLabel("link")))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.Encode(buf, doc))
+	doc, err := graphml.Decode(buf)
+	require.NoError(t, err)
+	require.Equal(t, []NodeLabel{l}, NodeLabels(doc, &doc.Graphs[0].Nodes[0]))
+	require.Equal(t, []EdgeLabel{NewEdgeLabel("link")}, EdgeLabels(doc, &doc.Graphs[0].Edges[0]))
+
+	doc = &graphml.Document{Graphs: []graphml.Graph{{
+		Nodes: []graphml.Node{{}},
+		Edges: []graphml.Edge{{}},
+	}}}
+	require.NoError(t, SetNodeLabel(doc, &doc.Graphs[0].Nodes[0], NewNodeLabel("a")))
+	require.NoError(t, SetEdgeLabel(doc, &doc.Graphs[0].Edges[0], NewEdgeLabel("b")))
+	require.Equal(t, "a", NodeLabels(doc, &doc.Graphs[0].Nodes[0])[0].Text)
+	require.Equal(t, "b", EdgeLabels(doc, &doc.Graphs[0].Edges[0])[0].Text)
+}
+
+func TestGroups(t *testing.T) {
+	doc := &graphml.Document{Attrs: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: graphml.Namespace}}}
+	doc.Graphs = []graphml.Graph{{
+		Nodes: []graphml.Node{
+			{ExtObject: graphml.ExtObject{Object: graphml.Object{ID: "n0"}}, Graphs: []graphml.Graph{{
+				Nodes: []graphml.Node{{ExtObject: graphml.ExtObject{Object: graphml.Object{ID: "n0::n0"}}}},
+			}}},
+			{ExtObject: graphml.ExtObject{Object: graphml.Object{ID: "n1"}}},
+		},
+	}}
+	require.NoError(t, SetNodeLabel(doc, &doc.Graphs[0].Nodes[0], NewNodeLabel("Group")))
+	require.NoError(t, NormalizeGroups(doc))
+	require.NoError(t, MakeGroup(doc, &doc.Graphs[0].Nodes[1], "Empty"))
+	require.NoError(t, SetGeometry(doc, &doc.Graphs[0].Nodes[1], Geometry{Width: 100, Height: 80}))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.Encode(buf, doc))
+	doc, err := graphml.Decode(buf)
+	require.NoError(t, err)
+
+	groups := Groups(doc, &doc.Graphs[0])
+	require.Len(t, groups, 2)
+	require.Equal(t, "Group", groups[0].Label)
+	require.False(t, groups[0].Closed)
+	require.Len(t, groups[0].Graph.Nodes, 1)
+	require.Equal(t, "Empty", groups[1].Label)
+	require.Equal(t, "n1:", groups[1].Graph.ID)
+
+	p, ok := NodeGraphics(doc, groups[1].Node).Value().(*ProxyAutoBoundsNode)
+	require.True(t, ok)
+	require.Len(t, p.Realizers.Nodes, 2)
+	require.Equal(t, &Geometry{Width: 100, Height: 80}, p.Realizers.Nodes[0].Geometry)
+	require.True(t, p.Realizers.Nodes[1].State.Closed)
+}
+
+func TestPolyLineEdge(t *testing.T) {
+	doc := decodeTree(t)
+	e := &doc.Graphs[0].Edges[0]
+	p, ok := GetPolyLineEdge(doc, e)
+	require.True(t, ok)
+	exp := NewPolyLineEdge()
+	exp.XMLName = p.XMLName
+	require.Equal(t, exp, p)
+
+	p.Path.Points = []Point{{X: 10, Y: 20}, {X: 30, Y: 20}}
+	p.Arrows.Source = ArrowDiamond
+	p.LineStyle.Type = LineDashed
+	require.NoError(t, SetPolyLineEdge(doc, e, p))
+	require.NoError(t, SetEdgeLabel(doc, e, NewEdgeLabel("link")))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.Encode(buf, doc))
+	doc, err := graphml.Decode(buf)
+	require.NoError(t, err)
+	p2, ok := GetPolyLineEdge(doc, &doc.Graphs[0].Edges[0])
+	require.True(t, ok)
+	require.Equal(t, p.Path, p2.Path)
+	require.Equal(t, p.Arrows, p2.Arrows)
+	require.Equal(t, LineDashed, p2.LineStyle.Type)
+	require.Len(t, p2.Labels, 1)
+	require.Equal(t, "link", p2.Labels[0].Text)
+}
+
+func TestResources(t *testing.T) {
+	doc := decodeTree(t)
+	rs := GetResources(doc)
+	require.NotNil(t, rs)
+	require.Empty(t, rs.Items)
+
+	img := []byte("\x89PNG\r\n\x1a\nfake")
+	id1, err := AddImage(doc, img)
+	require.NoError(t, err)
+	require.Equal(t, "1", id1)
+	const svg = `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><rect width="1" height="1"/></svg>`
+	id2, err := AddSVG(doc, svg)
+	require.NoError(t, err)
+	require.Equal(t, "2", id2)
+	_, err = AddResource(doc, Resource{ID: id1})
+	require.Error(t, err)
+
+	n := &doc.Graphs[0].Nodes[0]
+	geom, _ := GetGeometry(doc, n)
+	require.NoError(t, SetNodeResource(doc, n, id2))
+	require.NoError(t, SetNodeResource(doc, &doc.Graphs[0].Nodes[1], id1))
+	require.Error(t, SetNodeResource(doc, n, "3"))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.Encode(buf, doc))
+	doc, err = graphml.Decode(buf)
+	require.NoError(t, err)
+
+	rs = GetResources(doc)
+	require.Len(t, rs.Items, 2)
+	n = &doc.Graphs[0].Nodes[0]
+	r, ok := NodeResource(doc, n)
+	require.True(t, ok)
+	require.False(t, r.IsImage())
+	require.Equal(t, svg, r.Content)
+	got, ok := GetGeometry(doc, n)
+	require.True(t, ok)
+	require.Equal(t, geom, got)
+	require.Equal(t, "ROOT", NodeLabels(doc, n)[0].Text)
+
+	r, ok = NodeResource(doc, &doc.Graphs[0].Nodes[1])
+	require.True(t, ok)
+	data, err := r.Image()
+	require.NoError(t, err)
+	require.Equal(t, img, data)
+}
+
+func TestNewDocument(t *testing.T) {
+	doc := NewDocument()
+	g := &doc.Graphs[0]
+	g.Nodes = make([]graphml.Node, 2)
+	g.Nodes[0].ID, g.Nodes[1].ID = "n0", "n1"
+	g.Edges = make([]graphml.Edge, 1)
+	g.Edges[0].ID, g.Edges[0].Source, g.Edges[0].Target = "e0", "n0", "n1"
+	require.NoError(t, SetNodeLabel(doc, &g.Nodes[0], NewNodeLabel("a")))
+	require.NoError(t, SetEdgeLabel(doc, &g.Edges[0], NewEdgeLabel("b")))
+	require.Len(t, doc.Keys, 11)
+	require.Equal(t, KeyNodeGraphics, g.Nodes[0].Data[0].Key)
+	require.Equal(t, KeyEdgeGraphics, g.Edges[0].Data[0].Key)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.Encode(buf, doc))
+	require.Contains(t, buf.String(), ` xmlns:y="`+Namespace+`" `)
+	require.Contains(t, buf.String(), ` xsi:schemaLocation="`)
+
+	tree := decodeTree(t)
+	doc, err := graphml.Decode(buf)
+	require.NoError(t, err)
+	require.Equal(t, tree.Attrs, doc.Attrs)
+	require.Equal(t, tree.Keys, doc.Keys)
+	require.NotNil(t, GetResources(doc))
+	require.Equal(t, "a", NodeLabels(doc, &doc.Graphs[0].Nodes[0])[0].Text)
+}
+
+func TestStyle(t *testing.T) {
+	require.Equal(t, "#0A80FF", RGB(10, 128, 255))
+
+	doc := decodeTree(t)
+	n := &doc.Graphs[0].Nodes[0]
+	require.NoError(t, SetFillColor(doc, n, "#FF0000"))
+	require.NoError(t, SetBorderStyle(doc, n, BorderStyle{Color: "#00FF00", Type: LineDotted, Width: 2}))
+	require.NoError(t, SetShape(doc, n, ShapeHexagon))
+	s, ok := GetShapeNode(doc, n)
+	require.True(t, ok)
+	require.Equal(t, &Fill{Color: "#FF0000"}, s.Fill)
+	require.Equal(t, &BorderStyle{Color: "#00FF00", Type: LineDotted, Width: 2}, s.BorderStyle)
+	require.Equal(t, ShapeHexagon, s.Shape.Type)
+	require.Equal(t, "ROOT", s.Labels[0].Text)
+
+	e := &doc.Graphs[0].Edges[0]
+	require.NoError(t, SetEdgeColor(doc, e, "#0000FF"))
+	require.NoError(t, SetEdgeWidth(doc, e, 3))
+	require.NoError(t, SetArrows(doc, e, ArrowNone, ArrowDelta))
+	p, ok := GetPolyLineEdge(doc, e)
+	require.True(t, ok)
+	require.Equal(t, &LineStyle{Color: "#0000FF", Type: LineSolid, Width: 3}, p.LineStyle)
+	require.Equal(t, &Arrows{Source: ArrowNone, Target: ArrowDelta}, p.Arrows)
+
+	n = &graphml.Node{}
+	require.NoError(t, SetFillColor(doc, n, "#FFFFFF"))
+	s, ok = GetShapeNode(doc, n)
+	require.True(t, ok)
+	require.Equal(t, &Fill{Color: "#FFFFFF"}, s.Fill)
+
+	require.NoError(t, MakeGroup(doc, n, "group"))
+	require.Error(t, SetShape(doc, n, ShapeEllipse))
+}
+
+func TestURLAndDescription(t *testing.T) {
+	doc := decodeTree(t)
+	n := &doc.Graphs[0].Nodes[0]
+	_, ok := NodeURL(doc, n)
+	require.False(t, ok)
+	desc, ok := NodeDescription(doc, n)
+	require.True(t, ok)
+	require.Equal(t, "", desc)
+
+	SetNodeURL(doc, n, "https://example.com/root")
+	SetNodeDescription(doc, n, "root node")
+	e := &doc.Graphs[0].Edges[0]
+	SetEdgeURL(doc, e, "https://example.com/e0")
+	SetEdgeDescription(doc, e, "first edge")
+	require.Len(t, doc.Keys, 11)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.Encode(buf, doc))
+	doc, err := graphml.Decode(buf)
+	require.NoError(t, err)
+	n, e = &doc.Graphs[0].Nodes[0], &doc.Graphs[0].Edges[0]
+	url, _ := NodeURL(doc, n)
+	require.Equal(t, "https://example.com/root", url)
+	desc, _ = NodeDescription(doc, n)
+	require.Equal(t, "root node", desc)
+	url, _ = EdgeURL(doc, e)
+	require.Equal(t, "https://example.com/e0", url)
+	desc, _ = EdgeDescription(doc, e)
+	require.Equal(t, "first edge", desc)
+
+	doc = &graphml.Document{}
+	SetNodeURL(doc, &graphml.Node{}, "x")
+	require.Equal(t, []graphml.Key{graphml.NewKey(graphml.KindNode, "d0", AttrURL, "string")}, doc.Keys)
+}
+
+func TestExtractLabels(t *testing.T) {
+	doc := decodeTree(t)
+	require.NoError(t, SetEdgeLabel(doc, &doc.Graphs[0].Edges[0], NewEdgeLabel("link")))
+	ExtractLabels(doc, &ExtractOptions{StripGraphics: true})
+
+	for _, k := range doc.Keys {
+		require.Empty(t, k.YFilesType)
+	}
+	require.Empty(t, doc.Data)
+	n := &doc.Graphs[0].Nodes[0]
+	require.Nil(t, NodeGraphics(doc, n))
+	label, ok := getText(doc, graphml.KindNode, n.Data, "label")
+	require.True(t, ok)
+	require.Equal(t, "ROOT", label)
+	label, ok = getText(doc, graphml.KindEdge, doc.Graphs[0].Edges[0].Data, "label")
+	require.True(t, ok)
+	require.Equal(t, "link", label)
+	_, ok = getText(doc, graphml.KindEdge, doc.Graphs[0].Edges[1].Data, "label")
+	require.False(t, ok)
+}
+
+func TestRoundTripBytes(t *testing.T) {
+	f, err := os.Open("../data/yed_tree" + graphml.ExtGzip)
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	orig, err := io.ReadAll(zr)
+	require.NoError(t, err)
+
+	doc, err := graphml.Decode(bytes.NewReader(orig))
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	require.NoError(t, graphml.EncodeWith(buf, doc, &graphml.Options{Profile: graphml.ProfileYEd}))
+	require.Equal(t, string(orig), buf.String())
+}